@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/draw"
+)
+
+var (
+	flag_title    = flag.String("title", "", `Title above bitmap images, "auto" for quantity name, unit and time`)
+	flag_legend   = flag.Bool("legend", false, "Color bar (scalar data) or color wheel (vector data) next to bitmap images")
+	flag_scalebar = flag.Bool("scalebar", false, "Scale bar below bitmap images, from the cell size")
+)
+
+// true if any of -title, -legend, -scalebar is set.
+func annotated() bool {
+	return *flag_title != "" || *flag_legend || *flag_scalebar
+}
+
+// renders f in the format given by ext (".png", ...), with annotations if requested.
+func render(f *data.Slice, info data.Meta, out io.Writer, ext string) {
+	if !annotated() {
+		draw.RenderFormat(out, f, *flag_min, *flag_max, *flag_arrows, ext, colormap...)
+		return
+	}
+	img := draw.AnnotatedImage(f, *flag_min, *flag_max, *flag_arrows, annotation(info), colormap...)
+	draw.EncodeFormat(out, img, ext)
+}
+
+func annotation(info data.Meta) draw.Annotation {
+	a := draw.Annotation{Title: *flag_title, Unit: info.Unit, Legend: *flag_legend}
+	if a.Title == "auto" {
+		a.Title = draw.AutoTitle(info)
+	}
+	if *flag_scalebar {
+		a.CellSize = info.CellSize
+	}
+	return a
+}
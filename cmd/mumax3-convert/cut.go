@@ -0,0 +1,163 @@
+package main
+
+// Line profiles and oblique plane cuts, in physical coordinates
+// measured from the corner of the input mesh (like xmin, ymin, zmin = 0 in OVF),
+// so -line is sampled before cropping or resizing, and -plane before cropping.
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/data"
+)
+
+var (
+	flag_line  = flag.String("line", "", "Profile along a line x1,y1,z1:x2,y2,z2:N (meters), CSV output")
+	flag_plane = flag.String("plane", "", "Resample the plane through corners x0,y0,z0:x1,y1,z1:x2,y2,z2 (meters) on NxM points, e.g. 0,0,1e-9:1e-6,0,1e-9:0,1e-6,5e-9:256x256")
+)
+
+// profile along -line: distance followed by the interpolated components, one line per point
+func dumpLine(f *data.Slice, info data.Meta, out io.Writer) {
+	p1, p2, n := parseLine(*flag_line)
+	length := dist(p1, p2)
+	for i := 0; i < n; i++ {
+		s := 0.
+		if n > 1 {
+			s = float64(i) / float64(n-1)
+		}
+		p := lerp(p1, p2, s)
+		fmt.Fprintf(out, *flag_format, s*length)
+		for _, v := range interpolate(f, info.CellSize, p) {
+			fmt.Fprintf(out, ", "+*flag_format, v)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// resamples f on the plane given by -plane, as a N x M x 1 slice.
+func plane(f *data.Slice, info *data.Meta) {
+	p0, p1, p2, N, M := parsePlane(*flag_plane)
+	out := data.NewSlice(f.NComp(), [3]int{N, M, 1})
+	for j := 0; j < M; j++ {
+		for i := 0; i < N; i++ {
+			// cell centers, like the mumax3 mesh
+			u, v := (float64(i)+0.5)/float64(N), (float64(j)+0.5)/float64(M)
+			var p [3]float64
+			for c := range p {
+				p[c] = p0[c] + u*(p1[c]-p0[c]) + v*(p2[c]-p0[c])
+			}
+			for c, val := range interpolate(f, info.CellSize, p) {
+				out.Set(c, i, j, 0, val)
+			}
+		}
+	}
+	*f = *out
+	info.CellSize = [3]float64{dist(p0, p1) / float64(N), dist(p0, p2) / float64(M), info.CellSize[Z]}
+}
+
+// trilinear interpolation of f at position p, between cell centers.
+// Points outside the mesh yield zero, within the outer half cells the border value.
+func interpolate(f *data.Slice, cellsize [3]float64, p [3]float64) []float64 {
+	size := f.Size()
+	var i0, i1 [3]int
+	var w [3]float64 // weight of i1
+	for c := range p {
+		if cellsize[c] == 0 {
+			log.Fatal("need the cell size of the input data for -line or -plane")
+		}
+		x := p[c]/cellsize[c] - 0.5 // fractional cell index
+		if x < -0.5 || x > float64(size[c])-0.5 {
+			return make([]float64, f.NComp())
+		}
+		x = math.Max(0, math.Min(x, float64(size[c]-1)))
+		i0[c] = int(x)
+		i1[c] = i0[c] + 1
+		w[c] = x - float64(i0[c])
+		if i1[c] >= size[c] {
+			i1[c], w[c] = i0[c], 0
+		}
+	}
+	v := make([]float64, f.NComp())
+	for comp := range v {
+		for corner := 0; corner < 8; corner++ {
+			weight := 1.
+			var idx [3]int
+			for c := 0; c < 3; c++ {
+				if corner&(1<<uint(c)) != 0 {
+					idx[c] = i1[c]
+					weight *= w[c]
+				} else {
+					idx[c] = i0[c]
+					weight *= 1 - w[c]
+				}
+			}
+			if weight != 0 {
+				v[comp] += weight * f.Get(comp, idx[X], idx[Y], idx[Z])
+			}
+		}
+	}
+	return v
+}
+
+func parseLine(arg string) (p1, p2 [3]float64, n int) {
+	parts := strings.Split(arg, ":")
+	if len(parts) != 3 {
+		log.Fatal("-line needs x1,y1,z1:x2,y2,z2:N, have: ", arg)
+	}
+	p1, p2 = parsePoint(parts[0]), parsePoint(parts[1])
+	n = atoi(parts[2])
+	if n < 1 {
+		log.Fatal("-line needs at least 1 point, have: ", arg)
+	}
+	return
+}
+
+func parsePlane(arg string) (p0, p1, p2 [3]float64, N, M int) {
+	parts := strings.Split(arg, ":")
+	if len(parts) != 4 {
+		log.Fatal("-plane needs x0,y0,z0:x1,y1,z1:x2,y2,z2:NxM, have: ", arg)
+	}
+	p0, p1, p2 = parsePoint(parts[0]), parsePoint(parts[1]), parsePoint(parts[2])
+	size := strings.Split(parts[3], "x")
+	if len(size) != 2 {
+		log.Fatal("-plane needs NxM points, have: ", parts[3])
+	}
+	N, M = atoi(size[0]), atoi(size[1])
+	if N < 1 || M < 1 {
+		log.Fatal("-plane needs at least 1x1 points, have: ", parts[3])
+	}
+	return
+}
+
+func parsePoint(s string) (p [3]float64) {
+	words := strings.Split(s, ",")
+	if len(words) != 3 {
+		log.Fatal("need x,y,z coordinates, have: ", s)
+	}
+	for c, w := range words {
+		v, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p[c] = v
+	}
+	return
+}
+
+func lerp(a, b [3]float64, s float64) (p [3]float64) {
+	for c := range p {
+		p[c] = a[c] + s*(b[c]-a[c])
+	}
+	return
+}
+
+func dist(a, b [3]float64) float64 {
+	return math.Sqrt(sqr(b[X]-a[X]) + sqr(b[Y]-a[Y]) + sqr(b[Z]-a[Z]))
+}
+
+func sqr(x float64) float64 { return x * x }
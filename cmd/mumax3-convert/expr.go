@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/script"
+)
+
+// names of the -expr operands, a is the file being converted
+const operands = "abcde"
+
+var (
+	flag_expr    = flag.String("expr", "", `Per-cell expression of the input files a, b, ..., e. E.g.: "dot(a, b)"`)
+	flag_operand [len(operands)]*string
+	fixed        [len(operands)]*operand // operands given by -a, ..., -e
+)
+
+func init() {
+	for i := range operands {
+		name := operands[i : i+1]
+		flag_operand[i] = flag.String(name, "", "File to use as "+name+" in -expr")
+	}
+}
+
+type operand struct {
+	*data.Slice
+	info  data.Meta
+	fname string
+}
+
+// loads the operands given by flags. If no input files are given, -a is used as input file.
+func loadOperands() {
+	for i, fname := range flag_operand {
+		if *fname == "" {
+			continue
+		}
+		if i == 0 && flag.NArg() == 0 {
+			continue // converted as input file
+		}
+		if i == 0 {
+			log.Fatal("-a cannot be combined with input files, it refers to each input file in turn")
+		}
+		s, info, err := readFile(*fname)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fixed[i] = &operand{s, info, *fname}
+	}
+}
+
+// evaluates -expr for every cell, with a the current input file.
+func evalExpr(a *data.Slice, info data.Meta, fname string) (*data.Slice, data.Meta) {
+	w := script.NewWorld()
+	w.AutoCall = true
+	w.EnterScope() // so that norm can shadow the standard normal distribution
+	w.Func("vector", func(x, y, z float64) data.Vector { return data.Vector{x, y, z} })
+	w.Func("dot", func(a, b data.Vector) float64 { return a[X]*b[X] + a[Y]*b[Y] + a[Z]*b[Z] })
+	w.Func("cross", func(a, b data.Vector) data.Vector {
+		return data.Vector{a[Y]*b[Z] - a[Z]*b[Y], a[Z]*b[X] - a[X]*b[Z], a[X]*b[Y] - a[Y]*b[X]}
+	})
+	w.Func("norm", func(a data.Vector) float64 { return a.Len() })
+
+	// per-cell values of the operands
+	ops := fixed
+	ops[0] = &operand{a, info, fname}
+	var scalars [len(operands)]float64
+	var vectors [len(operands)]data.Vector
+	for i, op := range ops {
+		if op == nil {
+			continue
+		}
+		if op.Size() != a.Size() {
+			panic(fmt.Errorf("mesh size mismatch: %v has %v cells, %v has %v", fname, a.Size(), op.fname, op.Size()))
+		}
+		if !sameCellSize(op.info.CellSize, info.CellSize) {
+			panic(fmt.Errorf("cell size mismatch: %v has %v, %v has %v", fname, info.CellSize, op.fname, op.info.CellSize))
+		}
+		name := operands[i : i+1]
+		switch op.NComp() {
+		default:
+			panic(fmt.Errorf("%v: cannot use %v components in -expr", op.fname, op.NComp()))
+		case 1:
+			w.Var(name, &scalars[i])
+		case 3:
+			w.Var(name, &vectors[i])
+		}
+	}
+
+	code, err := w.CompileExpr(*flag_expr)
+	if err != nil {
+		panic(err)
+	}
+
+	// evaluate once to find the number of output components
+	value := func(i int) []float64 {
+		for j, op := range ops {
+			if op == nil {
+				continue
+			}
+			if op.NComp() == 1 {
+				scalars[j] = float64(op.Host()[0][i])
+			} else {
+				vectors[j] = data.Vector{float64(op.Host()[0][i]), float64(op.Host()[1][i]), float64(op.Host()[2][i])}
+			}
+		}
+		switch v := code.Eval().(type) {
+		case float64:
+			return []float64{v}
+		case int:
+			return []float64{float64(v)}
+		case bool:
+			if v {
+				return []float64{1}
+			}
+			return []float64{0}
+		case data.Vector:
+			return v[:]
+		case func() float64:
+			return []float64{v()}
+		case func() data.Vector:
+			v2 := v()
+			return v2[:]
+		default:
+			panic(fmt.Errorf("-expr %v: cannot use type %v as output", *flag_expr, reflect.TypeOf(v)))
+		}
+	}
+	out := data.NewSlice(len(value(0)), a.Size())
+	host := out.Host()
+	for i := 0; i < a.Len(); i++ {
+		for c, v := range value(i) {
+			host[c][i] = float32(v)
+		}
+	}
+
+	info.Name = *flag_expr
+	info.Unit = ""
+	return out, info
+}
+
+func sameCellSize(a, b [3]float64) bool {
+	for c := range a {
+		if math.Abs(a[c]-b[c]) > 1e-6*math.Max(math.Abs(a[c]), math.Abs(b[c])) {
+			return false
+		}
+	}
+	return true
+}
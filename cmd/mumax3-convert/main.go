@@ -21,6 +21,25 @@ Example: convert all .ovf files to VTK binary saving only the X component. Also
 	mumax3-convert -comp 0 -vtk binary -jpg *.ovf
 Example: convert legacy .dump files to .ovf:
 	mumax3-convert -ovf2 *.dump
+Example: convert .ovf files to NumPy arrays, shaped [z][y][x][component]. With -npz, time, cell size, name and unit are stored alongside the data:
+	mumax3-convert -npz *.ovf
+Example: print the mean, RMS, min, max and standard deviation of each component, with a 10-bin histogram:
+	mumax3-convert -stats text -hist 10 file.ovf
+Example: write statistics per region of the top layer to file.stats.csv (-stats json for JSON). The regions file must have the same size as the data, it is cropped alongside:
+	mumax3-convert -stats csv -regions regions.ovf -zrange 3: file.ovf
+Example: evaluate a per-cell expression of several files, here the dot product of two states and the change of m, saved as m000010_expr.ovf.
+The input files are called a, b, c, d and e, a being each input file in turn. Vectors have components a.x, a.y, a.z. Besides the usual math functions, there are vector(x, y, z), dot, cross and norm:
+	mumax3-convert -ovf2 text -expr "dot(a, b)" -b m000000.ovf m000010.ovf
+	mumax3-convert -ovf2 text -expr "vector(a.x-b.x, a.y-b.y, a.z-b.z)" -a m000010.ovf -b m000000.ovf
+Example: profile along a line between two points (x,y,z in meters from the corner of the input mesh, -xrange, -yrange, -zrange and -resize do not apply) on 100 points, interpolated trilinearly. Written to file.line.csv as distance followed by the components:
+	mumax3-convert -line 0,64e-9,2e-9:500e-9,64e-9,2e-9:100 file.ovf
+Example: resample an oblique plane, given by three corners (origin, end of the first and second axis) and the number of points, and render it:
+	mumax3-convert -png -plane 0,0,0:500e-9,0,20e-9:0,128e-9,0:256x64 file.ovf
+Example: render all files as an animated GIF (or .apng), with one color scale for all frames and the time in the corner. Other outputs can be requested alongside:
+	mumax3-convert -movie m.gif -fps 5 -arrows 8 m*.ovf
+	mumax3-convert -movie mz.apng -comp z -min -1 -max 1 m*.ovf
+Example: add a title with quantity name, unit and time, a color bar (color wheel for vector data) and a scale bar to the images. Also works with -movie:
+	mumax3-convert -png -comp z -title auto -legend -scalebar file.ovf
 Example: cut out a piece of the data between min:max. max is exclusive bound. bounds can be omitted, default to 0 lower bound or maximum upper bound
 	mumax3-convert -xrange 50:100 -yrange :100 file.ovf
 Example: select the bottom layer
@@ -47,6 +66,7 @@ import (
 	"github.com/mumax/3/draw"
 	"github.com/mumax/3/dump"
 	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/npy"
 	"github.com/mumax/3/oommf"
 	"github.com/mumax/3/util"
 )
@@ -66,6 +86,8 @@ var (
 	flag_ovf2      = flag.String("ovf2", "", `"text" or "binary" OVF2 output`)
 	flag_vtk       = flag.String("vtk", "", `"ascii" or "binary" VTK output`)
 	flag_dump      = flag.Bool("dump", false, `output in dump format`)
+	flag_npy       = flag.Bool("npy", false, `NumPy .npy output`)
+	flag_npz       = flag.Bool("npz", false, `NumPy .npz output, including metadata`)
 	flag_csv       = flag.Bool("csv", false, `output in CSV format`)
 	flag_json      = flag.Bool("json", false, `output in JSON format`)
 	flag_min       = flag.String("min", "auto", `Minimum of color scale: "auto" or value.`)
@@ -79,6 +101,9 @@ var (
 	flag_dir       = flag.String("o", "", "Save all output in this directory")
 	flag_arrows    = flag.Int("arrows", 0, "Arrow size for vector bitmap image output")
 	flag_color     = flag.String("color", "black,gray,white", "Colormap for scalar image output.")
+	flag_stats     = flag.String("stats", "", `Statistics per component: "text" (to stdout), "csv" or "json" output`)
+	flag_regions   = flag.String("regions", "", "Regions file (e.g. regions.ovf) to split -stats by region")
+	flag_hist      = flag.Int("hist", 0, "Number of histogram bins for -stats")
 )
 
 var (
@@ -94,7 +119,7 @@ type task struct {
 func main() {
 	log.SetFlags(0)
 	flag.Parse()
-	if flag.NArg() == 0 {
+	if flag.NArg() == 0 && !(*flag_expr != "" && *flag_operand[0] != "") {
 		log.Fatal("no input files")
 	}
 
@@ -122,18 +147,55 @@ func main() {
 	case *flag_vtk != "":
 		wantOut = append(wantOut, output{".vts", outputVTK})
 	}
-	if len(wantOut) == 0 && *flag_show == false {
+	switch *flag_stats {
+	default:
+		log.Fatal(`-stats needs "text", "csv" or "json", have: `, *flag_stats)
+	case "":
+	case "text":
+		wantOut = append(wantOut, output{"", showStats})
+	case "csv":
+		wantOut = append(wantOut, output{".stats.csv", dumpStatsCSV})
+	case "json":
+		wantOut = append(wantOut, output{".stats.json", dumpStatsJSON})
+	}
+	if *flag_line != "" {
+		if *flag_plane != "" {
+			log.Fatal("-line can not be combined with -plane")
+		}
+		parseLine(*flag_line) // check syntax before starting
+		wantOut = append(wantOut, output{".line.csv", dumpLine})
+	}
+	if *flag_plane != "" {
+		parsePlane(*flag_plane)
+	}
+	if *flag_regions != "" {
+		loadRegions(*flag_regions)
+	}
+	if *flag_expr != "" {
+		loadOperands()
+	}
+	if len(wantOut) == 0 && *flag_show == false && *flag_movie == "" {
 		log.Fatal("no output format specified (e.g.: -png)")
 	}
 
 	// expand wildcards which are not expanded by the shell
 	// (pointing a finger at cmd.exe)
 	var fnames []string
+	if flag.NArg() == 0 {
+		fnames = []string{*flag_operand[0]} // -expr with -a
+	}
 	for _, input := range flag.Args() {
 		fmt.Println(input)
 		expanded, _ := filepath.Glob(input)
 		fnames = append(fnames, expanded...)
 	}
+	if *flag_movie != "" {
+		movie(fnames)
+		if len(wantOut) == 0 {
+			return
+		}
+	}
+
 	// read all input files and put them in the task que
 	for _, fname := range fnames {
 		for _, outp := range wantOut {
@@ -161,6 +223,9 @@ var (
 func doFile(infname string, outp output) {
 	// determine output file
 	outfname := util.NoExt(infname) + outp.Ext
+	if *flag_expr != "" {
+		outfname = util.NoExt(infname) + "_expr" + outp.Ext
+	}
 	if *flag_dir != "" {
 		outfname = *flag_dir + "/" + path.Base(outfname)
 	}
@@ -180,7 +245,8 @@ func doFile(infname string, outp output) {
 		}
 	}()
 
-	if !(strings.HasPrefix(infname, "http://") || strings.HasPrefix(outfname, "http://")) {
+	// with -expr, the output also depends on the other operands and the expression itself
+	if outp.Ext != "" && *flag_expr == "" && !(strings.HasPrefix(infname, "http://") || strings.HasPrefix(outfname, "http://")) {
 		inStat, errS := os.Stat(infname)
 		if errS != nil {
 			panic(errS)
@@ -193,46 +259,58 @@ func doFile(infname string, outp output) {
 		}
 	}
 
-	var slice *data.Slice
-	var info data.Meta
-	var err error
-
-	in, errI := httpfs.Open(infname)
-	if errI != nil {
-		msg = fail(msg, errI)
-		return
-	}
-	defer in.Close()
-
-	switch path.Ext(infname) {
-	default:
-		msg = fail(msg, ": skipping unsupported type: "+path.Ext(infname))
-		return
-	case ".ovf", ".omf", ".ovf2":
-		slice, info, err = oommf.Read(in)
-	case ".dump":
-		slice, info, err = dump.Read(in)
-	}
-
+	slice, info, err := readFile(infname)
 	if err != nil {
 		msg = fail(msg, err)
 		return
 	}
+	if *flag_expr != "" {
+		slice, info = evalExpr(slice, info, infname)
+	}
 
-	out, err := httpfs.Create(outfname)
-	if err != nil {
-		msg = fail(msg, err)
-		return
+	// outputs without extension print to stdout
+	var out io.Writer = os.Stdout
+	if outp.Ext != "" {
+		f, err := httpfs.Create(outfname)
+		if err != nil {
+			msg = fail(msg, err)
+			return
+		}
+		defer f.Close()
+		out = f
 	}
-	defer out.Close()
 
-	preprocess(slice)
+	if outp.Ext == ".line.csv" {
+		preprocessValues(slice) // -line points are on the input mesh, not cropped or resized
+	} else {
+		preprocess(slice, &info)
+	}
 	outp.Convert(slice, info, panicWriter{out})
 	succeeded.Add(1)
 	msg = "[ ok ] " + msg
 
 }
 
+// reads any of the supported input formats.
+func readFile(fname string) (*data.Slice, data.Meta, error) {
+	in, err := httpfs.Open(fname)
+	if err != nil {
+		return nil, data.Meta{}, err
+	}
+	defer in.Close()
+
+	switch path.Ext(fname) {
+	default:
+		return nil, data.Meta{}, fmt.Errorf("skipping unsupported type: %v", path.Ext(fname))
+	case ".ovf", ".omf", ".ovf2":
+		return oommf.Read(in)
+	case ".dump":
+		return dump.Read(in)
+	case ".npy", ".npz":
+		return npy.Read(in)
+	}
+}
+
 func fail(msg string, x ...interface{}) string {
 	failed.Add(1)
 	return "[fail] " + msg + ": " + fmt.Sprint(x...)
@@ -264,21 +342,23 @@ var outputs = map[*bool]output{
 	flag_svgz:    {".svgz", renderSVGZ},
 	flag_gnuplot: {".gplot", dumpGnuplot},
 	flag_dump:    {".dump", outputDUMP},
+	flag_npy:     {".npy", outputNPY},
+	flag_npz:     {".npz", outputNPZ},
 	flag_csv:     {".csv", dumpCSV},
 	flag_json:    {".json", dumpJSON},
 	flag_show:    {"", show},
 }
 
 func renderPNG(f *data.Slice, info data.Meta, out io.Writer) {
-	draw.RenderFormat(out, f, *flag_min, *flag_max, *flag_arrows, ".png", colormap...)
+	render(f, info, out, ".png")
 }
 
 func renderJPG(f *data.Slice, info data.Meta, out io.Writer) {
-	draw.RenderFormat(out, f, *flag_min, *flag_max, *flag_arrows, ".jpg", colormap...)
+	render(f, info, out, ".jpg")
 }
 
 func renderGIF(f *data.Slice, info data.Meta, out io.Writer) {
-	draw.RenderFormat(out, f, *flag_min, *flag_max, *flag_arrows, ".gif", colormap...)
+	render(f, info, out, ".gif")
 }
 
 func renderSVG(f *data.Slice, info data.Meta, out io.Writer) {
@@ -311,13 +391,33 @@ func outputDUMP(f *data.Slice, info data.Meta, out io.Writer) {
 	dump.Write(out, f, info)
 }
 
+func outputNPY(f *data.Slice, info data.Meta, out io.Writer) {
+	npy.Write(out, f, info)
+}
+
+func outputNPZ(f *data.Slice, info data.Meta, out io.Writer) {
+	npy.WriteNPZ(out, f, info)
+}
+
 // does not output to out, just prints to stdout
 func show(f *data.Slice, info data.Meta, out io.Writer) {
 	fmt.Println(info)
 	util.Fprintf(os.Stdout, *flag_format, f.Tensors())
 }
 
-func preprocess(f *data.Slice) {
+func preprocess(f *data.Slice, info *data.Meta) {
+	preprocessValues(f)
+	if *flag_plane != "" {
+		plane(f, info)
+	}
+	crop(f)
+	if *flag_resize != "" {
+		resize(f, *flag_resize)
+	}
+}
+
+// the part of preprocess that leaves the mesh unchanged
+func preprocessValues(f *data.Slice) {
 	if *flag_normalize {
 		normalize(f, 1)
 	}
@@ -327,10 +427,6 @@ func preprocess(f *data.Slice) {
 	if *flag_comp != "" {
 		*f = *f.Comp(parseComp(*flag_comp))
 	}
-	crop(f)
-	if *flag_resize != "" {
-		resize(f, *flag_resize)
-	}
 }
 
 func parseComp(c string) int {
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/draw"
+	"github.com/mumax/3/httpfs"
+)
+
+var (
+	flag_movie = flag.String("movie", "", "Render all input files, in order, as frames of an animated .gif or .apng")
+	flag_fps   = flag.Int("fps", 10, "Frames per second for -movie")
+)
+
+// frames narrower than this are enlarged, so that the time stamp fits
+const minMovieWidth = 256
+
+// renders all files as one movie, with a common color scale.
+func movie(fnames []string) {
+	ext := strings.ToLower(path.Ext(*flag_movie))
+	if ext != ".gif" && ext != ".apng" && ext != ".png" {
+		log.Fatal("-movie needs a .gif or .apng file name, have: ", *flag_movie)
+	}
+	if *flag_fps < 1 || *flag_fps > 100 {
+		log.Fatal("-fps needs 1 to 100 frames per second, have: ", *flag_fps)
+	}
+
+	var slices []*data.Slice
+	var infos []data.Meta
+	for _, fname := range fnames {
+		s, info, err := readFile(fname)
+		if err != nil {
+			log.Fatal(fname, ": ", err)
+		}
+		if *flag_expr != "" {
+			s, info = evalExpr(s, info, fname)
+		}
+		preprocess(s, &info)
+		if len(slices) > 0 && (s.Size() != slices[0].Size() || s.NComp() != slices[0].NComp()) {
+			log.Fatal(fname, ": size ", s.Size(), " differs from first file ", slices[0].Size())
+		}
+		slices = append(slices, s)
+		infos = append(infos, info)
+	}
+	if len(slices) == 0 {
+		log.Fatal("-movie: no input files")
+	}
+
+	// one color scale for all frames, unless given
+	min, max := *flag_min, *flag_max
+	if slices[0].NComp() == 1 {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, s := range slices {
+			for _, v := range s.Host()[0] {
+				lo = math.Min(lo, float64(v))
+				hi = math.Max(hi, float64(v))
+			}
+		}
+		if min == "auto" {
+			min = strconv.FormatFloat(lo, 'g', -1, 32)
+		}
+		if max == "auto" {
+			max = strconv.FormatFloat(hi, 'g', -1, 32)
+		}
+	}
+
+	frames := make([]*image.RGBA, len(slices))
+	for i, s := range slices {
+		if annotated() {
+			frames[i] = draw.AnnotatedImage(s, min, max, *flag_arrows, annotation(infos[i]), colormap...)
+			continue
+		}
+		img := draw.Image(s, min, max, *flag_arrows, colormap...)
+		img = draw.Enlarge(img, (minMovieWidth-1)/img.Bounds().Dx()+1)
+		draw.Label(img, 2, img.Bounds().Dy()-13, fmt.Sprintf("t = %.3f ns", infos[i].Time*1e9))
+		frames[i] = img
+	}
+
+	out, err := httpfs.Create(*flag_movie)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+	delay := 100 / *flag_fps
+	if ext == ".gif" {
+		err = draw.GIFAnim(out, frames, delay)
+	} else {
+		err = draw.APNG(out, frames, delay)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println(len(frames), "frames ->", *flag_movie)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mumax/3/data"
+)
+
+// regions read from -regions, cropped like the data
+var regions *data.Slice
+
+func loadRegions(fname string) {
+	if *flag_resize != "" {
+		log.Fatal("-regions cannot be combined with -resize")
+	}
+	r, _, err := readFile(fname)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if r.NComp() != 1 {
+		log.Fatal(fname, ": regions need scalar data, have ", r.NComp(), " components")
+	}
+	crop(r)
+	regions = r
+}
+
+// statistics of one component in one region
+type compStats struct {
+	Comp      string  `json:"comp"`
+	N         int     `json:"n"`
+	Mean      float64 `json:"mean"`
+	RMS       float64 `json:"rms"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	StdDev    float64 `json:"stddev"`
+	Hist      []int   `json:"hist,omitempty"` // equal bins between Min and Max
+	sum, sum2 float64
+}
+
+// statistics of all components in one region ("all" without -regions)
+type regionStats struct {
+	Region string       `json:"region"`
+	Comp   []*compStats `json:"components"`
+}
+
+type fileStats struct {
+	Name    string         `json:"name"`
+	Unit    string         `json:"unit"`
+	Time    float64        `json:"time"`
+	Regions []*regionStats `json:"regions"`
+}
+
+// computes the statistics per region and component.
+func stats(f *data.Slice, info data.Meta) []*regionStats {
+	if regions != nil && regions.Size() != f.Size() {
+		panic(fmt.Errorf("regions size %v does not match data size %v", regions.Size(), f.Size()))
+	}
+
+	// groups per region, in order of appearance
+	var cellRegion []float32
+	if regions != nil {
+		cellRegion = regions.Host()[0]
+	}
+	var all []*regionStats
+	byRegion := make(map[float32]*regionStats)
+	group := func(i int) *regionStats {
+		var r float32
+		if cellRegion != nil {
+			r = cellRegion[i]
+		}
+		if g, ok := byRegion[r]; ok {
+			return g
+		}
+		g := &regionStats{Region: "all"}
+		if cellRegion != nil {
+			g.Region = fmt.Sprint(r)
+		}
+		for c := 0; c < f.NComp(); c++ {
+			g.Comp = append(g.Comp, &compStats{Comp: compName(c, f.NComp()), Min: math.Inf(1), Max: math.Inf(-1)})
+		}
+		byRegion[r] = g
+		all = append(all, g)
+		return g
+	}
+
+	host := f.Host()
+	for i := 0; i < f.Len(); i++ {
+		g := group(i)
+		for c, s := range g.Comp {
+			v := float64(host[c][i])
+			s.N++
+			s.sum += v
+			s.sum2 += v * v
+			s.Min = math.Min(s.Min, v)
+			s.Max = math.Max(s.Max, v)
+		}
+	}
+	for _, g := range all {
+		for _, s := range g.Comp {
+			n := float64(s.N)
+			s.Mean = s.sum / n
+			s.RMS = math.Sqrt(s.sum2 / n)
+			s.StdDev = math.Sqrt(math.Max(0, s.sum2/n-s.Mean*s.Mean))
+			if *flag_hist > 0 {
+				s.Hist = make([]int, *flag_hist)
+			}
+		}
+	}
+
+	// second pass for histograms, now that the ranges are known
+	if *flag_hist > 0 {
+		for i := 0; i < f.Len(); i++ {
+			g := group(i)
+			for c, s := range g.Comp {
+				s.Hist[bin(float64(host[c][i]), s.Min, s.Max, len(s.Hist))]++
+			}
+		}
+	}
+	return all
+}
+
+// histogram bin for v in [min, max], max included in the last bin.
+func bin(v, min, max float64, n int) int {
+	if max == min {
+		return 0
+	}
+	b := int(float64(n) * (v - min) / (max - min))
+	if b >= n {
+		b = n - 1
+	}
+	return b
+}
+
+func compName(c, ncomp int) string {
+	if ncomp == 3 {
+		return "xyz"[c : c+1]
+	}
+	return fmt.Sprint(c)
+}
+
+// human-readable statistics, printed to stdout
+func showStats(f *data.Slice, info data.Meta, out io.Writer) {
+	var buf strings.Builder // print all at once, files are processed concurrently
+	fmt.Fprintf(&buf, "%v (%v) t=%vs\n", info.Name, info.Unit, info.Time)
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "region\tcomp\tN\tmean\trms\tmin\tmax\tstddev")
+	for _, g := range stats(f, info) {
+		for _, s := range g.Comp {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t", g.Region, s.Comp, s.N, fmtStat(s.Mean))
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", fmtStat(s.RMS), fmtStat(s.Min), fmtStat(s.Max), fmtStat(s.StdDev))
+			if s.Hist != nil {
+				fmt.Fprintf(w, "\t\thist:\t%v\n", strings.Trim(fmt.Sprint(s.Hist), "[]"))
+			}
+		}
+	}
+	w.Flush()
+	fmt.Fprint(out, buf.String())
+}
+
+// comma-separated statistics, one line per region and component
+func dumpStatsCSV(f *data.Slice, info data.Meta, out io.Writer) {
+	fmt.Fprint(out, "region, comp, N, mean, rms, min, max, stddev")
+	for i := 0; i < *flag_hist; i++ {
+		fmt.Fprint(out, ", hist", i)
+	}
+	fmt.Fprintln(out)
+	for _, g := range stats(f, info) {
+		for _, s := range g.Comp {
+			fmt.Fprintf(out, "%v, %v, %v", g.Region, s.Comp, s.N)
+			for _, v := range []float64{s.Mean, s.RMS, s.Min, s.Max, s.StdDev} {
+				fmt.Fprint(out, ", ", fmtStat(v))
+			}
+			for _, h := range s.Hist {
+				fmt.Fprint(out, ", ", h)
+			}
+			fmt.Fprintln(out)
+		}
+	}
+}
+
+func dumpStatsJSON(f *data.Slice, info data.Meta, out io.Writer) {
+	w := json.NewEncoder(out)
+	w.SetIndent("", "\t")
+	w.Encode(fileStats{Time: info.Time, Name: info.Name, Unit: info.Unit, Regions: stats(f, info)})
+}
+
+func fmtStat(v float64) string {
+	return fmt.Sprintf(*flag_format, v)
+}
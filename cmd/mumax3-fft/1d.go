@@ -10,8 +10,8 @@ import (
 	"path"
 	"strings"
 
-	"github.com/barnex/fftw"
 	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/odt"
 	"github.com/mumax/3/util"
 )
 
@@ -31,7 +31,13 @@ func processTable(infname, outfname string) {
 	in := httpfs.MustOpen(infname)
 	defer in.Close()
 
-	header, data := ReadTable(in)
+	var header []string
+	var data [][]float32
+	if path.Ext(infname) == ".odt" {
+		header, data = ReadODT(in)
+	} else {
+		header, data = ReadTable(in)
+	}
 
 	// Process data
 	if *flag_Interp {
@@ -56,6 +62,11 @@ func processTable(infname, outfname string) {
 		panic(fmt.Sprint("invalid window:", *flag_Win, " options:", windows))
 	}
 
+	if *flag_STFT != "" {
+		stft(infname, header, data, window)
+		return
+	}
+
 	for c := range data {
 		applyWindow(data[c], window)
 		data[c] = zeropad(data[c], rows*(*flag_Pad))
@@ -72,6 +83,11 @@ func processTable(infname, outfname string) {
 		divCol(transf, transf[*flag_NormCol-2])
 	}
 
+	if *flag_Peaks > 0 {
+		writePeaks(infname, header, transf, deltaF)
+		return
+	}
+
 	output := reduce(transf, deltaF)
 
 	outHdr := makeFFTHeader(header)
@@ -92,7 +108,11 @@ func processTable(infname, outfname string) {
 		defer o.Close()
 		out = o
 	}
-	writeTable(out, outHdr, output)
+	if path.Ext(outfname) == ".odt" {
+		writeODT(out, outHdr, output)
+	} else {
+		writeTable(out, outHdr, output)
+	}
 }
 
 // turn original table header into FFT header
@@ -170,6 +190,22 @@ func writeTable(out io.Writer, header []string, output [][]float32) {
 	}
 }
 
+// output FFT data as OOMMF ODT table
+func writeODT(out io.Writer, header []string, output [][]float32) {
+	t := &odt.Table{Title: "mumax3-fft"}
+	for c, h := range header {
+		name, unit := splitHeader(h)
+		t.Columns = append(t.Columns, name)
+		t.Units = append(t.Units, unit)
+		col := make([]float64, len(output[c]))
+		for r, v := range output[c] {
+			col[r] = float64(v)
+		}
+		t.Data = append(t.Data, col)
+	}
+	check(t.Write(out))
+}
+
 func WriteHeader(out io.Writer, header []string) {
 	Fprint(out, "# ", header[0])
 	for _, h := range header[1:] {
@@ -226,10 +262,7 @@ func FFT(data [][]float32) [][]complex64 {
 	transf := make([][]complex64, cols)
 
 	for c := range transf {
-		rows := len(data[c])
-		transf[c] = make([]complex64, rows/2+1)
-		plan := fftw.PlanR2C([]int{rows}, data[c], transf[c], fftw.ESTIMATE)
-		plan.Execute()
+		transf[c] = fftR2C(data[c])
 
 		// normalize FFT
 		norm := float32(math.Sqrt(float64(len(data[c]))))
@@ -266,6 +299,38 @@ func ReadTable(in_ io.Reader) (header []string, data [][]float32) {
 	return
 }
 
+// read OOMMF ODT table, moving the simulation time column to the front
+// and converting it to seconds. Header entries are formatted like mumax3's: "name (unit)".
+func ReadODT(in io.Reader) (header []string, data [][]float32) {
+	t, err := odt.Read(in)
+	check(err)
+	tcol, scale, err := t.TimeColumn()
+	check(err)
+
+	cols := []int{tcol}
+	for c := range t.Columns {
+		if c != tcol {
+			cols = append(cols, c)
+		}
+	}
+	for _, c := range cols {
+		unit := t.Units[c]
+		col := make([]float32, t.Rows())
+		for r, v := range t.Data[c] {
+			col[r] = float32(v)
+		}
+		if c == tcol {
+			unit = "s"
+			for r := range col {
+				col[r] = float32(t.Data[c][r] * scale)
+			}
+		}
+		header = append(header, t.Columns[c]+" ("+unit+")")
+		data = append(data, col)
+	}
+	return
+}
+
 func readData(in *bufio.Reader, cols int) [][]float32 {
 	data := make([][]float32, cols)
 	var v float32
@@ -0,0 +1,241 @@
+package main
+
+// Spin-wave dispersion: 2D FFT of OVF files along one spatial axis and time.
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/draw"
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/oommf"
+)
+
+var (
+	flag_KW     = flag.String("kw", "", "k-omega dispersion of OVF files along axis x, y or z")
+	flag_KWLine = flag.String("kwline", "", "for -kw: take the line of cells at these indices of the other two axes, e.g. 16,0, instead of averaging")
+)
+
+// k-omega dispersion |m(k, f)| of the -comp component of all input files,
+// averaged over the cells perpendicular to the -kw axis or along a -kwline.
+// Writes dispersion.png, dispersion.csv and dispersion.ovf.
+func mainDispersion() {
+	axis := parseAxis(*flag_KW)
+	Nt := flag.NArg()
+	if Nt < 2 {
+		log.Fatal("need at least 2 inputs")
+	}
+
+	// load the selected component of all files
+	var frames [][]float32
+	var times []float32
+	var size [3]int
+	var cellsize [3]float64
+	for i, fname := range flag.Args() {
+		log.Println("loading", fname)
+		s, meta := oommf.MustReadFile(fname)
+		if i == 0 {
+			size, cellsize = s.Size(), meta.CellSize
+		}
+		if s.Size() != size {
+			log.Fatal(fname, ": size ", s.Size(), " differs from first file ", size)
+		}
+		if *flag_Comp < 0 || *flag_Comp >= s.NComp() {
+			log.Fatal("-comp ", *flag_Comp, " out of range, ", fname, " has ", s.NComp(), " components")
+		}
+		frames = append(frames, s.Host()[*flag_Comp])
+		times = append(times, float32(meta.Time))
+	}
+	deltaT := times[Nt-1] - times[0]
+	deltaF := 1 / deltaT
+	Nk := size[axis]
+	if cellsize[axis] == 0 {
+		log.Fatal("need the cell size of the input files for -kw")
+	}
+	deltaK := 2 * math.Pi / (float64(Nk) * cellsize[axis]) // rad/m
+
+	frames = resample(times, frames)
+
+	// remove the static background and apply the window along time
+	window := windows[*flag_Win]
+	if window == nil {
+		log.Fatal("invalid window: ", *flag_Win, " options: ", windows)
+	}
+	series := make([]float32, Nt)
+	for i := range frames[0] {
+		var avg float32
+		for t := range frames {
+			avg += frames[t][i]
+		}
+		avg /= float32(Nt)
+		for t := range frames {
+			series[t] = frames[t][i] - avg
+		}
+		applyWindow(series, window)
+		for t := range frames {
+			frames[t][i] = series[t]
+		}
+	}
+
+	// 2D FFT of each line along the axis, magnitudes averaged over lines
+	Nf := Nt / 2
+	spec := make([]float64, Nf*Nk) // spec[f*Nk+k], k shifted to -Nk/2 ... Nk/2-1
+	lines := cellLines(size, axis)
+	byTime := make([]complex64, Nt*Nk)
+	byK := make([]complex64, Nk*Nt)
+	for _, line := range lines {
+		for t := range frames {
+			for k, cell := range line {
+				byTime[t*Nk+k] = complex(frames[t][cell], 0)
+			}
+		}
+		fftMany(byTime, Nt, Nk) // along time
+		for t := 0; t < Nt; t++ {
+			for k := 0; k < Nk; k++ {
+				byK[k*Nt+t] = byTime[t*Nk+k]
+			}
+		}
+		fftMany(byK, Nk, Nt) // along space
+		for f := 0; f < Nf; f++ {
+			for k := 0; k < Nk; k++ {
+				// with the forward FFT along time, waves travelling in the +axis
+				// direction end up at -k for positive frequencies: mirror k
+				v := byK[((Nk-k)%Nk)*Nt+f]
+				spec[f*Nk+(k+Nk/2)%Nk] += math.Hypot(float64(real(v)), float64(imag(v))) / float64(len(lines))
+			}
+		}
+	}
+
+	kmin := -float64(Nk/2) * deltaK
+	comp := strconv.Itoa(*flag_Comp)
+	if *flag_Comp < 3 {
+		comp = string("xyz"[*flag_Comp])
+	}
+	writeDispersionCSV("dispersion.csv", spec, Nf, Nk, kmin, deltaK, float64(deltaF))
+	writeDispersionOVF("dispersion.ovf", spec, Nf, Nk, deltaK, float64(deltaF))
+	x := draw.Axis{Label: "k (rad/um)", Min: kmin * 1e-6, Max: (kmin + float64(Nk)*deltaK) * 1e-6}
+	y := draw.Axis{Label: "f (GHz)", Min: 0, Max: float64(Nf) * float64(deltaF) * 1e-9}
+	renderHeatMap("dispersion.png", fmt.Sprintf("|m%v(k, f)|", comp), spec, Nk, Nf, x, y)
+}
+
+// parses the -kw axis
+func parseAxis(s string) int {
+	switch strings.ToLower(s) {
+	case "x":
+		return 0
+	case "y":
+		return 1
+	case "z":
+		return 2
+	}
+	log.Fatal("-kw needs axis x, y or z, have: ", s)
+	panic("unreachable")
+}
+
+// cell indices of the lines along axis: all of them, or the one given by -kwline.
+func cellLines(size [3]int, axis int) [][]int {
+	var other []int // the other two axes
+	for c := 0; c < 3; c++ {
+		if c != axis {
+			other = append(other, c)
+		}
+	}
+	var fixed [][2]int // indices along the other axes, for each line
+	if *flag_KWLine != "" {
+		words := strings.Split(*flag_KWLine, ",")
+		if len(words) != 2 {
+			log.Fatal("-kwline needs two cell indices, have: ", *flag_KWLine)
+		}
+		var idx [2]int
+		for i, w := range words {
+			v, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil {
+				log.Fatal("-kwline: ", err)
+			}
+			if v < 0 || v >= size[other[i]] {
+				log.Fatal("-kwline: index ", v, " out of range 0..", size[other[i]]-1)
+			}
+			idx[i] = v
+		}
+		fixed = [][2]int{idx}
+	} else {
+		for j := 0; j < size[other[1]]; j++ {
+			for i := 0; i < size[other[0]]; i++ {
+				fixed = append(fixed, [2]int{i, j})
+			}
+		}
+	}
+
+	lines := make([][]int, len(fixed))
+	for l, f := range fixed {
+		for k := 0; k < size[axis]; k++ {
+			var i [3]int
+			i[axis], i[other[0]], i[other[1]] = k, f[0], f[1]
+			lines[l] = append(lines[l], (i[2]*size[1]+i[1])*size[0]+i[0])
+		}
+	}
+	return lines
+}
+
+// interpolates frames at non-equidistant times to equidistant ones, like the table mode.
+func resample(times []float32, frames [][]float32) [][]float32 {
+	Nt := len(times)
+	deltaT := times[Nt-1] - times[0]
+	out := make([][]float32, Nt)
+	si := 0
+	for di := range out {
+		want := times[0] + float32(di)*deltaT/float32(Nt)
+		for si < Nt-1 && !(times[si] <= want && times[si+1] > want && times[si] != times[si+1]) {
+			si++
+		}
+		x := (want - times[si]) / (times[si+1] - times[si])
+		if x < 0 || x > 1 {
+			panic(fmt.Sprint("x=", x))
+		}
+		out[di] = make([]float32, len(frames[si]))
+		for i := range out[di] {
+			out[di][i] = (1-x)*frames[si][i] + x*frames[si+1][i]
+		}
+	}
+	return out
+}
+
+// CSV matrix: first row the wave numbers (rad/m), first column the frequencies (Hz).
+func writeDispersionCSV(fname string, spec []float64, Nf, Nk int, kmin, deltaK, deltaF float64) {
+	f := httpfs.MustCreate(fname)
+	defer f.Close()
+	out := bufio.NewWriter(f)
+	defer out.Flush()
+	fmt.Fprint(out, "f (Hz) \\ k (rad/m)")
+	for k := 0; k < Nk; k++ {
+		fmt.Fprint(out, ",", float32(kmin+float64(k)*deltaK))
+	}
+	fmt.Fprintln(out)
+	for i := 0; i < Nf; i++ {
+		fmt.Fprint(out, float32(float64(i)*deltaF))
+		for k := 0; k < Nk; k++ {
+			fmt.Fprint(out, ",", float32(spec[i*Nk+k]))
+		}
+		fmt.Fprintln(out)
+	}
+	log.Println(fname)
+}
+
+// OVF matrix with k along x and f along y. The cell size holds the k (rad/m) and f (Hz) steps.
+func writeDispersionOVF(fname string, spec []float64, Nf, Nk int, deltaK, deltaF float64) {
+	s := data.NewSlice(1, [3]int{Nk, Nf, 1})
+	host := s.Host()[0]
+	for i, v := range spec {
+		host[i] = float32(v)
+	}
+	f := httpfs.MustCreate(fname)
+	defer f.Close()
+	oommf.WriteOVF2(f, s, data.Meta{Name: "dispersion", CellSize: [3]float64{deltaK, deltaF, 1}}, "binary")
+	log.Println(fname)
+}
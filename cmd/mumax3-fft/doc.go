@@ -4,6 +4,10 @@ mumax3-fft performs a Fourier transform on mumax3 table output. E.g.:
 will create table_fft.txt with per-column FFTs of the data in table.txt.
 The first column will contain frequencies in Hz.
 
+OOMMF ODT tables are accepted as well, e.g. from TableFormat = ODT:
+ 	mumax3-fft table.odt
+The simulation time column is used as time axis, wherever it is in the table. The output is an ODT table again (table_fft.odt).
+
 
 Flags
 
@@ -58,6 +62,33 @@ The following windowing functions are provided: boxcar (no windowing), hamming,
 
 
 
+Resonance peaks
+
+
+-peaks N locates the N strongest peaks in the power spectrum |X|² of each column (after -divcol, if given) and fits them together with a sum of Lorentzians A / (1 + (2(f-f0)/FWHM)²) plus a constant background, by nonlinear least squares (Levenberg-Marquardt):
+ 	mumax3-fft -peaks 2 table.txt
+Instead of the spectrum, a CSV table is written to table_peaks.csv, or to stdout with -stdout. It lists, for each column and peak, the center frequency, FWHM and amplitude with their standard errors. Local maxima within the half-maximum width of a stronger peak are not counted as separate peaks. Fits that end up with a center outside the fitted frequency range, a negative height, a width larger than that range or narrower than one frequency step, or a width or height smaller than its standard error are dropped with a warning on stderr and the remaining peaks are fitted again, so asking for more peaks than there are reports only the real ones.
+
+
+Spectrogram
+
+
+To see when frequencies appear, e.g. for chirped excitations or switching, -stft computes a short-time FFT of each column. It takes the window length and hop between windows, in table rows after interpolation:
+ 	mumax3-fft -stft 256,32 -window hann table.txt
+Each window is multiplied by the -window function and zero-padded by -zeropad. For each column, the magnitude is written to table_stft_<column>.txt, a gnuplot nonuniform matrix (first line: number of windows and their center times in s, next lines: frequency in Hz and magnitudes), and to table_stft_<column>.png, a heat map with a logarithmic color scale spanning -decades. In gnuplot:
+ 	gnuplot> plot "table_stft_mx.txt" nonuniform matrix with image
+
+
+Spin-wave dispersion
+
+
+For a stack of OVF files, -kw transforms along one spatial axis and time, giving the dispersion |m(k, f)| of the component selected by -comp (0, 1, 2 = x, y, z). E.g.:
+ 	mumax3-fft -kw x -comp 2 -window hann m*.ovf
+The time average of each cell is removed first and the window is applied along time. The magnitude is averaged over all lines of cells along the axis, or taken along one line with -kwline, giving the cell indices of the other two axes (e.g. y,z for -kw x):
+ 	mumax3-fft -kw x -kwline 16,0 m*.ovf
+Positive k are waves travelling in the positive axis direction. The output is written to dispersion.png (heat map with a logarithmic color scale spanning -decades, k in rad/µm and f in GHz), dispersion.csv (first row k in rad/m, first column f in Hz) and dispersion.ovf (k along x, f along y, the cell size holds the k and f steps).
+
+
 Use with gnuplot
 
 
@@ -66,9 +97,19 @@ mumax3-fft is easy to use with gnuplot. Inside gnuplot, type, e.g.:
 this will perform the FFT on-the-fly and pipe the output directly to gnuplot.
 
 
+FFT backend
+
+
+By default, mumax3-fft uses the pure-Go FFT of package github.com/mumax/3/fft, which handles any length (Bluestein's algorithm for large prime factors). It needs no cgo and can be built statically:
+	CGO_ENABLED=0 go install
+To use FFTW instead, build with:
+	go install -tags fftw
+which needs libfftw3f and the FFTW bindings at http://github.com/barnex/fftw. Spectra agree to float32 precision.
+
+
 License
 
-mumax3-fft inherits the GPLv3 from the FFTW bindings at http://github.com/barnex/fftw
+mumax3-fft is licensed under the GPLv3, like mumax3. When built with -tags fftw, it also inherits the GPLv3 from the FFTW bindings.
 
 */
 package main
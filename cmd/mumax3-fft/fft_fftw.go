@@ -0,0 +1,36 @@
+//go:build fftw
+// +build fftw
+
+package main
+
+// FFTW backend, selected with -tags fftw. Needs cgo and libfftw3f.
+
+import (
+	"github.com/barnex/fftw"
+)
+
+// fftR2C returns the forward FFT of real data: the first len(data)/2+1 frequencies.
+func fftR2C(data []float32) []complex64 {
+	transf := make([]complex64, len(data)/2+1)
+	plan := fftw.PlanR2C([]int{len(data)}, data, transf, fftw.ESTIMATE)
+	plan.Execute()
+	return transf
+}
+
+// fftMany performs in-place forward FFTs of length Nt on Nc interleaved sequences:
+// element t of sequence c is stored at dataList[t*Nc+c].
+func fftMany(dataList []complex64, Nt, Nc int) {
+	howmany := Nc
+	n := []int{Nt}
+	in := dataList
+	out := dataList
+	istride := Nc
+	idist := 1
+	inembed := n
+	ostride := istride
+	odist := idist
+	onembed := inembed
+	plan := fftw.PlanManyC2C(n, howmany, in, inembed, istride, idist, out, onembed, ostride, odist, fftw.FORWARD, fftw.ESTIMATE)
+	plan.Execute()
+	//plan.Destroy()
+}
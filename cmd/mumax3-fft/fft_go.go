@@ -0,0 +1,53 @@
+//go:build !fftw
+// +build !fftw
+
+package main
+
+// Pure-Go FFT backend, the default. Build with -tags fftw to use FFTW instead.
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/mumax/3/fft"
+)
+
+// fftR2C returns the forward FFT of real data: the first len(data)/2+1 frequencies.
+func fftR2C(data []float32) []complex64 {
+	n := len(data)
+	x := make([]complex128, n)
+	for i, v := range data {
+		x[i] = complex(float64(v), 0)
+	}
+	fft.NewPlan(n).Forward(x)
+	transf := make([]complex64, n/2+1)
+	for i := range transf {
+		transf[i] = complex64(x[i])
+	}
+	return transf
+}
+
+// fftMany performs in-place forward FFTs of length Nt on Nc interleaved sequences:
+// element t of sequence c is stored at dataList[t*Nc+c].
+func fftMany(dataList []complex64, Nt, Nc int) {
+	workers := runtime.NumCPU()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			plan := fft.NewPlan(Nt) // plans are not thread-safe
+			x := make([]complex128, Nt)
+			for c := w; c < Nc; c += workers {
+				for t := range x {
+					x[t] = complex128(dataList[t*Nc+c])
+				}
+				plan.Forward(x)
+				for t, v := range x {
+					dataList[t*Nc+c] = complex64(v)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
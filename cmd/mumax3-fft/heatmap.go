@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/mumax/3/draw"
+	"github.com/mumax/3/httpfs"
+)
+
+var flag_Decades = flag.Float64("decades", 4, "for -kw and -stft: range of the logarithmic color scale, in decades below the maximum")
+
+// colors of the heat maps, from low to high
+var heatmap = []color.RGBA{{0, 0, 0, 255}, {0, 0, 160, 255}, {200, 0, 0, 255}, {255, 200, 0, 255}, {255, 255, 255, 255}}
+
+// renders z[j*Nx+i] (i along x, j along y) to a PNG heat map with axes x, y
+// and a logarithmic color scale spanning -decades below the maximum.
+func renderHeatMap(fname, title string, z []float64, Nx, Ny int, x, y draw.Axis) {
+	max := 0.
+	for _, v := range z {
+		max = math.Max(max, v)
+	}
+	if max == 0 {
+		max = 1 // no signal, all black
+	}
+	hi := float32(math.Log10(max))
+	lo := hi - float32(*flag_Decades)
+
+	p := draw.NewPlot(640, 480, title, x, y)
+	a := p.Area
+	for py := a.Min.Y; py < a.Max.Y; py++ {
+		j := (a.Max.Y - 1 - py) * Ny / a.Dy()
+		for px := a.Min.X; px < a.Max.X; px++ {
+			i := (px - a.Min.X) * Nx / a.Dx()
+			v := float32(math.Log10(z[j*Nx+i]))
+			p.Set(px, py, draw.ColorMap(lo, hi, v, heatmap...))
+		}
+	}
+
+	out := httpfs.MustCreate(fname)
+	defer out.Close()
+	check(draw.EncodeFormat(out, p, ".png"))
+	log.Println(fname)
+}
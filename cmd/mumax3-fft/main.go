@@ -12,6 +12,7 @@ import (
 
 var (
 	flag_CleanPhase = flag.Bool("cleanph", false, "output phase without 2pi jumps")
+	flag_Comp       = flag.Int("comp", 0, "component of OVF input (0, 1, 2 = x, y, z)")
 	flag_Im         = flag.Bool("im", false, "output imaginary part")
 	flag_Interp     = flag.Bool("interpolate", true, "re-sample intput at equidistant points")
 	flag_Inv        = flag.Bool("inv", false, "inverse FFT")
@@ -42,7 +43,9 @@ func main() {
 		return
 	}
 
-	if path.Ext(flag.Arg(0)) == ".ovf" {
+	if *flag_KW != "" {
+		mainDispersion()
+	} else if path.Ext(flag.Arg(0)) == ".ovf" {
 		mainSpatial()
 	} else {
 		mainTable()
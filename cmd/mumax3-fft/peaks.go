@@ -0,0 +1,364 @@
+package main
+
+// Resonance peak detection and Lorentzian fitting.
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/util"
+)
+
+var flag_Peaks = flag.Int("peaks", 0, "fit Lorentzians to the N strongest peaks of each column, CSV output")
+
+// peak of the power spectrum, A / (1 + (2(f-F0)/FWHM)²)
+type peak struct {
+	F0, FWHM, A    float64 // center, full width at half maximum (in frequency steps), height
+	dF0, dFWHM, dA float64 // standard errors
+}
+
+// fits the -peaks strongest peaks in the power spectrum |X|² of each column
+// and writes them as CSV to <table>_peaks.csv, or stdout.
+func writePeaks(infname string, header []string, transf [][]complex64, deltaF float32) {
+	var out io.Writer = os.Stdout
+	if !*flag_Stdout {
+		outfname := util.NoExt(infname) + "_peaks.csv"
+		f := httpfs.MustCreate(outfname)
+		defer f.Close()
+		out = f
+	}
+
+	Fprint(out, "column,peak,f0 (Hz),f0 error (Hz),FWHM (Hz),FWHM error (Hz),amplitude,amplitude error\n")
+	for c := range transf {
+		power := make([]float64, len(transf[c]))
+		for i, v := range transf[c] {
+			power[i] = float64(real(v)*real(v) + imag(v)*imag(v))
+		}
+		name, _ := splitHeader(header[c+1])
+		peaks, err := fitPeaks(power, *flag_Peaks)
+		if err != nil {
+			errPrintln(name, ": ", err)
+		}
+		for i, p := range peaks {
+			df := float64(deltaF)
+			_, err := fmt.Fprintf(out, "%v,%v,%v,%v,%v,%v,%v,%v\n", name, i+1,
+				float32(p.F0*df), float32(p.dF0*df), float32(p.FWHM*df), float32(p.dFWHM*df), float32(p.A), float32(p.dA))
+			check(err)
+		}
+	}
+}
+
+// locates up to n peaks in y (excluding DC) and fits them with a sum of
+// Lorentzians plus a constant background. Frequencies are in units of the bin width.
+// Peaks that fit to a center outside the fit range, a height <= 0, a width
+// larger than the fit range or below one bin, or a width or height smaller
+// than its standard error are not physical or not resolved (e.g. noise when
+// there are fewer peaks than requested). They are dropped and the others are fitted again,
+// the returned error then lists what was dropped.
+func fitPeaks(y []float64, n int) ([]peak, error) {
+	guess := findPeaks(y, n)
+	var dropped []string
+	for len(guess) > 0 {
+		peaks, lo, hi, err := fitLorentzians(y, guess)
+		if err != nil {
+			return guess, fmt.Errorf("fit failed (%v), reporting initial estimates", err)
+		}
+
+		var keep []peak
+		for i, p := range peaks {
+			if reason := p.check(lo, hi); reason != "" {
+				dropped = append(dropped, fmt.Sprint("peak near bin ", guess[i].F0, ": ", reason))
+			} else {
+				keep = append(keep, guess[i])
+			}
+		}
+		if len(keep) == len(peaks) {
+			sort.Slice(peaks, func(i, j int) bool { return peaks[i].F0 < peaks[j].F0 })
+			return peaks, droppedErr(dropped)
+		}
+		guess = keep
+	}
+	return nil, droppedErr(dropped)
+}
+
+func droppedErr(dropped []string) error {
+	if len(dropped) == 0 {
+		return nil
+	}
+	return fmt.Errorf("dropped %v", strings.Join(dropped, "; "))
+}
+
+// reason why a fitted peak is not physical, empty if it is fine.
+func (p *peak) check(lo, hi int) string {
+	switch {
+	case !(p.F0 >= float64(lo) && p.F0 <= float64(hi)):
+		return fmt.Sprint("center ", p.F0, " outside fit range [", lo, ",", hi, "]")
+	case !(p.A > 0):
+		return fmt.Sprint("height ", p.A, " <= 0")
+	case !(p.FWHM <= float64(hi-lo)):
+		return fmt.Sprint("width ", p.FWHM, " larger than fit range")
+	case p.FWHM < 1:
+		return fmt.Sprint("width ", p.FWHM, " below one bin")
+	case !(p.dFWHM <= p.FWHM):
+		return fmt.Sprint("width ", p.FWHM, " ± ", p.dFWHM, " not determined")
+	case !(p.dA <= p.A):
+		return fmt.Sprint("height ", p.A, " ± ", p.dA, " not determined")
+	}
+	return ""
+}
+
+// fits a sum of Lorentzians, starting from guess, to y in a few widths around them.
+// Returns the fitted peaks in the order of guess, and the fit range.
+func fitLorentzians(y []float64, guess []peak) (peaks []peak, lo, hi int, err error) {
+	// fit range: the peaks and a few widths around them
+	lo, hi = len(y), 0
+	for _, p := range guess {
+		lo = imin(lo, int(p.F0-3*p.FWHM))
+		hi = imax(hi, int(p.F0+3*p.FWHM)+1)
+	}
+	lo, hi = imax(lo, 1), imin(hi, len(y)-1)
+
+	// normalize for conditioning
+	scale := 0.
+	for _, p := range guess {
+		scale = math.Max(scale, p.A)
+	}
+	var x, yn []float64
+	for i := lo; i <= hi; i++ {
+		x = append(x, float64(i))
+		yn = append(yn, y[i]/scale)
+	}
+
+	params := make([]float64, 3*len(guess)+1) // F0, FWHM, A for each peak, background
+	for i, p := range guess {
+		params[3*i], params[3*i+1], params[3*i+2] = p.F0, p.FWHM, p.A/scale
+	}
+	sigma, err := levenbergMarquardt(x, yn, params, lorentzians)
+	if err != nil {
+		return nil, lo, hi, err
+	}
+
+	peaks = make([]peak, len(guess))
+	for i := range peaks {
+		p := &peaks[i]
+		p.F0, p.FWHM, p.A = params[3*i], math.Abs(params[3*i+1]), params[3*i+2]*scale
+		p.dF0, p.dFWHM, p.dA = sigma[3*i], sigma[3*i+1], sigma[3*i+2]*scale
+	}
+	return peaks, lo, hi, nil
+}
+
+// initial estimates of the n strongest peaks: local maxima, their height
+// and the width between the half-maximum crossings. A maximum within the
+// half-maximum width of a stronger peak is not counted as a separate peak.
+func findPeaks(y []float64, n int) []peak {
+	var cand []int
+	for i := 1; i < len(y)-1; i++ {
+		if y[i] > y[i-1] && y[i] >= y[i+1] {
+			cand = append(cand, i)
+		}
+	}
+	sort.Slice(cand, func(a, b int) bool { return y[cand[a]] > y[cand[b]] })
+
+	var peaks []peak
+	var spans [][2]float64
+	for _, i := range cand {
+		if len(peaks) == n {
+			break
+		}
+		inside := false
+		for _, s := range spans {
+			if float64(i) >= s[0] && float64(i) <= s[1] {
+				inside = true
+			}
+		}
+		if inside {
+			continue
+		}
+		half := y[i] / 2
+		l := i
+		for l > 0 && y[l] > half {
+			l--
+		}
+		r := i
+		for r < len(y)-1 && y[r] > half {
+			r++
+		}
+		left := float64(l) + (half-y[l])/(y[l+1]-y[l])
+		right := float64(r) - (half-y[r])/(y[r-1]-y[r])
+		if y[l] > half { // no crossing down to the edge
+			left = float64(l)
+		}
+		if y[r] > half {
+			right = float64(r)
+		}
+		fwhm := math.Max(right-left, 1)
+		peaks = append(peaks, peak{F0: float64(i), FWHM: fwhm, A: y[i]})
+		spans = append(spans, [2]float64{left, right})
+	}
+	return peaks
+}
+
+// sum of Lorentzians A / (1 + (2(x-F0)/FWHM)²) with parameters F0, FWHM, A, ...
+// and a constant background as last parameter. Sets the gradient to the parameters.
+func lorentzians(x float64, p, grad []float64) float64 {
+	npeak := len(p) / 3
+	v := p[len(p)-1]
+	grad[len(p)-1] = 1
+	for i := 0; i < npeak; i++ {
+		f0, w, a := p[3*i], p[3*i+1], p[3*i+2]
+		u := 2 * (x - f0) / w
+		d := 1 / (1 + u*u)
+		v += a * d
+		grad[3*i] = 4 * a * u * d * d / w
+		grad[3*i+1] = 2 * a * u * u * d * d / w
+		grad[3*i+2] = d
+	}
+	return v
+}
+
+// Levenberg-Marquardt least-squares fit of model to y(x), starting from params, which are updated.
+// Returns the standard errors of the parameters, from the covariance matrix scaled by the residual variance.
+func levenbergMarquardt(x, y, params []float64, model func(x float64, p, grad []float64) float64) ([]float64, error) {
+	np := len(params)
+	if len(x) <= np {
+		return nil, fmt.Errorf("%v points for %v parameters", len(x), np)
+	}
+	grad := make([]float64, np)
+	trial := make([]float64, np)
+
+	rss := func(p []float64) float64 {
+		sum := 0.
+		for i := range x {
+			r := y[i] - model(x[i], p, grad)
+			sum += r * r
+		}
+		return sum
+	}
+
+	// JᵀJ and Jᵀr at p
+	normal := func(p []float64) (jtj [][]float64, jtr []float64) {
+		jtj = make([][]float64, np)
+		for i := range jtj {
+			jtj[i] = make([]float64, np)
+		}
+		jtr = make([]float64, np)
+		for i := range x {
+			r := y[i] - model(x[i], p, grad)
+			for a := 0; a < np; a++ {
+				jtr[a] += grad[a] * r
+				for b := 0; b < np; b++ {
+					jtj[a][b] += grad[a] * grad[b]
+				}
+			}
+		}
+		return
+	}
+
+	lambda := 1e-3
+	cost := rss(params)
+	for iter := 0; iter < 500; iter++ {
+		jtj, jtr := normal(params)
+		improved, converged := false, false
+		for !improved && lambda < 1e12 {
+			a := make([][]float64, np)
+			for i := range a {
+				a[i] = append([]float64{}, jtj[i]...)
+				a[i][i] += lambda * math.Max(jtj[i][i], 1e-30)
+			}
+			step, ok := solve(a, append([]float64{}, jtr...))
+			if !ok {
+				lambda *= 10
+				continue
+			}
+			for i := range trial {
+				trial[i] = params[i] + step[i]
+			}
+			c := rss(trial)
+			if c >= cost {
+				lambda *= 10
+				continue
+			}
+			converged = cost-c <= 1e-12*cost
+			copy(params, trial)
+			cost = c
+			lambda = math.Max(lambda/10, 1e-12)
+			improved = true
+		}
+		if !improved || converged {
+			break // minimum reached within precision
+		}
+	}
+
+	// covariance = (JᵀJ)⁻¹ * rss/(n-p)
+	jtj, _ := normal(params)
+	variance := cost / float64(len(x)-np)
+	sigma := make([]float64, np)
+	for i := range sigma {
+		e := make([]float64, np)
+		e[i] = 1
+		a := make([][]float64, np)
+		for j := range a {
+			a[j] = append([]float64{}, jtj[j]...)
+		}
+		col, ok := solve(a, e)
+		if !ok {
+			return nil, fmt.Errorf("singular fit")
+		}
+		sigma[i] = math.Sqrt(math.Abs(col[i]) * variance)
+	}
+	return sigma, nil
+}
+
+// solves a x = b by Gaussian elimination with partial pivoting, overwriting a and b.
+func solve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for k := 0; k < n; k++ {
+		piv := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[piv][k]) {
+				piv = i
+			}
+		}
+		if a[piv][k] == 0 {
+			return nil, false
+		}
+		a[k], a[piv] = a[piv], a[k]
+		b[k], b[piv] = b[piv], b[k]
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+			for j := k; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+			b[i] -= f * b[k]
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := b[i]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i][j] * x[j]
+		}
+		x[i] = sum / a[i][i]
+	}
+	return x, true
+}
+
+func imin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func imax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
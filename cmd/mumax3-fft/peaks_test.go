@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// spectrum with Lorentzian peaks at f0 with width fwhm and height 1,
+// on top of a little noise.
+func spectrum(seed int64, f0, fwhm []float64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	y := make([]float64, 512)
+	for i := range y {
+		y[i] = 1e-3 * rng.Float64()
+		for j := range f0 {
+			u := 2 * (float64(i) - f0[j]) / fwhm[j]
+			y[i] += 1 / (1 + u*u)
+		}
+	}
+	return y
+}
+
+// Asking for more peaks than there are should not report noise fits as peaks.
+func TestFitPeaksFewerThanRequested(t *testing.T) {
+	f0 := []float64{100.3, 300.7}
+	fwhm := []float64{6, 10}
+	for seed := int64(0); seed < 20; seed++ {
+		y := spectrum(seed, f0, fwhm)
+		for _, n := range []int{3, 5} {
+			peaks, _ := fitPeaks(y, n)
+			found := 0
+			for _, p := range peaks {
+				if !(p.A > 0) || !(p.F0 >= 0 && p.F0 < float64(len(y))) || !(p.FWHM < float64(len(y))) {
+					t.Errorf("seed %v, n=%v: unphysical peak %+v", seed, n, p)
+				}
+				for j := range f0 {
+					if math.Abs(p.F0-f0[j]) < 0.1 && math.Abs(p.FWHM-fwhm[j]) < 0.2 && math.Abs(p.A-1) < 0.02 {
+						found++
+					}
+				}
+			}
+			if found != len(f0) {
+				t.Errorf("seed %v, n=%v: found %v of %v peaks: %+v", seed, n, found, len(f0), peaks)
+			}
+		}
+	}
+}
+
+func TestPeakCheck(t *testing.T) {
+	for _, p := range []peak{
+		{F0: 6.4e17, FWHM: 3, A: 1},
+		{F0: 50, FWHM: 3, A: -0.0037},
+		{F0: 50, FWHM: 300, A: 1},
+		{F0: math.NaN(), FWHM: 3, A: 1},
+		{F0: 50, FWHM: 0.028, A: 3.37, dFWHM: 17.2, dA: 4108}, // 1.4e7 ± 8.6e9 Hz wide, with 0.5 GHz bins
+		{F0: 50, FWHM: 3, A: 1, dFWHM: 4, dA: 0.1},
+		{F0: 50, FWHM: 3, A: 1, dFWHM: 0.1, dA: 2},
+	} {
+		if p.check(10, 100) == "" {
+			t.Errorf("%+v accepted", p)
+		}
+	}
+	if reason := (&peak{F0: 50, FWHM: 3, A: 1, dFWHM: 0.1, dA: 0.01}).check(10, 100); reason != "" {
+		t.Error(reason)
+	}
+}
+
+// A spike in a single bin is not a resolved peak, it should be dropped
+// and the remaining peaks fitted again.
+func TestFitPeaksSpike(t *testing.T) {
+	f0 := []float64{100.3, 300.7}
+	fwhm := []float64{6, 10}
+	for seed := int64(0); seed < 20; seed++ {
+		y := spectrum(seed, f0, fwhm)
+		y[450] += 3
+		peaks, err := fitPeaks(y, 3)
+		if len(peaks) != len(f0) || err == nil {
+			t.Errorf("seed %v: have %+v (%v), want %v peaks and the spike dropped", seed, peaks, err, len(f0))
+			continue
+		}
+		for i, p := range peaks {
+			if math.Abs(p.F0-f0[i]) > 0.1 || math.Abs(p.FWHM-fwhm[i]) > 0.2 {
+				t.Errorf("seed %v: have %+v, want F0 %v, FWHM %v", seed, p, f0[i], fwhm[i])
+			}
+		}
+	}
+}
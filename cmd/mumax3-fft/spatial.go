@@ -6,8 +6,6 @@ import (
 	"log"
 	"math"
 
-	"github.com/barnex/fftw"
-	"github.com/barnex/matrix"
 	"github.com/mumax/3/data"
 	"github.com/mumax/3/httpfs"
 	"github.com/mumax/3/oommf"
@@ -24,7 +22,7 @@ func mainSpatial() {
 	go loadloop()
 
 	// select one component
-	comp := 0 // todo
+	comp := *flag_Comp
 
 	// get size, time span from first and last file
 	data1, meta1 := oommf.MustReadFile(flag.Args()[0])
@@ -42,7 +40,10 @@ func mainSpatial() {
 
 	// allocate buffer for everything
 	dataList := make([]complex64, Nt*Nx*Ny*Nz)
-	dataLists := matrix.ReshapeC2(dataList, [2]int{Nt, Nz * Ny * Nx})
+	dataLists := make([][]complex64, Nt) // dataLists[t] = all cells at time t
+	for t := range dataLists {
+		dataLists[t] = dataList[t*Nz*Ny*Nx : (t+1)*Nz*Ny*Nx]
+	}
 
 	// interpolate non-equidistant time points
 	// make complex in the meanwhile
@@ -120,22 +121,6 @@ func normalize(dataList [][]complex64) {
 	}
 }
 
-func fftMany(dataList []complex64, Nt, Nc int) {
-	howmany := Nc
-	n := []int{Nt}
-	in := dataList
-	out := dataList
-	istride := Nc
-	idist := 1
-	inembed := n
-	ostride := istride
-	odist := idist
-	onembed := inembed
-	plan := fftw.PlanManyC2C(n, howmany, in, inembed, istride, idist, out, onembed, ostride, odist, fftw.FORWARD, fftw.ESTIMATE)
-	plan.Execute()
-	//plan.Destroy()
-}
-
 func output3D(D [][]complex64, reduce func(complex64) float32, size [3]int, prefix string, deltaF float32) {
 	const NCOMP = 1
 	for i := 0; i < len(D)/2; i++ {
@@ -0,0 +1,126 @@
+package main
+
+// Short-time Fourier transform (spectrogram) of table columns.
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/draw"
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/util"
+)
+
+var flag_STFT = flag.String("stft", "", "short-time FFT (spectrogram) of table columns: window,hop in number of rows, e.g. 256,32")
+
+// writes the spectrogram |X(t, f)| of each column of an equidistant table,
+// as a gnuplot matrix (<table>_stft_<column>.txt) and a PNG heat map.
+// Segments of -stft window rows, every hop rows, are windowed and zero-padded by -zeropad.
+func stft(infname string, header []string, data [][]float32, window windowFunc) {
+	const TIME = 0 // time column
+	size, hop := parseSTFT(*flag_STFT)
+	rows := len(data[TIME])
+	if size > rows {
+		log.Fatal("-stft window of ", size, " rows does not fit in ", rows, " rows of ", infname)
+	}
+	deltaT := data[TIME][1] - data[TIME][0]
+	n := size * (*flag_Pad)                      // transform length
+	deltaF := 1 / (float64(deltaT) * float64(n)) // frequency resolution
+	Nf := n / 2                                  // frequencies without nyquist
+	Ns := (rows-size)/hop + 1                    // number of segments
+
+	// segment center times
+	times := make([]float64, Ns)
+	for s := range times {
+		times[s] = float64(data[TIME][s*hop]) + float64(size-1)/2*float64(deltaT)
+	}
+
+	segment := make([]float32, size)
+	for c := 1; c < len(data); c++ {
+		spec := make([]float64, Nf*Ns) // spec[f*Ns+s]
+		for s := 0; s < Ns; s++ {
+			copy(segment, data[c][s*hop:s*hop+size])
+			applyWindow(segment, window)
+			transf := fftR2C(zeropad(segment, n))
+			norm := math.Sqrt(float64(n))
+			for f := 0; f < Nf; f++ {
+				v := transf[f]
+				spec[f*Ns+s] = math.Hypot(float64(real(v)), float64(imag(v))) / norm
+			}
+		}
+
+		name, unit := splitHeader(header[c])
+		base := util.NoExt(infname) + "_stft_" + fileName(name)
+		writeMatrix(base+".txt", spec, times, deltaF)
+		x := draw.Axis{Label: "t (ns)", Min: (times[0] - float64(hop)*float64(deltaT)/2) * 1e9, Max: (times[Ns-1] + float64(hop)*float64(deltaT)/2) * 1e9}
+		y := draw.Axis{Label: "f (GHz)", Min: 0, Max: float64(Nf) * deltaF * 1e-9}
+		title := "|" + name + "(t, f)|"
+		if unit != "" {
+			title += " (" + unit + ")"
+		}
+		renderHeatMap(base+".png", title, spec, Ns, Nf, x, y)
+	}
+}
+
+// parses -stft window,hop
+func parseSTFT(arg string) (size, hop int) {
+	words := strings.Split(arg, ",")
+	if len(words) != 2 {
+		log.Fatal("-stft needs window,hop in rows, e.g. 256,32, have: ", arg)
+	}
+	var err1, err2 error
+	size, err1 = strconv.Atoi(strings.TrimSpace(words[0]))
+	hop, err2 = strconv.Atoi(strings.TrimSpace(words[1]))
+	if err1 != nil || err2 != nil || size < 2 || hop < 1 {
+		log.Fatal("-stft needs a window of at least 2 rows and a hop of at least 1, have: ", arg)
+	}
+	return size, hop
+}
+
+// gnuplot nonuniform matrix: the first line holds the number of times followed by the times (s),
+// each next line a frequency (Hz) followed by the magnitudes. Plot with:
+//
+//	plot "table_stft_mx.txt" nonuniform matrix with image
+func writeMatrix(fname string, spec []float64, times []float64, deltaF float64) {
+	f := httpfs.MustCreate(fname)
+	defer f.Close()
+	out := bufio.NewWriter(f)
+	defer out.Flush()
+	Ns := len(times)
+	fmt.Fprint(out, Ns)
+	for _, t := range times {
+		fmt.Fprint(out, " ", float32(t))
+	}
+	fmt.Fprintln(out)
+	for i := 0; i < len(spec)/Ns; i++ {
+		fmt.Fprint(out, float32(float64(i)*deltaF))
+		for _, v := range spec[i*Ns : (i+1)*Ns] {
+			fmt.Fprint(out, " ", float32(v))
+		}
+		fmt.Fprintln(out)
+	}
+	log.Println(fname)
+}
+
+// splits a table header entry "name (unit)"
+func splitHeader(h string) (name, unit string) {
+	if i := strings.LastIndex(h, " ("); i >= 0 && strings.HasSuffix(h, ")") {
+		return h[:i], h[i+2 : len(h)-1]
+	}
+	return h, ""
+}
+
+// column name usable in a file name
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == '.' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mumax/3/engine"
+	"github.com/mumax/3/script"
+)
+
+// open input file, recompiled on every change
+type document struct {
+	uri   string
+	lines []string
+	refs  []script.Ref // identifiers found by the compiler
+	err   error        // compile error, if any
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri}
+	d.update(text)
+	return d
+}
+
+// update sets new text and compiles it, like mumax3 -vet.
+func (d *document) update(text string) {
+	d.lines = strings.Split(text, "\n")
+	d.refs, d.err = compile(d.dir(), text)
+}
+
+func compile(dir, text string) (refs []script.Ref, err error) {
+	if dir != "" {
+		os.Chdir(dir) // for source()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	engine.World.EnterScope() // avoid name collisions between separate files
+	defer engine.World.ExitScope()
+	_, refs, err = engine.World.CompileRefs(text)
+	return refs, err
+}
+
+// directory of the file, if it's local.
+func (d *document) dir() string {
+	u, err := url.Parse(d.uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return path.Dir(u.Path)
+}
+
+func (d *document) diagnostics() []diagnostic {
+	diag := []diagnostic{} // not nil: empty list clears old diagnostics
+	if d.err == nil {
+		return diag
+	}
+	var p script.Position
+	msg := d.err.Error()
+	if e, ok := d.err.(*script.Error); ok {
+		p, msg = e.Position, e.Msg
+	}
+	if p.Line == 0 { // unknown position
+		p = script.Position{Line: 1, Column: 1}
+	}
+	start := d.lspPos(p)
+	end := start
+	if _, e, ok := d.word(start); ok && e.Character > start.Character {
+		end = e
+	} else if start.Character < d.utf16Len(start.Line) {
+		end.Character++
+	}
+	return append(diag, diagnostic{Range: span{start, end}, Severity: 1, Source: "mumax3", Message: msg})
+}
+
+// hover text for the identifier at pos.
+func (d *document) hover(pos position) *hover {
+	start, end, ok := d.word(pos)
+	if !ok {
+		return nil
+	}
+	name := d.text(start, end)
+	var text string
+	if ref := d.refAt(start); ref != nil && ref.Decl.Line != 0 {
+		text = fmt.Sprintf("```go\n%v\n```\ndeclared on line %v", signature(ref.Name, ref.Type), ref.Decl.Line)
+	} else if ident, ok := lookup(name); ok {
+		text = ident.markdown()
+	} else {
+		return nil
+	}
+	return &hover{Contents: markupContent{"markdown", text}, Range: &span{start, end}}
+}
+
+// location where the identifier at pos was declared.
+// If the document does not compile, the compiler may not have got that far,
+// and the declaration is looked up with scanDecl instead.
+func (d *document) definition(pos position) *location {
+	start, end, ok := d.word(pos)
+	if !ok {
+		return nil
+	}
+	name := d.text(start, end)
+	var decl script.Position
+	if ref := d.refAt(start); ref != nil {
+		decl = ref.Decl
+	}
+	if decl.Line == 0 && d.err != nil {
+		decl = d.scanDecl(name, start)
+	}
+	if decl.Line == 0 {
+		return nil
+	}
+	s := d.lspPos(decl)
+	e := s
+	e.Character += len(utf16.Encode([]rune(name)))
+	return &location{URI: d.uri, Range: span{s, e}}
+}
+
+// scanDecl finds the declaration of name used at pos without compiling,
+// so that it works on code with errors: the last "name :=", "a, name :=",
+// "var name" or "func name" before pos, or else the first one after it.
+// Returns a zero Position if there is none.
+func (d *document) scanDecl(name string, pos position) script.Position {
+	src := []byte(strings.Join(d.lines, "\n"))
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", -1, len(src)), src, nil, 0) // errors are expected
+
+	type ident struct {
+		pos token.Pos
+		lit string
+	}
+	var decls []script.Position
+	var list []ident // identifiers in a list like "a, b", that may precede :=
+	declare := func(p token.Pos, lit string) {
+		if strings.EqualFold(lit, name) { // script identifiers are case-insensitive
+			q := fset.Position(p)
+			decls = append(decls, script.Position{Line: q.Line, Column: q.Column})
+		}
+	}
+	prev := token.ILLEGAL
+	for {
+		p, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch {
+		case tok == token.IDENT && (prev == token.VAR || prev == token.FUNC):
+			declare(p, lit)
+		case tok == token.IDENT && (prev == token.COMMA || len(list) == 0):
+			list = append(list, ident{p, lit})
+		case tok == token.DEFINE:
+			for _, id := range list {
+				declare(id.pos, id.lit)
+			}
+		}
+		if tok != token.IDENT && tok != token.COMMA {
+			list = list[:0]
+		}
+		prev = tok
+	}
+
+	var found script.Position
+	for _, p := range decls {
+		if found.Line != 0 && d.lspPos(p).Line > pos.Line {
+			break
+		}
+		found = p
+	}
+	return found
+}
+
+// completion candidates at pos: all identifiers,
+// or methods if pos follows "identifier.".
+func (d *document) completion(pos position) []completionItem {
+	line, col := d.byteOffset(pos)
+	text := d.lines[line][:col]
+	prefix := strings.TrimRightFunc(text, isIdentRune)
+	if strings.HasSuffix(prefix, ".") {
+		recv := strings.TrimSuffix(prefix, ".")
+		name := recv[len(strings.TrimRightFunc(recv, isIdentRune)):]
+		return d.methods(line, len(recv)-len(name), name)
+	}
+
+	items := []completionItem{}
+	seen := make(map[string]bool)
+	for _, r := range d.refs {
+		lname := strings.ToLower(r.Name)
+		if r.Decl.Line == 0 || seen[lname] {
+			continue
+		}
+		seen[lname] = true
+		items = append(items, completionItem{Label: r.Name, Kind: kindOf(r.Type), Detail: signature(r.Name, r.Type)})
+	}
+	for _, ident := range idents {
+		if seen[strings.ToLower(ident.name)] {
+			continue // shadowed
+		}
+		items = append(items, completionItem{Label: ident.name, Kind: ident.kind(), Detail: ident.signature(),
+			Documentation: &markupContent{"markdown", ident.doc()}})
+	}
+	return items
+}
+
+// methods of the identifier name, found at given line and byte offset.
+func (d *document) methods(line, col int, name string) []completionItem {
+	var t reflect.Type
+	if ref := d.refAt(position{line, d.utf16Col(line, col)}); ref != nil {
+		t = ref.Type
+	} else if ident, ok := lookup(name); ok {
+		t = ident.typ
+	}
+	items := []completionItem{}
+	if t == nil {
+		return items
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if hidden(m.Name) {
+			continue
+		}
+		ft := m.Type
+		if t.Kind() != reflect.Interface {
+			ft = methodType(ft) // strip receiver
+		}
+		items = append(items, completionItem{Label: m.Name, Kind: kindMethod, Detail: signature(m.Name, ft)})
+	}
+	return items
+}
+
+// type of method without its receiver argument
+func methodType(t reflect.Type) reflect.Type {
+	in := make([]reflect.Type, t.NumIn()-1)
+	for i := range in {
+		in[i] = t.In(i + 1)
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+	return reflect.FuncOf(in, out, t.IsVariadic())
+}
+
+// reference starting at pos, if any.
+func (d *document) refAt(pos position) *script.Ref {
+	for i, r := range d.refs {
+		if d.lspPos(r.Pos) == pos {
+			return &d.refs[i]
+		}
+	}
+	return nil
+}
+
+// start and end of the identifier around pos.
+func (d *document) word(pos position) (start, end position, ok bool) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return pos, pos, false
+	}
+	line, col := d.byteOffset(pos)
+	text := d.lines[line]
+	s := len(strings.TrimRightFunc(text[:col], isIdentRune))
+	e := col + len(text[col:]) - len(strings.TrimLeftFunc(text[col:], isIdentRune))
+	if s == e {
+		return pos, pos, false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[s:]); unicode.IsDigit(r) {
+		return pos, pos, false // number
+	}
+	return position{line, d.utf16Col(line, s)}, position{line, d.utf16Col(line, e)}, true
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// text between start and end on the same line.
+func (d *document) text(start, end position) string {
+	line, s := d.byteOffset(start)
+	_, e := d.byteOffset(end)
+	return d.lines[line][s:e]
+}
+
+// LSP position of a script position.
+func (d *document) lspPos(p script.Position) position {
+	line := p.Line - 1
+	if line >= len(d.lines) {
+		line = len(d.lines) - 1
+	}
+	return position{line, d.utf16Col(line, p.Column-1)}
+}
+
+// UTF-16 offset of the byte offset col in line.
+func (d *document) utf16Col(line, col int) int {
+	text := d.lines[line]
+	if col > len(text) {
+		col = len(text)
+	}
+	return len(utf16.Encode([]rune(text[:col])))
+}
+
+func (d *document) utf16Len(line int) int {
+	return d.utf16Col(line, len(d.lines[line]))
+}
+
+// line and byte offset of an LSP position, clipped to the document.
+func (d *document) byteOffset(p position) (line, col int) {
+	line = p.Line
+	if line >= len(d.lines) {
+		line = len(d.lines) - 1
+	}
+	if line < 0 {
+		line = 0
+	}
+	n := 0
+	for i, r := range d.lines[line] {
+		if n >= p.Character {
+			return line, i
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return line, len(d.lines[line])
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+// Go to definition should work while the document does not compile,
+// here due to the missing ) on line 11.
+func TestDefinitionWithErrors(t *testing.T) {
+	src := `a := 1
+func f(x float64) float64 {
+	return x * 2
+}
+b, c := a, f(1)
+/* d := 3 */
+print(c, "e := 4")
+var w = 2
+for i := 0; i < 3; i++ {
+	c := a + i
+	print(c
+}
+print(c, w, F(2))
+`
+	d := newDocument("file:///tmp/test.mx3", src)
+	if d.err == nil {
+		t.Fatal("document should not compile")
+	}
+	for _, c := range []struct {
+		use, decl position
+	}{
+		{position{4, 8}, position{0, 0}},   // a
+		{position{6, 6}, position{4, 3}},   // c in "b, c :="
+		{position{10, 7}, position{9, 1}},  // c in the loop
+		{position{8, 12}, position{8, 4}},  // i
+		{position{12, 9}, position{7, 4}},  // w
+		{position{12, 12}, position{1, 5}}, // F: case-insensitive
+	} {
+		l := d.definition(c.use)
+		if l == nil {
+			t.Errorf("%v: no definition, want %v", c.use, c.decl)
+			continue
+		}
+		if l.Range.Start != c.decl {
+			t.Errorf("%v: have definition at %v, want %v", c.use, l.Range.Start, c.decl)
+		}
+	}
+	if l := d.definition(position{6, 6 + len(`c, "e`)}); l != nil {
+		t.Errorf("definition in string: %v", l)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mumax/3/engine"
+)
+
+// identifier declared by the engine, like Msat or SetGridSize.
+type ident struct {
+	name string // as declared, e.g. "Msat"
+	typ  reflect.Type
+}
+
+var (
+	idents    []*ident          // sorted by name
+	identsByL map[string]*ident // by lower-case name (script is case-independent)
+)
+
+func loadIdents() {
+	identsByL = make(map[string]*ident)
+	for name := range engine.World.Doc {
+		e := engine.World.Identifiers[strings.ToLower(name)]
+		if e == nil || e.Type() == nil {
+			continue
+		}
+		id := &ident{name, e.Type()}
+		idents = append(idents, id)
+		identsByL[strings.ToLower(name)] = id
+	}
+	sort.Slice(idents, func(i, j int) bool { return strings.ToLower(idents[i].name) < strings.ToLower(idents[j].name) })
+}
+
+func lookup(name string) (*ident, bool) {
+	id, ok := identsByL[strings.ToLower(name)]
+	return id, ok
+}
+
+func (id *ident) signature() string { return signature(id.name, id.typ) }
+func (id *ident) kind() int         { return kindOf(id.typ) }
+
+// documentation, including the unit if any.
+func (id *ident) doc() string {
+	doc := engine.World.Doc[id.name]
+	if unit := engine.IdentUnit(id.name); unit != "" {
+		doc += "\n\nunit: " + unit
+	}
+	return doc
+}
+
+func (id *ident) markdown() string {
+	return "```go\n" + id.signature() + "\n```\n" + id.doc()
+}
+
+// e.g.: "SetGridSize(int, int, int)", "Msat float64".
+func signature(name string, t reflect.Type) string {
+	if t == nil {
+		return name
+	}
+	typ := cleanType(t.String())
+	if t.Kind() == reflect.Func {
+		return name + strings.TrimPrefix(typ, "func")
+	}
+	return name + " " + typ
+}
+
+func kindOf(t reflect.Type) int {
+	if t != nil && t.Kind() == reflect.Func {
+		return kindFunction
+	}
+	return kindVariable
+}
+
+// dumbed-down type, as in the API documentation.
+func cleanType(typ string) string {
+	typ = strings.Replace(typ, "engine.", "", -1)
+	typ = strings.Replace(typ, "*data.", "", -1)
+	typ = strings.Replace(typ, "script.", "", -1)
+	return typ
+}
+
+// methods not meant to be called from scripts.
+func hidden(name string) bool {
+	switch name {
+	default:
+		return name[0] < 'A' || name[0] > 'Z'
+	case "Eval", "InputType", "Type", "Slice", "Name", "Unit", "NComp", "Mesh", "SetValue", "String", "Child", "Fix":
+		return true
+	}
+}
@@ -0,0 +1,181 @@
+/*
+mumax3-lsp is a language server for mumax3 input files (.mx3).
+It speaks the Language Server Protocol over stdin/stdout and provides
+diagnostics (compile errors, as reported by mumax3 -vet, while you type),
+completion of identifiers like Msat or SetGridSize with their documentation,
+hover with signature, unit and documentation,
+and go to definition of variables and functions declared in the script.
+Files are compiled but never run, no GPU is needed.
+
+Configure your editor to start mumax3-lsp for .mx3 files. E.g., for vim with vim-lsp:
+
+	au User lsp_setup call lsp#register_server({
+		\ 'name': 'mumax3-lsp',
+		\ 'cmd': ['mumax3-lsp'],
+		\ 'allowlist': ['mx3'],
+		\ })
+	au BufRead,BufNewFile *.mx3 set filetype=mx3
+
+Or for neovim:
+
+	vim.lsp.start({name = 'mumax3-lsp', cmd = {'mumax3-lsp'}})
+
+Errors are logged to stderr.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+)
+
+// accepted for compatibility with editors that pass it, stdio is the only transport.
+var _ = flag.Bool("stdio", true, "communicate over stdin/stdout")
+
+func main() {
+	flag.Parse()
+	log.SetPrefix("mumax3-lsp: ")
+	log.SetFlags(0)
+
+	out := os.Stdout
+	os.Stdout = os.Stderr // stray output would corrupt the protocol
+
+	loadIdents()
+	s := &server{conn: newConn(os.Stdin, out), docs: make(map[string]*document)}
+	s.serve()
+}
+
+type server struct {
+	conn     *conn
+	docs     map[string]*document // open documents by URI
+	shutdown bool                 // shutdown requested, exit is next
+}
+
+func (s *server) serve() {
+	for {
+		req, err := s.conn.read()
+		if err == io.EOF {
+			os.Exit(1) // client died without exit
+		}
+		if err != nil {
+			if _, ok := err.(*rpcError); !ok {
+				log.Fatal(err) // broken stream, can't recover
+			}
+			log.Println(err)
+			continue
+		}
+
+		result, err := s.handle(req)
+		if err != nil {
+			log.Println(req.Method, ":", err)
+		}
+		if req.ID != nil { // request, not notification
+			if err := s.conn.reply(req.ID, result, err); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func (s *server) handle(req *request) (result interface{}, err error) {
+	switch req.Method {
+	default:
+		if req.ID == nil {
+			return nil, nil // unhandled notifications are ignored
+		}
+		return nil, &rpcError{errMethodNotFound, "method not supported: " + req.Method}
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // full text on every change
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{"."}},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "mumax3-lsp"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "exit":
+		if s.shutdown {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := unmarshal(req.Params, &p); err != nil {
+			return nil, err
+		}
+		d := newDocument(p.TextDocument.URI, p.TextDocument.Text)
+		s.docs[d.uri] = d
+		return nil, s.publish(d)
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := unmarshal(req.Params, &p); err != nil {
+			return nil, err
+		}
+		d := s.docs[p.TextDocument.URI]
+		if d == nil || len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		d.update(p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return nil, s.publish(d)
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := unmarshal(req.Params, &p); err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return nil, s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{p.TextDocument.URI, []diagnostic{}})
+	case "textDocument/completion":
+		d, p, err := s.position(req)
+		if d == nil {
+			return nil, err
+		}
+		return d.completion(p), nil
+	case "textDocument/hover":
+		d, p, err := s.position(req)
+		if d == nil {
+			return nil, err
+		}
+		if h := d.hover(p); h != nil {
+			return h, nil
+		}
+		return nil, nil // not *hover(nil), which would not marshal to null
+	case "textDocument/definition":
+		d, p, err := s.position(req)
+		if d == nil {
+			return nil, err
+		}
+		if l := d.definition(p); l != nil {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+// sends the diagnostics for d.
+func (s *server) publish(d *document) error {
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{d.uri, d.diagnostics()})
+}
+
+// document and position of a textDocument/... request.
+// Returns a nil document if it is not open.
+func (s *server) position(req *request) (*document, position, error) {
+	var p positionParams
+	if err := unmarshal(req.Params, &p); err != nil {
+		return nil, position{}, err
+	}
+	return s.docs[p.TextDocument.URI], p.Position, nil
+}
+
+func unmarshal(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{errInvalidParams, err.Error()}
+	}
+	return nil
+}
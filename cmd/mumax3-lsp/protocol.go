@@ -0,0 +1,181 @@
+package main
+
+// JSON-RPC 2.0 with the LSP base protocol framing:
+// 	Content-Length: 123\r\n
+// 	\r\n
+// 	{"jsonrpc":"2.0", ...}
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// incoming request or notification (notifications have no ID)
+type request struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+// JSON-RPC error codes
+const (
+	errParse          = -32700
+	errMethodNotFound = -32601
+	errInvalidParams  = -32602
+	errInternal       = -32603
+)
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+type conn struct {
+	in  *textproto.Reader
+	out io.Writer
+}
+
+func newConn(in io.Reader, out io.Writer) *conn {
+	return &conn{in: textproto.NewReader(bufio.NewReader(in)), out: out}
+}
+
+// read the next message.
+func (c *conn) read() (*request, error) {
+	header, err := c.in.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("bad Content-Length: %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.in.R, body); err != nil {
+		return nil, err
+	}
+	req := new(request)
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, &rpcError{errParse, err.Error()}
+	}
+	return req, nil
+}
+
+// reply to request with given id, sends err instead of result if not nil.
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if err != nil {
+		e, ok := err.(*rpcError)
+		if !ok {
+			e = &rpcError{errInternal, err.Error()}
+		}
+		msg["error"] = e
+	} else {
+		msg["result"] = result // null is a valid result
+	}
+	return c.write(msg)
+}
+
+// send a notification.
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (c *conn) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.out.Write(body)
+	return err
+}
+
+// LSP types, only the fields we use.
+
+type position struct {
+	Line      int `json:"line"`      // counts from 0
+	Character int `json:"character"` // in UTF-16 code units, counts from 0
+}
+
+type span struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string `json:"uri"`
+	Range span   `json:"range"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"` // full text, we only support full sync
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type diagnostic struct {
+	Range    span   `json:"range"`
+	Severity int    `json:"severity"` // 1: error
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"` // "markdown"
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    *span         `json:"range,omitempty"`
+}
+
+// completion item kinds
+const (
+	kindMethod   = 2
+	kindFunction = 3
+	kindVariable = 6
+)
+
+type completionItem struct {
+	Label         string         `json:"label"`
+	Kind          int            `json:"kind"`
+	Detail        string         `json:"detail,omitempty"`
+	Documentation *markupContent `json:"documentation,omitempty"`
+}
@@ -0,0 +1,174 @@
+package main
+
+// Evaluator for Tcl expr with floating-point arithmetic and math functions.
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// evaluates an arithmetic expression like "2*(1+3)/sqrt(4)".
+func evalExpr(src string) (float64, error) {
+	e := &exprParser{src: src}
+	v, err := e.sum()
+	if err != nil {
+		return 0, err
+	}
+	e.skipSpace()
+	if e.pos != len(e.src) {
+		return 0, fmt.Errorf("expr %q: unexpected %q", src, e.src[e.pos:])
+	}
+	return v, nil
+}
+
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (e *exprParser) skipSpace() {
+	for e.pos < len(e.src) && strings.ContainsRune(" \t\r\n", rune(e.src[e.pos])) {
+		e.pos++
+	}
+}
+
+// consumes op if it comes next
+func (e *exprParser) accept(op string) bool {
+	e.skipSpace()
+	if strings.HasPrefix(e.src[e.pos:], op) {
+		e.pos += len(op)
+		return true
+	}
+	return false
+}
+
+// sum = product {("+"|"-") product}
+func (e *exprParser) sum() (float64, error) {
+	v, err := e.product()
+	for err == nil {
+		switch {
+		case e.accept("+"):
+			var w float64
+			w, err = e.product()
+			v += w
+		case e.accept("-"):
+			var w float64
+			w, err = e.product()
+			v -= w
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+// product = power {("*"|"/"|"%") power}
+func (e *exprParser) product() (float64, error) {
+	v, err := e.power()
+	for err == nil {
+		switch {
+		case strings.HasPrefix(e.src[e.pos:], "**"):
+			return v, nil
+		case e.accept("*"):
+			var w float64
+			w, err = e.power()
+			v *= w
+		case e.accept("/"):
+			var w float64
+			w, err = e.power()
+			v /= w
+		case e.accept("%"):
+			var w float64
+			w, err = e.power()
+			v = math.Mod(v, w)
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+// power = unary ["**" power]
+func (e *exprParser) power() (float64, error) {
+	v, err := e.unary()
+	if err != nil {
+		return 0, err
+	}
+	if e.accept("**") {
+		w, err := e.power()
+		return math.Pow(v, w), err
+	}
+	return v, nil
+}
+
+// unary = ("-"|"+") unary | number | func "(" args ")" | "(" sum ")"
+func (e *exprParser) unary() (float64, error) {
+	switch {
+	case e.accept("-"):
+		v, err := e.unary()
+		return -v, err
+	case e.accept("+"):
+		return e.unary()
+	case e.accept("("):
+		v, err := e.sum()
+		if err == nil && !e.accept(")") {
+			err = fmt.Errorf("expr %q: missing )", e.src)
+		}
+		return v, err
+	}
+	e.skipSpace()
+	start := e.pos
+	for e.pos < len(e.src) && (isAlnum(e.src[e.pos]) || e.src[e.pos] == '.' || e.src[e.pos] == '_' ||
+		(e.pos > start && (e.src[e.pos] == '+' || e.src[e.pos] == '-') && (e.src[e.pos-1] == 'e' || e.src[e.pos-1] == 'E') && isDigit(e.src[start]))) {
+		e.pos++
+	}
+	tok := e.src[start:e.pos]
+	if tok == "" {
+		return 0, fmt.Errorf("expr %q: syntax error at %q", e.src, e.src[start:])
+	}
+	if isDigit(tok[0]) || tok[0] == '.' {
+		return strconv.ParseFloat(tok, 64)
+	}
+	return e.call(tok)
+}
+
+// calls a math function
+func (e *exprParser) call(name string) (float64, error) {
+	if !e.accept("(") {
+		return 0, fmt.Errorf("expr %q: unknown %q", e.src, name)
+	}
+	var args []float64
+	for !e.accept(")") {
+		if len(args) > 0 && !e.accept(",") {
+			return 0, fmt.Errorf("expr %q: missing , or )", e.src)
+		}
+		v, err := e.sum()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	if f, ok := funcs1[name]; ok && len(args) == 1 {
+		return f(args[0]), nil
+	}
+	if f, ok := funcs2[name]; ok && len(args) == 2 {
+		return f(args[0], args[1]), nil
+	}
+	return 0, fmt.Errorf("expr %q: unsupported function %v with %v arguments", e.src, name, len(args))
+}
+
+var funcs1 = map[string]func(float64) float64{
+	"abs": math.Abs, "acos": math.Acos, "asin": math.Asin, "atan": math.Atan,
+	"ceil": math.Ceil, "cos": math.Cos, "cosh": math.Cosh, "double": func(x float64) float64 { return x },
+	"exp": math.Exp, "floor": math.Floor, "int": math.Trunc, "log": math.Log, "log10": math.Log10,
+	"round": math.Round, "sin": math.Sin, "sinh": math.Sinh, "sqrt": math.Sqrt, "tan": math.Tan, "tanh": math.Tanh,
+}
+
+var funcs2 = map[string]func(float64, float64) float64{
+	"atan2": math.Atan2, "fmod": math.Mod, "hypot": math.Hypot, "pow": math.Pow,
+	"max": math.Max, "min": math.Min,
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
@@ -0,0 +1,214 @@
+/*
+mumax3-mif2mx3 translates OOMMF MIF input files to mumax3 input scripts.
+
+Usage
+
+	mumax3-mif2mx3 [flags] files.mif
+
+For each input file, a .mx3 file with the same base name is written, or the script is printed with -stdout.
+Existing files are not overwritten, unless -f is given. With a single input file, -o sets the output file.
+
+MIF 2.x files consist of Specify blocks for OOMMF's Oxs objects. The Tcl needed to define them is understood: variables (set, Parameter), substitution, expr and list. Other Tcl commands, like proc, are not.
+The following objects are translated:
+
+	Oxs_BoxAtlas, Oxs_EllipsoidAtlas, Oxs_MultiAtlas           regions, geometry
+	Oxs_RectangularMesh, Oxs_PeriodicRectangularMesh           grid and cell size, PBC
+	Oxs_UniformExchange, Oxs_Exchange6Ngbr                     Aex, ext_ScaleExchange
+	Oxs_UniaxialAnisotropy, Oxs_CubicAnisotropy                Ku1, AnisU, Kc1, AnisC1, AnisC2
+	Oxs_FixedZeeman, Oxs_UZeeman                               B_ext, field steps per stage
+	Oxs_Demag                                                  EnableDemag
+	Oxs_RungeKuttaEvolve, Oxs_EulerEvolve                      alpha, GammaLL, SetSolver, MinDt, MaxDt
+	Oxs_CGEvolve, Oxs_MinDriver                                minimize()
+	Oxs_TimeDriver                                             Msat, m, run() or RunWhile() per stage
+	Oxs_Uniform*Field, Oxs_Atlas*Field, Oxs_RandomVectorField  uniform and per-region values
+	Schedule ... Stage 1                                       TableSave(), save()
+
+MIF 1.x files, consisting of "key: value" lines, are translated as well.
+
+Fields (A/m in OOMMF) are converted to Tesla. Anything that cannot be translated is left as a "// WARNING:" comment in the output, which should always be reviewed before running. The number of warnings is reported on stderr, -warn lists them:
+
+	mumax3-mif2mx3 -warn -o sp4.mx3 sp4.mif
+
+Stopping criteria on the torque (Oxs_MinDriver stopping_mxHxm, MIF 1.x "converge mxh value") have no equivalent in minimize() and relax(), which stop by their own criteria, and are reported as warnings.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/util"
+)
+
+var (
+	flag_stdout = flag.Bool("stdout", false, "Print the translated script to stdout instead of a file")
+	flag_force  = flag.Bool("f", false, "Overwrite existing output files")
+	flag_out    = flag.String("o", "", "Output file, for a single input file")
+	flag_warn   = flag.Bool("warn", false, "List the constructs that could not be translated")
+)
+
+const mu0 = 4 * 3.141592653589793 * 1e-7
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("no input files")
+	}
+	if *flag_out != "" && flag.NArg() > 1 {
+		log.Fatal("-o needs exactly one input file")
+	}
+	for _, fname := range flag.Args() {
+		translate(fname)
+	}
+}
+
+func translate(fname string) {
+	src, err := ioutil.ReadFile(fname)
+	util.FatalErr(err)
+
+	s := translateMIF(fname, string(src))
+	s.reportWarnings()
+	if *flag_stdout {
+		os.Stdout.Write(s.out.Bytes())
+		return
+	}
+	out := util.NoExt(fname) + ".mx3"
+	if *flag_out != "" {
+		out = *flag_out
+	}
+	if _, err := os.Stat(out); err == nil && !*flag_force {
+		log.Fatalf("%v: %v already exists, use -f to overwrite or -o to write elsewhere", fname, out)
+	}
+	util.FatalErr(ioutil.WriteFile(out, s.out.Bytes(), 0666))
+	log.Println(fname, "->", out)
+}
+
+// translates the MIF source text read from fname.
+func translateMIF(fname, text string) *script {
+	s := &script{fname: fname}
+	s.printf("// Translated from %v by mumax3-mif2mx3\n\n", fname)
+
+	first := strings.ToUpper(strings.TrimSpace(strings.SplitN(text, "\n", 2)[0]))
+	switch {
+	default:
+		log.Fatalf("%v: not a MIF file, first line should be # MIF 1.x or 2.x", fname)
+	case strings.HasPrefix(first, "# MIF 2"):
+		translateMIF2(s, text)
+	case strings.HasPrefix(first, "# MIF 1"):
+		translateMIF1(s, text)
+	}
+	return s
+}
+
+// script accumulates the translated output.
+type script struct {
+	fname    string
+	out      bytes.Buffer
+	indent   int
+	warnings []string
+}
+
+func (s *script) printf(format string, args ...interface{}) {
+	s.write(fmt.Sprintf(format, args...))
+}
+
+func (s *script) println(args ...interface{}) {
+	s.write(fmt.Sprintln(args...))
+}
+
+// writes text, indenting each line.
+func (s *script) write(text string) {
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line != "" && line != "\n" {
+			s.out.WriteString(strings.Repeat("\t", s.indent))
+		}
+		s.out.WriteString(line)
+	}
+}
+
+// warn adds a warning comment to the output, to be reported by reportWarnings.
+func (s *script) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	s.printf("// WARNING: %v\n", msg)
+	s.warnings = append(s.warnings, msg)
+}
+
+// reports the number of warnings on stderr, or lists them with -warn.
+func (s *script) reportWarnings() {
+	switch {
+	case len(s.warnings) == 0:
+		return
+	case !*flag_warn:
+		log.Printf("%v: %v warning(s), see the WARNING comments in the output or use -warn", s.fname, len(s.warnings))
+	default:
+		log.Printf("%v: not translated:", s.fname)
+		for _, w := range s.warnings {
+			log.Println("\t" + w)
+		}
+	}
+}
+
+func (s *script) fatal(err error) {
+	log.Fatalf("%v: %v", s.fname, err)
+}
+
+// formats a number, rounded to 15 digits to hide floating-point noise like 5.000000000000002e-09.
+func num(v float64) string {
+	if v == 0 {
+		return "0" // no -0
+	}
+	return strconv.FormatFloat(v, 'g', 15, 64)
+}
+
+// formats the loop expression x0 + i*dx.
+func ramp(x0, dx float64) string {
+	switch {
+	case dx == 0:
+		return num(x0)
+	case x0 == 0:
+		return "i*" + num(dx)
+	case dx < 0:
+		return num(x0) + " - i*" + num(-dx)
+	default:
+		return num(x0) + " + i*" + num(dx)
+	}
+}
+
+// parses a list of exactly n numbers, which may be Tcl expressions.
+func floats(s string, n int) ([]float64, error) {
+	f, err := floatList(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(f) != n {
+		return nil, fmt.Errorf("need %v numbers, have %q", n, s)
+	}
+	return f, nil
+}
+
+// parses a list of numbers.
+func floatList(s string) ([]float64, error) {
+	list, err := splitList(s)
+	if err != nil {
+		return nil, err
+	}
+	f := make([]float64, len(list))
+	for i, e := range list {
+		v, err := strconv.ParseFloat(e, 64)
+		if err != nil {
+			v, err = evalExpr(e)
+			if err != nil {
+				return nil, err
+			}
+		}
+		f[i] = v
+	}
+	return f, nil
+}
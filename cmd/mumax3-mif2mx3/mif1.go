@@ -0,0 +1,246 @@
+package main
+
+// Translation of MIF 1.x files, consisting of "key: value" lines, as used by OOMMF's mmSolve.
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+func translateMIF1(s *script, src string) {
+	// keys are case-insensitive, field range may be repeated
+	values := make(map[string]string)
+	var keys []string
+	var fieldRanges []string
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			s.warn("ignoring line %q", line)
+			continue
+		}
+		k := strings.Join(strings.Fields(strings.ToLower(kv[0])), " ")
+		v := strings.TrimSpace(kv[1])
+		if k == "field range" {
+			fieldRanges = append(fieldRanges, v)
+			continue
+		}
+		if _, ok := values[k]; !ok {
+			keys = append(keys, k)
+		}
+		values[k] = v
+	}
+	used := make(map[string]bool)
+	get := func(k string) (string, bool) {
+		v, ok := values[k]
+		used[k] = true
+		return v, ok
+	}
+	getFloat := func(k string) (float64, bool) {
+		v, ok := get(k)
+		if !ok {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			s.warn("%v: %v", k, err)
+			return 0, false
+		}
+		return f, true
+	}
+
+	// geometry
+	var size [3]float64
+	for c, k := range []string{"part width", "part height", "part thickness"} {
+		size[c], _ = getFloat(k)
+	}
+	cell, ok := getFloat("cell size")
+	if !ok || cell == 0 || size[0] == 0 || size[1] == 0 || size[2] == 0 {
+		s.warn("need part width, height, thickness and cell size")
+		return
+	}
+	// mmSolve is 2D: a single layer of cells with the part thickness
+	nx, ny := int(math.Round(size[0]/cell)), int(math.Round(size[1]/cell))
+	s.printf("SetGridSize(%v, %v, 1)\n", nx, ny)
+	s.printf("SetCellSize(%v, %v, %v)\n", num(cell), num(cell), num(size[2]))
+	if v, ok := get("part shape"); ok {
+		switch strings.ToLower(append(strings.Fields(v), "")[0]) {
+		case "rectangle":
+		case "ellipse":
+			s.println("SetGeom(Ellipse(" + num(size[0]) + ", " + num(size[1]) + "))")
+		case "ellipsoid":
+			s.println("SetGeom(Ellipsoid(" + num(size[0]) + ", " + num(size[1]) + ", " + num(size[2]) + "))")
+		default:
+			s.warn("cannot translate part shape %v", v)
+		}
+	}
+	s.println()
+
+	// material
+	if v, ok := getFloat("ms"); ok {
+		s.printf("Msat = %v\n", num(v))
+	}
+	if v, ok := getFloat("a"); ok {
+		s.printf("Aex = %v\n", num(v))
+	}
+	if v, ok := getFloat("damp coef"); ok {
+		s.printf("alpha = %v\n", num(v))
+	}
+	if v, ok := getFloat("gyratio"); ok {
+		s.printf("GammaLL = %v\n", num(v/mu0))
+	}
+	if k1, ok := getFloat("k1"); ok && k1 != 0 {
+		typ, _ := get("anisotropy type")
+		dir1, _ := get("anisotropy dir1")
+		dir2, _ := get("anisotropy dir2")
+		if init, ok := get("anisotropy init"); ok && strings.ToLower(init) != "constant" {
+			s.warn("cannot translate anisotropy init %v", init)
+		}
+		switch strings.ToLower(typ) {
+		default:
+			s.warn("cannot translate anisotropy type %v", typ)
+		case "uniaxial":
+			s.printf("Ku1 = %v\n", num(k1))
+			s.printf("AnisU = vector(%v)\n", vector(dir1))
+		case "cubic":
+			s.printf("Kc1 = %v\n", num(k1))
+			s.printf("AnisC1 = vector(%v)\n", vector(dir1))
+			s.printf("AnisC2 = vector(%v)\n", vector(dir2))
+		}
+	}
+	if v, ok := get("demag type"); ok {
+		switch strings.ToLower(v) {
+		case "constmag", "fastpipe", "3dslab", "3dcharge":
+		case "none":
+			s.println("EnableDemag = false")
+		default:
+			s.warn("cannot translate demag type %v", v)
+		}
+	}
+
+	// initial magnetization
+	if v, ok := get("init mag"); ok {
+		f := strings.Fields(v)
+		if len(f) == 0 {
+			f = []string{""}
+		}
+		switch strings.ToLower(f[0]) {
+		default:
+			s.warn("cannot translate init mag %v", v)
+		case "uniform":
+			if len(f) != 3 {
+				s.warn("cannot translate init mag %v", v)
+				break
+			}
+			// polar angle from z, azimuth in xy plane (degrees)
+			theta, err1 := strconv.ParseFloat(f[1], 64)
+			phi, err2 := strconv.ParseFloat(f[2], 64)
+			if err1 != nil || err2 != nil {
+				s.warn("cannot translate init mag %v", v)
+				break
+			}
+			theta, phi = theta*math.Pi/180, phi*math.Pi/180
+			s.printf("m = uniform(%v, %v, %v)\n", round(math.Sin(theta)*math.Cos(phi)), round(math.Sin(theta)*math.Sin(phi)), round(math.Cos(theta)))
+		case "vortex":
+			s.println("m = vortex(1, 1)")
+		case "random":
+			s.println("m = RandomMag()")
+		case "avffile":
+			if len(f) < 2 {
+				s.warn("cannot translate init mag %v", v)
+				break
+			}
+			s.printf("m.LoadFile(%q) // must have the same grid size\n", f[1])
+		}
+	}
+	s.println()
+
+	// stages, stopping either on time or on torque
+	torque, relax := getFloat("converge mxh value")
+	if !relax {
+		torque, relax = getFloat("converge |mxh| value")
+	}
+	if relax {
+		s.warn("stopping criterion |mxh| < %v not translated, relax() uses its own criterion", num(torque))
+	}
+	if len(fieldRanges) > 0 {
+		s.println("TableAdd(B_ext)")
+	}
+	var prev []float64
+	for _, r := range fieldRanges {
+		f := strings.Fields(r)
+		if len(f) < 7 {
+			s.warn("cannot translate field range %v", r)
+			continue
+		}
+		B, err := floatList(strings.Join(f[:7], " "))
+		if err != nil {
+			s.warn("field range %v: %v", r, err)
+			continue
+		}
+		stop := "relax()"
+		for i := 7; i+1 < len(f); i += 2 {
+			switch strings.ToLower(f[i]) {
+			case "-time":
+				if !relax {
+					stop = "run(" + f[i+1] + ")"
+				}
+			case "-mxh":
+			default:
+				s.warn("field range %v: ignoring %v", r, f[i])
+			}
+		}
+		if !relax && stop == "relax()" {
+			s.warn("field range %v: no stopping criterion, relaxing", r)
+		}
+
+		steps := int(B[6])
+		first := 0
+		if prev != nil && prev[3] == B[0] && prev[4] == B[1] && prev[5] == B[2] {
+			first = 1 // mmSolve does not repeat the last field of the previous range
+		}
+		prev = B
+		if steps == 0 {
+			if first == 0 {
+				s.printf("B_ext = vector(%v, %v, %v)\n%v\nTableSave()\n", num(B[0]), num(B[1]), num(B[2]), stop)
+			}
+			continue
+		}
+		var comp [3]string
+		for c := range comp {
+			comp[c] = ramp(B[c], (B[c+3]-B[c])/float64(steps))
+		}
+		s.printf("for i := %v; i <= %v; i++ {\n", first, steps)
+		s.indent++
+		s.printf("B_ext = vector(%v, %v, %v)\n%v\nTableSave()\n", comp[0], comp[1], comp[2], stop)
+		s.indent--
+		s.println("}")
+	}
+
+	// output and solver settings have no equivalent
+	for _, k := range []string{"base output filename", "magnetization output format", "total field output format",
+		"data table output format", "randomizer seed", "material name", "user interaction level", "user report code",
+		"log level", "solver type", "min timestep", "max timestep", "default control point spec",
+		"user output interval", "allow spin regeneration"} {
+		used[k] = true
+	}
+	for _, k := range keys {
+		if !used[k] {
+			s.warn("ignoring %v: %v", k, values[k])
+		}
+	}
+}
+
+// rounds away floating-point noise from trigonometric functions.
+func round(v float64) string {
+	return num(math.Round(v*1e12) / 1e12)
+}
+
+// comma-separated vector from space-separated numbers
+func vector(v string) string {
+	return strings.Join(strings.Fields(v), ", ")
+}
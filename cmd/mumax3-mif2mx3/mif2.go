@@ -0,0 +1,1078 @@
+package main
+
+// Translation of MIF 2.x files, consisting of Specify blocks for OOMMF's Oxs objects.
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// object defined by a Specify block, e.g.:
+//
+//	Specify Oxs_BoxAtlas:atlas { xrange {0 100e-9} ... }
+type object struct {
+	class, name string
+	args        []kv
+	used        map[string]bool
+}
+
+type kv struct{ key, value string }
+
+// get returns the (first) value for key, marking it as used.
+func (o *object) get(key string) (string, bool) {
+	for _, a := range o.args {
+		if a.key == key {
+			o.used[key] = true
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// all returns all values for key, which may be repeated (e.g. atlas in Oxs_MultiAtlas).
+func (o *object) all(key string) []string {
+	var v []string
+	for _, a := range o.args {
+		if a.key == key {
+			o.used[key] = true
+			v = append(v, a.value)
+		}
+	}
+	return v
+}
+
+func (o *object) String() string {
+	if o.name == "" {
+		return o.class
+	}
+	return o.class + ":" + o.name
+}
+
+type mif2 struct {
+	*script
+	tcl       *tcl
+	objects   []*object
+	schedules [][]string // Schedule commands: output destination event frequency
+
+	regions  []region       // regions of the mesh atlas, in mumax3 order
+	regionID map[string]int // region name -> mumax3 region number
+	world    box            // mesh bounding box
+	fixedB   [3]float64     // field from Oxs_FixedZeeman (T)
+}
+
+func translateMIF2(s *script, src string) {
+	m := &mif2{script: s, tcl: newTcl(), regionID: map[string]int{"universe": 0}}
+	if err := m.parse(src); err != nil {
+		s.fatal(err)
+		return
+	}
+	driver := m.find("Oxs_TimeDriver", "Oxs_MinDriver")
+	if driver == nil {
+		s.warn("no Oxs_TimeDriver or Oxs_MinDriver found")
+		return
+	}
+
+	m.mesh(driver)
+	m.energies()
+	evolver := m.evolver(driver)
+	m.material(driver)
+	m.stages(driver, evolver)
+
+	for _, o := range m.objects {
+		m.checkUsed(o)
+	}
+}
+
+// executes the top-level MIF commands.
+func (m *mif2) parse(src string) error {
+	cmds, err := m.tcl.parse(src)
+	if err != nil {
+		return err
+	}
+	for _, c := range cmds {
+		switch c[0] {
+		default:
+			m.warn("ignoring Tcl command %q", c[0])
+		case "set", "Parameter":
+			// defined while parsing
+		case "Specify":
+			if len(c) != 3 {
+				return fmt.Errorf("Specify needs 2 arguments, have %v", len(c)-1)
+			}
+			// MIF 2.0 substitutes variables and commands in Specify blocks, later versions use explicit subst
+			block := c[2]
+			if strings.HasPrefix(src, "# MIF 2.0") {
+				var err error
+				if block, err = m.tcl.subst(block); err != nil {
+					return fmt.Errorf("Specify %v: %v", c[1], err)
+				}
+			}
+			o, err := newObject(c[1], block)
+			if err != nil {
+				return err
+			}
+			m.objects = append(m.objects, o)
+		case "Schedule":
+			m.schedules = append(m.schedules, c[1:])
+		case "Destination", "SetOptions", "RandomSeed", "Report", "Ignore", "OOMMFRootDir", "EvalScalarField", "EvalVectorField":
+			// output locations and options: nothing to translate
+		}
+	}
+	return nil
+}
+
+// makes an object from its instance name ("Class:name") and Specify block.
+func newObject(id, block string) (*object, error) {
+	o := &object{used: make(map[string]bool)}
+	o.class, o.name = id, ""
+	if i := strings.Index(id, ":"); i >= 0 {
+		o.class, o.name = id[:i], id[i+1:]
+	}
+	list, err := splitList(block)
+	if err != nil {
+		return nil, fmt.Errorf("Specify %v: %v", id, err)
+	}
+	if len(list)%2 != 0 {
+		return nil, fmt.Errorf("Specify %v: odd number of elements in %q", id, block)
+	}
+	for i := 0; i < len(list); i += 2 {
+		o.args = append(o.args, kv{list[i], list[i+1]})
+	}
+	return o, nil
+}
+
+// first object of any of the given classes.
+func (m *mif2) find(class ...string) *object {
+	for _, o := range m.objects {
+		for _, c := range class {
+			if o.class == c {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// all objects of given class
+func (m *mif2) findAll(class string) []*object {
+	var all []*object
+	for _, o := range m.objects {
+		if o.class == class {
+			all = append(all, o)
+		}
+	}
+	return all
+}
+
+// resolves an object reference: ":name", "Class:name", "name",
+// or an inline definition like {Oxs_UniformScalarField {value 1}}.
+func (m *mif2) ref(v string) (*object, error) {
+	list, err := splitList(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 2 && strings.HasPrefix(list[0], "Oxs_") {
+		return newObject(list[0], list[1])
+	}
+	for _, o := range m.objects {
+		if v == ":"+o.name || v == o.String() || (o.name != "" && v == o.name) {
+			return o, nil
+		}
+	}
+	return nil, fmt.Errorf("undefined object %q", v)
+}
+
+// warns about arguments of o that were not translated.
+func (m *mif2) checkUsed(o *object) {
+	for _, a := range o.args {
+		if !o.used[a.key] && !ignoredArgs[a.key] {
+			m.warn("%v: ignoring %v %v", o, a.key, a.value)
+		}
+	}
+}
+
+// arguments that don't affect the simulation
+var ignoredArgs = map[string]bool{
+	"basename": true, "comment": true, "scalar_output_format": true, "vector_field_output_format": true,
+	"scalar_field_output_format": true, "vector_field_output_meshunit": true, "scalar_field_output_meshunit": true,
+	"report_max_spin_angle": true, "normalize_aveM_output": true, "checkpoint_file": true,
+	"checkpoint_interval": true, "checkpoint_disposal": true, "total_iteration_limit": true,
+	"stage_iteration_limit": true, "report_wall_time": true, "start_iteration": true, "start_stage": true,
+	"start_time": true, "start_stage_time": true, "start_stage_start_time": true, "start_last_timestep": true,
+	"stage_count_check": true, "start_stage_elapsed_time": true,
+}
+
+// Mesh and atlas: grid size, cell size and regions.
+func (m *mif2) mesh(driver *object) {
+	mref, ok := driver.get("mesh")
+	if !ok {
+		m.warn("%v: no mesh", driver)
+		return
+	}
+	mesh, err := m.ref(mref)
+	if err != nil {
+		m.warn("%v: mesh: %v", driver, err)
+		return
+	}
+	if mesh.class != "Oxs_RectangularMesh" && mesh.class != "Oxs_PeriodicRectangularMesh" {
+		m.warn("cannot translate mesh %v", mesh)
+		return
+	}
+	mesh.used["class"] = true
+
+	var cell [3]float64
+	if v, ok := mesh.get("cellsize"); ok {
+		c, err := floats(v, 3)
+		if err != nil {
+			m.warn("%v: cellsize: %v", mesh, err)
+			return
+		}
+		copy(cell[:], c)
+	} else {
+		m.warn("%v: no cellsize", mesh)
+		return
+	}
+
+	aref, ok := mesh.get("atlas")
+	if !ok {
+		m.warn("%v: no atlas", mesh)
+		return
+	}
+	atlas, err := m.ref(aref)
+	if err != nil {
+		m.warn("%v: atlas: %v", mesh, err)
+		return
+	}
+	regions, world, err := m.atlas(atlas)
+	if err != nil {
+		m.warn("%v", err)
+		return
+	}
+	m.world = world
+
+	var n [3]int
+	for c := range n {
+		n[c] = int(math.Round((world.max[c] - world.min[c]) / cell[c]))
+		if n[c] < 1 {
+			n[c] = 1
+		}
+		if math.Abs(float64(n[c])*cell[c]-(world.max[c]-world.min[c])) > 1e-3*cell[c] {
+			m.warn("%v: %v range is not a multiple of the cell size", mesh, "xyz"[c:c+1])
+		}
+	}
+	m.printf("SetGridSize(%v, %v, %v)\n", n[0], n[1], n[2])
+	m.printf("SetCellSize(%v, %v, %v)\n", num(cell[0]), num(cell[1]), num(cell[2]))
+
+	if mesh.class == "Oxs_PeriodicRectangularMesh" {
+		p, _ := mesh.get("periodic")
+		var pbc [3]int
+		for c := range pbc {
+			if strings.Contains(strings.ToLower(p), "xyz"[c:c+1]) {
+				pbc[c] = 10
+			}
+		}
+		m.printf("SetPBC(%v, %v, %v) // OOMMF's periodic demag is exact, mumax3 sums a finite number of images\n", pbc[0], pbc[1], pbc[2])
+	}
+
+	// a single region covering everything is just the default region 0
+	if len(regions) == 1 && regions[0].box == world && regions[0].kind == "Cuboid" {
+		m.regionID[regions[0].name] = 0
+		return
+	}
+	m.println()
+	// first atlas wins in OOMMF, last DefRegion in mumax3
+	for i := len(regions) - 1; i >= 0; i-- {
+		r := regions[i]
+		id, ok := m.regionID[r.name]
+		if !ok {
+			id = len(m.regionID)
+			m.regionID[r.name] = id
+		}
+		m.printf("DefRegion(%v, %v) // %v\n", id, m.shape(r), r.name)
+	}
+	m.regions = regions
+}
+
+// axis-aligned box
+type box struct{ min, max [3]float64 }
+
+type region struct {
+	name string
+	kind string // mumax3 shape: Cuboid or Ellipsoid
+	box  box
+}
+
+// mumax3 shape for a region, in coordinates centered on the mesh.
+func (m *mif2) shape(r region) string {
+	var size, center [3]string
+	for c := 0; c < 3; c++ {
+		size[c] = num(r.box.max[c] - r.box.min[c])
+		center[c] = num((r.box.max[c]+r.box.min[c])/2 - (m.world.max[c]+m.world.min[c])/2)
+	}
+	s := fmt.Sprintf("%v(%v, %v, %v)", r.kind, size[0], size[1], size[2])
+	if center != [3]string{"0", "0", "0"} {
+		s += fmt.Sprintf(".Transl(%v, %v, %v)", center[0], center[1], center[2])
+	}
+	return s
+}
+
+// regions and bounding box of an atlas.
+func (m *mif2) atlas(a *object) ([]region, box, error) {
+	switch a.class {
+	default:
+		return nil, box{}, fmt.Errorf("cannot translate atlas %v", a)
+	case "Oxs_BoxAtlas", "Oxs_EllipsoidAtlas":
+		b, err := ranges(a)
+		if err != nil {
+			return nil, box{}, err
+		}
+		name := a.name
+		if n, ok := a.get("name"); ok {
+			name = n
+		}
+		kind := "Cuboid"
+		if a.class == "Oxs_EllipsoidAtlas" {
+			kind = "Ellipsoid"
+		}
+		return []region{{name, kind, b}}, b, nil
+	case "Oxs_MultiAtlas":
+		var regions []region
+		var world box
+		for i, aref := range a.all("atlas") {
+			sub, err := m.ref(aref)
+			if err != nil {
+				return nil, box{}, fmt.Errorf("%v: %v", a, err)
+			}
+			r, b, err := m.atlas(sub)
+			if err != nil {
+				return nil, box{}, err
+			}
+			regions = append(regions, r...)
+			if i == 0 {
+				world = b
+			}
+			for c := 0; c < 3; c++ {
+				world.min[c] = math.Min(world.min[c], b.min[c])
+				world.max[c] = math.Max(world.max[c], b.max[c])
+			}
+		}
+		if len(regions) == 0 {
+			return nil, box{}, fmt.Errorf("%v: no atlases", a)
+		}
+		// explicit bounding box, if any
+		for c, key := range []string{"xrange", "yrange", "zrange"} {
+			if v, ok := a.get(key); ok {
+				r, err := floats(v, 2)
+				if err != nil {
+					return nil, box{}, fmt.Errorf("%v: %v: %v", a, key, err)
+				}
+				world.min[c], world.max[c] = r[0], r[1]
+			}
+		}
+		return regions, world, nil
+	}
+}
+
+// box from xrange, yrange, zrange
+func ranges(a *object) (box, error) {
+	var b box
+	for c, key := range []string{"xrange", "yrange", "zrange"} {
+		v, ok := a.get(key)
+		if !ok {
+			return b, fmt.Errorf("%v: no %v", a, key)
+		}
+		r, err := floats(v, 2)
+		if err != nil {
+			return b, fmt.Errorf("%v: %v: %v", a, key, err)
+		}
+		b.min[c], b.max[c] = math.Min(r[0], r[1]), math.Max(r[0], r[1])
+	}
+	return b, nil
+}
+
+// Energy terms.
+func (m *mif2) energies() {
+	if m.find("Oxs_Demag") == nil {
+		m.println()
+		m.println("EnableDemag = false")
+	}
+	m.println()
+	for _, o := range m.objects {
+		switch o.class {
+		case "Oxs_Demag":
+			// on by default
+		case "Oxs_UniformExchange":
+			if v, ok := o.get("A"); ok {
+				m.setScalar("Aex", v, 1)
+			} else {
+				m.warn("%v: only A can be translated", o)
+			}
+		case "Oxs_Exchange6Ngbr":
+			m.exchange6Ngbr(o)
+		case "Oxs_UniaxialAnisotropy":
+			if v, ok := o.get("K1"); ok {
+				m.setScalar("Ku1", v, 1)
+			}
+			if v, ok := o.get("axis"); ok {
+				m.setVector("AnisU", v, 1)
+			}
+		case "Oxs_CubicAnisotropy":
+			if v, ok := o.get("K1"); ok {
+				m.setScalar("Kc1", v, 1)
+			}
+			if v, ok := o.get("axis1"); ok {
+				m.setVector("AnisC1", v, 1)
+			}
+			if v, ok := o.get("axis2"); ok {
+				m.setVector("AnisC2", v, 1)
+			}
+		case "Oxs_FixedZeeman":
+			m.fixedZeeman(o)
+		case "Oxs_UZeeman":
+			// translated per stage
+		default:
+			if strings.HasSuffix(o.class, "Atlas") || strings.HasSuffix(o.class, "Mesh") || strings.HasSuffix(o.class, "Field") ||
+				strings.HasSuffix(o.class, "Driver") || strings.HasSuffix(o.class, "Evolve") {
+				continue // translated where referenced
+			}
+			m.warn("cannot translate %v", o)
+			for _, a := range o.args {
+				o.used[a.key] = true // warned already
+			}
+		}
+	}
+}
+
+// Exchange between region pairs: same-region values set Aex,
+// others scale the inter-region exchange.
+func (m *mif2) exchange6Ngbr(o *object) {
+	if v, ok := o.get("default_A"); ok {
+		m.setScalar("Aex", v, 1)
+	}
+	v, ok := o.get("A")
+	if !ok {
+		m.warn("%v: only A can be translated", o)
+		return
+	}
+	o.get("atlas")
+	list, err := splitList(v)
+	if err != nil || len(list)%3 != 0 {
+		m.warn("%v: cannot translate A %v", o, v)
+		return
+	}
+	A := map[string]float64{}
+	type pair struct {
+		r1, r2 string
+		A      float64
+	}
+	var inter []pair
+	for i := 0; i < len(list); i += 3 {
+		a, err := floats(list[i+2], 1)
+		if err != nil {
+			m.warn("%v: A %v %v: %v", o, list[i], list[i+1], err)
+			continue
+		}
+		if list[i] == list[i+1] {
+			if id, ok := m.region(list[i]); ok {
+				A[list[i]] = a[0]
+				m.printf("Aex.SetRegion(%v, %v)\n", id, num(a[0]))
+			}
+		} else {
+			inter = append(inter, pair{list[i], list[i+1], a[0]})
+		}
+	}
+	for _, p := range inter {
+		id1, ok1 := m.region(p.r1)
+		id2, ok2 := m.region(p.r2)
+		A1, A2 := A[p.r1], A[p.r2]
+		if !ok1 || !ok2 {
+			continue
+		}
+		if A1 == 0 || A2 == 0 {
+			m.warn("%v: cannot translate exchange between %v and %v without their own A", o, p.r1, p.r2)
+			continue
+		}
+		hm := 2 / (1/A1 + 1/A2)
+		m.printf("ext_ScaleExchange(%v, %v, %v) // A = %v, assuming equal Msat in both regions\n", id1, id2, num(p.A/hm), num(p.A))
+	}
+}
+
+func (m *mif2) fixedZeeman(o *object) {
+	mul := 1.0
+	if v, ok := o.get("multiplier"); ok {
+		f, err := floats(v, 1)
+		if err != nil {
+			m.warn("%v: multiplier: %v", o, err)
+			return
+		}
+		mul = f[0]
+	}
+	v, ok := o.get("field")
+	if !ok {
+		m.warn("%v: no field", o)
+		return
+	}
+	f, err := m.uniformVector(v)
+	if err != nil {
+		m.setVector("B_ext", v, mu0*mul) // maybe regionwise
+		return
+	}
+	for c := range f {
+		m.fixedB[c] = mu0 * mul * f[c]
+	}
+	if m.find("Oxs_UZeeman") == nil {
+		m.printf("B_ext = vector(%v, %v, %v)\n", num(m.fixedB[0]), num(m.fixedB[1]), num(m.fixedB[2]))
+	}
+}
+
+// Evolver: damping, gyromagnetic ratio and solver type.
+// Returns the evolver class, empty if not translated.
+func (m *mif2) evolver(driver *object) string {
+	eref, ok := driver.get("evolver")
+	if !ok {
+		m.warn("%v: no evolver", driver)
+		return ""
+	}
+	e, err := m.ref(eref)
+	if err != nil {
+		m.warn("%v: evolver: %v", driver, err)
+		return ""
+	}
+	switch e.class {
+	default:
+		m.warn("cannot translate evolver %v, using the default solver", e)
+		for _, a := range e.args {
+			e.used[a.key] = true
+		}
+		return ""
+	case "Oxs_CGEvolve":
+		for _, k := range []string{"gradient_reset_angle", "gradient_reset_count", "minimum_bracket_step", "maximum_bracket_step", "line_minimum_angle_precision", "line_minimum_relwidth", "energy_precision", "method", "conjugate_method", "fixed_spins"} {
+			e.get(k) // precision settings of OOMMF's minimizer
+		}
+		return e.class
+	case "Oxs_RungeKuttaEvolve", "Oxs_EulerEvolve", "Oxs_SpinXferEvolve":
+	}
+	if e.class == "Oxs_SpinXferEvolve" {
+		m.warn("%v: spin-transfer torque is not translated", e)
+	}
+
+	alpha := 0.5 // OOMMF default
+	if v, ok := e.get("alpha"); ok {
+		m.setScalar("alpha", v, 1)
+		if f, err := floats(v, 1); err == nil {
+			alpha = f[0]
+		}
+	} else {
+		m.println("alpha = 0.5")
+	}
+	if v, ok := e.get("gamma_G"); ok {
+		if g, err := floats(v, 1); err == nil {
+			m.printf("GammaLL = %v\n", num(g[0]/mu0))
+		} else {
+			m.warn("%v: gamma_G: %v", e, err)
+		}
+	}
+	if v, ok := e.get("gamma_LL"); ok {
+		if g, err := floats(v, 1); err == nil {
+			m.printf("GammaLL = %v\n", num(g[0]*(1+alpha*alpha)/mu0))
+		} else {
+			m.warn("%v: gamma_LL: %v", e, err)
+		}
+	}
+	for _, k := range []string{"min_timestep", "max_timestep"} {
+		if v, ok := e.get(k); ok {
+			f, err := floats(v, 1)
+			if err != nil {
+				m.warn("%v: %v: %v", e, k, err)
+				continue
+			}
+			m.printf("%v = %v\n", map[string]string{"min_timestep": "MinDt", "max_timestep": "MaxDt"}[k], num(f[0]))
+		}
+	}
+
+	solver := 5 // default rkf54
+	if e.class == "Oxs_EulerEvolve" {
+		solver = 1
+	}
+	if v, ok := e.get("method"); ok {
+		switch v {
+		default:
+			m.warn("%v: unknown method %v", e, v)
+		case "rk2", "rk2heun":
+			solver = 2
+		case "rk4":
+			solver = 4
+		case "rkf54", "rkf54m", "rkf54s":
+			solver = 5
+		}
+	}
+	if solver != 5 {
+		m.printf("SetSolver(%v)\n", solver)
+	}
+	return e.class
+}
+
+// Saturation magnetization and initial magnetization.
+func (m *mif2) material(driver *object) {
+	m.println()
+	if v, ok := driver.get("Ms"); ok {
+		m.setScalar("Msat", v, 1)
+		m.geometry(v)
+	} else {
+		m.warn("%v: no Ms", driver)
+	}
+	if v, ok := driver.get("m0"); ok {
+		m.setMagnetization(v)
+	} else {
+		m.warn("%v: no m0", driver)
+	}
+}
+
+// geometry from regions with zero Ms
+func (m *mif2) geometry(ms string) {
+	f, err := m.scalarField(ms)
+	if err != nil || f.regions == nil {
+		return
+	}
+	var shapes []string
+	empty := false
+	for _, r := range m.regions {
+		v, ok := f.regions[r.name]
+		if !ok {
+			v = f.value
+		}
+		if v == 0 {
+			empty = true
+		} else {
+			shapes = append(shapes, m.shape(r))
+		}
+	}
+	if !empty {
+		return
+	}
+	if len(shapes) == 0 {
+		m.warn("Ms is zero everywhere")
+		return
+	}
+	if f.value != 0 {
+		m.warn("cannot translate geometry: Ms is non-zero outside the atlas regions")
+		return
+	}
+	m.printf("SetGeom(%v)\n", strings.Join(shapes, ".Add("+"")+strings.Repeat(")", len(shapes)-1))
+}
+
+func (m *mif2) setMagnetization(v string) {
+	if o, err := m.ref(v); err == nil {
+		switch o.class {
+		case "Oxs_RandomVectorField":
+			o.get("min_norm")
+			o.get("max_norm")
+			m.println("m = RandomMag()")
+			return
+		case "Oxs_FileVectorField":
+			if f, ok := o.get("file"); ok {
+				o.get("atlas")
+				m.printf("m.LoadFile(%q) // must have the same grid size\n", f)
+				return
+			}
+		}
+	}
+	m.setVector("m", v, 1)
+}
+
+// Stages: field steps (Oxs_UZeeman), stopping criteria and output.
+func (m *mif2) stages(driver *object, evolver string) {
+	// stopping criterion
+	var stop []string
+	switch {
+	case driver.class == "Oxs_MinDriver":
+		if v, ok := driver.get("stopping_mxHxm"); ok {
+			m.warn("%v: stopping_mxHxm %v A/m not translated, minimize() stops when m no longer changes (MinimizerStop)", driver, v)
+		}
+		stop = []string{"minimize()"}
+	case evolver == "Oxs_CGEvolve":
+		m.warn("%v with Oxs_CGEvolve", driver)
+		stop = []string{"minimize()"}
+	default:
+		stop = m.timeStop(driver)
+	}
+
+	// output after each stage
+	var output []string
+	for _, s := range m.schedules {
+		if len(s) != 4 {
+			m.warn("cannot translate Schedule %v", strings.Join(s, " "))
+			continue
+		}
+		if strings.ToLower(s[2]) != "stage" {
+			m.warn("cannot translate Schedule %v: only output per stage is translated, use AutoSave or TableAutoSave", strings.Join(s, " "))
+			continue
+		}
+		if s[3] != "1" {
+			m.warn("Schedule %v: saving every stage instead", strings.Join(s, " "))
+		}
+		q := outputQuantity(s[0])
+		if q == "" {
+			m.warn("cannot translate Schedule %v: unknown output", strings.Join(s, " "))
+			continue
+		}
+		if q == "table" {
+			output = append(output, "TableSave()")
+			m.printf("TableAdd(E_total)\n")
+		} else {
+			output = append(output, "save("+q+")")
+		}
+	}
+
+	stageCount := 1
+	if v, ok := driver.get("stage_count"); ok {
+		if n, err := floats(v, 1); err == nil && n[0] > 0 {
+			stageCount = int(n[0])
+		}
+	}
+
+	uz := m.find("Oxs_UZeeman")
+	var hranges [][]float64
+	mul := 1.0
+	if uz != nil {
+		if v, ok := uz.get("multiplier"); ok {
+			if f, err := floats(v, 1); err == nil {
+				mul = f[0]
+			}
+		}
+		if v, ok := uz.get("Hrange"); ok {
+			list, _ := splitList(v)
+			for _, r := range list {
+				f, err := floats(r, 7)
+				if err != nil {
+					f, err = floats(r, 6) // number of steps is optional
+					if err != nil {
+						m.warn("%v: cannot translate Hrange %v: %v", uz, r, err)
+						continue
+					}
+					f = append(f, 0)
+				}
+				hranges = append(hranges, f)
+			}
+		}
+	}
+
+	m.println()
+	if strings.HasPrefix(stop[0], "t0 = t") {
+		m.println("t0 := t // start time of the stage")
+	}
+	if len(hranges) == 0 {
+		for i := 0; i < stageCount; i++ {
+			m.block("", stop[min(i, len(stop)-1)], output)
+		}
+		return
+	}
+
+	// one loop per Hrange, the first step of a range is skipped when equal to the last of the previous range
+	var prev []float64
+	stage := 0
+	for _, r := range hranges {
+		steps := int(r[6])
+		first := 0
+		if prev != nil && r[0] == prev[3] && r[1] == prev[4] && r[2] == prev[5] {
+			first = 1
+		}
+		prev = r
+		var B0, dB [3]float64
+		for c := 0; c < 3; c++ {
+			B0[c] = mu0*mul*r[c] + m.fixedB[c]
+			if steps > 0 {
+				dB[c] = mu0 * mul * (r[c+3] - r[c]) / float64(steps)
+			}
+		}
+		s := stop[min(stage, len(stop)-1)]
+		if steps == 0 || steps == first {
+			if first == 0 {
+				m.block(fmt.Sprintf("B_ext = vector(%v, %v, %v)", num(B0[0]), num(B0[1]), num(B0[2])), s, output)
+				stage++
+			} else if steps == 1 {
+				B := [3]float64{B0[0] + dB[0], B0[1] + dB[1], B0[2] + dB[2]}
+				m.block(fmt.Sprintf("B_ext = vector(%v, %v, %v)", num(B[0]), num(B[1]), num(B[2])), s, output)
+				stage++
+			}
+			continue
+		}
+		var comp [3]string
+		for c := 0; c < 3; c++ {
+			comp[c] = ramp(B0[c], dB[c])
+		}
+		m.printf("for i := %v; i <= %v; i++ {\n", first, steps)
+		m.indent++
+		m.block(fmt.Sprintf("B_ext = vector(%v, %v, %v)", comp[0], comp[1], comp[2]), s, output)
+		m.indent--
+		m.println("}")
+		stage += steps + 1 - first
+	}
+	if len(stop) > 1 {
+		m.warn("stage-dependent stopping criteria are not translated, using the first one")
+	}
+	if stageCount > 1 && stageCount != stage {
+		m.warn("stage_count %v does not match the %v field steps", stageCount, stage)
+	}
+}
+
+// statements for one stage
+func (m *mif2) block(field, stop string, output []string) {
+	if field != "" {
+		m.println(field)
+	}
+	m.println(stop)
+	for _, o := range output {
+		m.println(o)
+	}
+}
+
+// run statements for the stopping criteria of a time driver, per stage.
+func (m *mif2) timeStop(driver *object) []string {
+	var times, dmdt []float64
+	if v, ok := driver.get("stopping_time"); ok {
+		times, _ = floatList(v)
+	}
+	if v, ok := driver.get("stopping_dm_dt"); ok {
+		dmdt, _ = floatList(v)
+	}
+	n := max(len(times), len(dmdt))
+	if n == 0 {
+		m.warn("%v: no stopping_time or stopping_dm_dt", driver)
+		return []string{"run(1e-9) // no stopping criterion given"}
+	}
+	stop := make([]string, n)
+	for i := range stop {
+		var T, D float64
+		if len(times) > 0 {
+			T = times[min(i, len(times)-1)]
+		}
+		if len(dmdt) > 0 {
+			D = dmdt[min(i, len(dmdt)-1)]
+		}
+		// dm/dt (deg/ns) = maxTorque * GammaLL (rad/s)
+		dm := fmt.Sprintf("maxTorque*GammaLL*180/pi*1e-9 > %v", num(D))
+		switch {
+		case T > 0 && D > 0:
+			stop[i] = fmt.Sprintf("t0 = t\nRunWhile(t < t0+%v && %v)", num(T), dm)
+		case T > 0:
+			stop[i] = fmt.Sprintf("run(%v)", num(T))
+		default:
+			stop[i] = fmt.Sprintf("RunWhile(%v)", dm)
+		}
+	}
+	return stop
+}
+
+// mumax3 quantity for an OOMMF output name, like Oxs_TimeDriver::Magnetization.
+func outputQuantity(name string) string {
+	if name == "DataTable" {
+		return "table"
+	}
+	i := strings.Index(name, "::")
+	if i < 0 {
+		return ""
+	}
+	class, output := name[:i], name[i+2:]
+	if j := strings.Index(class, ":"); j >= 0 {
+		class = class[:j]
+	}
+	switch {
+	case output == "Magnetization" || output == "Spin":
+		return "m"
+	case output == "Total field":
+		return "B_eff"
+	case output != "Field":
+		return ""
+	case class == "Oxs_Demag":
+		return "B_demag"
+	case strings.HasSuffix(class, "Anisotropy"):
+		return "B_anis"
+	case strings.Contains(class, "Exchange"):
+		return "B_exch"
+	case strings.HasSuffix(class, "Zeeman"):
+		return "B_ext"
+	}
+	return ""
+}
+
+// scalar field: uniform value, or per-region values
+type scalarField struct {
+	value   float64            // uniform or default value
+	regions map[string]float64 // per atlas region
+	names   []string           // region names in order of appearance
+}
+
+func (m *mif2) scalarField(v string) (*scalarField, error) {
+	if f, err := floats(v, 1); err == nil {
+		return &scalarField{value: f[0]}, nil
+	}
+	o, err := m.ref(v)
+	if err != nil {
+		return nil, err
+	}
+	switch o.class {
+	default:
+		return nil, fmt.Errorf("cannot translate %v", o)
+	case "Oxs_UniformScalarField":
+		s, _ := o.get("value")
+		f, err := floats(s, 1)
+		if err != nil {
+			return nil, fmt.Errorf("%v: value: %v", o, err)
+		}
+		return &scalarField{value: f[0]}, nil
+	case "Oxs_AtlasScalarField":
+		o.get("atlas")
+		sf := &scalarField{regions: map[string]float64{}}
+		if d, ok := o.get("default_value"); ok {
+			f, err := floats(d, 1)
+			if err != nil {
+				return nil, fmt.Errorf("%v: default_value: %v", o, err)
+			}
+			sf.value = f[0]
+		}
+		vals, _ := o.get("values")
+		list, err := splitList(vals)
+		if err != nil || len(list)%2 != 0 {
+			return nil, fmt.Errorf("%v: bad values %v", o, vals)
+		}
+		for i := 0; i < len(list); i += 2 {
+			f, err := floats(list[i+1], 1)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v: %v", o, list[i], err)
+			}
+			sf.regions[list[i]] = f[0]
+			sf.names = append(sf.names, list[i])
+		}
+		return sf, nil
+	}
+}
+
+// emits param = v, or per-region assignments.
+func (m *mif2) setScalar(param, v string, scale float64) {
+	f, err := m.scalarField(v)
+	if err != nil {
+		m.warn("cannot translate %v: %v", param, err)
+		return
+	}
+	if f.regions == nil || f.value != 0 {
+		m.printf("%v = %v\n", param, num(scale*f.value))
+	}
+	for _, name := range f.names {
+		if id, ok := m.region(name); ok {
+			m.printf("%v.SetRegion(%v, %v) // %v\n", param, id, num(scale*f.regions[name]), name)
+		}
+	}
+}
+
+// mumax3 region number for an atlas region name.
+func (m *mif2) region(name string) (int, bool) {
+	id, ok := m.regionID[name]
+	if !ok {
+		m.warn("unknown region %q", name)
+	}
+	return id, ok
+}
+
+// vector field: uniform value, or per-region values
+type vectorField struct {
+	value   [3]float64
+	regions map[string][3]float64
+	names   []string
+}
+
+func (m *mif2) vectorField(v string) (*vectorField, error) {
+	if f, err := floats(v, 3); err == nil {
+		return &vectorField{value: [3]float64{f[0], f[1], f[2]}}, nil
+	}
+	o, err := m.ref(v)
+	if err != nil {
+		return nil, err
+	}
+	norm := 0.
+	if n, ok := o.get("norm"); ok {
+		f, err := floats(n, 1)
+		if err != nil {
+			return nil, fmt.Errorf("%v: norm: %v", o, err)
+		}
+		norm = f[0]
+	}
+	normalize := func(v [3]float64) [3]float64 {
+		if norm == 0 {
+			return v
+		}
+		l := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+		return [3]float64{norm * v[0] / l, norm * v[1] / l, norm * v[2] / l}
+	}
+	switch o.class {
+	default:
+		return nil, fmt.Errorf("cannot translate %v", o)
+	case "Oxs_UniformVectorField":
+		s, _ := o.get("vector")
+		f, err := floats(s, 3)
+		if err != nil {
+			return nil, fmt.Errorf("%v: vector: %v", o, err)
+		}
+		return &vectorField{value: normalize([3]float64{f[0], f[1], f[2]})}, nil
+	case "Oxs_AtlasVectorField":
+		o.get("atlas")
+		vf := &vectorField{regions: map[string][3]float64{}}
+		if d, ok := o.get("default_value"); ok {
+			f, err := floats(d, 3)
+			if err != nil {
+				return nil, fmt.Errorf("%v: default_value: %v", o, err)
+			}
+			vf.value = normalize([3]float64{f[0], f[1], f[2]})
+		}
+		vals, _ := o.get("values")
+		list, err := splitList(vals)
+		if err != nil || len(list)%2 != 0 {
+			return nil, fmt.Errorf("%v: bad values %v", o, vals)
+		}
+		for i := 0; i < len(list); i += 2 {
+			f, err := floats(list[i+1], 3)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v: %v", o, list[i], err)
+			}
+			vf.regions[list[i]] = normalize([3]float64{f[0], f[1], f[2]})
+			vf.names = append(vf.names, list[i])
+		}
+		return vf, nil
+	}
+}
+
+// value of a uniform vector field
+func (m *mif2) uniformVector(v string) ([3]float64, error) {
+	f, err := m.vectorField(v)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	if f.regions != nil {
+		return [3]float64{}, fmt.Errorf("not uniform")
+	}
+	return f.value, nil
+}
+
+// emits param = vector(...), or per-region assignments.
+func (m *mif2) setVector(param, v string, scale float64) {
+	f, err := m.vectorField(v)
+	if err != nil {
+		m.warn("cannot translate %v: %v", param, err)
+		return
+	}
+	vec := func(v [3]float64) string {
+		if param == "m" {
+			return fmt.Sprintf("uniform(%v, %v, %v)", num(v[0]), num(v[1]), num(v[2]))
+		}
+		return fmt.Sprintf("vector(%v, %v, %v)", num(scale*v[0]), num(scale*v[1]), num(scale*v[2]))
+	}
+	if f.regions == nil || f.value != [3]float64{} {
+		m.printf("%v = %v\n", param, vec(f.value))
+	}
+	for _, name := range f.names {
+		if id, ok := m.region(name); ok {
+			m.printf("%v.SetRegion(%v, %v) // %v\n", param, id, vec(f.regions[name]), name)
+		}
+	}
+}
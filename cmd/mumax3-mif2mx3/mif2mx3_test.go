@@ -0,0 +1,206 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// MIF 1.x files in test/, which have hand-written mumax3 counterparts there.
+func TestTranslateMIF1(t *testing.T) {
+	for _, c := range []struct{ fname, want string }{
+		{"cubicanisotropy.mif", `// Translated from cubicanisotropy.mif by mumax3-mif2mx3
+
+SetGridSize(64, 64, 1)
+SetCellSize(4e-09, 4e-09, 2e-09)
+SetGeom(Ellipse(2.56e-07, 2.56e-07))
+
+Msat = 1100000
+Aex = 1.3e-11
+alpha = 0.25
+Kc1 = 500000
+AnisC1 = vector(1, 0, 0)
+AnisC2 = vector(0, 1, 0)
+m = uniform(1, 0, 0)
+
+// WARNING: stopping criterion |mxh| < 1e-05 not translated, relax() uses its own criterion
+TableAdd(B_ext)
+B_ext = vector(0, 0, 0)
+relax()
+TableSave()
+B_ext = vector(0.01, 0.01, 0)
+relax()
+TableSave()
+B_ext = vector(0.03, 0.03, 0)
+relax()
+TableSave()
+B_ext = vector(0.1, 0.1, 0)
+relax()
+TableSave()
+B_ext = vector(0.3, 0.3, 0)
+relax()
+TableSave()
+`},
+		{"uniaxialanisotropy.mif", `// Translated from uniaxialanisotropy.mif by mumax3-mif2mx3
+
+SetGridSize(64, 64, 1)
+SetCellSize(4e-09, 4e-09, 2e-09)
+SetGeom(Ellipse(2.56e-07, 2.56e-07))
+
+Msat = 1100000
+Aex = 1.3e-11
+alpha = 0.25
+Ku1 = 500000
+AnisU = vector(1, 0, 0)
+m = uniform(0.5, 0.5, 0.707106781187)
+
+// WARNING: stopping criterion |mxh| < 1e-05 not translated, relax() uses its own criterion
+TableAdd(B_ext)
+B_ext = vector(0, 0, 0)
+relax()
+TableSave()
+B_ext = vector(0, 0.01, 0)
+relax()
+TableSave()
+B_ext = vector(0, 0.03, 0)
+relax()
+TableSave()
+B_ext = vector(0, 0.1, 0)
+relax()
+TableSave()
+B_ext = vector(0, 0.3, 0)
+relax()
+TableSave()
+`},
+		{"energy.mif", `// Translated from energy.mif by mumax3-mif2mx3
+
+SetGridSize(128, 64, 1)
+SetCellSize(4e-09, 4e-09, 4e-08)
+SetGeom(Ellipse(5.12e-07, 2.56e-07))
+
+Msat = 860000
+Aex = 1.3e-11
+alpha = 0.5
+Ku1 = 50
+AnisU = vector(1, 0, 0)
+m = vortex(1, 1)
+
+// WARNING: stopping criterion |mxh| < 1e-05 not translated, relax() uses its own criterion
+TableAdd(B_ext)
+B_ext = vector(0, 0, 0)
+relax()
+TableSave()
+B_ext = vector(0.1, 0, 0)
+relax()
+TableSave()
+`},
+	} {
+		src, err := ioutil.ReadFile("../../test/" + c.fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := translateMIF(c.fname, string(src))
+		checkOutput(t, c.fname, s.out.String(), c.want)
+		if len(s.warnings) != 1 {
+			t.Errorf("%v: have %v warnings, want 1: %q", c.fname, len(s.warnings), s.warnings)
+		}
+	}
+}
+
+// Field steps over two Hrange stages, the second starting where the first ends,
+// and gamma_G (m/As) converted to GammaLL (rad/Ts): 2.211e5 / mu0.
+const mif2Sample = `# MIF 2.1
+
+set pi [expr {4*atan(1.0)}]
+set mu0 [expr {4*$pi*1e-7}]
+
+Specify Oxs_BoxAtlas:atlas {
+  xrange {0 100e-9}
+  yrange {0 50e-9}
+  zrange {0 5e-9}
+}
+
+Specify Oxs_RectangularMesh:mesh {
+  cellsize {5e-9 5e-9 5e-9}
+  atlas :atlas
+}
+
+Specify Oxs_UniformExchange {
+  A 13e-12
+}
+
+Specify Oxs_UZeeman [subst {
+  multiplier [expr {0.001/$mu0}]
+  Hrange {
+    {  0 0 0  100 0 0  2 }
+    { 100 0 0  0 0 0  2 }
+  }
+}]
+
+Specify Oxs_Demag {}
+
+Specify Oxs_RungeKuttaEvolve:evolve {
+  alpha 0.02
+  gamma_G 2.211e5
+}
+
+Specify Oxs_TimeDriver {
+  evolver :evolve
+  stopping_time 1e-10
+  mesh :mesh
+  Ms 8e5
+  m0 {1 0 0}
+}
+
+Destination table mmArchive
+Schedule DataTable table Stage 1
+`
+
+func TestTranslateMIF2(t *testing.T) {
+	s := translateMIF("sample.mif", mif2Sample)
+	checkOutput(t, "sample.mif", s.out.String(), `// Translated from sample.mif by mumax3-mif2mx3
+
+SetGridSize(20, 10, 1)
+SetCellSize(5e-09, 5e-09, 5e-09)
+
+Aex = 1.3e-11
+alpha = 0.02
+GammaLL = 175945789588.09
+
+Msat = 800000
+m = uniform(1, 0, 0)
+TableAdd(E_total)
+
+for i := 0; i <= 2; i++ {
+	B_ext = vector(i*0.05, 0, 0)
+	run(1e-10)
+	TableSave()
+}
+for i := 1; i <= 2; i++ {
+	B_ext = vector(0.1 - i*0.05, 0, 0)
+	run(1e-10)
+	TableSave()
+}
+`)
+	if len(s.warnings) != 0 {
+		t.Errorf("have warnings: %q", s.warnings)
+	}
+}
+
+// reports the first line where have and want differ.
+func checkOutput(t *testing.T, name, have, want string) {
+	h, w := strings.Split(have, "\n"), strings.Split(want, "\n")
+	for i := 0; i < len(h) || i < len(w); i++ {
+		var hl, wl string
+		if i < len(h) {
+			hl = h[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if hl != wl {
+			t.Errorf("%v line %v: have %q, want %q\nfull output:\n%v", name, i+1, hl, wl, have)
+			return
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+// Just enough Tcl to read MIF 2 files: commands, words, lists,
+// variable and command substitution for set, expr and list.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// interpreter state: Tcl variables
+type tcl struct {
+	vars map[string]string
+}
+
+func newTcl() *tcl {
+	return &tcl{vars: map[string]string{"pi": "3.141592653589793", "mu0": "1.2566370614359173e-06"}}
+}
+
+// splits a script into commands, each a list of (substituted) words.
+func (t *tcl) parse(script string) ([][]string, error) {
+	var cmds [][]string
+	p := &parser{src: script}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return cmds, nil
+		}
+		if p.peek() == '#' {
+			p.skipComment()
+			continue
+		}
+		var words []string
+		for !p.eof() && !p.atEnd() {
+			w, err := p.word(t)
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, w)
+			p.skipSpace(false)
+		}
+		if !p.eof() && p.peek() == ']' {
+			return nil, fmt.Errorf("unexpected close-bracket")
+		}
+		if len(words) > 0 {
+			t.define(words)
+			cmds = append(cmds, words)
+		}
+	}
+}
+
+// executes set and Parameter right away, later commands may use the variables.
+// Parameter does not override values given on the command line.
+func (t *tcl) define(words []string) {
+	if len(words) != 3 {
+		return
+	}
+	switch words[0] {
+	case "set":
+		t.vars[words[1]] = words[2]
+	case "Parameter":
+		if _, ok := t.vars[words[1]]; !ok {
+			t.vars[words[1]] = words[2]
+		}
+	}
+}
+
+// subst performs variable and command substitution on s, like Tcl's subst.
+func (t *tcl) subst(s string) (string, error) {
+	p := &parser{src: s}
+	var out strings.Builder
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case '$':
+			v, err := p.variable(t)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(v)
+		case '[':
+			v, err := p.command(t)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(v)
+		case '\\':
+			out.WriteString(p.backslash())
+		default:
+			out.WriteByte(c)
+			p.pos++
+		}
+	}
+	return out.String(), nil
+}
+
+// evaluates one command used in substitution, e.g. [expr {2*$a}].
+func (t *tcl) eval(words []string) (string, error) {
+	if len(words) == 0 {
+		return "", nil
+	}
+	switch words[0] {
+	case "expr":
+		e, err := t.subst(strings.Join(words[1:], " "))
+		if err != nil {
+			return "", err
+		}
+		v, err := evalExpr(e)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(v), nil
+	case "set":
+		if len(words) == 3 {
+			t.vars[words[1]] = words[2]
+		}
+		if len(words) < 2 {
+			return "", fmt.Errorf("set: missing variable name")
+		}
+		v, ok := t.vars[words[1]]
+		if !ok {
+			return "", fmt.Errorf("can't read %q: no such variable", words[1])
+		}
+		return v, nil
+	case "list":
+		return joinList(words[1:]), nil
+	case "subst":
+		if len(words) != 2 {
+			return "", fmt.Errorf("subst: need 1 argument")
+		}
+		return t.subst(words[1])
+	default:
+		return "", fmt.Errorf("unsupported Tcl command: %v", words[0])
+	}
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) eof() bool  { return p.pos >= len(p.src) }
+func (p *parser) peek() byte { return p.src[p.pos] }
+
+// end of command
+func (p *parser) atEnd() bool {
+	c := p.peek()
+	return c == '\n' || c == ';' || c == ']'
+}
+
+// skips white space, including newlines and ; if all is set.
+// backslash-newline counts as white space.
+func (p *parser) skipSpace(all bool) {
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '\n':
+			p.pos += 2
+		case all && (c == '\n' || c == ';'):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skips a comment up to the end of the line, honoring backslash-newline.
+func (p *parser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+}
+
+// parses one word, with substitution unless braced.
+func (p *parser) word(t *tcl) (string, error) {
+	switch p.peek() {
+	case '{':
+		return p.braced()
+	case '"':
+		p.pos++
+		var out strings.Builder
+		for !p.eof() && p.peek() != '"' {
+			if err := p.substOne(t, &out); err != nil {
+				return "", err
+			}
+		}
+		if p.eof() {
+			return "", fmt.Errorf("missing close-quote")
+		}
+		p.pos++
+		return out.String(), nil
+	default:
+		var out strings.Builder
+		for !p.eof() && !strings.ContainsRune(" \t\r\n;]", rune(p.peek())) {
+			if err := p.substOne(t, &out); err != nil {
+				return "", err
+			}
+		}
+		return out.String(), nil
+	}
+}
+
+// copies one character or substitution to out.
+func (p *parser) substOne(t *tcl, out *strings.Builder) error {
+	switch p.peek() {
+	case '$':
+		v, err := p.variable(t)
+		if err != nil {
+			return err
+		}
+		out.WriteString(v)
+	case '[':
+		v, err := p.command(t)
+		if err != nil {
+			return err
+		}
+		out.WriteString(v)
+	case '\\':
+		out.WriteString(p.backslash())
+	default:
+		out.WriteByte(p.peek())
+		p.pos++
+	}
+	return nil
+}
+
+// parses {...}, returning the contents without substitution.
+func (p *parser) braced() (string, error) {
+	start := p.pos
+	depth := 0
+	for ; !p.eof(); p.pos++ {
+		switch p.peek() {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start+1 : p.pos-1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("missing close-brace")
+}
+
+// parses $name or ${name}, returning its value.
+func (p *parser) variable(t *tcl) (string, error) {
+	p.pos++ // $
+	var name string
+	if !p.eof() && p.peek() == '{' {
+		n, err := p.braced()
+		if err != nil {
+			return "", err
+		}
+		name = n
+	} else {
+		start := p.pos
+		for !p.eof() && (isAlnum(p.peek()) || p.peek() == '_' || p.peek() == ':') {
+			p.pos++
+		}
+		name = p.src[start:p.pos]
+		if name == "" {
+			return "$", nil
+		}
+	}
+	v, ok := t.vars[name]
+	if !ok {
+		return "", fmt.Errorf("can't read %q: no such variable", name)
+	}
+	return v, nil
+}
+
+// parses and evaluates [cmd ...].
+func (p *parser) command(t *tcl) (string, error) {
+	p.pos++ // [
+	var words []string
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return "", fmt.Errorf("missing close-bracket")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return t.eval(words)
+		}
+		w, err := p.word(t)
+		if err != nil {
+			return "", err
+		}
+		words = append(words, w)
+	}
+}
+
+func (p *parser) backslash() string {
+	p.pos++
+	if p.eof() {
+		return "\\"
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case '\n':
+		return " "
+	default:
+		return string(c)
+	}
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// splits a Tcl list (no substitution).
+func splitList(s string) ([]string, error) {
+	var list []string
+	p := &parser{src: s}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return list, nil
+		}
+		var w string
+		var err error
+		switch p.peek() {
+		case '{':
+			w, err = p.braced()
+		case '"':
+			p.pos++
+			start := p.pos
+			for !p.eof() && p.peek() != '"' {
+				p.pos++
+			}
+			if p.eof() {
+				return nil, fmt.Errorf("missing close-quote in list")
+			}
+			w = s[start:p.pos]
+			p.pos++
+		default:
+			start := p.pos
+			for !p.eof() && !strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+				p.pos++
+			}
+			w = s[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+}
+
+// joins elements into a Tcl list, bracing where needed.
+func joinList(list []string) string {
+	q := make([]string, len(list))
+	for i, e := range list {
+		if e == "" || strings.ContainsAny(e, " \t\n;$[]\"") {
+			e = "{" + e + "}"
+		}
+		q[i] = e
+	}
+	return strings.Join(q, " ")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/mumax/3/draw"
+	"github.com/mumax/3/freetype/raster"
+	"github.com/mumax/3/svgo"
+)
+
+// renders f as SVG.
+func renderSVG(out io.Writer, f *figure) {
+	c := &svgCanvas{svg.New(out)}
+	c.Start(width, height)
+	f.draw(c)
+	c.End()
+}
+
+// renders f as PNG.
+func renderPNG(out io.Writer, f *figure) error {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	c := &pngCanvas{img: img, painter: raster.NewRGBAPainter(img), rasterizer: raster.NewRasterizer(width, height)}
+	c.rasterizer.UseNonZeroWinding = true
+	f.draw(c)
+	return draw.EncodeFormat(out, img, ".png")
+}
+
+type svgCanvas struct {
+	*svg.SVG
+}
+
+const svgFontSize = 12
+
+func (c *svgCanvas) polyline(x, y []float64, col color.RGBA, width float64) {
+	c.Polyline(x, y, fmt.Sprintf("fill:none;stroke:%v;stroke-width:%v;stroke-linejoin:round", rgb(col), width))
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+	c.Rect(x, y, w, h, "fill:"+rgb(fill))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, anchor int) {
+	a := [...]string{"start", "middle", "end"}[anchor+1]
+	c.Text(int(math.Floor(x+0.5)), int(math.Floor(y+0.5)), s,
+		fmt.Sprintf("font-family:monospace;font-size:%vpx;text-anchor:%v;dominant-baseline:central", svgFontSize, a))
+}
+
+// monospace characters are about 0.6 em wide
+func (c *svgCanvas) textSize(s string) (w, h float64) {
+	return 0.6 * svgFontSize * float64(len([]rune(s))), svgFontSize
+}
+
+func rgb(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// pngCanvas draws anti-aliased lines and the 5x7 pixel font of package draw.
+type pngCanvas struct {
+	img        *image.RGBA
+	painter    *raster.RGBAPainter
+	rasterizer *raster.Rasterizer
+}
+
+func (c *pngCanvas) polyline(x, y []float64, col color.RGBA, width float64) {
+	var path raster.Path
+	path.Start(fix(x[0], y[0]))
+	for i := 1; i < len(x); i++ {
+		path.Add1(fix(x[i], y[i]))
+	}
+	raster.Stroke(c.rasterizer, path, raster.Fix32(width*256), nil, nil)
+	c.painter.SetColor(col)
+	c.rasterizer.Rasterize(c.painter)
+	c.rasterizer.Clear()
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+	r := image.Rect(int(x), int(y), int(x+w), int(y+h))
+	for j := r.Min.Y; j < r.Max.Y; j++ {
+		for i := r.Min.X; i < r.Max.X; i++ {
+			c.img.Set(i, j, fill)
+		}
+	}
+}
+
+func (c *pngCanvas) text(x, y float64, s string, anchor int) {
+	w, h := draw.TextSize(s)
+	x0 := int(x) - (anchor+1)*w/2
+	draw.Text(c.img, x0, int(y)-h/2, s, black)
+}
+
+func (c *pngCanvas) textSize(s string) (w, h float64) {
+	iw, ih := draw.TextSize(s)
+	return float64(iw), float64(ih)
+}
+
+// pixel centers are at half-integer coordinates
+func fix(x, y float64) raster.Point {
+	return raster.Point{X: raster.Fix32((x + 0.5) * 256), Y: raster.Fix32((y + 0.5) * 256)}
+}
@@ -0,0 +1,252 @@
+package main
+
+// Backend-independent line plots with linear or logarithmic axes.
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// figure size in pixels
+const width, height = 640, 400
+
+// figure is a line plot of one or more series, like one gnuplot "plot" command.
+type figure struct {
+	xname, yname string // quantity names, e.g. "t", "m"
+	xunit, yunit string // units without parentheses, e.g. "s", "T"
+	xlog, ylog   bool
+	series       []series
+}
+
+type series struct {
+	name string
+	x, y []float64
+}
+
+// line colors, like gnuplot's default
+var palette = []color.RGBA{
+	{148, 0, 211, 255},
+	{0, 158, 115, 255},
+	{86, 180, 233, 255},
+	{230, 159, 0, 255},
+	{0, 114, 178, 255},
+	{229, 30, 16, 255},
+	{240, 228, 66, 255},
+	{0, 0, 0, 255},
+}
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+// canvas is a drawing backend: SVG or PNG.
+type canvas interface {
+	polyline(x, y []float64, col color.RGBA, width float64)
+	rect(x, y, w, h float64, fill color.RGBA)
+	// text with vertical center y, anchored at x by its start (anchor -1), middle (0) or end (1).
+	text(x, y float64, s string, anchor int)
+	textSize(s string) (w, h float64)
+}
+
+// draws the figure with axes, ticks, labels and legend.
+func (f *figure) draw(c canvas) {
+	var xs, ys [][]float64
+	for _, s := range f.series {
+		xs = append(xs, s.x)
+		ys = append(ys, s.y)
+	}
+	xa := newAxis(xs, f.xlog, f.xname, f.xunit)
+	ya := newAxis(ys, f.ylog, f.yname, f.yunit)
+
+	// layout
+	_, th := c.textSize("0")
+	const pad, tick = 10, 5
+	labelW := 0.
+	for _, l := range ya.labels {
+		w, _ := c.textSize(l)
+		labelW = math.Max(labelW, w)
+	}
+	lastW, _ := c.textSize(xa.labels[len(xa.labels)-1])
+	left := pad + labelW + 6
+	right := width - math.Max(2*pad, lastW/2+pad)
+	top := pad + th + 8
+	bottom := height - (pad + th + 8 + th + 6)
+	xa.from, xa.to = left, right
+	ya.from, ya.to = bottom, top
+
+	c.rect(0, 0, width, height, white)
+
+	// data, split where log axes have no valid point
+	for i, s := range f.series {
+		col := palette[i%len(palette)]
+		var px, py []float64
+		flush := func() {
+			if len(px) > 1 {
+				c.polyline(px, py, col, 1.5)
+			}
+			px, py = px[:0], py[:0]
+		}
+		for j := range s.x {
+			x, okx := xa.pos(s.x[j])
+			y, oky := ya.pos(s.y[j])
+			if !okx || !oky {
+				flush()
+				continue
+			}
+			px = append(px, x)
+			py = append(py, y)
+		}
+		flush()
+	}
+
+	// frame and ticks
+	c.polyline([]float64{left, right, right, left, left}, []float64{top, top, bottom, bottom, top}, black, 1)
+	for i, v := range xa.ticks {
+		x, _ := xa.pos(v)
+		c.polyline([]float64{x, x}, []float64{bottom, bottom - tick}, black, 1)
+		c.polyline([]float64{x, x}, []float64{top, top + tick}, black, 1)
+		c.text(x, bottom+6+th/2, xa.labels[i], 0)
+	}
+	for i, v := range ya.ticks {
+		y, _ := ya.pos(v)
+		c.polyline([]float64{left, left + tick}, []float64{y, y}, black, 1)
+		c.polyline([]float64{right, right - tick}, []float64{y, y}, black, 1)
+		c.text(left-6, y, ya.labels[i], 1)
+	}
+	c.text((left+right)/2, height-pad-th/2, xa.title, 0)
+	c.text(pad, pad+th/2, ya.title, -1)
+
+	// legend, top right inside the frame
+	const lineLen = 20
+	legendW := 0.
+	for _, s := range f.series {
+		w, _ := c.textSize(s.name)
+		legendW = math.Max(legendW, w)
+	}
+	lx := right - pad - lineLen - 6 - legendW
+	ly := top + pad
+	c.rect(lx-4, ly-4, lineLen+6+legendW+8, float64(len(f.series))*(th+6)+2, white)
+	for i, s := range f.series {
+		y := ly + float64(i)*(th+6) + th/2
+		c.polyline([]float64{lx, lx + lineLen}, []float64{y, y}, palette[i%len(palette)], 1.5)
+		c.text(lx+lineLen+6, y, s.name, -1)
+	}
+}
+
+// axis maps data values to image coordinates.
+type axis struct {
+	min, max float64 // range, log10 of it for log axes
+	log      bool
+	from, to float64 // image coordinates of min and max
+	ticks    []float64
+	labels   []string
+	title    string // e.g. "t (ns)"
+}
+
+// image coordinate of value v, false if v cannot be shown (log axis, v <= 0).
+func (a *axis) pos(v float64) (float64, bool) {
+	if a.log {
+		if v <= 0 {
+			return 0, false
+		}
+		v = math.Log10(v)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return a.from + (v-a.min)/(a.max-a.min)*(a.to-a.from), true
+}
+
+// axis spanning all values, with round ticks. Linear axes get an SI prefix for the unit.
+func newAxis(values [][]float64, log bool, name, unit string) *axis {
+	a := &axis{log: log}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, vs := range values {
+		for _, v := range vs {
+			if math.IsNaN(v) || math.IsInf(v, 0) || log && v <= 0 {
+				continue
+			}
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+	if min > max { // no data
+		min, max = 1, 10
+	}
+
+	if log {
+		a.min, a.max = math.Floor(math.Log10(min)), math.Ceil(math.Log10(max))
+		if a.min == a.max {
+			a.max++
+		}
+		step := math.Ceil((a.max - a.min) / 8) // decades per tick
+		for e := a.min; e <= a.max; e += step {
+			v := math.Pow(10, e)
+			a.ticks = append(a.ticks, v)
+			a.labels = append(a.labels, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		a.title = withUnit(name, unit)
+		return a
+	}
+
+	if min == max {
+		d := math.Abs(min) / 10
+		if d == 0 {
+			d = 1
+		}
+		min, max = min-d, max+d
+	}
+	step := tickStep(max - min)
+	a.min, a.max = math.Floor(min/step)*step, math.Ceil(max/step)*step
+
+	exp, prefix := 0, ""
+	if unit != "" {
+		exp, prefix = siPrefix(math.Max(math.Abs(a.min), math.Abs(a.max)))
+	}
+	scale := math.Pow(10, float64(-exp))
+	decimals := int(math.Max(0, -math.Floor(math.Log10(step*scale)+1e-9)))
+	for i := math.Round(a.min / step); i*step <= a.max+step/2; i++ {
+		v := i * step
+		a.ticks = append(a.ticks, v)
+		a.labels = append(a.labels, strconv.FormatFloat(v*scale, 'f', decimals, 64))
+	}
+	a.title = withUnit(name, prefix+unit)
+	return a
+}
+
+// about 5 ticks over span, spaced by 1, 2 or 5 x 10^n.
+func tickStep(span float64) float64 {
+	raw := span / 5
+	e := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*e >= raw*0.7 {
+			return m * e
+		}
+	}
+	return 10 * e
+}
+
+// SI prefix for values up to max, e.g. 3e-9 -> -9, "n".
+func siPrefix(max float64) (int, string) {
+	const prefixes = "yzafpnµm kMGTPEZY"
+	if max == 0 {
+		return 0, ""
+	}
+	exp := int(math.Floor(math.Log10(max)/3)) * 3
+	exp = int(math.Max(-24, math.Min(24, float64(exp))))
+	p := []rune(prefixes)[exp/3+8]
+	if p == ' ' {
+		return 0, ""
+	}
+	return exp, string(p)
+}
+
+// e.g. "B_ext (mT)", or just the name without unit.
+func withUnit(name, unit string) string {
+	if unit == "" {
+		return name
+	}
+	return name + " (" + unit + ")"
+}
@@ -1,7 +1,27 @@
 /*
-The mumax3-plot utility uses gnuplot to automatically plot mumax3 data tables.
+The mumax3-plot utility automatically plots mumax3 data tables.
+
 	mumax3-plot table.txt
-Creates graphs of all columns as .svg files.
+
+Creates graphs of all columns as .svg files, components of vector quantities (mx, my, mz) in one graph.
+OOMMF ODT tables are plotted against their simulation time column:
+
+	mumax3-plot table.odt
+
+Graphs are rendered without external programs. Add -png for PNG images besides SVG,
+or use gnuplot with -gnuplot (needs gnuplot installed):
+
+	mumax3-plot -png table.txt
+
+Plot against another column instead of time, e.g. a hysteresis loop. -y selects quantities or columns:
+
+	mumax3-plot -x B_extx -y mx table.txt
+
+Overlay several tables in the same graphs, written to the current directory:
+
+	mumax3-plot -overlay a.out/table.txt b.out/table.txt
+
+Logarithmic axes are set with -logx and -logy.
 */
 package main
 
@@ -14,12 +34,34 @@ import (
 	"os/exec"
 	"path"
 	"strings"
+
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/odt"
+)
+
+var (
+	flag_gnuplot = flag.Bool("gnuplot", false, "Plot with gnuplot instead of the built-in renderer (time plots only)")
+	flag_svg     = flag.Bool("svg", true, "SVG output")
+	flag_png     = flag.Bool("png", false, "PNG output")
+	flag_x       = flag.String("x", "", `Column to plot on the x axis instead of time, e.g. "B_extx"`)
+	flag_y       = flag.String("y", "", `Only plot these quantities or columns, comma separated, e.g. "m" or "mx,my"`)
+	flag_logx    = flag.Bool("logx", false, "Logarithmic x axis")
+	flag_logy    = flag.Bool("logy", false, "Logarithmic y axis")
+	flag_overlay = flag.Bool("overlay", false, "Plot all input files in the same graphs, in the current directory")
 )
 
 func main() {
 	log.SetFlags(0)
 	flag.Parse()
 
+	if *flag_gnuplot && (*flag_x != "" || *flag_y != "" || *flag_logx || *flag_logy || *flag_overlay || *flag_png) {
+		log.Fatal("-gnuplot only makes SVG plots against time, without -x, -y, -logx, -logy, -overlay or -png")
+	}
+
+	if *flag_overlay {
+		overlay(flag.Args())
+		return
+	}
 	for _, f := range flag.Args() {
 		plotFile(f)
 	}
@@ -27,22 +69,44 @@ func main() {
 
 func plotFile(fname string) {
 
-	hdr := readHeader(fname)
+	var names, units []string
+	tcol, tscale := 1, 1.0 // time column (counts from 1, like gnuplot) and unit in s
+	if path.Ext(fname) == ".odt" {
+		names, units, tcol, tscale = readODTHeader(fname)
+	} else {
+		names, units = readTxtHeader(fname)
+	}
+	Qs := quantities(names, units, tcol)
+	log.Println(Qs)
+
+	if *flag_gnuplot {
+		for i := range Qs {
+			makePlot(fname, Qs[i], tcol, tscale)
+		}
+		return
+	}
+
+	t := readTable(fname)
+	for _, q := range Qs {
+		if f := newFigure(t, q, ""); f != nil {
+			saveFigure(path.Dir(fname)+"/"+figureName(q), f)
+		}
+	}
+}
 
-	// quantities grouped by vector
-	Qs := []*Q{&Q{[]string{"t"}, "s", []int{1}}}
-	prev := Qs[0]
+// quantities grouped by vector
+func quantities(names, units []string, tcol int) []*Q {
+	var Qs []*Q
+	var prev *Q
 
-	quants := strings.Split(hdr, "\t")
-	for i := 1; i < len(quants); i++ {
-		spl := strings.Split(quants[i], " ")
-		name := spl[0]
-		unit := spl[1]
-		if unit == "()" {
-			unit = ""
+	for i := range names {
+		if i+1 == tcol {
+			continue
 		}
+		name := names[i]
+		unit := units[i]
 
-		if name[:len(name)-1] == prev.name[0][:len(prev.name[0])-1] {
+		if prev != nil && len(name) > 1 && name[:len(name)-1] == prev.name[0][:len(prev.name[0])-1] {
 			prev.cols = append(prev.cols, i+1)
 			prev.name = append(prev.name, name)
 		} else {
@@ -51,24 +115,117 @@ func plotFile(fname string) {
 			prev = n
 		}
 	}
-	log.Println(Qs)
+	return Qs
+}
+
+// plots all files in the same graphs, one per quantity.
+func overlay(fnames []string) {
+	var figs []*figure
+	var figNames []string
+	byName := make(map[string]*figure)
+	for _, fname := range fnames {
+		t := readTable(fname)
+		for _, q := range quantities(t.names, t.units, t.tcol) {
+			label := strings.TrimSuffix(fname, path.Ext(fname)) + " "
+			f := newFigure(t, q, label)
+			if f == nil {
+				continue
+			}
+			name := figureName(q)
+			if prev := byName[name]; prev != nil {
+				prev.series = append(prev.series, f.series...)
+				continue
+			}
+			byName[name] = f
+			figs = append(figs, f)
+			figNames = append(figNames, name)
+		}
+	}
+	for i, f := range figs {
+		saveFigure(figNames[i], f)
+	}
+}
+
+// figure of the columns of q selected by -y, against time or the -x column.
+// Series names get a prefix label. Returns nil if nothing is selected.
+func newFigure(t *table, q *Q, label string) *figure {
+	f := &figure{xname: "t", xunit: "s", yname: q.vecname(), yunit: unitOf(q.unit), xlog: *flag_logx, ylog: *flag_logy}
+	xcol := t.tcol
+	if *flag_x != "" {
+		xcol = t.find(*flag_x)
+		if xcol == 0 {
+			log.Fatal("-x: no column ", *flag_x, " in table, have: ", t.names)
+		}
+		f.xname, f.xunit = *flag_x, unitOf(t.units[xcol-1])
+	}
+	for i, col := range q.cols {
+		if col == xcol || !selected(q, i) {
+			continue
+		}
+		f.series = append(f.series, series{label + q.name[i], t.column(xcol), t.column(col)})
+	}
+	if len(f.series) == 0 {
+		return nil
+	}
+	return f
+}
+
+// whether component i of q is selected by -y
+func selected(q *Q, i int) bool {
+	if *flag_y == "" {
+		return true
+	}
+	for _, w := range strings.Split(*flag_y, ",") {
+		w = strings.TrimSpace(w)
+		if w == q.vecname() || w == q.name[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// output file name without extension, e.g. "m" or "m-B_extx".
+func figureName(q *Q) string {
+	name := q.vecname()
+	if *flag_x != "" {
+		name += "-" + *flag_x
+	}
+	return fileName(name)
+}
 
-	for i := 1; i < len(Qs); i++ {
-		makePlot(fname, Qs[i])
+// writes f as fname.svg and/or fname.png
+func saveFigure(fname string, f *figure) {
+	if *flag_svg {
+		out := httpfs.MustCreate(fname + ".svg")
+		renderSVG(out, f)
+		check(out.Close())
+		log.Println(fname + ".svg")
 	}
+	if *flag_png {
+		out := httpfs.MustCreate(fname + ".png")
+		check(renderPNG(out, f))
+		check(out.Close())
+		log.Println(fname + ".png")
+	}
+}
+
+// unit without parentheses, "(T)" -> "T"
+func unitOf(u string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(u, "("), ")")
 }
 
-func makePlot(fname string, q *Q) {
+func makePlot(fname string, q *Q, tcol int, tscale float64) {
 	term := "svg"
-	outf := path.Dir(fname) + "/" + q.vecname()
+	outf := path.Dir(fname) + "/" + fileName(q.vecname())
 	cmd := fmt.Sprintf(`set term %v size 400 300 fsize 10; set output "%v.%v";`, term, outf, term)
 	cmd += fmt.Sprintf(`set xlabel "t(ns)";`)
 
 	cmd += fmt.Sprintf(`set ylabel "%v %v";`, q.vecname(), q.unit)
-	cmd += fmt.Sprint(`set format y "%g";`)
-	cmd += fmt.Sprint(`plot "`, fname, `" u ($1*1e9):`, q.cols[0], ` w li title "`, q.name[0], `"`)
+	cmd += `set format y "%g";`
+	t := fmt.Sprint(`($`, tcol, `*`, tscale*1e9, `)`)
+	cmd += fmt.Sprint(`plot "`, fname, `" u `, t, `:`, q.cols[0], ` w li title "`, q.name[0], `"`)
 	for i := 1; i < len(q.cols); i++ {
-		cmd += fmt.Sprint(`, "`, fname, `" u ($1*1e9):`, q.cols[i], ` w li title "`, q.name[i], `"`)
+		cmd += fmt.Sprint(`, "`, fname, `" u `, t, `:`, q.cols[i], ` w li title "`, q.name[i], `"`)
 	}
 	cmd += "; set output;"
 
@@ -93,7 +250,18 @@ func (q *Q) vecname() string {
 	}
 }
 
-func readHeader(fname string) string {
+// replaces characters that don't belong in a file name, like in OOMMF's "Mx/Ms".
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' || r == '|' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// column names and units from a mumax3 table header, like "# t (s)	mx ()	...".
+func readTxtHeader(fname string) (names, units []string) {
 	f, err := os.Open(fname)
 	check(err)
 	defer f.Close()
@@ -105,7 +273,33 @@ func readHeader(fname string) string {
 		log.Fatal("invalid table header:", hdr)
 	}
 	hdr = hdr[2:]
-	return hdr
+
+	for _, q := range strings.Split(hdr, "\t") {
+		spl := strings.Split(q, " ")
+		unit := ""
+		if len(spl) > 1 && spl[1] != "()" {
+			unit = spl[1]
+		}
+		names = append(names, spl[0])
+		units = append(units, unit)
+	}
+	return
+}
+
+// column names and units from an ODT table, as well as its time column (counting from 1) and unit.
+func readODTHeader(fname string) (names, units []string, tcol int, tscale float64) {
+	t, err := odt.ReadFile(fname)
+	check(err)
+	c, tscale, err := t.TimeColumn()
+	check(err)
+	for i := range t.Units {
+		if t.Units[i] != "" {
+			units = append(units, "("+t.Units[i]+")")
+		} else {
+			units = append(units, "")
+		}
+	}
+	return t.Columns, units, c + 1, tscale
 }
 
 func check(err error) {
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/odt"
+)
+
+// table holds the columns of a mumax3 or ODT table.
+type table struct {
+	names, units []string    // units in parentheses, like "(T)", or empty
+	data         [][]float64 // data[column][row]
+	tcol         int         // time column, counts from 1 like gnuplot
+	tscale       float64     // time unit in s
+}
+
+func readTable(fname string) *table {
+	if path.Ext(fname) == ".odt" {
+		t, err := odt.ReadFile(fname)
+		check(err)
+		names, units, tcol, tscale := readODTHeader(fname)
+		return &table{names, units, t.Data, tcol, tscale}
+	}
+	names, units := readTxtHeader(fname)
+	return &table{names, units, readTxtData(fname, len(names)), 1, 1}
+}
+
+// numbers of a mumax3 table, skipping comment lines.
+func readTxtData(fname string, cols int) [][]float64 {
+	f, err := os.Open(fname)
+	check(err)
+	defer f.Close()
+	data := make([][]float64, cols)
+	in := bufio.NewScanner(f)
+	in.Buffer(nil, 1<<24)
+	for in.Scan() {
+		line := strings.TrimSpace(in.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		words := strings.Fields(line)
+		if len(words) != cols {
+			continue // truncated last line of a running simulation
+		}
+		for c, w := range words {
+			v, err := strconv.ParseFloat(w, 64)
+			check(err)
+			data[c] = append(data[c], v)
+		}
+	}
+	check(in.Err())
+	return data
+}
+
+// column of a table, time converted to s.
+func (t *table) column(col int) []float64 {
+	d := t.data[col-1]
+	if col != t.tcol || t.tscale == 1 {
+		return d
+	}
+	s := make([]float64, len(d))
+	for i, v := range d {
+		s[i] = v * t.tscale
+	}
+	return s
+}
+
+// column number (counting from 1) of a column named name, 0 if absent.
+func (t *table) find(name string) int {
+	for i, n := range t.names {
+		if n == name {
+			return i + 1
+		}
+	}
+	return 0
+}
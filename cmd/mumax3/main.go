@@ -20,6 +20,7 @@ var (
 	flag_test     = flag.Bool("test", false, "Cuda test (internal)")
 	flag_version  = flag.Bool("v", true, "Print version")
 	flag_vet      = flag.Bool("vet", false, "Check input files for errors, but don't run them")
+	flag_debug    = flag.Bool("debug", false, "Run input file in the debugger: set breakpoints and inspect the simulation from stdin")
 	// more flags in engine/gofiles.go
 )
 
@@ -28,6 +29,7 @@ func main() {
 	log.SetPrefix("")
 	log.SetFlags(0)
 
+	cuda.CPU = cuda.CPU || *engine.Flag_cpu
 	cuda.Init(*engine.Flag_gpu)
 
 	cuda.Synchronous = *engine.Flag_sync
@@ -49,12 +51,19 @@ func main() {
 		return
 	}
 
+	if *flag_debug && flag.NArg() != 1 {
+		log.Fatal("-debug needs exactly one input file")
+	}
+
 	switch flag.NArg() {
 	case 0:
 		runInteractive()
 	case 1:
 		runFileAndServe(flag.Arg(0))
 	default:
+		if *engine.Flag_resume != "" {
+			log.Fatal("-resume needs exactly one input file")
+		}
 		RunQueue(flag.Args())
 	}
 }
@@ -95,9 +104,13 @@ func runScript(fname string) {
 	if *engine.Flag_od != "" {
 		outDir = *engine.Flag_od
 	}
-	engine.InitIO(fname, outDir, *engine.Flag_forceclean)
+	resume := *engine.Flag_resume
+	engine.InitIO(fname, outDir, *engine.Flag_forceclean && resume == "")
 
 	fname = engine.InputFile
+	if resume != "" {
+		engine.Resume(resume)
+	}
 
 	var code *script.BlockStmt
 	var err2 error
@@ -114,6 +127,10 @@ func runScript(fname string) {
 		openbrowser("http://127.0.0.1" + *engine.Flag_port)
 	}
 
+	if *flag_debug {
+		engine.Debug()
+	}
+
 	// start executing the tree, possibly injecting commands from web gui
 	engine.EvalFile(code)
 
@@ -161,7 +178,11 @@ func goServeGUI() string {
 // print version to stdout
 func printVersion() {
 	fmt.Print("//", engine.UNAME, "\n")
-	fmt.Print("//", cuda.GPUInfo, ", using CC", cuda.UseCC, " PTX \n")
+	if cuda.CPU {
+		fmt.Print("//", cuda.GPUInfo, "\n")
+	} else {
+		fmt.Print("//", cuda.GPUInfo, ", using CC", cuda.UseCC, " PTX \n")
+	}
 	fmt.Print("//(c) Arne Vansteenkiste, Dynamat LAB, Ghent University, Belgium", "\n")
 	fmt.Print("//This is free software without any warranty. See license.txt", "\n")
 	fmt.Print("//If you use mumax in any work or publication,", "\n")
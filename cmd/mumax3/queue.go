@@ -5,6 +5,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/mumax/3/cuda"
 	"github.com/mumax/3/cuda/cu"
 	"github.com/mumax/3/engine"
 	"io"
@@ -80,7 +81,10 @@ func (s *stateTab) Finish(j job) {
 
 // Runs all the jobs in stateTab.
 func (s *stateTab) Run() {
-	nGPU := cu.DeviceGetCount()
+	nGPU := 1 // CPU backend runs one job at a time
+	if !cuda.CPU {
+		nGPU = cu.DeviceGetCount()
+	}
 	idle := initGPUs(nGPU)
 	for {
 		gpu := <-idle
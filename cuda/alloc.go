@@ -10,6 +10,9 @@ import (
 
 // Wrapper for cu.MemAlloc, fatal exit on out of memory.
 func MemAlloc(bytes int64) unsafe.Pointer {
+	if CPU {
+		return cpuAlloc(bytes)
+	}
 	defer func() {
 		err := recover()
 		if err == cu.ERROR_OUT_OF_MEMORY {
@@ -28,3 +31,8 @@ func GPUCopy(in *data.Slice) *data.Slice {
 	data.Copy(s, in)
 	return s
 }
+
+// Frees memory allocated with MemAlloc.
+func MemFree(ptr unsafe.Pointer) {
+	memFree(ptr)
+}
@@ -78,7 +78,7 @@ func FreeBuffers() {
 	Sync()
 	for _, size := range buf_pool {
 		for i := range size {
-			cu.DevicePtr(uintptr(size[i])).Free()
+			memFree(size[i])
 			size[i] = nil
 		}
 	}
@@ -11,62 +11,114 @@ import (
 )
 
 // 3D byte slice, used for region lookup.
+// Wide slices hold 16-bit elements, used for more than 256 regions.
 type Bytes struct {
-	Ptr unsafe.Pointer
-	Len int
+	Ptr  unsafe.Pointer
+	Len  int
+	Wide bool
 }
 
 // Construct new byte slice with given length,
 // initialised to zeros.
 func NewBytes(Len int) *Bytes {
-	ptr := cu.MemAlloc(int64(Len))
-	cu.MemsetD8(cu.DevicePtr(ptr), 0, int64(Len))
-	return &Bytes{unsafe.Pointer(uintptr(ptr)), Len}
+	return newBytes(Len, false)
+}
+
+// Construct new slice of 16-bit elements with given length,
+// initialised to zeros.
+func NewWideBytes(Len int) *Bytes {
+	return newBytes(Len, true)
+}
+
+func newBytes(Len int, wide bool) *Bytes {
+	b := &Bytes{nil, Len, wide}
+	b.Ptr = MemAlloc(b.bytes())
+	if !CPU {
+		cu.MemsetD8(cu.DevicePtr(uintptr(b.Ptr)), 0, b.bytes())
+	}
+	return b
+}
+
+// size of one element in bytes
+func (b *Bytes) elemSize() int64 {
+	if b.Wide {
+		return 2
+	}
+	return 1
+}
+
+// total size in bytes
+func (b *Bytes) bytes() int64 {
+	return b.elemSize() * int64(b.Len)
 }
 
 // Upload src (host) to dst (gpu).
 func (dst *Bytes) Upload(src []byte) {
-	util.Argument(dst.Len == len(src))
-	MemCpyHtoD(dst.Ptr, unsafe.Pointer(&src[0]), int64(dst.Len))
+	util.Argument(dst.Len == len(src) && !dst.Wide)
+	MemCpyHtoD(dst.Ptr, unsafe.Pointer(&src[0]), dst.bytes())
+}
+
+// Upload src (host) to wide dst (gpu).
+func (dst *Bytes) Upload16(src []uint16) {
+	util.Argument(dst.Len == len(src) && dst.Wide)
+	MemCpyHtoD(dst.Ptr, unsafe.Pointer(&src[0]), dst.bytes())
 }
 
 // Copy on device: dst = src.
 func (dst *Bytes) Copy(src *Bytes) {
-	util.Argument(dst.Len == src.Len)
-	MemCpy(dst.Ptr, src.Ptr, int64(dst.Len))
+	util.Argument(dst.Len == src.Len && dst.Wide == src.Wide)
+	MemCpy(dst.Ptr, src.Ptr, dst.bytes())
 }
 
 // Copy to host: dst = src.
 func (src *Bytes) Download(dst []byte) {
-	util.Argument(src.Len == len(dst))
-	MemCpyDtoH(unsafe.Pointer(&dst[0]), src.Ptr, int64(src.Len))
+	util.Argument(src.Len == len(dst) && !src.Wide)
+	MemCpyDtoH(unsafe.Pointer(&dst[0]), src.Ptr, src.bytes())
+}
+
+// Copy wide src to host: dst = src.
+func (src *Bytes) Download16(dst []uint16) {
+	util.Argument(src.Len == len(dst) && src.Wide)
+	MemCpyDtoH(unsafe.Pointer(&dst[0]), src.Ptr, src.bytes())
 }
 
 // Set one element to value.
 // data.Index can be used to find the index for x,y,z.
-func (dst *Bytes) Set(index int, value byte) {
+func (dst *Bytes) Set(index int, value int) {
 	if index < 0 || index >= dst.Len {
 		log.Panic("Bytes.Set: index out of range:", index)
 	}
-	src := value
-	MemCpyHtoD(unsafe.Pointer(uintptr(dst.Ptr)+uintptr(index)), unsafe.Pointer(&src), 1)
+	ptr := unsafe.Pointer(uintptr(dst.Ptr) + uintptr(int64(index)*dst.elemSize()))
+	if dst.Wide {
+		src := uint16(value)
+		MemCpyHtoD(ptr, unsafe.Pointer(&src), 2)
+	} else {
+		src := byte(value)
+		MemCpyHtoD(ptr, unsafe.Pointer(&src), 1)
+	}
 }
 
 // Get one element.
 // data.Index can be used to find the index for x,y,z.
-func (src *Bytes) Get(index int) byte {
+func (src *Bytes) Get(index int) int {
 	if index < 0 || index >= src.Len {
 		log.Panic("Bytes.Set: index out of range:", index)
 	}
+	ptr := unsafe.Pointer(uintptr(src.Ptr) + uintptr(int64(index)*src.elemSize()))
+	if src.Wide {
+		var dst uint16
+		MemCpyDtoH(unsafe.Pointer(&dst), ptr, 2)
+		return int(dst)
+	}
 	var dst byte
-	MemCpyDtoH(unsafe.Pointer(&dst), unsafe.Pointer(uintptr(src.Ptr)+uintptr(index)), 1)
-	return dst
+	MemCpyDtoH(unsafe.Pointer(&dst), ptr, 1)
+	return int(dst)
 }
 
 // Frees the GPU memory and disables the slice.
 func (b *Bytes) Free() {
 	if b.Ptr != nil {
-		cu.MemFree(cu.DevicePtr(uintptr(b.Ptr)))
+		memFree(b.Ptr)
 	}
 	b.Ptr = nil
 	b.Len = 0
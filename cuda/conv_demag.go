@@ -88,6 +88,10 @@ func (c *DemagConvolution) is2D() bool {
 
 // zero 1-component slice
 func zero1_async(dst *data.Slice) {
+	if CPU {
+		cpuMemset(dst.DevPtr(0), 0, dst.Len())
+		return
+	}
 	cu.MemsetD32Async(cu.DevicePtr(uintptr(dst.DevPtr(0))), 0, int64(dst.Len()), stream0)
 }
 
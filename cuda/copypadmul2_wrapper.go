@@ -52,6 +52,11 @@ func init() {
 
 // Wrapper for copypadmul2 CUDA kernel, asynchronous.
 func k_copypadmul2_async(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, Ms_ unsafe.Pointer, Ms_mul float32, vol unsafe.Pointer, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_copypadmul2(dst, Dx, Dy, Dz, src, Sx, Sy, Sz, Ms_, Ms_mul, vol)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("copypadmul2")
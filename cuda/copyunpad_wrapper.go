@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for copyunpad CUDA kernel, asynchronous.
 func k_copyunpad_async(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_copyunpad(dst, Dx, Dy, Dz, src, Sx, Sy, Sz)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("copyunpad")
@@ -0,0 +1,244 @@
+package cuda
+
+// Pure-Go CPU backend.
+//
+// When CPU is set before Init, "device memory" is ordinary Go memory and every
+// CUDA kernel is replaced by its Go twin in cpu_*.go, which the generated
+// k_*_async wrappers dispatch to. Slices keep their GPU memory type, so the
+// engine code runs unchanged. Builds with -tags nocuda do not link against
+// CUDA at all and always use this backend.
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Use the pure-Go CPU backend instead of CUDA. Must be set before Init.
+var CPU bool
+
+// number of goroutines used by the CPU kernels
+var cpuNWorker = runtime.NumCPU()
+
+// below this number of elements, CPU kernels run on a single goroutine
+const cpuMinChunk = 4096
+
+// region index: byte, or uint16 for the *16 kernels used with more than 256 regions.
+type cpuRegion interface{ ~uint8 | ~uint16 }
+
+// number of LUT entries addressable by region index type R, see lut.go
+func cpuNRegion[R cpuRegion]() int {
+	var r R
+	return 1 << (8 * unsafe.Sizeof(r))
+}
+
+// number of entries in the symmetric inter-region LUT, see exchange.h
+func cpuNSymm[R cpuRegion]() int {
+	n := cpuNRegion[R]()
+	return n * (n + 1) / 2
+}
+
+// Go equivalent of constants.h
+const (
+	cpuMu0    = 4 * math.Pi * 1e-7 // Permeability of vacuum in Tm/A
+	cpuQe     = 1.60217646e-19     // Electron charge in C
+	cpuMuB    = 9.2740091523e-24   // Bohr magneton in J/T
+	cpuGamma0 = 1.7595e11          // Gyromagnetic ratio of electron, in rad/Ts
+	cpuHbar   = 1.05457173e-34
+)
+
+func initCPU() {
+	DevName = "CPU"
+	GPUInfo = fmt.Sprint("CPU backend (pure Go, ", cpuNWorker, " threads)")
+}
+
+// allocates zeroed host memory that stands in for device memory.
+func cpuAlloc(bytes int64) unsafe.Pointer {
+	buf := make([]float32, (bytes+3)/4+1) // float32 for alignment, +1 avoids zero-size allocations
+	return unsafe.Pointer(&buf[0])
+}
+
+func cpuMemcpy(dst, src unsafe.Pointer, bytes int64) {
+	copy(cpuBytes(dst, int(bytes)), cpuBytes(src, int(bytes)))
+}
+
+func cpuMemset(dst unsafe.Pointer, value float32, N int) {
+	d := cpuFloats(dst, N)
+	for i := range d {
+		d[i] = value
+	}
+}
+
+// float32 array of length N at ptr, nil if ptr is nil.
+func cpuFloats(ptr unsafe.Pointer, N int) []float32 {
+	if ptr == nil {
+		return nil
+	}
+	return unsafe.Slice((*float32)(ptr), N)
+}
+
+// byte array of length N at ptr.
+func cpuBytes(ptr unsafe.Pointer, N int) []byte {
+	return unsafe.Slice((*byte)(ptr), N)
+}
+
+// region index array of length N at ptr.
+func cpuRegions[R cpuRegion](ptr unsafe.Pointer, N int) []R {
+	return unsafe.Slice((*R)(ptr), N)
+}
+
+// cpuParallel splits [0, N) into contiguous chunks and calls f(chunk, start, stop)
+// for each of them concurrently. Chunks are numbered 0..cpuNChunk(N)-1.
+func cpuParallel(N int, f func(chunk, start, stop int)) {
+	cpuParallelN(cpuNChunk(N), N, f)
+}
+
+// cpuParallelN is like cpuParallel, but with a given number of chunks.
+func cpuParallelN(nChunk, N int, f func(chunk, start, stop int)) {
+	if nChunk == 1 {
+		f(0, 0, N)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(nChunk)
+	for c := 0; c < nChunk; c++ {
+		go func(c int) {
+			f(c, c*N/nChunk, (c+1)*N/nChunk)
+			wg.Done()
+		}(c)
+	}
+	wg.Wait()
+}
+
+// number of chunks cpuParallel uses for N elements.
+func cpuNChunk(N int) int {
+	n := iMin(cpuNWorker, divUp(N, cpuMinChunk))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// cpuParallel3D runs f(iy, iz) for all rows of an Nx x Ny x Nz array concurrently.
+func cpuParallel3D(Nx, Ny, Nz int, f func(iy, iz int)) {
+	nRow := Ny * Nz
+	cpuParallel(nRow*Nx, func(_, start, stop int) {
+		for r := start / Nx; r < stop/Nx; r++ {
+			f(r%Ny, r/Ny)
+		}
+	})
+}
+
+// Go equivalent of float3.h.
+type float3 struct{ x, y, z float32 }
+
+func (a float3) add(b float3) float3  { return float3{a.x + b.x, a.y + b.y, a.z + b.z} }
+func (a float3) sub(b float3) float3  { return float3{a.x - b.x, a.y - b.y, a.z - b.z} }
+func (a float3) mul(s float32) float3 { return float3{s * a.x, s * a.y, s * a.z} }
+func (a float3) dot(b float3) float32 { return a.x*b.x + a.y*b.y + a.z*b.z }
+func (a float3) is0() bool            { return a.dot(a) == 0 }
+func (a float3) len() float32         { return sqrtf(a.dot(a)) }
+func (a float3) cross(b float3) float3 {
+	return float3{a.y*b.z - a.z*b.y, a.z*b.x - a.x*b.z, a.x*b.y - a.y*b.x}
+}
+func load3(x, y, z []float32, i int) float3 { return float3{x[i], y[i], z[i]} }
+
+func (a float3) store(x, y, z []float32, i int) {
+	x[i] = a.x
+	y[i] = a.y
+	z[i] = a.z
+}
+
+// normalized copy, or zero for zero length.
+func (a float3) normalized() float3 {
+	var veclen float32
+	if a.len() != 0 {
+		veclen = 1 / a.len()
+	}
+	return a.mul(veclen)
+}
+
+func sqrtf(x float32) float32 { return float32(math.Sqrt(float64(x))) }
+func acosf(x float32) float32 { return float32(math.Acos(float64(x))) }
+func pow2(x float32) float32  { return x * x }
+func pow3(x float32) float32  { return x * x * x }
+func pow4(x float32) float32  { s := x * x; return s * s }
+
+// fmaxf semantics: NaN arguments are ignored.
+func fmaxf(a, b float32) float32 {
+	if a != a || b > a {
+		return b
+	}
+	return a
+}
+
+// Go equivalent of amul.h: mul * arr[i], or mul when arr == nil.
+func amul(arr []float32, mul float32, i int) float32 {
+	if arr == nil {
+		return mul
+	}
+	return mul * arr[i]
+}
+
+func vmul(ax, ay, az []float32, mx, my, mz float32, i int) float3 {
+	return float3{amul(ax, mx, i), amul(ay, my, i), amul(az, mz, i)}
+}
+
+// 1/Msat, or 0 when Msat == 0.
+func invMsat(Ms []float32, Ms_mul float32, i int) float32 {
+	ms := amul(Ms, Ms_mul, i)
+	if ms == 0 {
+		return 0
+	}
+	return 1 / ms
+}
+
+// Go equivalent of exchange.h: index in symmetric matrix.
+func symidx[R cpuRegion](i, j R) int {
+	I, J := int(i), int(j)
+	if J <= I {
+		return I*(I+1)/2 + J
+	}
+	return J*(J+1)/2 + I
+}
+
+// Go equivalent of stencil.h: clamp or wrap index at the boundary, depending on PBC.
+type cpuStencil struct {
+	Nx, Ny, Nz int
+	PBC        byte
+}
+
+func (s *cpuStencil) idx(ix, iy, iz int) int { return (iz*s.Ny+iy)*s.Nx + ix }
+func (s *cpuStencil) pbcx() bool             { return s.PBC&1 != 0 }
+func (s *cpuStencil) pbcy() bool             { return s.PBC&2 != 0 }
+func (s *cpuStencil) pbcz() bool             { return s.PBC&4 != 0 }
+func (s *cpuStencil) hclampx(ix int) int     { return hclamp(ix, s.Nx, s.pbcx()) }
+func (s *cpuStencil) lclampx(ix int) int     { return lclamp(ix, s.Nx, s.pbcx()) }
+func (s *cpuStencil) hclampy(iy int) int     { return hclamp(iy, s.Ny, s.pbcy()) }
+func (s *cpuStencil) lclampy(iy int) int     { return lclamp(iy, s.Ny, s.pbcy()) }
+func (s *cpuStencil) hclampz(iz int) int     { return hclamp(iz, s.Nz, s.pbcz()) }
+func (s *cpuStencil) lclampz(iz int) int     { return lclamp(iz, s.Nz, s.pbcz()) }
+
+func hclamp(i, N int, pbc bool) int {
+	if pbc {
+		return mod(i, N)
+	}
+	return iMin(i, N-1)
+}
+
+func lclamp(i, N int, pbc bool) int {
+	if pbc {
+		return mod(i, N)
+	}
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// modulo used for PBC wrap around
+func mod(n, M int) int {
+	return ((n % M) + M) % M
+}
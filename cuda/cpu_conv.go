@@ -0,0 +1,219 @@
+package cuda
+
+// Go twins of the copy, shift and kernel multiplication CUDA kernels, used by the CPU backend.
+// See the corresponding .cu files.
+
+import (
+	"unsafe"
+)
+
+func cpu_copypadmul2(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, Ms_ unsafe.Pointer, Ms_mul float32, vol unsafe.Pointer) {
+	D := &cpuStencil{Nx: Dx, Ny: Dy, Nz: Dz}
+	S := &cpuStencil{Nx: Sx, Ny: Sy, Nz: Sz}
+	d, s := cpuFloats(dst, Dx*Dy*Dz), cpuFloats(src, Sx*Sy*Sz)
+	Ms, v := cpuFloats(Ms_, Sx*Sy*Sz), cpuFloats(vol, Sx*Sy*Sz)
+	cpuParallel3D(Sx, Sy, Sz, func(iy, iz int) {
+		for ix := 0; ix < Sx; ix++ {
+			sI := S.idx(ix, iy, iz)
+			Bsat := float32(cpuMu0 * float64(amul(Ms, Ms_mul, sI)))
+			d[D.idx(ix, iy, iz)] = Bsat * amul(v, 1, sI) * s[sI]
+		}
+	})
+}
+
+func cpu_copyunpad(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int) {
+	cpu_crop(dst, Dx, Dy, Dz, src, Sx, Sy, Sz, 0, 0, 0)
+}
+
+func cpu_crop(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, Offx int, Offy int, Offz int) {
+	D := &cpuStencil{Nx: Dx, Ny: Dy, Nz: Dz}
+	S := &cpuStencil{Nx: Sx, Ny: Sy, Nz: Sz}
+	d, s := cpuFloats(dst, Dx*Dy*Dz), cpuFloats(src, Sx*Sy*Sz)
+	cpuParallel3D(Dx, Dy, Dz, func(iy, iz int) {
+		copy(d[D.idx(0, iy, iz):D.idx(Dx, iy, iz)], s[S.idx(Offx, iy+Offy, iz+Offz):])
+	})
+}
+
+func cpu_resize(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, layer int, scalex int, scaley int) {
+	d, s := cpuFloats(dst, Dx*Dy*Dz), cpuFloats(src, Sx*Sy*Sz)
+	cpuParallel3D(Dx, Dy, 1, func(iy, _ int) {
+		for ix := 0; ix < Dx; ix++ {
+			var sum, n float32
+			for J := 0; J < scaley; J++ {
+				j2 := iy*scaley + J
+				for K := 0; K < scalex; K++ {
+					k2 := ix*scalex + K
+					if j2 < Sy && k2 < Sx {
+						sum += s[(layer*Sy+j2)*Sx+k2]
+						n++
+					}
+				}
+			}
+			d[iy*Dx+ix] = sum / n
+		}
+	})
+}
+
+// shift along direction, shared by cpu_shiftx, cpu_shifty, cpu_shiftz.
+func cpuShift(dst, src unsafe.Pointer, Nx, Ny, Nz int, sh [3]int, clampL, clampR float32) {
+	N := Nx * Ny * Nz
+	S := &cpuStencil{Nx: Nx, Ny: Ny, Nz: Nz}
+	d, s := cpuFloats(dst, N), cpuFloats(src, N)
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			ix2, iy2, iz2 := ix-sh[X], iy-sh[Y], iz-sh[Z]
+			var newval float32
+			switch {
+			case ix2 < 0 || iy2 < 0 || iz2 < 0:
+				newval = clampL
+			case ix2 >= Nx || iy2 >= Ny || iz2 >= Nz:
+				newval = clampR
+			default:
+				newval = s[S.idx(ix2, iy2, iz2)]
+			}
+			d[S.idx(ix, iy, iz)] = newval
+		}
+	})
+}
+
+func cpu_shiftx(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clampL float32, clampR float32) {
+	cpuShift(dst, src, Nx, Ny, Nz, [3]int{shx, 0, 0}, clampL, clampR)
+}
+
+func cpu_shifty(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clampL float32, clampR float32) {
+	cpuShift(dst, src, Nx, Ny, Nz, [3]int{0, shy, 0}, clampL, clampR)
+}
+
+func cpu_shiftz(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shz int, clampL float32, clampR float32) {
+	cpuShift(dst, src, Nx, Ny, Nz, [3]int{0, 0, shz}, clampL, clampR)
+}
+
+// region shift along direction, shared by cpu_shiftbytes, cpu_shiftbytesy and their 16-bit versions.
+func cpuShiftBytes[R cpuRegion](dst, src unsafe.Pointer, Nx, Ny, Nz int, sh [3]int, clamp R) {
+	N := Nx * Ny * Nz
+	S := &cpuStencil{Nx: Nx, Ny: Ny, Nz: Nz}
+	d, s := cpuRegions[R](dst, N), cpuRegions[R](src, N)
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			ix2, iy2 := ix-sh[X], iy-sh[Y]
+			newval := clamp
+			if ix2 >= 0 && ix2 < Nx && iy2 >= 0 && iy2 < Ny {
+				newval = s[S.idx(ix2, iy2, iz)]
+			}
+			d[S.idx(ix, iy, iz)] = newval
+		}
+	})
+}
+
+func cpu_shiftbytes(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clamp byte) {
+	cpuShiftBytes(dst, src, Nx, Ny, Nz, [3]int{shx, 0, 0}, clamp)
+}
+
+func cpu_shiftbytesy(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clamp byte) {
+	cpuShiftBytes(dst, src, Nx, Ny, Nz, [3]int{0, shy, 0}, clamp)
+}
+
+func cpu_shiftbytes16(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clamp uint16) {
+	cpuShiftBytes(dst, src, Nx, Ny, Nz, [3]int{shx, 0, 0}, clamp)
+}
+
+func cpu_shiftbytesy16(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clamp uint16) {
+	cpuShiftBytes(dst, src, Nx, Ny, Nz, [3]int{0, shy, 0}, clamp)
+}
+
+func cpu_kernmulC(fftM unsafe.Pointer, fftK unsafe.Pointer, Nx int, Ny int) {
+	M, K := cpuFloats(fftM, 2*Nx*Ny), cpuFloats(fftK, 2*Nx*Ny)
+	cpuParallel(Nx*Ny, func(_, start, stop int) {
+		for I := start; I < stop; I++ {
+			e := 2 * I
+			reM, imM := M[e], M[e+1]
+			reK, imK := K[e], K[e+1]
+			M[e] = reM*reK - imM*imK
+			M[e+1] = reM*imK + imM*reK
+		}
+	})
+}
+
+func cpu_kernmulRSymm2Dz(fftMz unsafe.Pointer, fftKzz unsafe.Pointer, Nx int, Ny int) {
+	Mz, Kzz := cpuFloats(fftMz, 2*Nx*Ny), cpuFloats(fftKzz, Nx*(Ny/2+1))
+	cpuParallel3D(Nx, Ny, 1, func(iy, _ int) {
+		ky := iy
+		if ky > Ny/2 {
+			ky = Ny - ky
+		}
+		for ix := 0; ix < Nx; ix++ {
+			e := 2 * (iy*Nx + ix)
+			kzz := Kzz[ky*Nx+ix]
+			Mz[e] *= kzz
+			Mz[e+1] *= kzz
+		}
+	})
+}
+
+func cpu_kernmulRSymm2Dxy(fftMx unsafe.Pointer, fftMy unsafe.Pointer, fftKxx unsafe.Pointer, fftKyy unsafe.Pointer, fftKxy unsafe.Pointer, Nx int, Ny int) {
+	Mx, My := cpuFloats(fftMx, 2*Nx*Ny), cpuFloats(fftMy, 2*Nx*Ny)
+	NK := Nx * (Ny/2 + 1)
+	Kxx, Kyy, Kxy := cpuFloats(fftKxx, NK), cpuFloats(fftKyy, NK), cpuFloats(fftKxy, NK)
+	cpuParallel3D(Nx, Ny, 1, func(iy, _ int) {
+		// symmetry factor
+		ky, fxy := iy, float32(1)
+		if ky > Ny/2 {
+			ky = Ny - ky
+			fxy = -fxy
+		}
+		for ix := 0; ix < Nx; ix++ {
+			e := 2 * (iy*Nx + ix)
+			reMx, imMx := Mx[e], Mx[e+1]
+			reMy, imMy := My[e], My[e+1]
+			I := ky*Nx + ix
+			kxx, kyy, kxy := Kxx[I], Kyy[I], fxy*Kxy[I]
+			Mx[e] = reMx*kxx + reMy*kxy
+			Mx[e+1] = imMx*kxx + imMy*kxy
+			My[e] = reMx*kxy + reMy*kyy
+			My[e+1] = imMx*kxy + imMy*kyy
+		}
+	})
+}
+
+func cpu_kernmulRSymm3D(fftMx unsafe.Pointer, fftMy unsafe.Pointer, fftMz unsafe.Pointer, fftKxx unsafe.Pointer, fftKyy unsafe.Pointer, fftKzz unsafe.Pointer, fftKyz unsafe.Pointer, fftKxz unsafe.Pointer, fftKxy unsafe.Pointer, Nx int, Ny int, Nz int) {
+	NM := 2 * Nx * Ny * Nz
+	Mx, My, Mz := cpuFloats(fftMx, NM), cpuFloats(fftMy, NM), cpuFloats(fftMz, NM)
+	NK := Nx * (Ny/2 + 1) * (Nz/2 + 1)
+	Kxx, Kyy, Kzz := cpuFloats(fftKxx, NK), cpuFloats(fftKyy, NK), cpuFloats(fftKzz, NK)
+	Kyz, Kxz, Kxy := cpuFloats(fftKyz, NK), cpuFloats(fftKxz, NK), cpuFloats(fftKxy, NK)
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		// use symmetry to fetch from redundant parts:
+		// mirror index into first quadrant and set signs.
+		ky, kz := iy, iz
+		signYZ, signXZ, signXY := float32(1), float32(1), float32(1)
+		if ky > Ny/2 {
+			ky = Ny - ky
+			signYZ = -signYZ
+			signXY = -signXY
+		}
+		if kz > Nz/2 {
+			kz = Nz - kz
+			signYZ = -signYZ
+			signXZ = -signXZ
+		}
+		for ix := 0; ix < Nx; ix++ {
+			e := 2 * ((iz*Ny+iy)*Nx + ix)
+			reMx, imMx := Mx[e], Mx[e+1]
+			reMy, imMy := My[e], My[e+1]
+			reMz, imMz := Mz[e], Mz[e+1]
+
+			I := (kz*(Ny/2+1)+ky)*Nx + ix // Ny/2+1: only half is stored
+			kxx, kyy, kzz := Kxx[I], Kyy[I], Kzz[I]
+			kyz := Kyz[I] * signYZ
+			kxz := Kxz[I] * signXZ
+			kxy := Kxy[I] * signXY
+
+			Mx[e] = reMx*kxx + reMy*kxy + reMz*kxz
+			Mx[e+1] = imMx*kxx + imMy*kxy + imMz*kxz
+			My[e] = reMx*kxy + reMy*kyy + reMz*kyz
+			My[e+1] = imMx*kxy + imMy*kyy + imMz*kyz
+			Mz[e] = reMx*kxz + reMy*kyz + reMz*kzz
+			Mz[e+1] = imMx*kxz + imMy*kyz + imMz*kzz
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package cuda
+
+// 3D FFTs for the CPU backend, with the same data layout as
+// cuFFT R2C/C2R plans in FFTW padding mode: the complex array is
+// Nx/2+1 (interleaved) complex numbers wide, Ny high and Nz deep.
+
+import (
+	"math/cmplx"
+
+	"github.com/mumax/3/fft"
+)
+
+type cpuFFT3D struct {
+	size   [3]int
+	nChunk int            // number of concurrent lines
+	plans  [][3]*fft.Plan // 1D plans along X, Y, Z, one set per chunk
+	line   [][]complex128 // line buffer per chunk
+	work   []complex128   // complex intermediate, kept in double precision
+}
+
+func newCPUFFT3D(Nx, Ny, Nz int) *cpuFFT3D {
+	p := &cpuFFT3D{size: [3]int{Nx, Ny, Nz}, nChunk: cpuNWorker}
+	p.plans = make([][3]*fft.Plan, p.nChunk)
+	p.line = make([][]complex128, p.nChunk)
+	for c := range p.plans {
+		p.plans[c] = [3]*fft.Plan{fft.NewPlan(Nx), fft.NewPlan(Ny), fft.NewPlan(Nz)}
+		p.line[c] = make([]complex128, max(Nx, Ny, Nz))
+	}
+	p.work = make([]complex128, (Nx/2+1)*Ny*Nz)
+	return p
+}
+
+// unnormalized forward real-to-complex transform,
+// len(src) = Nx*Ny*Nz, len(dst) >= 2*(Nx/2+1)*Ny*Nz.
+func (p *cpuFFT3D) execR2C(dst, src []float32) {
+	Nx, Ny, Nz := p.size[X], p.size[Y], p.size[Z]
+	Nc := Nx/2 + 1
+
+	// X: real rows to non-redundant half,
+	// two rows at a time as the real and imaginary part of one complex transform.
+	nRow := Ny * Nz
+	p.lines(divUp(nRow, 2), func(c, pair int) {
+		r1, r2 := 2*pair, 2*pair+1
+		buf := p.line[c][:Nx]
+		for i := range buf {
+			im := 0.
+			if r2 < nRow {
+				im = float64(src[r2*Nx+i])
+			}
+			buf[i] = complex(float64(src[r1*Nx+i]), im)
+		}
+		p.plans[c][X].Forward(buf)
+		for k := 0; k < Nc; k++ {
+			z, zc := buf[k], cmplx.Conj(buf[(Nx-k)%Nx])
+			p.work[r1*Nc+k] = 0.5 * (z + zc)
+			if r2 < nRow {
+				p.work[r2*Nc+k] = complex(0, -0.5) * (z - zc)
+			}
+		}
+	})
+
+	p.yz(false)
+
+	for i, v := range p.work {
+		dst[2*i] = float32(real(v))
+		dst[2*i+1] = float32(imag(v))
+	}
+}
+
+// unnormalized inverse complex-to-real transform,
+// len(src) >= 2*(Nx/2+1)*Ny*Nz, len(dst) = Nx*Ny*Nz.
+func (p *cpuFFT3D) execC2R(dst, src []float32) {
+	Nx, Ny, Nz := p.size[X], p.size[Y], p.size[Z]
+	Nc := Nx/2 + 1
+
+	for i := range p.work {
+		p.work[i] = complex(float64(src[2*i]), float64(src[2*i+1]))
+	}
+
+	p.yz(true)
+
+	// X: restore Hermitian symmetric rows, back to real,
+	// two rows at a time as the real and imaginary part of one complex transform.
+	nRow := Ny * Nz
+	p.lines(divUp(nRow, 2), func(c, pair int) {
+		r1, r2 := 2*pair, 2*pair+1
+		buf := p.line[c][:Nx]
+		for k := range buf {
+			k2, conj := k, false
+			if k >= Nc {
+				k2, conj = Nx-k, true
+			}
+			a, b := p.work[r1*Nc+k2], complex128(0)
+			if r2 < nRow {
+				b = p.work[r2*Nc+k2]
+			}
+			switch {
+			case conj:
+				a, b = cmplx.Conj(a), cmplx.Conj(b)
+			case k == 0 || 2*k == Nx:
+				// like cuFFT, ignore imaginary parts that can't be there
+				a, b = complex(real(a), 0), complex(real(b), 0)
+			}
+			buf[k] = a + complex(0, 1)*b
+		}
+		p.plans[c][X].Inverse(buf)
+		for i, v := range buf {
+			dst[r1*Nx+i] = float32(real(v))
+			if r2 < nRow {
+				dst[r2*Nx+i] = float32(imag(v))
+			}
+		}
+	})
+}
+
+// transform work along Y and Z.
+func (p *cpuFFT3D) yz(inverse bool) {
+	Nx, Ny, Nz := p.size[X], p.size[Y], p.size[Z]
+	Nc := Nx/2 + 1
+
+	exec := func(plan *fft.Plan, buf []complex128) {
+		if inverse {
+			plan.Inverse(buf)
+		} else {
+			plan.Forward(buf)
+		}
+	}
+
+	// Y: columns (kx, iz), stride Nc
+	if Ny > 1 {
+		p.lines(Nc*Nz, func(c, col int) {
+			kx, iz := col%Nc, col/Nc
+			buf := p.line[c][:Ny]
+			for iy := range buf {
+				buf[iy] = p.work[(iz*Ny+iy)*Nc+kx]
+			}
+			exec(p.plans[c][Y], buf)
+			for iy, v := range buf {
+				p.work[(iz*Ny+iy)*Nc+kx] = v
+			}
+		})
+	}
+
+	// Z: columns (kx, iy), stride Nc*Ny
+	if Nz > 1 {
+		p.lines(Nc*Ny, func(c, col int) {
+			buf := p.line[c][:Nz]
+			for iz := range buf {
+				buf[iz] = p.work[iz*Nc*Ny+col]
+			}
+			exec(p.plans[c][Z], buf)
+			for iz, v := range buf {
+				p.work[iz*Nc*Ny+col] = v
+			}
+		})
+	}
+}
+
+// run f(chunk, line) for all lines, concurrently.
+func (p *cpuFFT3D) lines(nLine int, f func(chunk, line int)) {
+	cpuParallelN(iMin(p.nChunk, nLine), nLine, func(c, start, stop int) {
+		for l := start; l < stop; l++ {
+			f(c, l)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package cuda
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// Compare the CPU backend's R2C transform with a naive DFT,
+// and check that C2R brings it back.
+func TestCPUFFT3D(t *testing.T) {
+	for _, size := range [][3]int{{8, 1, 1}, {6, 5, 1}, {4, 6, 3}, {10, 3, 2}} {
+		Nx, Ny, Nz := size[X], size[Y], size[Z]
+		Nc := Nx/2 + 1
+		N := Nx * Ny * Nz
+
+		in := make([]float32, N)
+		for i := range in {
+			in[i] = rand.Float32() - 0.5
+		}
+		out := make([]float32, 2*Nc*Ny*Nz)
+		p := newCPUFFT3D(Nx, Ny, Nz)
+		p.execR2C(out, in)
+
+		for kz := 0; kz < Nz; kz++ {
+			for ky := 0; ky < Ny; ky++ {
+				for kx := 0; kx < Nc; kx++ {
+					var want complex128
+					for iz := 0; iz < Nz; iz++ {
+						for iy := 0; iy < Ny; iy++ {
+							for ix := 0; ix < Nx; ix++ {
+								phi := -2 * math.Pi * (float64(kx*ix)/float64(Nx) + float64(ky*iy)/float64(Ny) + float64(kz*iz)/float64(Nz))
+								want += complex(float64(in[(iz*Ny+iy)*Nx+ix]), 0) * cmplx.Exp(complex(0, phi))
+							}
+						}
+					}
+					I := 2 * ((kz*Ny+ky)*Nc + kx)
+					have := complex(float64(out[I]), float64(out[I+1]))
+					if cmplx.Abs(have-want) > 1e-5 {
+						t.Fatal(size, "k=", kx, ky, kz, "have", have, "want", want)
+					}
+				}
+			}
+		}
+
+		back := make([]float32, N)
+		p.execC2R(back, out)
+		for i := range back {
+			if math.Abs(float64(back[i]/float32(N)-in[i])) > 1e-6 {
+				t.Fatal(size, "i=", i, "have", back[i]/float32(N), "want", in[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package cuda
+
+// Go twins of the element-wise CUDA kernels, used by the CPU backend.
+// See the corresponding .cu files.
+
+import (
+	"unsafe"
+)
+
+func cpu_madd2(dst unsafe.Pointer, src1 unsafe.Pointer, fac1 float32, src2 unsafe.Pointer, fac2 float32, N int) {
+	d, s1, s2 := cpuFloats(dst, N), cpuFloats(src1, N), cpuFloats(src2, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			d[i] = fac1*s1[i] + fac2*s2[i]
+		}
+	})
+}
+
+func cpu_madd3(dst unsafe.Pointer, src1 unsafe.Pointer, fac1 float32, src2 unsafe.Pointer, fac2 float32, src3 unsafe.Pointer, fac3 float32, N int) {
+	d, s1, s2, s3 := cpuFloats(dst, N), cpuFloats(src1, N), cpuFloats(src2, N), cpuFloats(src3, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			d[i] = (fac1 * s1[i]) + (fac2*s2[i] + fac3*s3[i])
+		}
+	})
+}
+
+func cpu_mul(dst unsafe.Pointer, a unsafe.Pointer, b unsafe.Pointer, N int) {
+	d, A, B := cpuFloats(dst, N), cpuFloats(a, N), cpuFloats(b, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			d[i] = A[i] * B[i]
+		}
+	})
+}
+
+func cpu_pointwise_div(dst unsafe.Pointer, a unsafe.Pointer, b unsafe.Pointer, N int) {
+	d, A, B := cpuFloats(dst, N), cpuFloats(a, N), cpuFloats(b, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			if B[i] != 0 {
+				d[i] = A[i] / B[i]
+			} else {
+				d[i] = 0
+			}
+		}
+	})
+}
+
+func cpu_normalize(vx unsafe.Pointer, vy unsafe.Pointer, vz unsafe.Pointer, vol unsafe.Pointer, N int) {
+	x, y, z, V := cpuFloats(vx, N), cpuFloats(vy, N), cpuFloats(vz, N), cpuFloats(vol, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			v := amul(V, 1, i)
+			load3(x, y, z, i).mul(v).normalized().store(x, y, z, i)
+		}
+	})
+}
+
+func cpu_lltorque2(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, hx unsafe.Pointer, hy unsafe.Pointer, hz unsafe.Pointer, alpha_ unsafe.Pointer, alpha_mul float32, N int) {
+	Tx, Ty, Tz := cpuFloats(tx, N), cpuFloats(ty, N), cpuFloats(tz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Hx, Hy, Hz := cpuFloats(hx, N), cpuFloats(hy, N), cpuFloats(hz, N)
+	Alpha := cpuFloats(alpha_, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			m := load3(Mx, My, Mz, i)
+			H := load3(Hx, Hy, Hz, i)
+			alpha := amul(Alpha, alpha_mul, i)
+			mxH := m.cross(H)
+			gilb := -1 / (1 + alpha*alpha)
+			mxH.add(m.cross(mxH).mul(alpha)).mul(gilb).store(Tx, Ty, Tz, i)
+		}
+	})
+}
+
+func cpu_llnoprecess(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, hx unsafe.Pointer, hy unsafe.Pointer, hz unsafe.Pointer, N int) {
+	Tx, Ty, Tz := cpuFloats(tx, N), cpuFloats(ty, N), cpuFloats(tz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Hx, Hy, Hz := cpuFloats(hx, N), cpuFloats(hy, N), cpuFloats(hz, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			m := load3(Mx, My, Mz, i)
+			H := load3(Hx, Hy, Hz, i)
+			m.cross(m.cross(H)).mul(-1).store(Tx, Ty, Tz, i)
+		}
+	})
+}
+
+func cpu_minimize(mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, m0x unsafe.Pointer, m0y unsafe.Pointer, m0z unsafe.Pointer, tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, dt float32, N int) {
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	M0x, M0y, M0z := cpuFloats(m0x, N), cpuFloats(m0y, N), cpuFloats(m0z, N)
+	Tx, Ty, Tz := cpuFloats(tx, N), cpuFloats(ty, N), cpuFloats(tz, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			m0 := load3(M0x, M0y, M0z, i)
+			t := load3(Tx, Ty, Tz, i)
+			t2 := dt * dt * t.dot(t)
+			result := m0.mul(4 - t2).add(t.mul(4 * dt))
+			divisor := 4 + t2
+			Mx[i] = result.x / divisor
+			My[i] = result.y / divisor
+			Mz[i] = result.z / divisor
+		}
+	})
+}
+
+func cpu_dotproduct(dst unsafe.Pointer, prefactor float32, ax unsafe.Pointer, ay unsafe.Pointer, az unsafe.Pointer, bx unsafe.Pointer, by unsafe.Pointer, bz unsafe.Pointer, N int) {
+	d := cpuFloats(dst, N)
+	Ax, Ay, Az := cpuFloats(ax, N), cpuFloats(ay, N), cpuFloats(az, N)
+	Bx, By, Bz := cpuFloats(bx, N), cpuFloats(by, N), cpuFloats(bz, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			d[i] += prefactor * load3(Ax, Ay, Az, i).dot(load3(Bx, By, Bz, i))
+		}
+	})
+}
+
+// cpu_regionaddv with 8- and 16-bit region indices
+var (
+	cpu_regionaddv   = cpuRegionAddV[byte]
+	cpu_regionaddv16 = cpuRegionAddV[uint16]
+)
+
+func cpuRegionAddV[R cpuRegion](dstx unsafe.Pointer, dsty unsafe.Pointer, dstz unsafe.Pointer, LUTx unsafe.Pointer, LUTy unsafe.Pointer, LUTz unsafe.Pointer, regions unsafe.Pointer, N int) {
+	dx, dy, dz := cpuFloats(dstx, N), cpuFloats(dsty, N), cpuFloats(dstz, N)
+	lx, ly, lz := cpuFloats(LUTx, cpuNRegion[R]()), cpuFloats(LUTy, cpuNRegion[R]()), cpuFloats(LUTz, cpuNRegion[R]())
+	reg := cpuRegions[R](regions, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			r := reg[i]
+			dx[i] += lx[r]
+			dy[i] += ly[r]
+			dz[i] += lz[r]
+		}
+	})
+}
+
+// cpu_regiondecode with 8- and 16-bit region indices
+var (
+	cpu_regiondecode   = cpuRegionDecode[byte]
+	cpu_regiondecode16 = cpuRegionDecode[uint16]
+)
+
+func cpuRegionDecode[R cpuRegion](dst unsafe.Pointer, LUT unsafe.Pointer, regions unsafe.Pointer, N int) {
+	d, lut, reg := cpuFloats(dst, N), cpuFloats(LUT, cpuNRegion[R]()), cpuRegions[R](regions, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			d[i] = lut[reg[i]]
+		}
+	})
+}
+
+// cpu_regionselect with 8- and 16-bit region indices
+var (
+	cpu_regionselect   = cpuRegionSelect[byte]
+	cpu_regionselect16 = cpuRegionSelect[uint16]
+)
+
+func cpuRegionSelect[R cpuRegion](dst unsafe.Pointer, src unsafe.Pointer, regions unsafe.Pointer, region R, N int) {
+	d, s, reg := cpuFloats(dst, N), cpuFloats(src, N), cpuRegions[R](regions, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			if reg[i] == region {
+				d[i] = s[i]
+			} else {
+				d[i] = 0
+			}
+		}
+	})
+}
+
+// cpu_zeromask with 8- and 16-bit region indices
+var (
+	cpu_zeromask   = cpuZeroMask[byte]
+	cpu_zeromask16 = cpuZeroMask[uint16]
+)
+
+func cpuZeroMask[R cpuRegion](dst unsafe.Pointer, maskLUT unsafe.Pointer, regions unsafe.Pointer, N int) {
+	d, lut, reg := cpuFloats(dst, N), cpuFloats(maskLUT, cpuNRegion[R]()), cpuRegions[R](regions, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			if lut[reg[i]] != 0 {
+				d[i] = 0
+			}
+		}
+	})
+}
+
+func cpu_adduniaxialanisotropy2(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, K1_ unsafe.Pointer, K1_mul float32, K2_ unsafe.Pointer, K2_mul float32, ux_ unsafe.Pointer, ux_mul float32, uy_ unsafe.Pointer, uy_mul float32, uz_ unsafe.Pointer, uz_mul float32, N int) {
+	bx, by, bz := cpuFloats(Bx, N), cpuFloats(By, N), cpuFloats(Bz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Ms, K1, K2 := cpuFloats(Ms_, N), cpuFloats(K1_, N), cpuFloats(K2_, N)
+	ux, uy, uz := cpuFloats(ux_, N), cpuFloats(uy_, N), cpuFloats(uz_, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			u := vmul(ux, uy, uz, ux_mul, uy_mul, uz_mul, i).normalized()
+			invMs := invMsat(Ms, Ms_mul, i)
+			k1 := amul(K1, K1_mul, i) * invMs
+			k2 := amul(K2, K2_mul, i) * invMs
+			m := load3(Mx, My, Mz, i)
+			mu := m.dot(u)
+			Ba := u.mul(2 * k1 * mu).add(u.mul(4 * k2 * pow3(mu)))
+			bx[i] += Ba.x
+			by[i] += Ba.y
+			bz[i] += Ba.z
+		}
+	})
+}
+
+func cpu_addcubicanisotropy2(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, k1_ unsafe.Pointer, k1_mul float32, k2_ unsafe.Pointer, k2_mul float32, k3_ unsafe.Pointer, k3_mul float32, c1x_ unsafe.Pointer, c1x_mul float32, c1y_ unsafe.Pointer, c1y_mul float32, c1z_ unsafe.Pointer, c1z_mul float32, c2x_ unsafe.Pointer, c2x_mul float32, c2y_ unsafe.Pointer, c2y_mul float32, c2z_ unsafe.Pointer, c2z_mul float32, N int) {
+	bx, by, bz := cpuFloats(Bx, N), cpuFloats(By, N), cpuFloats(Bz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Ms, K1, K2, K3 := cpuFloats(Ms_, N), cpuFloats(k1_, N), cpuFloats(k2_, N), cpuFloats(k3_, N)
+	c1x, c1y, c1z := cpuFloats(c1x_, N), cpuFloats(c1y_, N), cpuFloats(c1z_, N)
+	c2x, c2y, c2z := cpuFloats(c2x_, N), cpuFloats(c2y_, N), cpuFloats(c2z_, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			invMs := invMsat(Ms, Ms_mul, i)
+			k1 := amul(K1, k1_mul, i) * invMs
+			k2 := amul(K2, k2_mul, i) * invMs
+			k3 := amul(K3, k3_mul, i) * invMs
+			u1 := vmul(c1x, c1y, c1z, c1x_mul, c1y_mul, c1z_mul, i).normalized()
+			u2 := vmul(c2x, c2y, c2z, c2x_mul, c2y_mul, c2z_mul, i).normalized()
+			u3 := u1.cross(u2) // 3rd axis perpendicular to u1,u2
+			m := load3(Mx, My, Mz, i)
+			u1m := u1.dot(m)
+			u2m := u2.dot(m)
+			u3m := u3.dot(m)
+
+			B1 := u1.mul(u1m).mul(pow2(u2m) + pow2(u3m)).
+				add(u2.mul(u2m).mul(pow2(u1m) + pow2(u3m))).
+				add(u3.mul(u3m).mul(pow2(u1m) + pow2(u2m))).mul(-2 * k1)
+			B2 := u1.mul(u1m).mul(pow2(u2m) * pow2(u3m)).
+				add(u2.mul(u2m).mul(pow2(u1m) * pow2(u3m))).
+				add(u3.mul(u3m).mul(pow2(u1m) * pow2(u2m))).mul(2 * k2)
+			B3 := u1.mul(pow3(u1m)).mul(pow4(u2m) + pow4(u3m)).
+				add(u2.mul(pow3(u2m)).mul(pow4(u1m) + pow4(u3m))).
+				add(u3.mul(pow3(u3m)).mul(pow4(u1m) + pow4(u2m))).mul(4 * k3)
+			B := B1.sub(B2).sub(B3)
+			bx[i] += B.x
+			by[i] += B.y
+			bz[i] += B.z
+		}
+	})
+}
+
+func cpu_addslonczewskitorque2(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, jz_ unsafe.Pointer, jz_mul float32, px_ unsafe.Pointer, px_mul float32, py_ unsafe.Pointer, py_mul float32, pz_ unsafe.Pointer, pz_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, pol_ unsafe.Pointer, pol_mul float32, lambda_ unsafe.Pointer, lambda_mul float32, epsPrime_ unsafe.Pointer, epsPrime_mul float32, flt_ unsafe.Pointer, flt_mul float32, N int) {
+	Tx, Ty, Tz := cpuFloats(tx, N), cpuFloats(ty, N), cpuFloats(tz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Ms, Jz := cpuFloats(Ms_, N), cpuFloats(jz_, N)
+	px, py, pz := cpuFloats(px_, N), cpuFloats(py_, N), cpuFloats(pz_, N)
+	Alpha, Pol, Lambda := cpuFloats(alpha_, N), cpuFloats(pol_, N), cpuFloats(lambda_, N)
+	EpsPrime, Flt := cpuFloats(epsPrime_, N), cpuFloats(flt_, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			m := load3(Mx, My, Mz, i)
+			J := amul(Jz, jz_mul, i)
+			p := vmul(px, py, pz, px_mul, py_mul, pz_mul, i).normalized()
+			ms := amul(Ms, Ms_mul, i)
+			alpha := amul(Alpha, alpha_mul, i)
+			flt := amul(Flt, flt_mul, i)
+			pol := amul(Pol, pol_mul, i)
+			lambda := amul(Lambda, lambda_mul, i)
+			epsilonPrime := amul(EpsPrime, epsPrime_mul, i)
+
+			if J == 0 || ms == 0 {
+				continue
+			}
+
+			beta := float32((cpuHbar / cpuQe) * float64(J/(flt*ms)))
+			lambda2 := lambda * lambda
+			epsilon := pol * lambda2 / ((lambda2 + 1) + (lambda2-1)*p.dot(m))
+
+			A := beta * epsilon
+			B := beta * epsilonPrime
+
+			gilb := 1 / (1 + alpha*alpha)
+			mxpxmFac := gilb * (A - alpha*B)
+			pxmFac := gilb * (B - alpha*A)
+
+			pxm := p.cross(m)
+			mxpxm := m.cross(pxm)
+
+			Tx[i] += mxpxmFac*mxpxm.x + pxmFac*pxm.x
+			Ty[i] += mxpxmFac*mxpxm.y + pxmFac*pxm.y
+			Tz[i] += mxpxmFac*mxpxm.z + pxmFac*pxm.z
+		}
+	})
+}
+
+func cpu_settemperature2(B unsafe.Pointer, noise unsafe.Pointer, kB2_VgammaDt float32, Ms_ unsafe.Pointer, Ms_mul float32, temp_ unsafe.Pointer, temp_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, N int) {
+	b, n := cpuFloats(B, N), cpuFloats(noise, N)
+	Ms, Temp, Alpha := cpuFloats(Ms_, N), cpuFloats(temp_, N), cpuFloats(alpha_, N)
+	cpuParallel(N, func(_, start, stop int) {
+		for i := start; i < stop; i++ {
+			invMs := invMsat(Ms, Ms_mul, i)
+			temp := amul(Temp, temp_mul, i)
+			alpha := amul(Alpha, alpha_mul, i)
+			b[i] = n[i] * sqrtf(kB2_VgammaDt*alpha*temp*invMs)
+		}
+	})
+}
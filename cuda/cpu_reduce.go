@@ -0,0 +1,79 @@
+package cuda
+
+// Go twins of the reduce CUDA kernels, used by the CPU backend.
+// See reduce.h and the corresponding .cu files.
+
+import (
+	"unsafe"
+)
+
+// cpuReduce reduces load(i) over [0, n) with op, starting from initVal,
+// and combines the result into *dst with atomicOp.
+// Mirrors the reduce macro in reduce.h with the launch configuration reducecfg,
+// so that float32 round-off is the same as on the GPU (up to the order of atomic operations).
+func cpuReduce(dst unsafe.Pointer, initVal float32, n int, load func(i int) float32, op, atomicOp func(a, b float32) float32) {
+	const B = REDUCE_BLOCKSIZE
+	nBlock := reducecfg.Grid.X
+	stride := nBlock * B
+	partial := make([]float32, nBlock)
+	cpuParallelN(nBlock, nBlock, func(block, _, _ int) {
+		var sdata [B]float32
+		for tid := range sdata {
+			sdata[tid] = initVal
+		}
+		// thread tid of this block loads i = block*B + tid + k*stride
+		for base := block * B; base < n; base += stride {
+			for tid := 0; tid < B && base+tid < n; tid++ {
+				sdata[tid] = op(sdata[tid], load(base+tid))
+			}
+		}
+		for s := B / 2; s > 0; s >>= 1 {
+			for tid := 0; tid < s; tid++ {
+				sdata[tid] = op(sdata[tid], sdata[tid+s])
+			}
+		}
+		partial[block] = sdata[0]
+	})
+	d := (*float32)(dst)
+	for _, p := range partial {
+		*d = atomicOp(*d, p)
+	}
+}
+
+func sum(a, b float32) float32 { return a + b }
+
+// atomicFmaxabs in atomicf.h
+func fmaxabs(a, b float32) float32 { return fmaxf(a, fabs(b)) }
+
+func cpu_reducesum(src unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	s := cpuFloats(src, n)
+	cpuReduce(dst, initVal, n, func(i int) float32 { return s[i] }, sum, sum)
+}
+
+func cpu_reducedot(x1 unsafe.Pointer, x2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	a, b := cpuFloats(x1, n), cpuFloats(x2, n)
+	cpuReduce(dst, initVal, n, func(i int) float32 { return a[i] * b[i] }, sum, sum)
+}
+
+func cpu_reducemaxabs(src unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	s := cpuFloats(src, n)
+	cpuReduce(dst, initVal, n, func(i int) float32 { return fabs(s[i]) }, fmaxf, fmaxabs)
+}
+
+func cpu_reducemaxdiff(src1 unsafe.Pointer, src2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	a, b := cpuFloats(src1, n), cpuFloats(src2, n)
+	cpuReduce(dst, initVal, n, func(i int) float32 { return fabs(a[i] - b[i]) }, fmaxf, fmaxabs)
+}
+
+func cpu_reducemaxvecdiff2(x1 unsafe.Pointer, y1 unsafe.Pointer, z1 unsafe.Pointer, x2 unsafe.Pointer, y2 unsafe.Pointer, z2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	X1, Y1, Z1 := cpuFloats(x1, n), cpuFloats(y1, n), cpuFloats(z1, n)
+	X2, Y2, Z2 := cpuFloats(x2, n), cpuFloats(y2, n), cpuFloats(z2, n)
+	load := func(i int) float32 { return pow2(X1[i]-X2[i]) + pow2(Y1[i]-Y2[i]) + pow2(Z1[i]-Z2[i]) }
+	cpuReduce(dst, initVal, n, load, fmaxf, fmaxabs)
+}
+
+func cpu_reducemaxvecnorm2(x unsafe.Pointer, y unsafe.Pointer, z unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int) {
+	X, Y, Z := cpuFloats(x, n), cpuFloats(y, n), cpuFloats(z, n)
+	load := func(i int) float32 { return pow2(X[i]) + pow2(Y[i]) + pow2(Z[i]) }
+	cpuReduce(dst, initVal, n, load, fmaxf, fmaxabs)
+}
@@ -0,0 +1,493 @@
+package cuda
+
+// Go twins of the stencil CUDA kernels, used by the CPU backend.
+// See the corresponding .cu files.
+
+import (
+	"unsafe"
+)
+
+// cpu_addexchange with 8- and 16-bit region indices
+var (
+	cpu_addexchange   = cpuAddExchange[byte]
+	cpu_addexchange16 = cpuAddExchange[uint16]
+)
+
+func cpuAddExchange[R cpuRegion](Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	bx, by, bz := cpuFloats(Bx, N), cpuFloats(By, N), cpuFloats(Bz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	aLUT, reg := cpuFloats(aLUT2d, cpuNSymm[R]()), cpuRegions[R](regions, N)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			m0 := load3(Mx, My, Mz, I)
+			if m0.is0() {
+				continue
+			}
+			r0 := reg[I]
+			B := load3(bx, by, bz, I)
+
+			// add exchange with neighbor i_, replacing missing non-boundary neighbor
+			add := func(i_ int, w float32) {
+				m_ := load3(Mx, My, Mz, i_)
+				if m_.is0() {
+					m_ = m0
+				}
+				a__ := aLUT[symidx(r0, reg[i_])]
+				B = B.add(m_.sub(m0).mul(w * a__))
+			}
+			add(s.idx(s.lclampx(ix-1), iy, iz), wx)
+			add(s.idx(s.hclampx(ix+1), iy, iz), wx)
+			add(s.idx(ix, s.lclampy(iy-1), iz), wy)
+			add(s.idx(ix, s.hclampy(iy+1), iz), wy)
+			// only take vertical derivative for 3D sim
+			if Nz != 1 {
+				add(s.idx(ix, iy, s.lclampz(iz-1)), wz)
+				add(s.idx(ix, iy, s.hclampz(iz+1)), wz)
+			}
+			B.store(bx, by, bz, I)
+		}
+	})
+}
+
+// cpu_exchangedecode with 8- and 16-bit region indices
+var (
+	cpu_exchangedecode   = cpuExchangeDecode[byte]
+	cpu_exchangedecode16 = cpuExchangeDecode[uint16]
+)
+
+func cpuExchangeDecode[R cpuRegion](dst unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	d := cpuFloats(dst, N)
+	aLUT, reg := cpuFloats(aLUT2d, cpuNSymm[R]()), cpuRegions[R](regions, N)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			r0 := reg[I]
+			avg := aLUT[symidx(r0, reg[s.idx(s.lclampx(ix-1), iy, iz)])]
+			avg += aLUT[symidx(r0, reg[s.idx(s.hclampx(ix+1), iy, iz)])]
+			avg += aLUT[symidx(r0, reg[s.idx(ix, s.lclampy(iy-1), iz)])]
+			avg += aLUT[symidx(r0, reg[s.idx(ix, s.hclampy(iy+1), iz)])]
+			if Nz != 1 {
+				avg += aLUT[symidx(r0, reg[s.idx(ix, iy, s.lclampz(iz-1))])]
+				avg += aLUT[symidx(r0, reg[s.idx(ix, iy, s.hclampz(iz+1))])]
+			}
+			d[I] = avg
+		}
+	})
+}
+
+// cpu_setmaxangle with 8- and 16-bit region indices
+var (
+	cpu_setmaxangle   = cpuSetMaxAngle[byte]
+	cpu_setmaxangle16 = cpuSetMaxAngle[uint16]
+)
+
+func cpuSetMaxAngle[R cpuRegion](dst unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	d := cpuFloats(dst, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	aLUT, reg := cpuFloats(aLUT2d, cpuNSymm[R]()), cpuRegions[R](regions, N)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			m0 := load3(Mx, My, Mz, I)
+			if m0.is0() {
+				continue
+			}
+			r0 := reg[I]
+			var angle float32
+
+			neighbor := func(i_ int) {
+				m_ := load3(Mx, My, Mz, i_)
+				if m_.is0() {
+					m_ = m0
+				}
+				if aLUT[symidx(r0, reg[i_])] != 0 {
+					angle = fmaxf(angle, acosf(m_.dot(m0)))
+				}
+			}
+			neighbor(s.idx(s.lclampx(ix-1), iy, iz))
+			neighbor(s.idx(s.hclampx(ix+1), iy, iz))
+			neighbor(s.idx(ix, s.lclampy(iy-1), iz))
+			neighbor(s.idx(ix, s.hclampy(iy+1), iz))
+			if Nz != 1 {
+				neighbor(s.idx(ix, iy, s.lclampz(iz-1)))
+				neighbor(s.idx(ix, iy, s.hclampz(iz+1)))
+			}
+			d[I] = angle
+		}
+	})
+}
+
+// cpu_adddmi with 8- and 16-bit region indices
+var (
+	cpu_adddmi   = cpuAddDMI[byte]
+	cpu_adddmi16 = cpuAddDMI[uint16]
+)
+
+func cpuAddDMI[R cpuRegion](Hx unsafe.Pointer, Hy unsafe.Pointer, Hz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, dLUT2d unsafe.Pointer, regions unsafe.Pointer, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	hx, hy, hz := cpuFloats(Hx, N), cpuFloats(Hy, N), cpuFloats(Hz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	aLUT, dLUT, reg := cpuFloats(aLUT2d, cpuNSymm[R]()), cpuFloats(dLUT2d, cpuNSymm[R]()), cpuRegions[R](regions, N)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			h := load3(hx, hy, hz, I)
+			m0 := load3(Mx, My, Mz, I)
+			r0 := reg[I]
+			if m0.is0() {
+				continue
+			}
+
+			// x derivatives (along length)
+			{
+				var m1 float3 // left neighbor
+				i_ := s.idx(s.lclampx(ix-1), iy, iz)
+				if ix-1 >= 0 || s.pbcx() {
+					m1 = load3(Mx, My, Mz, i_)
+				}
+				A1 := aLUT[symidx(r0, reg[i_])]
+				D1 := dLUT[symidx(r0, reg[i_])]
+				if m1.is0() { // neighbor missing: extrapolate from BC's
+					m1.x = m0.x - (-cx * (0.5 * D1 / A1) * m0.z)
+					m1.y = m0.y
+					m1.z = m0.z + (-cx * (0.5 * D1 / A1) * m0.x)
+				}
+				h = h.add(m1.sub(m0).mul(2 * A1 / (cx * cx))) // exchange
+				h.x += (D1 / cx) * (-m1.z)
+				h.z -= (D1 / cx) * (-m1.x)
+			}
+			{
+				var m2 float3 // right neighbor
+				i_ := s.idx(s.hclampx(ix+1), iy, iz)
+				if ix+1 < Nx || s.pbcx() {
+					m2 = load3(Mx, My, Mz, i_)
+				}
+				A2 := aLUT[symidx(r0, reg[i_])]
+				D2 := dLUT[symidx(r0, reg[i_])]
+				if m2.is0() {
+					m2.x = m0.x - (cx * (0.5 * D2 / A2) * m0.z)
+					m2.y = m0.y
+					m2.z = m0.z + (cx * (0.5 * D2 / A2) * m0.x)
+				}
+				h = h.add(m2.sub(m0).mul(2 * A2 / (cx * cx)))
+				h.x += (D2 / cx) * (m2.z)
+				h.z -= (D2 / cx) * (m2.x)
+			}
+
+			// y derivatives (along height)
+			{
+				var m1 float3
+				i_ := s.idx(ix, s.lclampy(iy-1), iz)
+				if iy-1 >= 0 || s.pbcy() {
+					m1 = load3(Mx, My, Mz, i_)
+				}
+				A1 := aLUT[symidx(r0, reg[i_])]
+				D1 := dLUT[symidx(r0, reg[i_])]
+				if m1.is0() {
+					m1.x = m0.x
+					m1.y = m0.y - (-cy * (0.5 * D1 / A1) * m0.z)
+					m1.z = m0.z + (-cy * (0.5 * D1 / A1) * m0.y)
+				}
+				h = h.add(m1.sub(m0).mul(2 * A1 / (cy * cy)))
+				h.y += (D1 / cy) * (-m1.z)
+				h.z -= (D1 / cy) * (-m1.y)
+			}
+			{
+				var m2 float3
+				i_ := s.idx(ix, s.hclampy(iy+1), iz)
+				if iy+1 < Ny || s.pbcy() {
+					m2 = load3(Mx, My, Mz, i_)
+				}
+				A2 := aLUT[symidx(r0, reg[i_])]
+				D2 := dLUT[symidx(r0, reg[i_])]
+				if m2.is0() {
+					m2.x = m0.x
+					m2.y = m0.y - (cy * (0.5 * D2 / A2) * m0.z)
+					m2.z = m0.z + (cy * (0.5 * D2 / A2) * m0.y)
+				}
+				h = h.add(m2.sub(m0).mul(2 * A2 / (cy * cy)))
+				h.y += (D2 / cy) * (m2.z)
+				h.z -= (D2 / cy) * (m2.y)
+			}
+
+			// only take vertical derivative for 3D sim
+			if Nz != 1 {
+				// bottom neighbor
+				{
+					i_ := s.idx(ix, iy, s.lclampz(iz-1))
+					m1 := load3(Mx, My, Mz, i_)
+					if m1.is0() { // Neumann BC
+						m1 = m0
+					}
+					A1 := aLUT[symidx(r0, reg[i_])]
+					h = h.add(m1.sub(m0).mul(2 * A1 / (cz * cz))) // Exchange only
+				}
+				// top neighbor
+				{
+					i_ := s.idx(ix, iy, s.hclampz(iz+1))
+					m2 := load3(Mx, My, Mz, i_)
+					if m2.is0() {
+						m2 = m0
+					}
+					A2 := aLUT[symidx(r0, reg[i_])]
+					h = h.add(m2.sub(m0).mul(2 * A2 / (cz * cz)))
+				}
+			}
+
+			// write back, result is H + Hdmi + Hex
+			h.store(hx, hy, hz, I)
+		}
+	})
+}
+
+// cpu_adddmibulk with 8- and 16-bit region indices
+var (
+	cpu_adddmibulk   = cpuAddDMIBulk[byte]
+	cpu_adddmibulk16 = cpuAddDMIBulk[uint16]
+)
+
+func cpuAddDMIBulk[R cpuRegion](Hx unsafe.Pointer, Hy unsafe.Pointer, Hz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, DLUT2d unsafe.Pointer, regions unsafe.Pointer, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	hx, hy, hz := cpuFloats(Hx, N), cpuFloats(Hy, N), cpuFloats(Hz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	aLUT, dLUT, reg := cpuFloats(aLUT2d, cpuNSymm[R]()), cpuFloats(DLUT2d, cpuNSymm[R]()), cpuRegions[R](regions, N)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			h := load3(hx, hy, hz, I)
+			m0 := load3(Mx, My, Mz, I)
+			r0 := reg[I]
+			A := aLUT[symidx(r0, r0)]
+			D := dLUT[symidx(r0, r0)]
+			D_2A := D / (2 * A)
+			if m0.is0() {
+				continue
+			}
+
+			// x derivatives (along length)
+			{
+				var m1 float3 // left neighbor
+				i_ := s.idx(s.lclampx(ix-1), iy, iz)
+				if ix-1 >= 0 || s.pbcx() {
+					m1 = load3(Mx, My, Mz, i_)
+				}
+				if m1.is0() { // neighbor missing
+					m1.x = m0.x
+					m1.y = m0.y - (-cx * D_2A * m0.z)
+					m1.z = m0.z + (-cx * D_2A * m0.y)
+				}
+				h = h.add(m1.sub(m0).mul(2 * A / (cx * cx))) // exchange
+				h.y += (D / cx) * (-m1.z)
+				h.z -= (D / cx) * (-m1.y)
+			}
+			{
+				var m2 float3 // right neighbor
+				i_ := s.idx(s.hclampx(ix+1), iy, iz)
+				if ix+1 < Nx || s.pbcx() {
+					m2 = load3(Mx, My, Mz, i_)
+				}
+				if m2.is0() {
+					m2.x = m0.x
+					m2.y = m0.y - (+cx * D_2A * m0.z)
+					m2.z = m0.z + (+cx * D_2A * m0.y)
+				}
+				h = h.add(m2.sub(m0).mul(2 * A / (cx * cx)))
+				h.y += (D / cx) * (m2.z)
+				h.z -= (D / cx) * (m2.y)
+			}
+
+			// y derivatives (along height)
+			{
+				var m1 float3
+				i_ := s.idx(ix, s.lclampy(iy-1), iz)
+				if iy-1 >= 0 || s.pbcy() {
+					m1 = load3(Mx, My, Mz, i_)
+				}
+				if m1.is0() {
+					m1.x = m0.x + (-cy * D_2A * m0.z)
+					m1.y = m0.y
+					m1.z = m0.z - (-cy * D_2A * m0.x)
+				}
+				h = h.add(m1.sub(m0).mul(2 * A / (cy * cy)))
+				h.x -= (D / cy) * (-m1.z)
+				h.z += (D / cy) * (-m1.x)
+			}
+			{
+				var m2 float3
+				i_ := s.idx(ix, s.hclampy(iy+1), iz)
+				if iy+1 < Ny || s.pbcy() {
+					m2 = load3(Mx, My, Mz, i_)
+				}
+				if m2.is0() {
+					m2.x = m0.x + (+cy * D_2A * m0.z)
+					m2.y = m0.y
+					m2.z = m0.z - (+cy * D_2A * m0.x)
+				}
+				h = h.add(m2.sub(m0).mul(2 * A / (cy * cy)))
+				h.x -= (D / cy) * (m2.z)
+				h.z += (D / cy) * (m2.x)
+			}
+
+			// only take vertical derivative for 3D sim
+			if Nz != 1 {
+				// bottom neighbor
+				{
+					var m1 float3
+					i_ := s.idx(ix, iy, s.lclampz(iz-1))
+					if iz-1 >= 0 || s.pbcz() {
+						m1 = load3(Mx, My, Mz, i_)
+					}
+					if m1.is0() {
+						m1.x = m0.x - (-cz * D_2A * m0.y)
+						m1.y = m0.y + (-cz * D_2A * m0.x)
+						m1.z = m0.z
+					}
+					h = h.add(m1.sub(m0).mul(2 * A / (cz * cz)))
+					h.x += (D / cz) * (-m1.y)
+					h.y -= (D / cz) * (-m1.x)
+				}
+				// top neighbor
+				{
+					var m2 float3
+					i_ := s.idx(ix, iy, s.hclampz(iz+1))
+					if iz+1 < Nz || s.pbcz() {
+						m2 = load3(Mx, My, Mz, i_)
+					}
+					if m2.is0() {
+						m2.x = m0.x - (+cz * D_2A * m0.y)
+						m2.y = m0.y + (+cz * D_2A * m0.x)
+						m2.z = m0.z
+					}
+					h = h.add(m2.sub(m0).mul(2 * A / (cz * cz)))
+					h.x += (D / cz) * (m2.y)
+					h.y -= (D / cz) * (m2.x)
+				}
+			}
+
+			// write back, result is H + Hdmi + Hex
+			h.store(hx, hy, hz, I)
+		}
+	})
+}
+
+func cpu_settopologicalcharge(s_ unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, icxcy float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	dst := cpuFloats(s_, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+
+	// derivative from neighbors at -2, -1, +1, +2 (zero when missing), see topologicalcharge.cu
+	deriv := func(m0, m_m2, m_m1, m_p1, m_p2 float3) float3 {
+		switch {
+		case m_p1.is0() && m_m1.is0():
+			return float3{} // --1-- zero
+		case (m_m2.is0() || m_p2.is0()) && !m_p1.is0() && !m_m1.is0():
+			return m_p1.sub(m_m1).mul(0.5) // -111-, 1111-, -1111 central difference,  ε ~ h^2
+		case m_p1.is0() && m_m2.is0():
+			return m0.sub(m_m1) // -11-- backward difference, ε ~ h^1
+		case m_m1.is0() && m_p2.is0():
+			return m_p1.sub(m0) // --11- forward difference,  ε ~ h^1
+		case !m_m2.is0() && m_p1.is0():
+			return m_m2.mul(0.5).sub(m_m1.mul(2)).add(m0.mul(1.5)) // 111-- backward difference, ε ~ h^2
+		case !m_p2.is0() && m_m1.is0():
+			return m_p2.mul(-0.5).add(m_p1.mul(2)).sub(m0.mul(1.5)) // --111 forward difference,  ε ~ h^2
+		default:
+			return m_p1.sub(m_m1).mul(2.0 / 3.0).add(m_m2.sub(m_p2).mul(1.0 / 12.0)) // 11111 central difference,  ε ~ h^4
+		}
+	}
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			I := s.idx(ix, iy, iz)
+			m0 := load3(Mx, My, Mz, I)
+			if m0.is0() {
+				dst[I] = 0
+				continue
+			}
+
+			// load neighbor m if inside grid, keep 0 otherwise
+			load := func(inside bool, i_ int) float3 {
+				if inside {
+					return load3(Mx, My, Mz, i_)
+				}
+				return float3{}
+			}
+
+			dmdx := deriv(m0,
+				load(ix-2 >= 0 || s.pbcx(), s.idx(s.lclampx(ix-2), iy, iz)),
+				load(ix-1 >= 0 || s.pbcx(), s.idx(s.lclampx(ix-1), iy, iz)),
+				load(ix+1 < Nx || s.pbcx(), s.idx(s.hclampx(ix+1), iy, iz)),
+				load(ix+2 < Nx || s.pbcx(), s.idx(s.hclampx(ix+2), iy, iz)))
+
+			dmdy := deriv(m0,
+				load(iy-2 >= 0 || s.pbcy(), s.idx(ix, s.lclampy(iy-2), iz)),
+				load(iy-1 >= 0 || s.pbcy(), s.idx(ix, s.lclampy(iy-1), iz)),
+				load(iy+1 < Ny || s.pbcy(), s.idx(ix, s.hclampy(iy+1), iz)),
+				load(iy+2 < Ny || s.pbcy(), s.idx(ix, s.hclampy(iy+2), iz)))
+
+			dst[I] = icxcy * m0.dot(dmdx.cross(dmdy))
+		}
+	})
+}
+
+func cpu_addzhanglitorque2(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, jx_ unsafe.Pointer, jx_mul float32, jy_ unsafe.Pointer, jy_mul float32, jz_ unsafe.Pointer, jz_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, xi_ unsafe.Pointer, xi_mul float32, pol_ unsafe.Pointer, pol_mul float32, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte) {
+	N := Nx * Ny * Nz
+	s := &cpuStencil{Nx, Ny, Nz, PBC}
+	Tx, Ty, Tz := cpuFloats(tx, N), cpuFloats(ty, N), cpuFloats(tz, N)
+	Mx, My, Mz := cpuFloats(mx, N), cpuFloats(my, N), cpuFloats(mz, N)
+	Ms := cpuFloats(Ms_, N)
+	jx, jy, jz := cpuFloats(jx_, N), cpuFloats(jy_, N), cpuFloats(jz_, N)
+	Alpha, Xi, Pol := cpuFloats(alpha_, N), cpuFloats(xi_, N), cpuFloats(pol_, N)
+
+	const prefactor = cpuMuB / (2 * cpuQe * cpuGamma0)
+
+	cpuParallel3D(Nx, Ny, Nz, func(iy, iz int) {
+		for ix := 0; ix < Nx; ix++ {
+			i := s.idx(ix, iy, iz)
+			alpha := amul(Alpha, alpha_mul, i)
+			xi := amul(Xi, xi_mul, i)
+			pol := amul(Pol, pol_mul, i)
+			invMs := invMsat(Ms, Ms_mul, i)
+			b := float32(float64(invMs) * prefactor / float64(1+xi*xi))
+			J := vmul(jx, jy, jz, jx_mul, jy_mul, jz_mul, i).mul(pol)
+
+			// spatial derivatives without dividing by cell size
+			delta := func(i1, i2 int) float3 { return load3(Mx, My, Mz, i1).sub(load3(Mx, My, Mz, i2)) }
+
+			var hspin float3 // (u·∇)m
+			if J.x != 0 {
+				hspin = hspin.add(delta(s.idx(s.hclampx(ix+1), iy, iz), s.idx(s.lclampx(ix-1), iy, iz)).mul((b / cx) * J.x))
+			}
+			if J.y != 0 {
+				hspin = hspin.add(delta(s.idx(ix, s.hclampy(iy+1), iz), s.idx(ix, s.lclampy(iy-1), iz)).mul((b / cy) * J.y))
+			}
+			if J.z != 0 {
+				hspin = hspin.add(delta(s.idx(ix, iy, s.hclampz(iz+1)), s.idx(ix, iy, s.lclampz(iz-1))).mul((b / cz) * J.z))
+			}
+
+			m := load3(Mx, My, Mz, i)
+			torque := m.cross(m.cross(hspin)).mul(1 + xi*alpha).
+				add(m.cross(hspin).mul(xi - alpha)).
+				mul(-1 / (1 + alpha*alpha))
+
+			// write back, adding to torque
+			Tx[i] += torque.x
+			Ty[i] += torque.y
+			Tz[i] += torque.z
+		}
+	})
+}
@@ -52,6 +52,11 @@ func init() {
 
 // Wrapper for crop CUDA kernel, asynchronous.
 func k_crop_async(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, Offx int, Offy int, Offz int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_crop(dst, Dx, Dy, Dz, src, Sx, Sy, Sz, Offx, Offy, Offz)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("crop")
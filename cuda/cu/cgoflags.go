@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 package cu
 
 // This file provides CGO flags to find CUDA libraries and headers.
@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 package cu
 
 // This file implements execution of CUDA kernels
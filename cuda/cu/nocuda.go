@@ -0,0 +1,85 @@
+//go:build nocuda
+// +build nocuda
+
+package cu
+
+// This file replaces the CUDA driver bindings when building without CUDA
+// (go build -tags nocuda). Only the API used by mumax3 is provided,
+// and any call that would need a GPU panics.
+// In such builds mumax3 runs on the pure-Go CPU backend (see package cuda).
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+type Result int
+
+func (err Result) String() string {
+	return "CUresult " + fmt.Sprint(int(err))
+}
+
+const (
+	SUCCESS                 Result = 0
+	ERROR_OUT_OF_MEMORY     Result = 2
+	ERROR_NO_DEVICE         Result = 100
+	ERROR_NO_BINARY_FOR_GPU Result = 209
+	ERROR_UNKNOWN           Result = 999
+)
+
+// Type size in bytes
+const (
+	SIZEOF_FLOAT32    = 4
+	SIZEOF_FLOAT64    = 8
+	SIZEOF_COMPLEX64  = 8
+	SIZEOF_COMPLEX128 = 16
+)
+
+const (
+	CTX_SCHED_AUTO  = 0
+	CTX_SCHED_SPIN  = 1
+	CTX_SCHED_YIELD = 2
+)
+
+type (
+	Context   uintptr
+	Device    int
+	DevicePtr uintptr
+	Function  uintptr
+	Module    uintptr
+	Stream    uintptr
+)
+
+// nocuda panics with ERROR_NO_DEVICE, like a driver without GPUs would.
+func nocuda() {
+	panic(ERROR_NO_DEVICE)
+}
+
+func Init(flags int)                              { nocuda() }
+func Version() int                                { return 0 }
+func DeviceGetCount() int                         { return 0 }
+func CtxCreate(flags uint, dev Device) Context    { nocuda(); return 0 }
+func (ctx Context) SetCurrent()                   { nocuda() }
+func (dev Device) ComputeCapability() (int, int)  { nocuda(); return 0, 0 }
+func (dev Device) Name() string                   { nocuda(); return "" }
+func (dev Device) TotalMem() int64                { nocuda(); return 0 }
+func (stream Stream) Synchronize()                { nocuda() }
+func ModuleLoadData(image string) Module          { nocuda(); return 0 }
+func (m Module) GetFunction(name string) Function { nocuda(); return 0 }
+
+func LaunchKernel(f Function, gridDimX, gridDimY, gridDimZ int, blockDimX, blockDimY, blockDimZ int, sharedMemBytes int, stream Stream, kernelParams []unsafe.Pointer) {
+	nocuda()
+}
+
+func MemAlloc(bytes int64) DevicePtr                                           { nocuda(); return 0 }
+func MemFree(p DevicePtr)                                                      { nocuda() }
+func (ptr DevicePtr) Free()                                                    { nocuda() }
+func MemAllocHost(bytes int64) unsafe.Pointer                                  { nocuda(); return nil }
+func MemFreeHost(ptr unsafe.Pointer)                                           { nocuda() }
+func MemGetInfo() (free, total int64)                                          { nocuda(); return 0, 0 }
+func MemcpyAsync(dst, src DevicePtr, bytes int64, stream Stream)               { nocuda() }
+func MemcpyHtoD(dst DevicePtr, src unsafe.Pointer, bytes int64)                { nocuda() }
+func MemcpyDtoH(dst unsafe.Pointer, src DevicePtr, bytes int64)                { nocuda() }
+func MemsetD32(deviceptr DevicePtr, value uint32, N int64)                     { nocuda() }
+func MemsetD32Async(deviceptr DevicePtr, value uint32, N int64, stream Stream) { nocuda() }
+func MemsetD8(deviceptr DevicePtr, value uint8, N int64)                       { nocuda() }
@@ -84,6 +84,11 @@ func init() {
 
 // Wrapper for addcubicanisotropy2 CUDA kernel, asynchronous.
 func k_addcubicanisotropy2_async(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, k1_ unsafe.Pointer, k1_mul float32, k2_ unsafe.Pointer, k2_mul float32, k3_ unsafe.Pointer, k3_mul float32, c1x_ unsafe.Pointer, c1x_mul float32, c1y_ unsafe.Pointer, c1y_mul float32, c1z_ unsafe.Pointer, c1z_mul float32, c2x_ unsafe.Pointer, c2x_mul float32, c2y_ unsafe.Pointer, c2y_mul float32, c2z_ unsafe.Pointer, c2z_mul float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_addcubicanisotropy2(Bx, By, Bz, mx, my, mz, Ms_, Ms_mul, k1_, k1_mul, k2_, k2_mul, k3_, k3_mul, c1x_, c1x_mul, c1y_, c1y_mul, c1z_, c1z_mul, c2x_, c2x_mul, c2y_, c2y_mul, c2z_, c2z_mul, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("addcubicanisotropy2")
@@ -93,7 +93,7 @@ func typemap(ctype string) string {
 	panic(fmt.Errorf("unsupported cuda type: %v", ctype))
 }
 
-var tm = map[string]string{"float*": "unsafe.Pointer", "float": "float32", "int": "int", "uint8_t*": "unsafe.Pointer", "uint8_t": "byte"}
+var tm = map[string]string{"float*": "unsafe.Pointer", "float": "float32", "int": "int", "uint8_t*": "unsafe.Pointer", "uint8_t": "byte", "uint16_t*": "unsafe.Pointer", "uint16_t": "uint16"}
 
 // template data
 type Kernel struct {
@@ -177,6 +177,11 @@ func init(){
 
 // Wrapper for {{.Name}} CUDA kernel, asynchronous.
 func k_{{.Name}}_async ( {{range $i, $t := .ArgT}}{{index $.ArgN $i}} {{$t}}, {{end}} cfg *config) {
+	if CPU{ // pure-Go backend, see cpu_*.go
+		cpu_{{.Name}}( {{range $i, $t := .ArgN}}{{if $i}}, {{end}}{{.}}{{end}} )
+		return
+	}
+
 	if Synchronous{ // debug
 		Sync()
 		timer.Start("{{.Name}}")
@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 package cufft
 
 // This file provides CGO flags to find CUDA libraries and headers.
@@ -0,0 +1,36 @@
+//go:build nocuda
+// +build nocuda
+
+package cufft
+
+// This file replaces the cuFFT bindings when building without CUDA
+// (go build -tags nocuda). Only the API used by mumax3 is provided,
+// and every call panics.
+
+import (
+	"github.com/mumax/3/cuda/cu"
+)
+
+type (
+	Handle            uintptr
+	Type              int
+	CompatibilityMode int
+)
+
+const (
+	R2C Type = 0x2a
+	C2R Type = 0x2c
+)
+
+const COMPATIBILITY_FFTW_PADDING CompatibilityMode = 0x01
+
+func nocuda() {
+	panic(cu.ERROR_NO_DEVICE)
+}
+
+func Plan3d(nx, ny, nz int, typ Type) Handle                    { nocuda(); return 0 }
+func (plan Handle) ExecR2C(idata, odata cu.DevicePtr)           { nocuda() }
+func (plan Handle) ExecC2R(idata, odata cu.DevicePtr)           { nocuda() }
+func (plan *Handle) Destroy()                                   { nocuda() }
+func (plan Handle) SetStream(stream cu.Stream)                  { nocuda() }
+func (plan Handle) SetCompatibilityMode(mode CompatibilityMode) { nocuda() }
@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 // Copyright 2011 Arne Vansteenkiste (barnex@gmail.com).  All rights reserved.
 // Use of this source code is governed by a freeBSD
 // license that can be found in the LICENSE.txt file.
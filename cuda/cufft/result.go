@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 package cufft
 
 //#include <cufft.h>
@@ -1,3 +1,6 @@
+//go:build !nocuda
+// +build !nocuda
+
 package curand
 
 //#include <curand.h>
@@ -49,4 +52,11 @@ func (g Generator) SetSeed(seed int64) {
 	}
 }
 
+func (g Generator) SetOffset(offset int64) {
+	err := Status(C.curandSetGeneratorOffset(C.curandGenerator_t(unsafe.Pointer(uintptr(g))), _Ctype_ulonglong(offset)))
+	if err != SUCCESS {
+		panic(err)
+	}
+}
+
 // Documentation was taken from the curand headers.
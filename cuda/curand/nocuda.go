@@ -0,0 +1,28 @@
+//go:build nocuda
+// +build nocuda
+
+package curand
+
+// This file replaces the cuRAND bindings when building without CUDA
+// (go build -tags nocuda). Only the API used by mumax3 is provided,
+// and every call panics.
+
+import (
+	"github.com/mumax/3/cuda/cu"
+)
+
+type (
+	Generator uintptr
+	RngType   int
+)
+
+const PSEUDO_DEFAULT RngType = 100
+
+func nocuda() {
+	panic(cu.ERROR_NO_DEVICE)
+}
+
+func CreateGenerator(rngType RngType) Generator                                  { nocuda(); return 0 }
+func (g Generator) GenerateNormal(output uintptr, n int64, mean, stddev float32) { nocuda() }
+func (g Generator) SetSeed(seed int64)                                           { nocuda() }
+func (g Generator) SetOffset(offset int64)                                       { nocuda() }
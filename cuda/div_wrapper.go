@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for pointwise_div CUDA kernel, asynchronous.
 func k_pointwise_div_async(dst unsafe.Pointer, a unsafe.Pointer, b unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_pointwise_div(dst, a, b, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("pointwise_div")
@@ -16,8 +16,15 @@ func AddDMI(Beff *data.Slice, m *data.Slice, Aex_red, Dex_red SymmLUT, regions *
 	util.Argument(m.Size() == N)
 	cfg := make3DConf(N)
 
-	k_adddmi_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
-		m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
-		unsafe.Pointer(Aex_red), unsafe.Pointer(Dex_red), regions.Ptr,
-		float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	if regions.Wide {
+		k_adddmi16_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), unsafe.Pointer(Dex_red), regions.Ptr,
+			float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	} else {
+		k_adddmi_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), unsafe.Pointer(Dex_red), regions.Ptr,
+			float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	}
 }
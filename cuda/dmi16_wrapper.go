@@ -0,0 +1,125 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for adddmi16 kernel
+var adddmi16_code cu.Function
+
+// Stores the arguments for adddmi16 kernel invocation
+type adddmi16_args_t struct {
+	arg_Hx      unsafe.Pointer
+	arg_Hy      unsafe.Pointer
+	arg_Hz      unsafe.Pointer
+	arg_mx      unsafe.Pointer
+	arg_my      unsafe.Pointer
+	arg_mz      unsafe.Pointer
+	arg_aLUT2d  unsafe.Pointer
+	arg_dLUT2d  unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_cx      float32
+	arg_cy      float32
+	arg_cz      float32
+	arg_Nx      int
+	arg_Ny      int
+	arg_Nz      int
+	arg_PBC     byte
+	argptr      [16]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for adddmi16 kernel invocation
+var adddmi16_args adddmi16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	adddmi16_args.argptr[0] = unsafe.Pointer(&adddmi16_args.arg_Hx)
+	adddmi16_args.argptr[1] = unsafe.Pointer(&adddmi16_args.arg_Hy)
+	adddmi16_args.argptr[2] = unsafe.Pointer(&adddmi16_args.arg_Hz)
+	adddmi16_args.argptr[3] = unsafe.Pointer(&adddmi16_args.arg_mx)
+	adddmi16_args.argptr[4] = unsafe.Pointer(&adddmi16_args.arg_my)
+	adddmi16_args.argptr[5] = unsafe.Pointer(&adddmi16_args.arg_mz)
+	adddmi16_args.argptr[6] = unsafe.Pointer(&adddmi16_args.arg_aLUT2d)
+	adddmi16_args.argptr[7] = unsafe.Pointer(&adddmi16_args.arg_dLUT2d)
+	adddmi16_args.argptr[8] = unsafe.Pointer(&adddmi16_args.arg_regions)
+	adddmi16_args.argptr[9] = unsafe.Pointer(&adddmi16_args.arg_cx)
+	adddmi16_args.argptr[10] = unsafe.Pointer(&adddmi16_args.arg_cy)
+	adddmi16_args.argptr[11] = unsafe.Pointer(&adddmi16_args.arg_cz)
+	adddmi16_args.argptr[12] = unsafe.Pointer(&adddmi16_args.arg_Nx)
+	adddmi16_args.argptr[13] = unsafe.Pointer(&adddmi16_args.arg_Ny)
+	adddmi16_args.argptr[14] = unsafe.Pointer(&adddmi16_args.arg_Nz)
+	adddmi16_args.argptr[15] = unsafe.Pointer(&adddmi16_args.arg_PBC)
+}
+
+// Wrapper for adddmi16 CUDA kernel, asynchronous.
+func k_adddmi16_async(Hx unsafe.Pointer, Hy unsafe.Pointer, Hz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, dLUT2d unsafe.Pointer, regions unsafe.Pointer, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_adddmi16(Hx, Hy, Hz, mx, my, mz, aLUT2d, dLUT2d, regions, cx, cy, cz, Nx, Ny, Nz, PBC)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("adddmi16")
+	}
+
+	adddmi16_args.Lock()
+	defer adddmi16_args.Unlock()
+
+	if adddmi16_code == 0 {
+		adddmi16_code = fatbinLoad(adddmi16_map, "adddmi16")
+	}
+
+	adddmi16_args.arg_Hx = Hx
+	adddmi16_args.arg_Hy = Hy
+	adddmi16_args.arg_Hz = Hz
+	adddmi16_args.arg_mx = mx
+	adddmi16_args.arg_my = my
+	adddmi16_args.arg_mz = mz
+	adddmi16_args.arg_aLUT2d = aLUT2d
+	adddmi16_args.arg_dLUT2d = dLUT2d
+	adddmi16_args.arg_regions = regions
+	adddmi16_args.arg_cx = cx
+	adddmi16_args.arg_cy = cy
+	adddmi16_args.arg_cz = cz
+	adddmi16_args.arg_Nx = Nx
+	adddmi16_args.arg_Ny = Ny
+	adddmi16_args.arg_Nz = Nz
+	adddmi16_args.arg_PBC = PBC
+
+	args := adddmi16_args.argptr[:]
+	cu.LaunchKernel(adddmi16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("adddmi16")
+	}
+}
+
+// maps compute capability on PTX code for adddmi16 kernel.
+var adddmi16_map = map[int]string{0: "",
+	20: adddmi16_ptx_20,
+	30: adddmi16_ptx_30,
+	35: adddmi16_ptx_35,
+	50: adddmi16_ptx_50,
+	52: adddmi16_ptx_52,
+	53: adddmi16_ptx_53}
+
+// adddmi16 PTX code for various compute capabilities.
+const (
+	adddmi16_ptx_20 = ``
+	adddmi16_ptx_30 = ``
+	adddmi16_ptx_35 = ``
+	adddmi16_ptx_50 = ``
+	adddmi16_ptx_52 = ``
+	adddmi16_ptx_53 = ``
+)
@@ -15,8 +15,15 @@ func AddDMIBulk(Beff *data.Slice, m *data.Slice, Aex_red, D_red SymmLUT, regions
 	util.Argument(m.Size() == N)
 	cfg := make3DConf(N)
 
-	k_adddmibulk_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
-		m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
-		unsafe.Pointer(Aex_red), unsafe.Pointer(D_red), regions.Ptr,
-		float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	if regions.Wide {
+		k_adddmibulk16_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), unsafe.Pointer(D_red), regions.Ptr,
+			float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	} else {
+		k_adddmibulk_async(Beff.DevPtr(X), Beff.DevPtr(Y), Beff.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), unsafe.Pointer(D_red), regions.Ptr,
+			float32(cellsize[X]*1e9), float32(cellsize[Y]*1e9), float32(cellsize[Z]*1e9), N[X], N[Y], N[Z], mesh.PBC_code(), cfg)
+	}
 }
@@ -0,0 +1,125 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for adddmibulk16 kernel
+var adddmibulk16_code cu.Function
+
+// Stores the arguments for adddmibulk16 kernel invocation
+type adddmibulk16_args_t struct {
+	arg_Hx      unsafe.Pointer
+	arg_Hy      unsafe.Pointer
+	arg_Hz      unsafe.Pointer
+	arg_mx      unsafe.Pointer
+	arg_my      unsafe.Pointer
+	arg_mz      unsafe.Pointer
+	arg_aLUT2d  unsafe.Pointer
+	arg_DLUT2d  unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_cx      float32
+	arg_cy      float32
+	arg_cz      float32
+	arg_Nx      int
+	arg_Ny      int
+	arg_Nz      int
+	arg_PBC     byte
+	argptr      [16]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for adddmibulk16 kernel invocation
+var adddmibulk16_args adddmibulk16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	adddmibulk16_args.argptr[0] = unsafe.Pointer(&adddmibulk16_args.arg_Hx)
+	adddmibulk16_args.argptr[1] = unsafe.Pointer(&adddmibulk16_args.arg_Hy)
+	adddmibulk16_args.argptr[2] = unsafe.Pointer(&adddmibulk16_args.arg_Hz)
+	adddmibulk16_args.argptr[3] = unsafe.Pointer(&adddmibulk16_args.arg_mx)
+	adddmibulk16_args.argptr[4] = unsafe.Pointer(&adddmibulk16_args.arg_my)
+	adddmibulk16_args.argptr[5] = unsafe.Pointer(&adddmibulk16_args.arg_mz)
+	adddmibulk16_args.argptr[6] = unsafe.Pointer(&adddmibulk16_args.arg_aLUT2d)
+	adddmibulk16_args.argptr[7] = unsafe.Pointer(&adddmibulk16_args.arg_DLUT2d)
+	adddmibulk16_args.argptr[8] = unsafe.Pointer(&adddmibulk16_args.arg_regions)
+	adddmibulk16_args.argptr[9] = unsafe.Pointer(&adddmibulk16_args.arg_cx)
+	adddmibulk16_args.argptr[10] = unsafe.Pointer(&adddmibulk16_args.arg_cy)
+	adddmibulk16_args.argptr[11] = unsafe.Pointer(&adddmibulk16_args.arg_cz)
+	adddmibulk16_args.argptr[12] = unsafe.Pointer(&adddmibulk16_args.arg_Nx)
+	adddmibulk16_args.argptr[13] = unsafe.Pointer(&adddmibulk16_args.arg_Ny)
+	adddmibulk16_args.argptr[14] = unsafe.Pointer(&adddmibulk16_args.arg_Nz)
+	adddmibulk16_args.argptr[15] = unsafe.Pointer(&adddmibulk16_args.arg_PBC)
+}
+
+// Wrapper for adddmibulk16 CUDA kernel, asynchronous.
+func k_adddmibulk16_async(Hx unsafe.Pointer, Hy unsafe.Pointer, Hz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, DLUT2d unsafe.Pointer, regions unsafe.Pointer, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_adddmibulk16(Hx, Hy, Hz, mx, my, mz, aLUT2d, DLUT2d, regions, cx, cy, cz, Nx, Ny, Nz, PBC)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("adddmibulk16")
+	}
+
+	adddmibulk16_args.Lock()
+	defer adddmibulk16_args.Unlock()
+
+	if adddmibulk16_code == 0 {
+		adddmibulk16_code = fatbinLoad(adddmibulk16_map, "adddmibulk16")
+	}
+
+	adddmibulk16_args.arg_Hx = Hx
+	adddmibulk16_args.arg_Hy = Hy
+	adddmibulk16_args.arg_Hz = Hz
+	adddmibulk16_args.arg_mx = mx
+	adddmibulk16_args.arg_my = my
+	adddmibulk16_args.arg_mz = mz
+	adddmibulk16_args.arg_aLUT2d = aLUT2d
+	adddmibulk16_args.arg_DLUT2d = DLUT2d
+	adddmibulk16_args.arg_regions = regions
+	adddmibulk16_args.arg_cx = cx
+	adddmibulk16_args.arg_cy = cy
+	adddmibulk16_args.arg_cz = cz
+	adddmibulk16_args.arg_Nx = Nx
+	adddmibulk16_args.arg_Ny = Ny
+	adddmibulk16_args.arg_Nz = Nz
+	adddmibulk16_args.arg_PBC = PBC
+
+	args := adddmibulk16_args.argptr[:]
+	cu.LaunchKernel(adddmibulk16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("adddmibulk16")
+	}
+}
+
+// maps compute capability on PTX code for adddmibulk16 kernel.
+var adddmibulk16_map = map[int]string{0: "",
+	20: adddmibulk16_ptx_20,
+	30: adddmibulk16_ptx_30,
+	35: adddmibulk16_ptx_35,
+	50: adddmibulk16_ptx_50,
+	52: adddmibulk16_ptx_52,
+	53: adddmibulk16_ptx_53}
+
+// adddmibulk16 PTX code for various compute capabilities.
+const (
+	adddmibulk16_ptx_20 = ``
+	adddmibulk16_ptx_30 = ``
+	adddmibulk16_ptx_35 = ``
+	adddmibulk16_ptx_50 = ``
+	adddmibulk16_ptx_52 = ``
+	adddmibulk16_ptx_53 = ``
+)
@@ -62,6 +62,11 @@ func init() {
 
 // Wrapper for adddmibulk CUDA kernel, asynchronous.
 func k_adddmibulk_async(Hx unsafe.Pointer, Hy unsafe.Pointer, Hz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, DLUT2d unsafe.Pointer, regions unsafe.Pointer, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_adddmibulk(Hx, Hy, Hz, mx, my, mz, aLUT2d, DLUT2d, regions, cx, cy, cz, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("adddmibulk")
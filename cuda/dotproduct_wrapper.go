@@ -48,6 +48,11 @@ func init() {
 
 // Wrapper for dotproduct CUDA kernel, asynchronous.
 func k_dotproduct_async(dst unsafe.Pointer, prefactor float32, ax unsafe.Pointer, ay unsafe.Pointer, az unsafe.Pointer, bx unsafe.Pointer, by unsafe.Pointer, bz unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_dotproduct(dst, prefactor, ax, ay, az, bx, by, bz, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("dotproduct")
@@ -19,10 +19,17 @@ func AddExchange(B, m *data.Slice, Aex_red SymmLUT, regions *Bytes, mesh *data.M
 	N := mesh.Size()
 	pbc := mesh.PBC_code()
 	cfg := make3DConf(N)
-	k_addexchange_async(B.DevPtr(X), B.DevPtr(Y), B.DevPtr(Z),
-		m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
-		unsafe.Pointer(Aex_red), regions.Ptr,
-		wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	if regions.Wide {
+		k_addexchange16_async(B.DevPtr(X), B.DevPtr(Y), B.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), regions.Ptr,
+			wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	} else {
+		k_addexchange_async(B.DevPtr(X), B.DevPtr(Y), B.DevPtr(Z),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), regions.Ptr,
+			wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	}
 }
 
 // Finds the average exchange strength around each cell, for debugging.
@@ -34,5 +41,9 @@ func ExchangeDecode(dst *data.Slice, Aex_red SymmLUT, regions *Bytes, mesh *data
 	N := mesh.Size()
 	pbc := mesh.PBC_code()
 	cfg := make3DConf(N)
-	k_exchangedecode_async(dst.DevPtr(0), unsafe.Pointer(Aex_red), regions.Ptr, wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	if regions.Wide {
+		k_exchangedecode16_async(dst.DevPtr(0), unsafe.Pointer(Aex_red), regions.Ptr, wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	} else {
+		k_exchangedecode_async(dst.DevPtr(0), unsafe.Pointer(Aex_red), regions.Ptr, wx, wy, wz, N[X], N[Y], N[Z], pbc, cfg)
+	}
 }
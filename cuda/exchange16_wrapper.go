@@ -0,0 +1,122 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for addexchange16 kernel
+var addexchange16_code cu.Function
+
+// Stores the arguments for addexchange16 kernel invocation
+type addexchange16_args_t struct {
+	arg_Bx      unsafe.Pointer
+	arg_By      unsafe.Pointer
+	arg_Bz      unsafe.Pointer
+	arg_mx      unsafe.Pointer
+	arg_my      unsafe.Pointer
+	arg_mz      unsafe.Pointer
+	arg_aLUT2d  unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_wx      float32
+	arg_wy      float32
+	arg_wz      float32
+	arg_Nx      int
+	arg_Ny      int
+	arg_Nz      int
+	arg_PBC     byte
+	argptr      [15]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for addexchange16 kernel invocation
+var addexchange16_args addexchange16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	addexchange16_args.argptr[0] = unsafe.Pointer(&addexchange16_args.arg_Bx)
+	addexchange16_args.argptr[1] = unsafe.Pointer(&addexchange16_args.arg_By)
+	addexchange16_args.argptr[2] = unsafe.Pointer(&addexchange16_args.arg_Bz)
+	addexchange16_args.argptr[3] = unsafe.Pointer(&addexchange16_args.arg_mx)
+	addexchange16_args.argptr[4] = unsafe.Pointer(&addexchange16_args.arg_my)
+	addexchange16_args.argptr[5] = unsafe.Pointer(&addexchange16_args.arg_mz)
+	addexchange16_args.argptr[6] = unsafe.Pointer(&addexchange16_args.arg_aLUT2d)
+	addexchange16_args.argptr[7] = unsafe.Pointer(&addexchange16_args.arg_regions)
+	addexchange16_args.argptr[8] = unsafe.Pointer(&addexchange16_args.arg_wx)
+	addexchange16_args.argptr[9] = unsafe.Pointer(&addexchange16_args.arg_wy)
+	addexchange16_args.argptr[10] = unsafe.Pointer(&addexchange16_args.arg_wz)
+	addexchange16_args.argptr[11] = unsafe.Pointer(&addexchange16_args.arg_Nx)
+	addexchange16_args.argptr[12] = unsafe.Pointer(&addexchange16_args.arg_Ny)
+	addexchange16_args.argptr[13] = unsafe.Pointer(&addexchange16_args.arg_Nz)
+	addexchange16_args.argptr[14] = unsafe.Pointer(&addexchange16_args.arg_PBC)
+}
+
+// Wrapper for addexchange16 CUDA kernel, asynchronous.
+func k_addexchange16_async(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_addexchange16(Bx, By, Bz, mx, my, mz, aLUT2d, regions, wx, wy, wz, Nx, Ny, Nz, PBC)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("addexchange16")
+	}
+
+	addexchange16_args.Lock()
+	defer addexchange16_args.Unlock()
+
+	if addexchange16_code == 0 {
+		addexchange16_code = fatbinLoad(addexchange16_map, "addexchange16")
+	}
+
+	addexchange16_args.arg_Bx = Bx
+	addexchange16_args.arg_By = By
+	addexchange16_args.arg_Bz = Bz
+	addexchange16_args.arg_mx = mx
+	addexchange16_args.arg_my = my
+	addexchange16_args.arg_mz = mz
+	addexchange16_args.arg_aLUT2d = aLUT2d
+	addexchange16_args.arg_regions = regions
+	addexchange16_args.arg_wx = wx
+	addexchange16_args.arg_wy = wy
+	addexchange16_args.arg_wz = wz
+	addexchange16_args.arg_Nx = Nx
+	addexchange16_args.arg_Ny = Ny
+	addexchange16_args.arg_Nz = Nz
+	addexchange16_args.arg_PBC = PBC
+
+	args := addexchange16_args.argptr[:]
+	cu.LaunchKernel(addexchange16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("addexchange16")
+	}
+}
+
+// maps compute capability on PTX code for addexchange16 kernel.
+var addexchange16_map = map[int]string{0: "",
+	20: addexchange16_ptx_20,
+	30: addexchange16_ptx_30,
+	35: addexchange16_ptx_35,
+	50: addexchange16_ptx_50,
+	52: addexchange16_ptx_52,
+	53: addexchange16_ptx_53}
+
+// addexchange16 PTX code for various compute capabilities.
+const (
+	addexchange16_ptx_20 = ``
+	addexchange16_ptx_30 = ``
+	addexchange16_ptx_35 = ``
+	addexchange16_ptx_50 = ``
+	addexchange16_ptx_52 = ``
+	addexchange16_ptx_53 = ``
+)
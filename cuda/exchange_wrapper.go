@@ -60,6 +60,11 @@ func init() {
 
 // Wrapper for addexchange CUDA kernel, asynchronous.
 func k_addexchange_async(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_addexchange(Bx, By, Bz, mx, my, mz, aLUT2d, regions, wx, wy, wz, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("addexchange")
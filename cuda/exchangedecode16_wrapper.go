@@ -0,0 +1,107 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for exchangedecode16 kernel
+var exchangedecode16_code cu.Function
+
+// Stores the arguments for exchangedecode16 kernel invocation
+type exchangedecode16_args_t struct {
+	arg_dst     unsafe.Pointer
+	arg_aLUT2d  unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_wx      float32
+	arg_wy      float32
+	arg_wz      float32
+	arg_Nx      int
+	arg_Ny      int
+	arg_Nz      int
+	arg_PBC     byte
+	argptr      [10]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for exchangedecode16 kernel invocation
+var exchangedecode16_args exchangedecode16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	exchangedecode16_args.argptr[0] = unsafe.Pointer(&exchangedecode16_args.arg_dst)
+	exchangedecode16_args.argptr[1] = unsafe.Pointer(&exchangedecode16_args.arg_aLUT2d)
+	exchangedecode16_args.argptr[2] = unsafe.Pointer(&exchangedecode16_args.arg_regions)
+	exchangedecode16_args.argptr[3] = unsafe.Pointer(&exchangedecode16_args.arg_wx)
+	exchangedecode16_args.argptr[4] = unsafe.Pointer(&exchangedecode16_args.arg_wy)
+	exchangedecode16_args.argptr[5] = unsafe.Pointer(&exchangedecode16_args.arg_wz)
+	exchangedecode16_args.argptr[6] = unsafe.Pointer(&exchangedecode16_args.arg_Nx)
+	exchangedecode16_args.argptr[7] = unsafe.Pointer(&exchangedecode16_args.arg_Ny)
+	exchangedecode16_args.argptr[8] = unsafe.Pointer(&exchangedecode16_args.arg_Nz)
+	exchangedecode16_args.argptr[9] = unsafe.Pointer(&exchangedecode16_args.arg_PBC)
+}
+
+// Wrapper for exchangedecode16 CUDA kernel, asynchronous.
+func k_exchangedecode16_async(dst unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_exchangedecode16(dst, aLUT2d, regions, wx, wy, wz, Nx, Ny, Nz, PBC)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("exchangedecode16")
+	}
+
+	exchangedecode16_args.Lock()
+	defer exchangedecode16_args.Unlock()
+
+	if exchangedecode16_code == 0 {
+		exchangedecode16_code = fatbinLoad(exchangedecode16_map, "exchangedecode16")
+	}
+
+	exchangedecode16_args.arg_dst = dst
+	exchangedecode16_args.arg_aLUT2d = aLUT2d
+	exchangedecode16_args.arg_regions = regions
+	exchangedecode16_args.arg_wx = wx
+	exchangedecode16_args.arg_wy = wy
+	exchangedecode16_args.arg_wz = wz
+	exchangedecode16_args.arg_Nx = Nx
+	exchangedecode16_args.arg_Ny = Ny
+	exchangedecode16_args.arg_Nz = Nz
+	exchangedecode16_args.arg_PBC = PBC
+
+	args := exchangedecode16_args.argptr[:]
+	cu.LaunchKernel(exchangedecode16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("exchangedecode16")
+	}
+}
+
+// maps compute capability on PTX code for exchangedecode16 kernel.
+var exchangedecode16_map = map[int]string{0: "",
+	20: exchangedecode16_ptx_20,
+	30: exchangedecode16_ptx_30,
+	35: exchangedecode16_ptx_35,
+	50: exchangedecode16_ptx_50,
+	52: exchangedecode16_ptx_52,
+	53: exchangedecode16_ptx_53}
+
+// exchangedecode16 PTX code for various compute capabilities.
+const (
+	exchangedecode16_ptx_20 = ``
+	exchangedecode16_ptx_30 = ``
+	exchangedecode16_ptx_35 = ``
+	exchangedecode16_ptx_50 = ``
+	exchangedecode16_ptx_52 = ``
+	exchangedecode16_ptx_53 = ``
+)
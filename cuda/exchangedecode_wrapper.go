@@ -50,6 +50,11 @@ func init() {
 
 // Wrapper for exchangedecode CUDA kernel, asynchronous.
 func k_exchangedecode_async(dst unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, wx float32, wy float32, wz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_exchangedecode(dst, aLUT2d, regions, wx, wy, wz, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("exchangedecode")
@@ -40,3 +40,10 @@ func ccIsOK(cc int) (ok bool) {
 	cu.ModuleLoadData(madd2_map[cc]).GetFunction("madd2")
 	return true
 }
+
+// HaveWideRegions reports whether the 16-bit region kernels (*16.cu), used for
+// more than 256 regions, can run: always on the CPU, on the GPU only when their
+// PTX has been generated with make.bash.
+func HaveWideRegions() bool {
+	return CPU || zeromask16_map[determineCC()] != ""
+}
@@ -17,10 +17,13 @@ type fft3DC2RPlan struct {
 
 // 3D single-precission real-to-complex FFT plan.
 func newFFT3DC2R(Nx, Ny, Nz int) fft3DC2RPlan {
+	if CPU {
+		return fft3DC2RPlan{fftplan{cpu: newCPUFFT3D(Nx, Ny, Nz)}, [3]int{Nx, Ny, Nz}}
+	}
 	handle := cufft.Plan3d(Nz, Ny, Nx, cufft.C2R) // new xyz swap
 	handle.SetCompatibilityMode(cufft.COMPATIBILITY_FFTW_PADDING)
 	handle.SetStream(stream0)
-	return fft3DC2RPlan{fftplan{handle}, [3]int{Nx, Ny, Nz}}
+	return fft3DC2RPlan{fftplan{handle: handle}, [3]int{Nx, Ny, Nz}}
 }
 
 // Execute the FFT plan, asynchronous.
@@ -38,7 +41,11 @@ func (p *fft3DC2RPlan) ExecAsync(src, dst *data.Slice) {
 	if dst.Len() != okdstlen {
 		panic(fmt.Errorf("fft size mismatch: expecting dst len %v, got %v", okdstlen, dst.Len()))
 	}
-	p.handle.ExecC2R(cu.DevicePtr(uintptr(src.DevPtr(0))), cu.DevicePtr(uintptr(dst.DevPtr(0))))
+	if p.cpu != nil {
+		p.cpu.execC2R(cpuFloats(dst.DevPtr(0), dst.Len()), cpuFloats(src.DevPtr(0), src.Len()))
+	} else {
+		p.handle.ExecC2R(cu.DevicePtr(uintptr(src.DevPtr(0))), cu.DevicePtr(uintptr(dst.DevPtr(0))))
+	}
 	if Synchronous {
 		Sync()
 		timer.Stop("fft")
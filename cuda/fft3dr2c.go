@@ -18,10 +18,13 @@ type fft3DR2CPlan struct {
 
 // 3D single-precission real-to-complex FFT plan.
 func newFFT3DR2C(Nx, Ny, Nz int) fft3DR2CPlan {
+	if CPU {
+		return fft3DR2CPlan{fftplan{cpu: newCPUFFT3D(Nx, Ny, Nz)}, [3]int{Nx, Ny, Nz}}
+	}
 	handle := cufft.Plan3d(Nz, Ny, Nx, cufft.R2C) // new xyz swap
 	handle.SetCompatibilityMode(cufft.COMPATIBILITY_FFTW_PADDING)
 	handle.SetStream(stream0)
-	return fft3DR2CPlan{fftplan{handle}, [3]int{Nx, Ny, Nz}}
+	return fft3DR2CPlan{fftplan{handle: handle}, [3]int{Nx, Ny, Nz}}
 }
 
 // Execute the FFT plan, asynchronous.
@@ -40,7 +43,11 @@ func (p *fft3DR2CPlan) ExecAsync(src, dst *data.Slice) {
 	if dst.Len() != okdstlen {
 		log.Panicf("fft size mismatch: expecting dst len %v, got %v", okdstlen, dst.Len())
 	}
-	p.handle.ExecR2C(cu.DevicePtr(uintptr(src.DevPtr(0))), cu.DevicePtr(uintptr(dst.DevPtr(0))))
+	if p.cpu != nil {
+		p.cpu.execR2C(cpuFloats(dst.DevPtr(0), dst.Len()), cpuFloats(src.DevPtr(0), src.Len()))
+	} else {
+		p.handle.ExecR2C(cu.DevicePtr(uintptr(src.DevPtr(0))), cu.DevicePtr(uintptr(dst.DevPtr(0))))
+	}
 	if Synchronous {
 		Sync()
 		timer.Stop("fft")
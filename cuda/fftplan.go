@@ -11,6 +11,7 @@ import (
 // Base implementation for all FFT plans.
 type fftplan struct {
 	handle cufft.Handle
+	cpu    *cpuFFT3D // used instead of handle by the CPU backend
 }
 
 func prod3(x, y, z int) int {
@@ -19,6 +20,7 @@ func prod3(x, y, z int) int {
 
 // Releases all resources associated with the FFT plan.
 func (p *fftplan) Free() {
+	p.cpu = nil
 	if p.handle != 0 {
 		p.handle.Destroy()
 		p.handle = 0
@@ -27,5 +29,8 @@ func (p *fftplan) Free() {
 
 // Associates a CUDA stream with the FFT plan.
 func (p *fftplan) setStream(stream cu.Stream) {
+	if p.cpu != nil {
+		return
+	}
 	p.handle.SetStream(stream)
 }
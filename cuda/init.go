@@ -25,6 +25,10 @@ func Init(gpu int) {
 	if cudaCtx != 0 {
 		return // needed for tests
 	}
+	if CPU {
+		initCPU()
+		return
+	}
 
 	runtime.LockOSThread()
 	tryCuInit()
@@ -68,5 +72,8 @@ const stream0 = cu.Stream(0)
 // Synchronize the global stream
 // This is called before and after all memcopy operations between host and device.
 func Sync() {
+	if CPU {
+		return // kernels run synchronously
+	}
 	stream0.Synchronize()
 }
@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for kernmulC CUDA kernel, asynchronous.
 func k_kernmulC_async(fftM unsafe.Pointer, fftK unsafe.Pointer, Nx int, Ny int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_kernmulC(fftM, fftK, Nx, Ny)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("kernmulC")
@@ -44,6 +44,11 @@ func init() {
 
 // Wrapper for kernmulRSymm2Dxy CUDA kernel, asynchronous.
 func k_kernmulRSymm2Dxy_async(fftMx unsafe.Pointer, fftMy unsafe.Pointer, fftKxx unsafe.Pointer, fftKyy unsafe.Pointer, fftKxy unsafe.Pointer, Nx int, Ny int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_kernmulRSymm2Dxy(fftMx, fftMy, fftKxx, fftKyy, fftKxy, Nx, Ny)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("kernmulRSymm2Dxy")
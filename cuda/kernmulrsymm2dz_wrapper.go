@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for kernmulRSymm2Dz CUDA kernel, asynchronous.
 func k_kernmulRSymm2Dz_async(fftMz unsafe.Pointer, fftKzz unsafe.Pointer, Nx int, Ny int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_kernmulRSymm2Dz(fftMz, fftKzz, Nx, Ny)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("kernmulRSymm2Dz")
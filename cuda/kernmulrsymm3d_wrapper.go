@@ -54,6 +54,11 @@ func init() {
 
 // Wrapper for kernmulRSymm3D CUDA kernel, asynchronous.
 func k_kernmulRSymm3D_async(fftMx unsafe.Pointer, fftMy unsafe.Pointer, fftMz unsafe.Pointer, fftKxx unsafe.Pointer, fftKyy unsafe.Pointer, fftKzz unsafe.Pointer, fftKyz unsafe.Pointer, fftKxz unsafe.Pointer, fftKxy unsafe.Pointer, Nx int, Ny int, Nz int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_kernmulRSymm3D(fftMx, fftMy, fftMz, fftKxx, fftKyy, fftKzz, fftKyz, fftKxz, fftKxy, Nx, Ny, Nz)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("kernmulRSymm3D")
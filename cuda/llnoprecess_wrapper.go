@@ -50,6 +50,11 @@ func init() {
 
 // Wrapper for llnoprecess CUDA kernel, asynchronous.
 func k_llnoprecess_async(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, hx unsafe.Pointer, hy unsafe.Pointer, hz unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_llnoprecess(tx, ty, tz, mx, my, mz, hx, hy, hz, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("llnoprecess")
@@ -54,6 +54,11 @@ func init() {
 
 // Wrapper for lltorque2 CUDA kernel, asynchronous.
 func k_lltorque2_async(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, hx unsafe.Pointer, hy unsafe.Pointer, hz unsafe.Pointer, alpha_ unsafe.Pointer, alpha_mul float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_lltorque2(tx, ty, tz, mx, my, mz, hx, hy, hz, alpha_, alpha_mul, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("lltorque2")
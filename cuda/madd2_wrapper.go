@@ -42,6 +42,11 @@ func init() {
 
 // Wrapper for madd2 CUDA kernel, asynchronous.
 func k_madd2_async(dst unsafe.Pointer, src1 unsafe.Pointer, fac1 float32, src2 unsafe.Pointer, fac2 float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_madd2(dst, src1, fac1, src2, fac2, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("madd2")
@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for madd3 CUDA kernel, asynchronous.
 func k_madd3_async(dst unsafe.Pointer, src1 unsafe.Pointer, fac1 float32, src2 unsafe.Pointer, fac2 float32, src3 unsafe.Pointer, fac3 float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_madd3(dst, src1, fac1, src2, fac2, src3, fac3, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("madd3")
@@ -6,13 +6,17 @@ NVCC='nvcc --compiler-options -Werror --compiler-options -Wall -Xptxas -O3 -ptx'
 
 for f in *.cu; do
 	g=$(echo $f | sed 's/\.cu$//') # file basename
+	wrap=false # regenerate wrapper if .cu or any PTX is newer
 	for cc in 20 30 35 50 52 53; do
 		if [[ $f -nt $g'_'$cc.ptx ]]; then
 			echo $NVCC -gencode arch=compute_$cc,code=sm_$cc $f -o $g'_'$cc.ptx
 			$NVCC -I/usr/local/cuda/include -gencode arch=compute_$cc,code=sm_$cc $f -o $g'_'$cc.ptx # error can be ignored
 		fi
+		if [[ $g'_'$cc.ptx -nt $g'_wrapper.go' ]]; then
+			wrap=true
+		fi
 	done
-	if [[ $f -nt $g'_wrapper.go' ]]; then
+	if [[ $f -nt $g'_wrapper.go' ]] || $wrap; then
 		./cuda2go $f || exit 1
 	fi
 done
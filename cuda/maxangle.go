@@ -12,8 +12,15 @@ func SetMaxAngle(dst, m *data.Slice, Aex_red SymmLUT, regions *Bytes, mesh *data
 	N := mesh.Size()
 	pbc := mesh.PBC_code()
 	cfg := make3DConf(N)
-	k_setmaxangle_async(dst.DevPtr(0),
-		m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
-		unsafe.Pointer(Aex_red), regions.Ptr,
-		N[X], N[Y], N[Z], pbc, cfg)
+	if regions.Wide {
+		k_setmaxangle16_async(dst.DevPtr(0),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), regions.Ptr,
+			N[X], N[Y], N[Z], pbc, cfg)
+	} else {
+		k_setmaxangle_async(dst.DevPtr(0),
+			m.DevPtr(X), m.DevPtr(Y), m.DevPtr(Z),
+			unsafe.Pointer(Aex_red), regions.Ptr,
+			N[X], N[Y], N[Z], pbc, cfg)
+	}
 }
@@ -0,0 +1,107 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for setmaxangle16 kernel
+var setmaxangle16_code cu.Function
+
+// Stores the arguments for setmaxangle16 kernel invocation
+type setmaxangle16_args_t struct {
+	arg_dst     unsafe.Pointer
+	arg_mx      unsafe.Pointer
+	arg_my      unsafe.Pointer
+	arg_mz      unsafe.Pointer
+	arg_aLUT2d  unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_Nx      int
+	arg_Ny      int
+	arg_Nz      int
+	arg_PBC     byte
+	argptr      [10]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for setmaxangle16 kernel invocation
+var setmaxangle16_args setmaxangle16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	setmaxangle16_args.argptr[0] = unsafe.Pointer(&setmaxangle16_args.arg_dst)
+	setmaxangle16_args.argptr[1] = unsafe.Pointer(&setmaxangle16_args.arg_mx)
+	setmaxangle16_args.argptr[2] = unsafe.Pointer(&setmaxangle16_args.arg_my)
+	setmaxangle16_args.argptr[3] = unsafe.Pointer(&setmaxangle16_args.arg_mz)
+	setmaxangle16_args.argptr[4] = unsafe.Pointer(&setmaxangle16_args.arg_aLUT2d)
+	setmaxangle16_args.argptr[5] = unsafe.Pointer(&setmaxangle16_args.arg_regions)
+	setmaxangle16_args.argptr[6] = unsafe.Pointer(&setmaxangle16_args.arg_Nx)
+	setmaxangle16_args.argptr[7] = unsafe.Pointer(&setmaxangle16_args.arg_Ny)
+	setmaxangle16_args.argptr[8] = unsafe.Pointer(&setmaxangle16_args.arg_Nz)
+	setmaxangle16_args.argptr[9] = unsafe.Pointer(&setmaxangle16_args.arg_PBC)
+}
+
+// Wrapper for setmaxangle16 CUDA kernel, asynchronous.
+func k_setmaxangle16_async(dst unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_setmaxangle16(dst, mx, my, mz, aLUT2d, regions, Nx, Ny, Nz, PBC)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("setmaxangle16")
+	}
+
+	setmaxangle16_args.Lock()
+	defer setmaxangle16_args.Unlock()
+
+	if setmaxangle16_code == 0 {
+		setmaxangle16_code = fatbinLoad(setmaxangle16_map, "setmaxangle16")
+	}
+
+	setmaxangle16_args.arg_dst = dst
+	setmaxangle16_args.arg_mx = mx
+	setmaxangle16_args.arg_my = my
+	setmaxangle16_args.arg_mz = mz
+	setmaxangle16_args.arg_aLUT2d = aLUT2d
+	setmaxangle16_args.arg_regions = regions
+	setmaxangle16_args.arg_Nx = Nx
+	setmaxangle16_args.arg_Ny = Ny
+	setmaxangle16_args.arg_Nz = Nz
+	setmaxangle16_args.arg_PBC = PBC
+
+	args := setmaxangle16_args.argptr[:]
+	cu.LaunchKernel(setmaxangle16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("setmaxangle16")
+	}
+}
+
+// maps compute capability on PTX code for setmaxangle16 kernel.
+var setmaxangle16_map = map[int]string{0: "",
+	20: setmaxangle16_ptx_20,
+	30: setmaxangle16_ptx_30,
+	35: setmaxangle16_ptx_35,
+	50: setmaxangle16_ptx_50,
+	52: setmaxangle16_ptx_52,
+	53: setmaxangle16_ptx_53}
+
+// setmaxangle16 PTX code for various compute capabilities.
+const (
+	setmaxangle16_ptx_20 = ``
+	setmaxangle16_ptx_30 = ``
+	setmaxangle16_ptx_35 = ``
+	setmaxangle16_ptx_50 = ``
+	setmaxangle16_ptx_52 = ``
+	setmaxangle16_ptx_53 = ``
+)
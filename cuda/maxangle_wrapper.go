@@ -50,6 +50,11 @@ func init() {
 
 // Wrapper for setmaxangle CUDA kernel, asynchronous.
 func k_setmaxangle_async(dst unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, aLUT2d unsafe.Pointer, regions unsafe.Pointer, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_setmaxangle(dst, mx, my, mz, aLUT2d, regions, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("setmaxangle")
@@ -52,6 +52,11 @@ func init() {
 
 // Wrapper for minimize CUDA kernel, asynchronous.
 func k_minimize_async(mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, m0x unsafe.Pointer, m0y unsafe.Pointer, m0z unsafe.Pointer, tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, dt float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_minimize(mx, my, mz, m0x, m0y, m0z, tx, ty, tz, dt, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("minimize")
@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for mul CUDA kernel, asynchronous.
 func k_mul_async(dst unsafe.Pointer, a unsafe.Pointer, b unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_mul(dst, a, b, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("mul")
@@ -0,0 +1,7 @@
+//go:build nocuda
+// +build nocuda
+
+package cuda
+
+// Built without CUDA: always use the CPU backend.
+func init() { CPU = true }
@@ -40,6 +40,11 @@ func init() {
 
 // Wrapper for normalize CUDA kernel, asynchronous.
 func k_normalize_async(vx unsafe.Pointer, vy unsafe.Pointer, vz unsafe.Pointer, vol unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_normalize(vx, vy, vz, vol, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("normalize")
@@ -9,11 +9,8 @@ import (
 	"github.com/mumax/3/util"
 )
 
-//#include "reduce.h"
-import "C"
-
-// Block size for reduce kernels.
-const REDUCE_BLOCKSIZE = C.REDUCE_BLOCKSIZE
+// Block size for reduce kernels, must match reduce.h.
+const REDUCE_BLOCKSIZE = 512
 
 // Sum of all elements.
 func Sum(in *data.Slice) float32 {
@@ -72,7 +69,11 @@ func reduceBuf(initVal float32) unsafe.Pointer {
 		initReduceBuf()
 	}
 	buf := <-reduceBuffers
-	cu.MemsetD32Async(cu.DevicePtr(uintptr(buf)), math.Float32bits(initVal), 1, stream0)
+	if CPU {
+		*(*float32)(buf) = initVal
+	} else {
+		cu.MemsetD32Async(cu.DevicePtr(uintptr(buf)), math.Float32bits(initVal), 1, stream0)
+	}
 	return buf
 }
 
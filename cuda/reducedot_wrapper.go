@@ -40,6 +40,11 @@ func init() {
 
 // Wrapper for reducedot CUDA kernel, asynchronous.
 func k_reducedot_async(x1 unsafe.Pointer, x2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducedot(x1, x2, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducedot")
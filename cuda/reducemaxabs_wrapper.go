@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for reducemaxabs CUDA kernel, asynchronous.
 func k_reducemaxabs_async(src unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducemaxabs(src, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducemaxabs")
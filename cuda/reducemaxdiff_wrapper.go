@@ -40,6 +40,11 @@ func init() {
 
 // Wrapper for reducemaxdiff CUDA kernel, asynchronous.
 func k_reducemaxdiff_async(src1 unsafe.Pointer, src2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducemaxdiff(src1, src2, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducemaxdiff")
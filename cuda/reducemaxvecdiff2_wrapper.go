@@ -48,6 +48,11 @@ func init() {
 
 // Wrapper for reducemaxvecdiff2 CUDA kernel, asynchronous.
 func k_reducemaxvecdiff2_async(x1 unsafe.Pointer, y1 unsafe.Pointer, z1 unsafe.Pointer, x2 unsafe.Pointer, y2 unsafe.Pointer, z2 unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducemaxvecdiff2(x1, y1, z1, x2, y2, z2, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducemaxvecdiff2")
@@ -42,6 +42,11 @@ func init() {
 
 // Wrapper for reducemaxvecnorm2 CUDA kernel, asynchronous.
 func k_reducemaxvecnorm2_async(x unsafe.Pointer, y unsafe.Pointer, z unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducemaxvecnorm2(x, y, z, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducemaxvecnorm2")
@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for reducesum CUDA kernel, asynchronous.
 func k_reducesum_async(src unsafe.Pointer, dst unsafe.Pointer, initVal float32, n int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_reducesum(src, dst, initVal, n)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("reducesum")
@@ -12,24 +12,37 @@ func RegionAddV(dst *data.Slice, lut LUTPtrs, regions *Bytes) {
 	util.Argument(dst.NComp() == 3)
 	N := dst.Len()
 	cfg := make1DConf(N)
-	k_regionaddv_async(dst.DevPtr(X), dst.DevPtr(Y), dst.DevPtr(Z),
-		lut[X], lut[Y], lut[Z], regions.Ptr, N, cfg)
+	if regions.Wide {
+		k_regionaddv16_async(dst.DevPtr(X), dst.DevPtr(Y), dst.DevPtr(Z),
+			lut[X], lut[Y], lut[Z], regions.Ptr, N, cfg)
+	} else {
+		k_regionaddv_async(dst.DevPtr(X), dst.DevPtr(Y), dst.DevPtr(Z),
+			lut[X], lut[Y], lut[Z], regions.Ptr, N, cfg)
+	}
 }
 
 // decode the regions+LUT pair into an uncompressed array
 func RegionDecode(dst *data.Slice, lut LUTPtr, regions *Bytes) {
 	N := dst.Len()
 	cfg := make1DConf(N)
-	k_regiondecode_async(dst.DevPtr(0), unsafe.Pointer(lut), regions.Ptr, N, cfg)
+	if regions.Wide {
+		k_regiondecode16_async(dst.DevPtr(0), unsafe.Pointer(lut), regions.Ptr, N, cfg)
+	} else {
+		k_regiondecode_async(dst.DevPtr(0), unsafe.Pointer(lut), regions.Ptr, N, cfg)
+	}
 }
 
 // select the part of src within the specified region, set 0's everywhere else.
-func RegionSelect(dst, src *data.Slice, regions *Bytes, region byte) {
+func RegionSelect(dst, src *data.Slice, regions *Bytes, region int) {
 	util.Argument(dst.NComp() == src.NComp())
 	N := dst.Len()
 	cfg := make1DConf(N)
 
 	for c := 0; c < dst.NComp(); c++ {
-		k_regionselect_async(dst.DevPtr(c), src.DevPtr(c), regions.Ptr, region, N, cfg)
+		if regions.Wide {
+			k_regionselect16_async(dst.DevPtr(c), src.DevPtr(c), regions.Ptr, uint16(region), N, cfg)
+		} else {
+			k_regionselect_async(dst.DevPtr(c), src.DevPtr(c), regions.Ptr, byte(region), N, cfg)
+		}
 	}
 }
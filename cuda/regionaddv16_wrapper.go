@@ -0,0 +1,101 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for regionaddv16 kernel
+var regionaddv16_code cu.Function
+
+// Stores the arguments for regionaddv16 kernel invocation
+type regionaddv16_args_t struct {
+	arg_dstx    unsafe.Pointer
+	arg_dsty    unsafe.Pointer
+	arg_dstz    unsafe.Pointer
+	arg_LUTx    unsafe.Pointer
+	arg_LUTy    unsafe.Pointer
+	arg_LUTz    unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_N       int
+	argptr      [8]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for regionaddv16 kernel invocation
+var regionaddv16_args regionaddv16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	regionaddv16_args.argptr[0] = unsafe.Pointer(&regionaddv16_args.arg_dstx)
+	regionaddv16_args.argptr[1] = unsafe.Pointer(&regionaddv16_args.arg_dsty)
+	regionaddv16_args.argptr[2] = unsafe.Pointer(&regionaddv16_args.arg_dstz)
+	regionaddv16_args.argptr[3] = unsafe.Pointer(&regionaddv16_args.arg_LUTx)
+	regionaddv16_args.argptr[4] = unsafe.Pointer(&regionaddv16_args.arg_LUTy)
+	regionaddv16_args.argptr[5] = unsafe.Pointer(&regionaddv16_args.arg_LUTz)
+	regionaddv16_args.argptr[6] = unsafe.Pointer(&regionaddv16_args.arg_regions)
+	regionaddv16_args.argptr[7] = unsafe.Pointer(&regionaddv16_args.arg_N)
+}
+
+// Wrapper for regionaddv16 CUDA kernel, asynchronous.
+func k_regionaddv16_async(dstx unsafe.Pointer, dsty unsafe.Pointer, dstz unsafe.Pointer, LUTx unsafe.Pointer, LUTy unsafe.Pointer, LUTz unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regionaddv16(dstx, dsty, dstz, LUTx, LUTy, LUTz, regions, N)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("regionaddv16")
+	}
+
+	regionaddv16_args.Lock()
+	defer regionaddv16_args.Unlock()
+
+	if regionaddv16_code == 0 {
+		regionaddv16_code = fatbinLoad(regionaddv16_map, "regionaddv16")
+	}
+
+	regionaddv16_args.arg_dstx = dstx
+	regionaddv16_args.arg_dsty = dsty
+	regionaddv16_args.arg_dstz = dstz
+	regionaddv16_args.arg_LUTx = LUTx
+	regionaddv16_args.arg_LUTy = LUTy
+	regionaddv16_args.arg_LUTz = LUTz
+	regionaddv16_args.arg_regions = regions
+	regionaddv16_args.arg_N = N
+
+	args := regionaddv16_args.argptr[:]
+	cu.LaunchKernel(regionaddv16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("regionaddv16")
+	}
+}
+
+// maps compute capability on PTX code for regionaddv16 kernel.
+var regionaddv16_map = map[int]string{0: "",
+	20: regionaddv16_ptx_20,
+	30: regionaddv16_ptx_30,
+	35: regionaddv16_ptx_35,
+	50: regionaddv16_ptx_50,
+	52: regionaddv16_ptx_52,
+	53: regionaddv16_ptx_53}
+
+// regionaddv16 PTX code for various compute capabilities.
+const (
+	regionaddv16_ptx_20 = ``
+	regionaddv16_ptx_30 = ``
+	regionaddv16_ptx_35 = ``
+	regionaddv16_ptx_50 = ``
+	regionaddv16_ptx_52 = ``
+	regionaddv16_ptx_53 = ``
+)
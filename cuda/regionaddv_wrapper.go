@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for regionaddv CUDA kernel, asynchronous.
 func k_regionaddv_async(dstx unsafe.Pointer, dsty unsafe.Pointer, dstz unsafe.Pointer, LUTx unsafe.Pointer, LUTy unsafe.Pointer, LUTz unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regionaddv(dstx, dsty, dstz, LUTx, LUTy, LUTz, regions, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("regionaddv")
@@ -0,0 +1,15 @@
+#include <stdint.h>
+
+// decode the regions+LUT pair into an uncompressed array
+// Same as regiondecode, but with 16-bit region indices, used for more than 256 regions.
+extern "C" __global__ void
+regiondecode16(float* __restrict__  dst, float* __restrict__ LUT, uint16_t* regions, int N) {
+
+    int i =  ( blockIdx.y*gridDim.x + blockIdx.x ) * blockDim.x + threadIdx.x;
+    if (i < N) {
+
+        dst[i] = LUT[regions[i]];
+
+    }
+}
+
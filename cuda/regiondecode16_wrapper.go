@@ -0,0 +1,89 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for regiondecode16 kernel
+var regiondecode16_code cu.Function
+
+// Stores the arguments for regiondecode16 kernel invocation
+type regiondecode16_args_t struct {
+	arg_dst     unsafe.Pointer
+	arg_LUT     unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_N       int
+	argptr      [4]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for regiondecode16 kernel invocation
+var regiondecode16_args regiondecode16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	regiondecode16_args.argptr[0] = unsafe.Pointer(&regiondecode16_args.arg_dst)
+	regiondecode16_args.argptr[1] = unsafe.Pointer(&regiondecode16_args.arg_LUT)
+	regiondecode16_args.argptr[2] = unsafe.Pointer(&regiondecode16_args.arg_regions)
+	regiondecode16_args.argptr[3] = unsafe.Pointer(&regiondecode16_args.arg_N)
+}
+
+// Wrapper for regiondecode16 CUDA kernel, asynchronous.
+func k_regiondecode16_async(dst unsafe.Pointer, LUT unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regiondecode16(dst, LUT, regions, N)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("regiondecode16")
+	}
+
+	regiondecode16_args.Lock()
+	defer regiondecode16_args.Unlock()
+
+	if regiondecode16_code == 0 {
+		regiondecode16_code = fatbinLoad(regiondecode16_map, "regiondecode16")
+	}
+
+	regiondecode16_args.arg_dst = dst
+	regiondecode16_args.arg_LUT = LUT
+	regiondecode16_args.arg_regions = regions
+	regiondecode16_args.arg_N = N
+
+	args := regiondecode16_args.argptr[:]
+	cu.LaunchKernel(regiondecode16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("regiondecode16")
+	}
+}
+
+// maps compute capability on PTX code for regiondecode16 kernel.
+var regiondecode16_map = map[int]string{0: "",
+	20: regiondecode16_ptx_20,
+	30: regiondecode16_ptx_30,
+	35: regiondecode16_ptx_35,
+	50: regiondecode16_ptx_50,
+	52: regiondecode16_ptx_52,
+	53: regiondecode16_ptx_53}
+
+// regiondecode16 PTX code for various compute capabilities.
+const (
+	regiondecode16_ptx_20 = ``
+	regiondecode16_ptx_30 = ``
+	regiondecode16_ptx_35 = ``
+	regiondecode16_ptx_50 = ``
+	regiondecode16_ptx_52 = ``
+	regiondecode16_ptx_53 = ``
+)
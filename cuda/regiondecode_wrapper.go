@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for regiondecode CUDA kernel, asynchronous.
 func k_regiondecode_async(dst unsafe.Pointer, LUT unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regiondecode(dst, LUT, regions, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("regiondecode")
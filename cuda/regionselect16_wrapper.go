@@ -0,0 +1,92 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for regionselect16 kernel
+var regionselect16_code cu.Function
+
+// Stores the arguments for regionselect16 kernel invocation
+type regionselect16_args_t struct {
+	arg_dst     unsafe.Pointer
+	arg_src     unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_region  uint16
+	arg_N       int
+	argptr      [5]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for regionselect16 kernel invocation
+var regionselect16_args regionselect16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	regionselect16_args.argptr[0] = unsafe.Pointer(&regionselect16_args.arg_dst)
+	regionselect16_args.argptr[1] = unsafe.Pointer(&regionselect16_args.arg_src)
+	regionselect16_args.argptr[2] = unsafe.Pointer(&regionselect16_args.arg_regions)
+	regionselect16_args.argptr[3] = unsafe.Pointer(&regionselect16_args.arg_region)
+	regionselect16_args.argptr[4] = unsafe.Pointer(&regionselect16_args.arg_N)
+}
+
+// Wrapper for regionselect16 CUDA kernel, asynchronous.
+func k_regionselect16_async(dst unsafe.Pointer, src unsafe.Pointer, regions unsafe.Pointer, region uint16, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regionselect16(dst, src, regions, region, N)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("regionselect16")
+	}
+
+	regionselect16_args.Lock()
+	defer regionselect16_args.Unlock()
+
+	if regionselect16_code == 0 {
+		regionselect16_code = fatbinLoad(regionselect16_map, "regionselect16")
+	}
+
+	regionselect16_args.arg_dst = dst
+	regionselect16_args.arg_src = src
+	regionselect16_args.arg_regions = regions
+	regionselect16_args.arg_region = region
+	regionselect16_args.arg_N = N
+
+	args := regionselect16_args.argptr[:]
+	cu.LaunchKernel(regionselect16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("regionselect16")
+	}
+}
+
+// maps compute capability on PTX code for regionselect16 kernel.
+var regionselect16_map = map[int]string{0: "",
+	20: regionselect16_ptx_20,
+	30: regionselect16_ptx_30,
+	35: regionselect16_ptx_35,
+	50: regionselect16_ptx_50,
+	52: regionselect16_ptx_52,
+	53: regionselect16_ptx_53}
+
+// regionselect16 PTX code for various compute capabilities.
+const (
+	regionselect16_ptx_20 = ``
+	regionselect16_ptx_30 = ``
+	regionselect16_ptx_35 = ``
+	regionselect16_ptx_50 = ``
+	regionselect16_ptx_52 = ``
+	regionselect16_ptx_53 = ``
+)
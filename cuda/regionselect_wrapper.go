@@ -40,6 +40,11 @@ func init() {
 
 // Wrapper for regionselect CUDA kernel, asynchronous.
 func k_regionselect_async(dst unsafe.Pointer, src unsafe.Pointer, regions unsafe.Pointer, region byte, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_regionselect(dst, src, regions, region, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("regionselect")
@@ -52,6 +52,11 @@ func init() {
 
 // Wrapper for resize CUDA kernel, asynchronous.
 func k_resize_async(dst unsafe.Pointer, Dx int, Dy int, Dz int, src unsafe.Pointer, Sx int, Sy int, Sz int, layer int, scalex int, scaley int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_resize(dst, Dx, Dy, Dz, src, Sx, Sy, Sz, layer, scalex, scaley)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("resize")
@@ -0,0 +1,86 @@
+package cuda
+
+// Random number generation on the GPU, or in Go for the CPU backend.
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+
+	"github.com/mumax/3/cuda/curand"
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/util"
+)
+
+// Generator produces normally distributed random numbers.
+type Generator struct {
+	gpu          curand.Generator
+	seed, offset int64     // GPU generator state
+	pcg          *rand.PCG // CPU backend
+	rng          *rand.Rand
+}
+
+// NewGenerator returns a pseudo-random generator with given seed.
+func NewGenerator(seed int64) *Generator {
+	g := new(Generator)
+	if CPU {
+		g.pcg = rand.NewPCG(0, 0)
+		g.rng = rand.New(g.pcg)
+	} else {
+		g.gpu = curand.CreateGenerator(curand.PSEUDO_DEFAULT)
+	}
+	g.SetSeed(seed)
+	return g
+}
+
+// SetSeed re-seeds the generator.
+func (g *Generator) SetSeed(seed int64) {
+	if CPU {
+		g.pcg.Seed(uint64(seed), 0)
+	} else {
+		g.gpu.SetSeed(seed)
+		g.seed, g.offset = seed, 0
+	}
+}
+
+// GenerateNormal fills the single-component slice dst with normally distributed numbers.
+func (g *Generator) GenerateNormal(dst *data.Slice, mean, stddev float32) {
+	util.Argument(dst.NComp() == 1)
+	N := dst.Len()
+	if CPU {
+		d := cpuFloats(dst.DevPtr(0), N)
+		for i := range d {
+			d[i] = mean + stddev*float32(g.rng.NormFloat64())
+		}
+		return
+	}
+	g.gpu.GenerateNormal(uintptr(dst.DevPtr(0)), int64(N), mean, stddev)
+	g.offset += int64(N)
+}
+
+// State returns the generator state, which can be restored with SetState.
+func (g *Generator) State() []byte {
+	if CPU {
+		b, err := g.pcg.MarshalBinary()
+		util.PanicErr(err)
+		return b
+	}
+	// cuRAND state is fully determined by the seed and the number of values generated since.
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint64(b[0:], uint64(g.seed))
+	binary.LittleEndian.PutUint64(b[8:], uint64(g.offset))
+	return b
+}
+
+// SetState restores a state obtained from State.
+func (g *Generator) SetState(state []byte) {
+	if CPU {
+		util.FatalErr(g.pcg.UnmarshalBinary(state))
+		return
+	}
+	if len(state) != 16 {
+		util.Fatal("random generator: invalid GPU state, was it saved by the CPU backend?")
+	}
+	g.SetSeed(int64(binary.LittleEndian.Uint64(state[0:])))
+	g.offset = int64(binary.LittleEndian.Uint64(state[8:]))
+	g.gpu.SetOffset(g.offset)
+}
@@ -31,15 +31,25 @@ func ShiftZ(dst, src *data.Slice, shiftZ int, clampL, clampR float32) {
 	k_shiftz_async(dst.DevPtr(0), src.DevPtr(0), N[X], N[Y], N[Z], shiftZ, clampL, clampR, cfg)
 }
 
-// Like Shift, but for bytes
-func ShiftBytes(dst, src *Bytes, m *data.Mesh, shiftX int, clamp byte) {
+// Like Shift, but for region bytes, wide or not
+func ShiftBytes(dst, src *Bytes, m *data.Mesh, shiftX int, clamp int) {
+	util.Argument(dst.Wide == src.Wide)
 	N := m.Size()
 	cfg := make3DConf(N)
-	k_shiftbytes_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftX, clamp, cfg)
+	if src.Wide {
+		k_shiftbytes16_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftX, uint16(clamp), cfg)
+	} else {
+		k_shiftbytes_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftX, byte(clamp), cfg)
+	}
 }
 
-func ShiftBytesY(dst, src *Bytes, m *data.Mesh, shiftY int, clamp byte) {
+func ShiftBytesY(dst, src *Bytes, m *data.Mesh, shiftY int, clamp int) {
+	util.Argument(dst.Wide == src.Wide)
 	N := m.Size()
 	cfg := make3DConf(N)
-	k_shiftbytesy_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftY, clamp, cfg)
+	if src.Wide {
+		k_shiftbytesy16_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftY, uint16(clamp), cfg)
+	} else {
+		k_shiftbytesy_async(dst.Ptr, src.Ptr, N[X], N[Y], N[Z], shiftY, byte(clamp), cfg)
+	}
 }
@@ -0,0 +1,98 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for shiftbytes16 kernel
+var shiftbytes16_code cu.Function
+
+// Stores the arguments for shiftbytes16 kernel invocation
+type shiftbytes16_args_t struct {
+	arg_dst   unsafe.Pointer
+	arg_src   unsafe.Pointer
+	arg_Nx    int
+	arg_Ny    int
+	arg_Nz    int
+	arg_shx   int
+	arg_clamp uint16
+	argptr    [7]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for shiftbytes16 kernel invocation
+var shiftbytes16_args shiftbytes16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	shiftbytes16_args.argptr[0] = unsafe.Pointer(&shiftbytes16_args.arg_dst)
+	shiftbytes16_args.argptr[1] = unsafe.Pointer(&shiftbytes16_args.arg_src)
+	shiftbytes16_args.argptr[2] = unsafe.Pointer(&shiftbytes16_args.arg_Nx)
+	shiftbytes16_args.argptr[3] = unsafe.Pointer(&shiftbytes16_args.arg_Ny)
+	shiftbytes16_args.argptr[4] = unsafe.Pointer(&shiftbytes16_args.arg_Nz)
+	shiftbytes16_args.argptr[5] = unsafe.Pointer(&shiftbytes16_args.arg_shx)
+	shiftbytes16_args.argptr[6] = unsafe.Pointer(&shiftbytes16_args.arg_clamp)
+}
+
+// Wrapper for shiftbytes16 CUDA kernel, asynchronous.
+func k_shiftbytes16_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clamp uint16, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftbytes16(dst, src, Nx, Ny, Nz, shx, clamp)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("shiftbytes16")
+	}
+
+	shiftbytes16_args.Lock()
+	defer shiftbytes16_args.Unlock()
+
+	if shiftbytes16_code == 0 {
+		shiftbytes16_code = fatbinLoad(shiftbytes16_map, "shiftbytes16")
+	}
+
+	shiftbytes16_args.arg_dst = dst
+	shiftbytes16_args.arg_src = src
+	shiftbytes16_args.arg_Nx = Nx
+	shiftbytes16_args.arg_Ny = Ny
+	shiftbytes16_args.arg_Nz = Nz
+	shiftbytes16_args.arg_shx = shx
+	shiftbytes16_args.arg_clamp = clamp
+
+	args := shiftbytes16_args.argptr[:]
+	cu.LaunchKernel(shiftbytes16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("shiftbytes16")
+	}
+}
+
+// maps compute capability on PTX code for shiftbytes16 kernel.
+var shiftbytes16_map = map[int]string{0: "",
+	20: shiftbytes16_ptx_20,
+	30: shiftbytes16_ptx_30,
+	35: shiftbytes16_ptx_35,
+	50: shiftbytes16_ptx_50,
+	52: shiftbytes16_ptx_52,
+	53: shiftbytes16_ptx_53}
+
+// shiftbytes16 PTX code for various compute capabilities.
+const (
+	shiftbytes16_ptx_20 = ``
+	shiftbytes16_ptx_30 = ``
+	shiftbytes16_ptx_35 = ``
+	shiftbytes16_ptx_50 = ``
+	shiftbytes16_ptx_52 = ``
+	shiftbytes16_ptx_53 = ``
+)
@@ -44,6 +44,11 @@ func init() {
 
 // Wrapper for shiftbytes CUDA kernel, asynchronous.
 func k_shiftbytes_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clamp byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftbytes(dst, src, Nx, Ny, Nz, shx, clamp)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("shiftbytes")
@@ -0,0 +1,98 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for shiftbytesy16 kernel
+var shiftbytesy16_code cu.Function
+
+// Stores the arguments for shiftbytesy16 kernel invocation
+type shiftbytesy16_args_t struct {
+	arg_dst   unsafe.Pointer
+	arg_src   unsafe.Pointer
+	arg_Nx    int
+	arg_Ny    int
+	arg_Nz    int
+	arg_shy   int
+	arg_clamp uint16
+	argptr    [7]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for shiftbytesy16 kernel invocation
+var shiftbytesy16_args shiftbytesy16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	shiftbytesy16_args.argptr[0] = unsafe.Pointer(&shiftbytesy16_args.arg_dst)
+	shiftbytesy16_args.argptr[1] = unsafe.Pointer(&shiftbytesy16_args.arg_src)
+	shiftbytesy16_args.argptr[2] = unsafe.Pointer(&shiftbytesy16_args.arg_Nx)
+	shiftbytesy16_args.argptr[3] = unsafe.Pointer(&shiftbytesy16_args.arg_Ny)
+	shiftbytesy16_args.argptr[4] = unsafe.Pointer(&shiftbytesy16_args.arg_Nz)
+	shiftbytesy16_args.argptr[5] = unsafe.Pointer(&shiftbytesy16_args.arg_shy)
+	shiftbytesy16_args.argptr[6] = unsafe.Pointer(&shiftbytesy16_args.arg_clamp)
+}
+
+// Wrapper for shiftbytesy16 CUDA kernel, asynchronous.
+func k_shiftbytesy16_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clamp uint16, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftbytesy16(dst, src, Nx, Ny, Nz, shy, clamp)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("shiftbytesy16")
+	}
+
+	shiftbytesy16_args.Lock()
+	defer shiftbytesy16_args.Unlock()
+
+	if shiftbytesy16_code == 0 {
+		shiftbytesy16_code = fatbinLoad(shiftbytesy16_map, "shiftbytesy16")
+	}
+
+	shiftbytesy16_args.arg_dst = dst
+	shiftbytesy16_args.arg_src = src
+	shiftbytesy16_args.arg_Nx = Nx
+	shiftbytesy16_args.arg_Ny = Ny
+	shiftbytesy16_args.arg_Nz = Nz
+	shiftbytesy16_args.arg_shy = shy
+	shiftbytesy16_args.arg_clamp = clamp
+
+	args := shiftbytesy16_args.argptr[:]
+	cu.LaunchKernel(shiftbytesy16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("shiftbytesy16")
+	}
+}
+
+// maps compute capability on PTX code for shiftbytesy16 kernel.
+var shiftbytesy16_map = map[int]string{0: "",
+	20: shiftbytesy16_ptx_20,
+	30: shiftbytesy16_ptx_30,
+	35: shiftbytesy16_ptx_35,
+	50: shiftbytesy16_ptx_50,
+	52: shiftbytesy16_ptx_52,
+	53: shiftbytesy16_ptx_53}
+
+// shiftbytesy16 PTX code for various compute capabilities.
+const (
+	shiftbytesy16_ptx_20 = ``
+	shiftbytesy16_ptx_30 = ``
+	shiftbytesy16_ptx_35 = ``
+	shiftbytesy16_ptx_50 = ``
+	shiftbytesy16_ptx_52 = ``
+	shiftbytesy16_ptx_53 = ``
+)
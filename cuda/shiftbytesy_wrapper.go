@@ -44,6 +44,11 @@ func init() {
 
 // Wrapper for shiftbytesy CUDA kernel, asynchronous.
 func k_shiftbytesy_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clamp byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftbytesy(dst, src, Nx, Ny, Nz, shy, clamp)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("shiftbytesy")
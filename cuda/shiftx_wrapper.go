@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for shiftx CUDA kernel, asynchronous.
 func k_shiftx_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shx int, clampL float32, clampR float32, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftx(dst, src, Nx, Ny, Nz, shx, clampL, clampR)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("shiftx")
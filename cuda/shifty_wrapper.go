@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for shifty CUDA kernel, asynchronous.
 func k_shifty_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shy int, clampL float32, clampR float32, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shifty(dst, src, Nx, Ny, Nz, shy, clampL, clampR)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("shifty")
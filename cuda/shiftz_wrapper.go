@@ -46,6 +46,11 @@ func init() {
 
 // Wrapper for shiftz CUDA kernel, asynchronous.
 func k_shiftz_async(dst unsafe.Pointer, src unsafe.Pointer, Nx int, Ny int, Nz int, shz int, clampL float32, clampR float32, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_shiftz(dst, src, Nx, Ny, Nz, shz, clampL, clampR)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("shiftz")
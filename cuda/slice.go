@@ -27,19 +27,30 @@ func newSlice(nComp int, size [3]int, alloc func(int64) unsafe.Pointer, memType
 	ptrs := make([]unsafe.Pointer, nComp)
 	for c := range ptrs {
 		ptrs[c] = unsafe.Pointer(alloc(bytes))
-		cu.MemsetD32(cu.DevicePtr(uintptr(ptrs[c])), 0, int64(length))
+		if !CPU { // CPU memory is already zeroed
+			cu.MemsetD32(cu.DevicePtr(uintptr(ptrs[c])), 0, int64(length))
+		}
 	}
 	return data.SliceFromPtrs(size, memType, ptrs)
 }
 
 // wrappers for data.EnableGPU arguments
 
-func memFree(ptr unsafe.Pointer) { cu.MemFree(cu.DevicePtr(uintptr(ptr))) }
+func memFree(ptr unsafe.Pointer) {
+	if CPU {
+		return // garbage collected
+	}
+	cu.MemFree(cu.DevicePtr(uintptr(ptr)))
+}
 
 func MemCpyDtoH(dst, src unsafe.Pointer, bytes int64) {
 	Sync() // sync previous kernels
 	timer.Start("memcpyDtoH")
-	cu.MemcpyDtoH(dst, cu.DevicePtr(uintptr(src)), bytes)
+	if CPU {
+		cpuMemcpy(dst, src, bytes)
+	} else {
+		cu.MemcpyDtoH(dst, cu.DevicePtr(uintptr(src)), bytes)
+	}
 	Sync() // sync copy
 	timer.Stop("memcpyDtoH")
 }
@@ -47,7 +58,11 @@ func MemCpyDtoH(dst, src unsafe.Pointer, bytes int64) {
 func MemCpyHtoD(dst, src unsafe.Pointer, bytes int64) {
 	Sync() // sync previous kernels
 	timer.Start("memcpyHtoD")
-	cu.MemcpyHtoD(cu.DevicePtr(uintptr(dst)), src, bytes)
+	if CPU {
+		cpuMemcpy(dst, src, bytes)
+	} else {
+		cu.MemcpyHtoD(cu.DevicePtr(uintptr(dst)), src, bytes)
+	}
 	Sync() // sync copy
 	timer.Stop("memcpyHtoD")
 }
@@ -55,7 +70,11 @@ func MemCpyHtoD(dst, src unsafe.Pointer, bytes int64) {
 func MemCpy(dst, src unsafe.Pointer, bytes int64) {
 	Sync()
 	timer.Start("memcpy")
-	cu.MemcpyAsync(cu.DevicePtr(uintptr(dst)), cu.DevicePtr(uintptr(src)), bytes, stream0)
+	if CPU {
+		cpuMemcpy(dst, src, bytes)
+	} else {
+		cu.MemcpyAsync(cu.DevicePtr(uintptr(dst)), cu.DevicePtr(uintptr(src)), bytes, stream0)
+	}
 	Sync()
 	timer.Stop("memcpy")
 }
@@ -69,6 +88,10 @@ func Memset(s *data.Slice, val ...float32) {
 	}
 	util.Argument(len(val) == s.NComp())
 	for c, v := range val {
+		if CPU {
+			cpuMemset(s.DevPtr(c), v, s.Len())
+			continue
+		}
 		cu.MemsetD32Async(cu.DevicePtr(uintptr(s.DevPtr(c))), math.Float32bits(v), int64(s.Len()), stream0)
 	}
 	if Synchronous { //debug
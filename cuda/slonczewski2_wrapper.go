@@ -84,6 +84,11 @@ func init() {
 
 // Wrapper for addslonczewskitorque2 CUDA kernel, asynchronous.
 func k_addslonczewskitorque2_async(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, jz_ unsafe.Pointer, jz_mul float32, px_ unsafe.Pointer, px_mul float32, py_ unsafe.Pointer, py_mul float32, pz_ unsafe.Pointer, pz_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, pol_ unsafe.Pointer, pol_mul float32, lambda_ unsafe.Pointer, lambda_mul float32, epsPrime_ unsafe.Pointer, epsPrime_mul float32, flt_ unsafe.Pointer, flt_mul float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_addslonczewskitorque2(tx, ty, tz, mx, my, mz, Ms_, Ms_mul, jz_, jz_mul, px_, px_mul, py_, py_mul, pz_, pz_mul, alpha_, alpha_mul, pol_, pol_mul, lambda_, lambda_mul, epsPrime_, epsPrime_mul, flt_, flt_mul, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("addslonczewskitorque2")
@@ -50,6 +50,11 @@ func init() {
 
 // Wrapper for settemperature2 CUDA kernel, asynchronous.
 func k_settemperature2_async(B unsafe.Pointer, noise unsafe.Pointer, kB2_VgammaDt float32, Ms_ unsafe.Pointer, Ms_mul float32, temp_ unsafe.Pointer, temp_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_settemperature2(B, noise, kB2_VgammaDt, Ms_, Ms_mul, temp_, temp_mul, alpha_, alpha_mul, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("settemperature2")
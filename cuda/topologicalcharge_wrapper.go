@@ -48,6 +48,11 @@ func init() {
 
 // Wrapper for settopologicalcharge CUDA kernel, asynchronous.
 func k_settopologicalcharge_async(s unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, icxcy float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_settopologicalcharge(s, mx, my, mz, icxcy, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("settopologicalcharge")
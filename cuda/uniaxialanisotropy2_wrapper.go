@@ -68,6 +68,11 @@ func init() {
 
 // Wrapper for adduniaxialanisotropy2 CUDA kernel, asynchronous.
 func k_adduniaxialanisotropy2_async(Bx unsafe.Pointer, By unsafe.Pointer, Bz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, K1_ unsafe.Pointer, K1_mul float32, K2_ unsafe.Pointer, K2_mul float32, ux_ unsafe.Pointer, ux_mul float32, uy_ unsafe.Pointer, uy_mul float32, uz_ unsafe.Pointer, uz_mul float32, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_adduniaxialanisotropy2(Bx, By, Bz, mx, my, mz, Ms_, Ms_mul, K1_, K1_mul, K2_, K2_mul, ux_, ux_mul, uy_, uy_mul, uz_, uz_mul, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("adduniaxialanisotropy2")
@@ -12,6 +12,10 @@ func ZeroMask(dst *data.Slice, mask LUTPtr, regions *Bytes) {
 	cfg := make1DConf(N)
 
 	for c := 0; c < dst.NComp(); c++ {
-		k_zeromask_async(dst.DevPtr(c), unsafe.Pointer(mask), regions.Ptr, N, cfg)
+		if regions.Wide {
+			k_zeromask16_async(dst.DevPtr(c), unsafe.Pointer(mask), regions.Ptr, N, cfg)
+		} else {
+			k_zeromask_async(dst.DevPtr(c), unsafe.Pointer(mask), regions.Ptr, N, cfg)
+		}
 	}
 }
@@ -0,0 +1,15 @@
+#include <stdint.h>
+#include "float3.h"
+
+// set dst to zero in cells where mask != 0
+// Same as zeromask, but with 16-bit region indices, used for more than 256 regions.
+extern "C" __global__ void
+zeromask16(float* __restrict__  dst, float* maskLUT, uint16_t* regions, int N) {
+
+    int i =  ( blockIdx.y*gridDim.x + blockIdx.x ) * blockDim.x + threadIdx.x;
+    if (i < N) {
+        if (maskLUT[regions[i]] != 0) {
+            dst[i] = 0;
+        }
+    }
+}
@@ -0,0 +1,89 @@
+package cuda
+
+/*
+ THIS FILE IS AUTO-GENERATED BY CUDA2GO.
+ EDITING IS FUTILE.
+*/
+
+import (
+	"github.com/mumax/3/cuda/cu"
+	"github.com/mumax/3/timer"
+	"sync"
+	"unsafe"
+)
+
+// CUDA handle for zeromask16 kernel
+var zeromask16_code cu.Function
+
+// Stores the arguments for zeromask16 kernel invocation
+type zeromask16_args_t struct {
+	arg_dst     unsafe.Pointer
+	arg_maskLUT unsafe.Pointer
+	arg_regions unsafe.Pointer
+	arg_N       int
+	argptr      [4]unsafe.Pointer
+	sync.Mutex
+}
+
+// Stores the arguments for zeromask16 kernel invocation
+var zeromask16_args zeromask16_args_t
+
+func init() {
+	// CUDA driver kernel call wants pointers to arguments, set them up once.
+	zeromask16_args.argptr[0] = unsafe.Pointer(&zeromask16_args.arg_dst)
+	zeromask16_args.argptr[1] = unsafe.Pointer(&zeromask16_args.arg_maskLUT)
+	zeromask16_args.argptr[2] = unsafe.Pointer(&zeromask16_args.arg_regions)
+	zeromask16_args.argptr[3] = unsafe.Pointer(&zeromask16_args.arg_N)
+}
+
+// Wrapper for zeromask16 CUDA kernel, asynchronous.
+func k_zeromask16_async(dst unsafe.Pointer, maskLUT unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_zeromask16(dst, maskLUT, regions, N)
+		return
+	}
+
+	if Synchronous { // debug
+		Sync()
+		timer.Start("zeromask16")
+	}
+
+	zeromask16_args.Lock()
+	defer zeromask16_args.Unlock()
+
+	if zeromask16_code == 0 {
+		zeromask16_code = fatbinLoad(zeromask16_map, "zeromask16")
+	}
+
+	zeromask16_args.arg_dst = dst
+	zeromask16_args.arg_maskLUT = maskLUT
+	zeromask16_args.arg_regions = regions
+	zeromask16_args.arg_N = N
+
+	args := zeromask16_args.argptr[:]
+	cu.LaunchKernel(zeromask16_code, cfg.Grid.X, cfg.Grid.Y, cfg.Grid.Z, cfg.Block.X, cfg.Block.Y, cfg.Block.Z, 0, stream0, args)
+
+	if Synchronous { // debug
+		Sync()
+		timer.Stop("zeromask16")
+	}
+}
+
+// maps compute capability on PTX code for zeromask16 kernel.
+var zeromask16_map = map[int]string{0: "",
+	20: zeromask16_ptx_20,
+	30: zeromask16_ptx_30,
+	35: zeromask16_ptx_35,
+	50: zeromask16_ptx_50,
+	52: zeromask16_ptx_52,
+	53: zeromask16_ptx_53}
+
+// zeromask16 PTX code for various compute capabilities.
+const (
+	zeromask16_ptx_20 = ``
+	zeromask16_ptx_30 = ``
+	zeromask16_ptx_35 = ``
+	zeromask16_ptx_50 = ``
+	zeromask16_ptx_52 = ``
+	zeromask16_ptx_53 = ``
+)
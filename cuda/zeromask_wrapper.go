@@ -38,6 +38,11 @@ func init() {
 
 // Wrapper for zeromask CUDA kernel, asynchronous.
 func k_zeromask_async(dst unsafe.Pointer, maskLUT unsafe.Pointer, regions unsafe.Pointer, N int, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_zeromask(dst, maskLUT, regions, N)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("zeromask")
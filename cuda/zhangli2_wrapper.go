@@ -84,6 +84,11 @@ func init() {
 
 // Wrapper for addzhanglitorque2 CUDA kernel, asynchronous.
 func k_addzhanglitorque2_async(tx unsafe.Pointer, ty unsafe.Pointer, tz unsafe.Pointer, mx unsafe.Pointer, my unsafe.Pointer, mz unsafe.Pointer, Ms_ unsafe.Pointer, Ms_mul float32, jx_ unsafe.Pointer, jx_mul float32, jy_ unsafe.Pointer, jy_mul float32, jz_ unsafe.Pointer, jz_mul float32, alpha_ unsafe.Pointer, alpha_mul float32, xi_ unsafe.Pointer, xi_mul float32, pol_ unsafe.Pointer, pol_mul float32, cx float32, cy float32, cz float32, Nx int, Ny int, Nz int, PBC byte, cfg *config) {
+	if CPU { // pure-Go backend, see cpu_*.go
+		cpu_addzhanglitorque2(tx, ty, tz, mx, my, mz, Ms_, Ms_mul, jx_, jx_mul, jy_, jy_mul, jz_, jz_mul, alpha_, alpha_mul, xi_, xi_mul, pol_, pol_mul, cx, cy, cz, Nx, Ny, Nz, PBC)
+		return
+	}
+
 	if Synchronous { // debug
 		Sync()
 		timer.Start("addzhanglitorque2")
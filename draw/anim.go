@@ -0,0 +1,161 @@
+package draw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	imgdraw "image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+// GIFAnim encodes frames as an endlessly looping animated GIF,
+// dithered to a 256-color palette. delay is the time per frame in 1/100 s.
+func GIFAnim(w io.Writer, frames []*image.RGBA, delay int) error {
+	anim := &gif.GIF{LoopCount: 0}
+	for _, f := range frames {
+		p := image.NewPaletted(f.Bounds(), palette.Plan9)
+		imgdraw.FloydSteinberg.Draw(p, f.Bounds(), f, image.Point{})
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// APNG encodes frames as an endlessly looping animated PNG.
+// delay is the time per frame in 1/100 s. All frames must have the same size.
+// Viewers without APNG support show the first frame.
+func APNG(w io.Writer, frames []*image.RGBA, delay int) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("apng: no frames")
+	}
+	size := frames[0].Bounds().Size()
+
+	// png.Encode drops the alpha channel of opaque images,
+	// but all frames need the color type of the first one.
+	alpha := false
+	for _, f := range frames {
+		alpha = alpha || !f.Opaque()
+	}
+
+	if _, err := w.Write([]byte(pngHeader)); err != nil {
+		return err
+	}
+	seq := uint32(0) // sequence number of fcTL and fdAT chunks
+	var ihdr []byte
+	for i, f := range frames {
+		if f.Bounds().Size() != size {
+			return fmt.Errorf("apng: frame %v has size %v, first frame %v", i, f.Bounds().Size(), size)
+		}
+		// encode as stand-alone PNG and re-use its chunks
+		var img image.Image = f
+		if alpha {
+			img = translucent{f}
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		var idat [][]byte
+		for _, c := range chunks {
+			switch c.typ {
+			case "IHDR":
+				if i == 0 {
+					ihdr = c.data
+					if err := writeChunk(w, "IHDR", ihdr); err != nil {
+						return err
+					}
+					actl := make([]byte, 8)
+					binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+					binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+					if err := writeChunk(w, "acTL", actl); err != nil {
+						return err
+					}
+				} else if !bytes.Equal(c.data, ihdr) {
+					return fmt.Errorf("apng: frame %v has a different color type than the first frame", i)
+				}
+			case "IDAT":
+				idat = append(idat, c.data)
+			}
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+		// x, y offset 0
+		binary.BigEndian.PutUint16(fctl[20:], uint16(delay))
+		binary.BigEndian.PutUint16(fctl[22:], 100) // delay denominator
+		// dispose op none, blend op source
+		seq++
+		if err := writeChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+
+		for _, d := range idat {
+			if i == 0 {
+				// the first frame is the default image
+				if err := writeChunk(w, "IDAT", d); err != nil {
+					return err
+				}
+				continue
+			}
+			fdat := make([]byte, 4+len(d))
+			binary.BigEndian.PutUint32(fdat, seq)
+			copy(fdat[4:], d)
+			seq++
+			if err := writeChunk(w, "fdAT", fdat); err != nil {
+				return err
+			}
+		}
+	}
+	return writeChunk(w, "IEND", nil)
+}
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// image that png.Encode always stores with alpha channel
+type translucent struct{ *image.RGBA }
+
+func (translucent) Opaque() bool { return false }
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// splits an encoded PNG into chunks.
+func pngChunks(b []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(b, []byte(pngHeader)) {
+		return nil, fmt.Errorf("apng: not a PNG")
+	}
+	b = b[len(pngHeader):]
+	var chunks []pngChunk
+	for len(b) >= 12 {
+		n := int(binary.BigEndian.Uint32(b))
+		if 12+n > len(b) {
+			return nil, fmt.Errorf("apng: truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{string(b[4:8]), b[8 : 8+n]})
+		b = b[12+n:]
+	}
+	return chunks, nil
+}
+
+func writeChunk(w io.Writer, typ string, data []byte) error {
+	b := make([]byte, 8+len(data)+4)
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	copy(b[4:], typ)
+	copy(b[8:], data)
+	binary.BigEndian.PutUint32(b[8+len(data):], crc32.ChecksumIEEE(b[4:8+len(data)]))
+	_, err := w.Write(b)
+	return err
+}
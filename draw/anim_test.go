@@ -0,0 +1,71 @@
+package draw
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func frame(c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// PNG color type (byte 9 of IHDR) and number of frames (acTL) of an APNG.
+func apngInfo(t *testing.T, b []byte) (colorType byte, nFrames int) {
+	chunks, err := pngChunks(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		switch c.typ {
+		case "IHDR":
+			colorType = c.data[9]
+		case "acTL":
+			nFrames = int(c.data[3])
+		}
+	}
+	return
+}
+
+// An opaque and a translucent frame must be stored with the same color type.
+func TestAPNGMixedAlpha(t *testing.T) {
+	opaque := frame(color.RGBA{255, 0, 0, 255})
+	translucent := frame(color.RGBA{0, 0, 128, 128})
+	for _, frames := range [][]*image.RGBA{{opaque, translucent}, {translucent, opaque}} {
+		var buf bytes.Buffer
+		if err := APNG(&buf, frames, 10); err != nil {
+			t.Fatal(err)
+		}
+		colorType, n := apngInfo(t, buf.Bytes())
+		if colorType != 6 || n != 2 {
+			t.Errorf("have color type %v and %v frames, want 6 (RGBA) and 2", colorType, n)
+		}
+
+		// first frame, as seen by viewers without APNG support
+		img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := img.At(1, 1), color.NRGBAModel.Convert(frames[0].At(1, 1)); have != want {
+			t.Errorf("first frame: have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestAPNGOpaque(t *testing.T) {
+	var buf bytes.Buffer
+	if err := APNG(&buf, []*image.RGBA{frame(color.RGBA{255, 0, 0, 255}), frame(color.RGBA{0, 255, 0, 255})}, 10); err != nil {
+		t.Fatal(err)
+	}
+	if colorType, _ := apngInfo(t, buf.Bytes()); colorType != 2 {
+		t.Errorf("have color type %v, want 2 (RGB)", colorType)
+	}
+}
@@ -0,0 +1,168 @@
+package draw
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	imgdraw "image/draw"
+	"math"
+	"strings"
+
+	"github.com/mumax/3/data"
+)
+
+// Annotation selects what AnnotatedImage draws around an image.
+type Annotation struct {
+	Title    string     // text above the image, e.g. quantity name and time
+	Unit     string     // unit for the color bar labels
+	Legend   bool       // color bar for scalar data, color wheel for vector data
+	CellSize [3]float64 // cell size (m), a scale bar is drawn if the x size is non-zero
+}
+
+// AutoTitle returns the quantity name, unit and time of info as a title,
+// e.g. "m_full (A/m), t = 1.250 ns".
+func AutoTitle(info data.Meta) string {
+	t := info.Name
+	if info.Unit != "" && info.Unit != "1" && info.Unit != "?" {
+		t += " (" + info.Unit + ")"
+	}
+	t += fmt.Sprintf(", t = %.3f ns", info.Time*1e9)
+	return strings.TrimPrefix(t, ", ")
+}
+
+// images narrower than this are enlarged, so that the annotations fit
+const minAnnotatedWidth = 256
+
+// AnnotatedImage renders f like Image, enlarged to at least 256 pixels wide,
+// on a white background with the title, legend and scale bar requested by a.
+func AnnotatedImage(f *data.Slice, fmin, fmax string, arrowSize int, a Annotation, colormap ...color.RGBA) *image.RGBA {
+	img := Image(f, fmin, fmax, arrowSize, colormap...)
+	factor := (minAnnotatedWidth-1)/img.Bounds().Dx() + 1
+	img = Enlarge(img, factor)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	const (
+		pad    = 6  // margin around the image and between elements
+		barW   = 12 // width of the color bar
+		wheelR = 24 // radius of the color wheel
+	)
+
+	top := pad
+	if a.Title != "" {
+		top += glyphH + pad
+	}
+
+	// legend right of the image
+	var legendW, legendH int
+	var min, max float32
+	var minLabel, maxLabel string
+	if a.Legend {
+		if f.NComp() == 1 {
+			min, max = scale(f, fmin, fmax)
+			minLabel, maxLabel = valueLabel(min, a.Unit), valueLabel(max, a.Unit)
+			legendW = pad + barW + 3 + imax(textWidth(minLabel), textWidth(maxLabel))
+			legendH = 3 * glyphH
+		} else {
+			legendW = pad + 2*wheelR + 2 + glyphW
+			legendH = glyphH + 2 + 2*wheelR
+		}
+	}
+	bodyH := imax(h, legendH)
+
+	// scale bar below the image
+	bottom := pad
+	scaleBar := a.CellSize[X] > 0
+	if scaleBar {
+		bottom += 3 + 2 + glyphH + pad
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, pad+w+legendW+pad, top+bodyH+bottom))
+	fill(canvas, canvas.Bounds(), white)
+	imgdraw.Draw(canvas, image.Rect(pad, top, pad+w, top+h), img, img.Bounds().Min, imgdraw.Src)
+
+	if a.Title != "" {
+		Text(canvas, pad, pad, a.Title, black)
+	}
+
+	x0 := pad + w + pad // left edge of the legend
+	if a.Legend && f.NComp() == 1 {
+		for j := 0; j < bodyH; j++ {
+			v := max - (max-min)*float32(j)/float32(imax(bodyH-1, 1))
+			fill(canvas, image.Rect(x0, top+j, x0+barW, top+j+1), ColorMap(min, max, v, colormap...))
+		}
+		Text(canvas, x0+barW+3, top, maxLabel, black)
+		Text(canvas, x0+barW+3, top+bodyH-glyphH, minLabel, black)
+	}
+	if a.Legend && f.NComp() == 3 {
+		cx, cy := x0+wheelR, top+glyphH+2+wheelR
+		for dy := -wheelR; dy <= wheelR; dy++ {
+			for dx := -wheelR; dx <= wheelR; dx++ {
+				x, y := float32(dx)/wheelR, float32(-dy)/wheelR
+				r2 := x*x + y*y
+				if r2 > 1 {
+					continue
+				}
+				canvas.Set(cx+dx, cy+dy, HSLMap(x, y, sqrtf(1-r2)))
+			}
+		}
+		Text(canvas, cx+wheelR+2, cy-glyphH/2, "x", black)
+		Text(canvas, cx-glyphW/2, top, "y", black)
+	}
+
+	if scaleBar {
+		perPixel := a.CellSize[X] / float64(factor)
+		length := niceLength(float64(w) / 4 * perPixel)
+		y0 := top + bodyH + pad
+		fill(canvas, image.Rect(pad, y0, pad+int(length/perPixel+0.5), y0+3), black)
+		Text(canvas, pad, y0+3+2, lengthLabel(length), black)
+	}
+	return canvas
+}
+
+// Enlarge scales up an image by an integer factor, without interpolation.
+func Enlarge(img *image.RGBA, factor int) *image.RGBA {
+	if factor <= 1 {
+		return img
+	}
+	b := img.Bounds()
+	big := image.NewRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < b.Dy()*factor; y++ {
+		for x := 0; x < b.Dx()*factor; x++ {
+			big.Set(x, y, img.At(b.Min.X+x/factor, b.Min.Y+y/factor))
+		}
+	}
+	return big
+}
+
+// color bar label, e.g. "8e+05 A/m". Dimensionless ("1") or unknown ("?") units are omitted.
+func valueLabel(v float32, unit string) string {
+	if unit == "1" || unit == "?" {
+		unit = ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.3g %s", v, unit))
+}
+
+// largest length of the form 1, 2 or 5 x 10^n not exceeding l.
+func niceLength(l float64) float64 {
+	e := math.Pow(10, math.Floor(math.Log10(l)))
+	for _, m := range []float64{5, 2} {
+		if m*e <= l {
+			return m * e
+		}
+	}
+	return e
+}
+
+// scale bar label, e.g. "200 nm".
+func lengthLabel(l float64) string {
+	units := []struct {
+		size float64
+		name string
+	}{{1, "m"}, {1e-3, "mm"}, {1e-6, "um"}, {1e-9, "nm"}, {1e-12, "pm"}}
+	for _, u := range units {
+		if l >= u.size*(1-1e-9) {
+			return fmt.Sprintf("%g %s", math.Floor(l/u.size+0.5), u.name)
+		}
+	}
+	return fmt.Sprintf("%g m", l)
+}
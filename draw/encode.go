@@ -25,13 +25,32 @@ func RenderFile(fname string, f *data.Slice, min, max string, arrowSize int, col
 }
 
 func RenderFormat(out io.Writer, f *data.Slice, min, max string, arrowSize int, format string, colormap ...color.RGBA) error {
+	enc, err := codecFor(format)
+	if err != nil {
+		return err
+	}
+	return Render(out, f, min, max, arrowSize, enc, colormap...)
+}
+
+// Encode img in the format given by the extension of format (e.g. "x.png", ".jpg").
+func EncodeFormat(out io.Writer, img image.Image, format string) error {
+	enc, err := codecFor(format)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(out)
+	defer buf.Flush()
+	return enc(buf, img)
+}
+
+func codecFor(format string) (codec, error) {
 	var codecs = map[string]codec{".png": PNG, ".jpg": JPEG100, ".gif": GIF256}
 	ext := strings.ToLower(path.Ext(format))
 	enc := codecs[ext]
 	if enc == nil {
-		return fmt.Errorf("render: unhandled image type: " + ext)
+		return nil, fmt.Errorf("render: unhandled image type: " + ext)
 	}
-	return Render(out, f, min, max, arrowSize, enc, colormap...)
+	return enc, nil
 }
 
 // encodes an image
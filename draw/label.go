@@ -0,0 +1,59 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+)
+
+var (
+	black = color.RGBA{A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// Label draws text in a 5x7 pixel font, white on a black box, with its top-left corner at x, y.
+// Supported are ASCII letters, digits and common punctuation, other characters are drawn as blanks.
+func Label(img *image.RGBA, x, y int, text string) {
+	const pad = 2
+	fill(img, image.Rect(x, y, x+textWidth(text)+2*pad, y+glyphH+2*pad), black)
+	Text(img, x+pad, y+pad, text, white)
+}
+
+// Text draws text in the 5x7 pixel font of Label, in color col, with its top-left corner at x, y.
+func Text(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	for n, r := range []rune(text) {
+		g, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		x0 := x + n*(glyphW+1)
+		for j, row := range g {
+			for i := 0; i < glyphW; i++ {
+				if row[i] == '#' {
+					img.Set(x0+i, y+j, col)
+				}
+			}
+		}
+	}
+}
+
+// TextSize returns the width and height in pixels of text drawn by Text.
+func TextSize(text string) (w, h int) {
+	return textWidth(text), glyphH
+}
+
+// width of text in pixels
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*(glyphW+1) - 1
+}
+
+func fill(img *image.RGBA, r image.Rectangle, col color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, col)
+		}
+	}
+}
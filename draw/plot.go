@@ -0,0 +1,111 @@
+package draw
+
+import (
+	"image"
+	"math"
+	"strconv"
+)
+
+// Axis is a linear plot axis from Min to Max, labeled e.g. "f (GHz)".
+type Axis struct {
+	Label    string
+	Min, Max float64
+}
+
+// Plot is an image with a framed plot area and tick-labeled axes.
+type Plot struct {
+	*image.RGBA
+	Area image.Rectangle // inside of the frame, where data is drawn
+	X, Y Axis
+}
+
+// NewPlot returns a white w x h pixel image with a title, a frame and ticks for axes x and y.
+func NewPlot(w, h int, title string, x, y Axis) *Plot {
+	x, y = x.widen(), y.widen()
+
+	xticks, yticks := ticks(x.Min, x.Max), ticks(y.Min, y.Max)
+	ylabelW := 0
+	for _, v := range yticks {
+		ylabelW = imax(ylabelW, textWidth(tickLabel(v)))
+	}
+
+	const tick = 4 // tick length in pixels
+	left := 4 + ylabelW + 3 + tick
+	top := 4 + 2*(glyphH+4) // title and y label
+	right := 4 + textWidth(tickLabel(xticks[len(xticks)-1]))/2
+	bottom := tick + 3 + glyphH + 4 + glyphH + 4 // tick labels and x label
+
+	p := &Plot{RGBA: image.NewRGBA(image.Rect(0, 0, w, h)), X: x, Y: y}
+	p.Area = image.Rect(left, top, w-right, h-bottom)
+	fill(p.RGBA, p.Bounds(), white)
+
+	a := p.Area
+	fill(p.RGBA, image.Rect(a.Min.X-1, a.Min.Y-1, a.Max.X+1, a.Min.Y), black)
+	fill(p.RGBA, image.Rect(a.Min.X-1, a.Max.Y, a.Max.X+1, a.Max.Y+1), black)
+	fill(p.RGBA, image.Rect(a.Min.X-1, a.Min.Y-1, a.Min.X, a.Max.Y+1), black)
+	fill(p.RGBA, image.Rect(a.Max.X, a.Min.Y-1, a.Max.X+1, a.Max.Y+1), black)
+
+	for _, v := range xticks {
+		px, _ := p.Pixel(v, y.Min)
+		fill(p.RGBA, image.Rect(px, a.Max.Y+1, px+1, a.Max.Y+1+tick), black)
+		l := tickLabel(v)
+		Text(p.RGBA, px-textWidth(l)/2, a.Max.Y+1+tick+3, l, black)
+	}
+	for _, v := range yticks {
+		_, py := p.Pixel(x.Min, v)
+		fill(p.RGBA, image.Rect(a.Min.X-1-tick, py, a.Min.X-1, py+1), black)
+		l := tickLabel(v)
+		Text(p.RGBA, a.Min.X-1-tick-3-textWidth(l), py-glyphH/2, l, black)
+	}
+
+	Text(p.RGBA, (a.Min.X+a.Max.X-textWidth(x.Label))/2, h-4-glyphH, x.Label, black)
+	Text(p.RGBA, 4, 4+glyphH+4, y.Label, black)
+	Text(p.RGBA, (w-textWidth(title))/2, 4, title, black)
+	return p
+}
+
+// Pixel returns the image coordinates of the point x, y.
+func (p *Plot) Pixel(x, y float64) (int, int) {
+	a := p.Area
+	px := a.Min.X + int(math.Floor((x-p.X.Min)/(p.X.Max-p.X.Min)*float64(a.Dx()-1)+0.5))
+	py := a.Max.Y - 1 - int(math.Floor((y-p.Y.Min)/(p.Y.Max-p.Y.Min)*float64(a.Dy()-1)+0.5))
+	return px, py
+}
+
+// avoids an empty axis range
+func (a Axis) widen() Axis {
+	if a.Max == a.Min {
+		a.Min -= 1
+		a.Max += 1
+	}
+	if a.Max < a.Min {
+		a.Min, a.Max = a.Max, a.Min
+	}
+	return a
+}
+
+// about 5 round tick values between min and max, spaced by 1, 2 or 5 x 10^n.
+func ticks(min, max float64) []float64 {
+	raw := (max - min) / 5
+	e := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * e
+	for _, m := range []float64{1, 2, 5} {
+		if m*e >= raw*0.7 {
+			step = m * e
+			break
+		}
+	}
+	var t []float64
+	for i := math.Ceil(min/step - 1e-9); i*step <= max+1e-9*step; i++ {
+		v := i * step
+		if math.Abs(v) < 1e-9*step {
+			v = 0
+		}
+		t = append(t, v)
+	}
+	return t
+}
+
+func tickLabel(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
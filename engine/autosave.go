@@ -25,6 +25,10 @@ func DoOutput() {
 	if Table.needSave() {
 		Table.Save()
 	}
+	if !relaxing && autoCheckpoint.needSave() {
+		autoCheckpoint.count++
+		Checkpoint(autoCheckpointFile)
+	}
 }
 
 // Register quant to be auto-saved every period.
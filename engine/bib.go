@@ -124,4 +124,39 @@ func buildLibrary() {
     url     = {http://dx.doi.org/10.1063/1.4883297}
 }`}
 
+	library["bessarab2015"] = &bibEntry{
+		reason: "Mumax3 used the geodesic nudged elastic band method",
+		bibtex: `
+@article{Bessarab2015,
+    author  = {Bessarab, Pavel F. and
+               Uzdin, Valery M. and
+               J{\'o}nsson, Hannes},
+    title   = {{Method for finding mechanism and activation energy of magnetic transitions,
+                applied to skyrmion and antivortex annihilation}},
+    journal = {Computer Physics Communications},
+    pages   = {335--347},
+    volume  = {196},
+    year    = {2015},
+    doi     = {10.1016/j.cpc.2015.07.001},
+    url     = {http://doi.org/10.1016/j.cpc.2015.07.001}
+}`}
+
+	library["henkelman2000"] = &bibEntry{
+		reason: "Mumax3 used the climbing image nudged elastic band method",
+		bibtex: `
+@article{Henkelman2000,
+    author  = {Henkelman, Graeme and
+               Uberuaga, Blas P. and
+               J{\'o}nsson, Hannes},
+    title   = {{A climbing image nudged elastic band method for finding saddle points
+                and minimum energy paths}},
+    journal = {The Journal of Chemical Physics},
+    number  = {22},
+    pages   = {9901--9904},
+    volume  = {113},
+    year    = {2000},
+    doi     = {10.1063/1.1329672},
+    url     = {http://doi.org/10.1063/1.1329672}
+}`}
+
 }
@@ -0,0 +1,565 @@
+package engine
+
+// Checkpoint/restart of a running simulation.
+//
+// Checkpoint() writes the full solver state to a zip archive holding a JSON
+// description (checkpoint.json) and the space-dependent state as OVF2 files.
+// Upon -resume, the input script is executed again from the top, but the
+// solver calls (Run, Steps, RunWhile, Relax, Minimize) that had completed
+// before the checkpoint are skipped and output is suppressed, until the point
+// where the checkpoint was taken. There, the state is restored and the
+// simulation continues as if it had never been interrupted.
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mumax/3/cuda"
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/oommf"
+	"github.com/mumax/3/util"
+)
+
+func init() {
+	DeclFunc("Checkpoint", Checkpoint, "Save the full simulation state to file, to be continued with the -resume flag")
+	DeclFunc("AutoCheckpoint", AutoCheckpoint, "Auto-save a checkpoint (checkpoint.zip) every period (s). Zero disables checkpoints.")
+}
+
+const (
+	checkpointVersion  = 1
+	autoCheckpointFile = "checkpoint.zip"
+)
+
+var (
+	resume         *checkpoint // checkpoint to be restored, nil when not resuming (anymore)
+	nRun           int         // number of completed top-level solver calls
+	runDepth       int         // nesting depth of solver calls (Minimize calls RunWhile)
+	runStart       runState    // state at the start of the current top-level solver call
+	nCheckpoint    int         // number of Checkpoint calls between solver calls
+	autoCheckpoint autosave    // when to auto-save checkpoints
+	resumeFile     string      // checkpoint file being resumed from
+)
+
+// files written by Checkpoint, relative to OD(), not to be rolled back upon resume
+var checkpoints = map[string]bool{autoCheckpointFile: true}
+
+// Time and step count at the start of a solver call.
+type runState struct {
+	Time   float64
+	NSteps int
+}
+
+// beginRun is called at the start of every solver call, followed by a deferred endRun.
+// It returns the time and step count at which the call started.
+// When resuming, ok is false for the top-level calls that had already completed
+// when the checkpoint was taken. They should be skipped, without calling endRun.
+func beginRun() (start runState, ok bool) {
+	if runDepth == 0 && resume != nil {
+		if nRun < resume.NRun {
+			nRun++
+			return runState{}, false
+		}
+		if !resume.InRun {
+			util.Fatal("resume: checkpoint not reached, does it belong to this input file?")
+		}
+		resume.restore()
+		runDepth++
+		return runStart, true
+	}
+	start = runState{Time, NSteps}
+	if runDepth == 0 {
+		runStart = start
+	}
+	runDepth++
+	return start, true
+}
+
+func endRun() {
+	runDepth--
+	if runDepth == 0 {
+		nRun++
+	}
+}
+
+// are we executing the input script up to the checkpoint being resumed?
+// Output is suppressed meanwhile.
+func replaying() bool {
+	return resume != nil
+}
+
+// called when the input script is done, to check if the checkpoint was restored.
+func checkResumed() {
+	if resume != nil {
+		util.Fatal("resume: end of input reached before the checkpoint, does it belong to this input file?")
+	}
+}
+
+// Checkpoint saves the complete simulation state, so that the simulation
+// can be continued later with the -resume flag.
+func Checkpoint(fname string) {
+	if replaying() {
+		if runDepth == 0 {
+			if nRun == resume.NRun && nCheckpoint == resume.NCheckpoint && !resume.InRun {
+				resume.restore()
+			}
+			nCheckpoint++
+		}
+		return
+	}
+	if relaxing {
+		util.Fatal("Checkpoint: not possible during Relax or Minimize")
+	}
+
+	if !strings.HasPrefix(fname, OD()) {
+		fname = OD() + fname
+	}
+	if path.Ext(fname) == "" {
+		fname += ".zip"
+	}
+	checkpoints[strings.TrimPrefix(fname, OD())] = true
+
+	// all output up to now should be on disk
+	drainOutput()
+	Table.flush()
+
+	newCheckpoint().write(fname)
+	if runDepth == 0 {
+		nCheckpoint++
+	}
+}
+
+// Register a checkpoint to be saved every period.
+// period == 0 stops auto-checkpointing.
+func AutoCheckpoint(period float64) {
+	autoCheckpoint = autosave{period, Time, 0, nil}
+}
+
+// Resume makes the input script continue from the checkpoint in fname.
+func Resume(fname string) {
+	LogOut("resuming from", fname)
+	resume = readCheckpoint(fname)
+	resumeFile = fname
+	for _, name := range resume.Checkpoints {
+		checkpoints[name] = true
+	}
+	if resume.Input != inputHash() {
+		LogOut("warning: input file has changed since the checkpoint")
+	}
+}
+
+// checkpoint holds the complete simulation state.
+// Exported fields are stored in checkpoint.json, slices as separate OVF files.
+type checkpoint struct {
+	Version                      int
+	Input                        string // SHA-1 of the input file
+	NRun, NCheckpoint            int
+	InRun                        bool // taken during a solver call, not between them
+	RunStart                     runState
+	Time, Dt_si, Alarm           float64
+	NSteps, NUndone, NEvals      int
+	LastErr, PeakErr, LastTorque float64
+	Solver                       int
+	TotalShift                   float64
+	Size                         [3]int
+	AutoSave                     map[string]autosaveState // by quantity name
+	AutoNum                      map[string]int           // by quantity name
+	AutoCheckpoint               autosaveState
+	Table                        tableState
+	Files                        map[string]int64       // sizes of the output files, relative to OD()
+	Series                       map[string]seriesState // OVF2 time series files, relative to OD()
+	Checkpoints                  []string               // files written by Checkpoint, relative to OD()
+	Therm                        thermState
+	Ext                          extState
+
+	slices map[string]*data.Slice // m, regions, geom, solver, therm
+	rng    []byte                 // thermal random generator state
+}
+
+type autosaveState struct {
+	Period, Start float64
+	Count         int
+}
+
+type tableState struct {
+	Autosave autosaveState
+	Inited   bool
+	Size     int64 // bytes written
+}
+
+// size and segment count of an OVF2 time series file
+type seriesState struct {
+	Size     int64
+	Segments int
+}
+
+type thermState struct {
+	Seed int64
+	Step int
+	Dt   float64
+}
+
+// state of the extensions
+type extState struct {
+	PrevBpos          [2]float64
+	BDist             float64
+	PrevBdist, PrevBt float64
+	LastShift, LastT  float64
+	LastV             float64
+}
+
+func (a *autosave) state() autosaveState {
+	return autosaveState{a.period, a.start, a.count}
+}
+
+func (a *autosave) setState(s autosaveState) {
+	a.period, a.start, a.count = s.Period, s.Start, s.Count
+}
+
+// snapshot of the current state
+func newCheckpoint() *checkpoint {
+	c := &checkpoint{
+		Version:     checkpointVersion,
+		Input:       inputHash(),
+		NRun:        nRun,
+		NCheckpoint: nCheckpoint,
+		InRun:       runDepth > 0,
+		RunStart:    runStart,
+		Time:        Time,
+		Dt_si:       Dt_si,
+		Alarm:       alarm,
+		NSteps:      NSteps,
+		NUndone:     NUndone,
+		NEvals:      NEvals,
+		LastErr:     LastErr,
+		PeakErr:     PeakErr,
+		LastTorque:  LastTorque,
+		Solver:      solvertype,
+		TotalShift:  TotalShift,
+		Size:        Mesh().Size(),
+
+		AutoSave:       make(map[string]autosaveState),
+		AutoNum:        make(map[string]int),
+		AutoCheckpoint: autoCheckpoint.state(),
+		Table:          tableState{Table.autosave.state(), Table.inited(), Table.size},
+		Files:          outputFiles(),
+		Series:         make(map[string]seriesState),
+		Therm:          thermState{B_therm.seed, B_therm.step, B_therm.dt},
+		Ext:            extState{prevBpos, bdist, prevBdist, prevBt, lastShift, lastT, lastV},
+
+		slices: make(map[string]*data.Slice),
+	}
+
+	for q, a := range output {
+		c.AutoSave[NameOf(q)] = a.state()
+	}
+	for q, n := range autonum {
+		name := NameOf(q.(Quantity))
+		if n > c.AutoNum[name] {
+			c.AutoNum[name] = n
+		}
+	}
+	for name := range checkpoints {
+		c.Checkpoints = append(c.Checkpoints, name)
+	}
+	sort.Strings(c.Checkpoints)
+	for fname, n := range seriesFiles {
+		name := strings.TrimPrefix(fname, OD())
+		c.Series[name] = seriesState{c.Files[name], n}
+		delete(c.Files, name)
+	}
+
+	c.slices["m"] = M.Buffer().HostCopy()
+	c.slices["regions"] = regionsToSlice(regions.HostList(), c.Size)
+	if !geometry.Gpu().IsNil() {
+		c.slices["geom"] = geometry.Gpu().HostCopy()
+	}
+	if k := stepperState(); k != nil && *k != nil {
+		c.slices["solver"] = (*k).HostCopy()
+	}
+	if B_therm.noise != nil {
+		c.slices["therm"] = B_therm.noise.HostCopy()
+	}
+	if B_therm.generator != nil {
+		c.rng = B_therm.generator.State()
+	}
+	return c
+}
+
+// restores the state and stops replaying the input script.
+func (c *checkpoint) restore() {
+	if c.Size != Mesh().Size() {
+		util.Fatal("resume: checkpoint has mesh size ", c.Size, ", input script ", Mesh().Size())
+	}
+	size := c.Size
+
+	Time, Dt_si, alarm = c.Time, c.Dt_si, c.Alarm
+	NSteps, NUndone, NEvals = c.NSteps, c.NUndone, c.NEvals
+	LastErr, PeakErr, LastTorque = c.LastErr, c.PeakErr, c.LastTorque
+	TotalShift = c.TotalShift
+	runStart = c.RunStart
+
+	data.Copy(M.Buffer(), c.slices["m"])
+	regions.upload(sliceToRegions(c.slices["regions"]))
+	if g, ok := c.slices["geom"]; ok {
+		if geometry.Gpu().IsNil() {
+			geometry.buffer = cuda.NewSlice(1, size)
+		}
+		data.Copy(geometry.buffer, g)
+	}
+
+	if solvertype != c.Solver {
+		SetSolver(c.Solver)
+	}
+	if k := stepperState(); k != nil {
+		if *k != nil {
+			(*k).Free()
+			*k = nil
+		}
+		if s, ok := c.slices["solver"]; ok {
+			*k = cuda.NewSlice(s.NComp(), size)
+			data.Copy(*k, s)
+		}
+	}
+
+	// autosave bookkeeping is matched by quantity name
+	for q, a := range output {
+		if s, ok := c.AutoSave[NameOf(q)]; ok {
+			a.setState(s)
+		}
+		if n, ok := c.AutoNum[NameOf(q)]; ok {
+			autonum[q] = n
+		}
+	}
+	for q := range autonum {
+		if n, ok := c.AutoNum[NameOf(q.(Quantity))]; ok {
+			autonum[q] = n
+		}
+	}
+	autoCheckpoint.setState(c.AutoCheckpoint)
+
+	c.restoreFiles()
+	Table.autosave.setState(c.Table.Autosave)
+	if c.Table.Inited {
+		Table.resume(c.Table.Size)
+	}
+
+	B_therm.seed, B_therm.step, B_therm.dt = c.Therm.Seed, c.Therm.Step, c.Therm.Dt
+	if n, ok := c.slices["therm"]; ok {
+		if B_therm.noise == nil {
+			B_therm.noise = cuda.NewSlice(3, size)
+		}
+		data.Copy(B_therm.noise, n)
+	}
+	B_therm.generator = nil
+	if c.rng != nil {
+		B_therm.generator = cuda.NewGenerator(c.Therm.Seed)
+		B_therm.generator.SetState(c.rng)
+	}
+
+	e := c.Ext
+	prevBpos, bdist, prevBdist, prevBt = e.PrevBpos, e.BDist, e.PrevBdist, e.PrevBt
+	lastShift, lastT, lastV = e.LastShift, e.LastT, e.LastV
+
+	resume = nil
+	LogOut("resumed at t =", Time, "s, step", NSteps)
+}
+
+// returns a pointer to the state the current stepper keeps between steps, if any.
+func stepperState() **data.Slice {
+	switch s := stepper.(type) {
+	case *RK45DP:
+		return &s.k1
+	case *RK23:
+		return &s.k1
+	case *BackwardEuler:
+		return &s.dy1
+	}
+	return nil
+}
+
+func (c *checkpoint) write(fname string) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+
+	w, err := z.Create("checkpoint.json")
+	util.FatalErr(err)
+	js, err := json.MarshalIndent(c, "", "\t")
+	util.FatalErr(err)
+	_, err = w.Write(js)
+	util.FatalErr(err)
+
+	names := make([]string, 0, len(c.slices))
+	for name := range c.slices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := z.Create(name + ".ovf")
+		util.FatalErr(err)
+		info := data.Meta{Time: c.Time, Name: name, CellSize: Mesh().CellSize()}
+		oommf.WriteOVF2(w, c.slices[name], info, "binary 4")
+	}
+
+	if c.rng != nil {
+		w, err := z.Create("rng")
+		util.FatalErr(err)
+		_, err = w.Write(c.rng)
+		util.FatalErr(err)
+	}
+
+	util.FatalErr(z.Close())
+	util.FatalErr(httpfs.Put(fname, buf.Bytes()))
+}
+
+func readCheckpoint(fname string) *checkpoint {
+	b, err := httpfs.Read(fname)
+	util.FatalErr(err)
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		util.Fatal("resume: ", fname, ": ", err)
+	}
+
+	c := &checkpoint{slices: make(map[string]*data.Slice)}
+	haveJSON := false
+	for _, f := range z.File {
+		r, err := f.Open()
+		util.FatalErr(err)
+		switch ext := path.Ext(f.Name); {
+		case f.Name == "checkpoint.json":
+			util.FatalErr(json.NewDecoder(r).Decode(c))
+			haveJSON = true
+		case f.Name == "rng":
+			c.rng, err = ioutil.ReadAll(r)
+			util.FatalErr(err)
+		case ext == ".ovf":
+			s, _, err := oommf.Read(r)
+			util.FatalErr(err)
+			c.slices[strings.TrimSuffix(f.Name, ext)] = s
+		}
+		r.Close()
+	}
+
+	if !haveJSON || c.slices["m"] == nil || c.slices["regions"] == nil {
+		util.Fatal("resume: ", fname, " is not a valid checkpoint")
+	}
+	if c.Version != checkpointVersion {
+		util.Fatal("resume: ", fname, ": unsupported checkpoint version ", c.Version)
+	}
+	return c
+}
+
+// sizes of the files in the output directory, by name relative to OD().
+// The log and checkpoints are not output to be rolled back upon resume.
+func outputFiles() map[string]int64 {
+	names, err := httpfs.ReadDir(OD())
+	util.FatalErr(err)
+	files := make(map[string]int64)
+	for _, name := range names {
+		if !isOutputFile(name) {
+			continue
+		}
+		if size, err := httpfs.Size(OD() + name); err == nil { // not a directory
+			files[name] = size
+		}
+	}
+	return files
+}
+
+func isOutputFile(name string) bool {
+	return name != "log.txt" && !checkpoints[name] && path.Clean(OD()+name) != path.Clean(resumeFile)
+}
+
+// brings the output directory back to the state at the checkpoint:
+// output files are truncated to their size at that time, OVF2 time series
+// also get their segment count back. Files that did not exist yet are removed.
+func (c *checkpoint) restoreFiles() {
+	names, err := httpfs.ReadDir(OD())
+	util.FatalErr(err)
+	for _, name := range names {
+		if !isOutputFile(name) {
+			continue
+		}
+		fname := OD() + name
+		size, ok := c.Files[name]
+		series, isSeries := c.Series[name]
+		switch {
+		case ok:
+			truncate(fname, size)
+		case isSeries:
+			truncate(fname, series.Size)
+			util.FatalErr(oommf.SetSegmentCount(httpfsWriterAt(fname), series.Segments))
+			seriesFiles[fname] = series.Segments
+		case isFile(fname):
+			LogOut("resume: removing", fname, "written after the checkpoint")
+			util.FatalErr(httpfs.Remove(fname))
+		}
+	}
+	for name := range c.Files {
+		checkExists(OD() + name)
+	}
+	for name := range c.Series {
+		checkExists(OD() + name)
+	}
+}
+
+func checkExists(fname string) {
+	if !isFile(fname) {
+		util.Fatal("resume: ", fname, " has been removed since the checkpoint")
+	}
+}
+
+func isFile(fname string) bool {
+	_, err := httpfs.Size(fname)
+	return err == nil
+}
+
+// truncate file to size, dropping output written after a checkpoint.
+func truncate(fname string, size int64) {
+	have, err := httpfs.Size(fname)
+	util.FatalErr(err)
+	if have == size {
+		return
+	}
+	if have < size {
+		util.Fatal("resume: ", fname, " is shorter than at checkpoint")
+	}
+	b, err := httpfs.Read(fname)
+	util.FatalErr(err)
+	util.FatalErr(httpfs.Put(fname, b[:size]))
+}
+
+// SHA-1 of the input file, empty if not available.
+func inputHash() string {
+	b, err := httpfs.Read(InputFile)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha1.Sum(b))
+}
+
+// region indices stored losslessly as float32
+func regionsToSlice(b []uint16, size [3]int) *data.Slice {
+	s := data.NewSlice(1, size)
+	l := s.Host()[0]
+	for i := range l {
+		l[i] = float32(b[i])
+	}
+	return s
+}
+
+func sliceToRegions(s *data.Slice) []uint16 {
+	l := s.Host()[0]
+	b := make([]uint16, len(l))
+	for i := range l {
+		b[i] = uint16(l[i])
+	}
+	return b
+}
@@ -0,0 +1,260 @@
+package engine
+
+// Interactive debugger for input files, see mumax3 -debug.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mumax/3/script"
+	"github.com/mumax/3/util"
+)
+
+const debugHelp = `commands:
+  break <line>     stop before executing the statements on a line of the input file
+  break <cond>     stop when a condition becomes true, e.g.: break t > 2e-9
+  break            list breakpoints
+  delete <n>       delete breakpoint n, or all breakpoints when n is omitted
+  step             execute the next statement and stop
+  continue         run until the next breakpoint
+  where            show the statement to be executed next
+  quit             stop the simulation
+  help             show this help
+anything else is executed as script code, values are printed, e.g.:
+  m.average()
+  maxTorque
+  save(m)
+  Msat = 900e3
+commands may be abbreviated to their first letter.`
+
+// debugger state, nil unless Debug was called.
+var dbg *debugger
+
+type debugger struct {
+	in       *bufio.Scanner
+	breaks   []*breakpoint
+	stepping bool   // stop before the next statement
+	prompt   bool   // at the prompt, don't stop in code entered there
+	line     int    // line of the statement being executed
+	stmt     string // statement being executed
+}
+
+// breakpoint on a line, or on a condition
+type breakpoint struct {
+	line int         // stop before the statements on this line, if cond is nil
+	cond script.Expr // stop when cond becomes true
+	src  string      // condition as entered
+	was  bool        // value of cond when last checked
+}
+
+func (b *breakpoint) String() string {
+	if b.cond != nil {
+		return b.src
+	}
+	return fmt.Sprint("line ", b.line)
+}
+
+// Debug enables the debugger: it reads commands from stdin before the first statement,
+// and whenever a breakpoint is hit, either before a statement or in between time steps.
+func Debug() {
+	if dbg != nil {
+		return
+	}
+	dbg = &debugger{in: bufio.NewScanner(os.Stdin), stepping: true}
+	script.StmtHook = dbg.beforeStmt
+	PostStep(dbg.afterStep)
+	fmt.Println("//debugging, type help for a list of commands")
+}
+
+// called before every statement of the input file.
+func (d *debugger) beforeStmt(b *script.BlockStmt, i int) {
+	if d.prompt {
+		return
+	}
+	d.line, d.stmt = b.Line[i], rmln(script.Format(b.Node[i]))
+	if d.stepping {
+		d.stop("")
+		return
+	}
+	for n, bp := range d.breaks {
+		if bp.cond == nil && bp.line == d.line && d.line != 0 {
+			d.stop(fmt.Sprint("breakpoint ", n+1, " (", bp, ")"))
+			return
+		}
+	}
+	d.checkConds()
+}
+
+// called after every time step.
+func (d *debugger) afterStep() {
+	if !d.prompt {
+		d.checkConds()
+	}
+}
+
+// stops if a condition breakpoint became true.
+func (d *debugger) checkConds() {
+	for n, bp := range d.breaks {
+		if bp.cond == nil {
+			continue
+		}
+		now := bp.cond.Eval().(bool)
+		hit := now && !bp.was
+		bp.was = now
+		if hit {
+			d.stop(fmt.Sprint("breakpoint ", n+1, " (", bp, ") at t=", Time))
+			return
+		}
+	}
+}
+
+// stops the run and reads commands until step or continue.
+func (d *debugger) stop(reason string) {
+	d.prompt = true
+	defer func() { d.prompt = false }()
+	d.stepping = false
+	if reason != "" {
+		fmt.Println("//" + reason)
+	}
+	d.where()
+	for {
+		fmt.Print("(debug) ")
+		if !d.in.Scan() {
+			fmt.Println()
+			d.disable() // end of input, finish the run
+			return
+		}
+		cmd := strings.TrimSpace(d.in.Text())
+		if cmd == "" {
+			continue
+		}
+		word, arg := cmd, ""
+		if i := strings.IndexAny(cmd, " \t"); i > 0 {
+			word, arg = cmd[:i], strings.TrimSpace(cmd[i:])
+		}
+		switch word {
+		default:
+			d.exec(cmd)
+		case "c", "continue":
+			return
+		case "s", "step":
+			d.stepping = true
+			return
+		case "b", "break":
+			d.addBreak(arg)
+		case "d", "delete":
+			d.deleteBreak(arg)
+		case "w", "where":
+			d.where()
+		case "q", "quit":
+			Exit()
+		case "h", "help":
+			fmt.Println(debugHelp)
+		}
+	}
+}
+
+func (d *debugger) where() {
+	if d.line != 0 {
+		fmt.Print("//line ", d.line, ": ", d.stmt, "\n")
+	} else if d.stmt != "" {
+		fmt.Print("//", d.stmt, "\n")
+	}
+}
+
+func (d *debugger) addBreak(arg string) {
+	if arg == "" {
+		if len(d.breaks) == 0 {
+			fmt.Println("//no breakpoints")
+		}
+		for n, bp := range d.breaks {
+			fmt.Print("//", n+1, ": ", bp, "\n")
+		}
+		return
+	}
+	bp := &breakpoint{src: arg}
+	if line, err := strconv.Atoi(arg); err == nil {
+		bp.line = line
+	} else {
+		cond, err := World.CompileExpr(arg)
+		if err != nil {
+			LogErr(err.Error())
+			return
+		}
+		if cond.Type() != reflect.TypeOf(false) {
+			LogErr("breakpoint condition should be bool: ", arg)
+			return
+		}
+		bp.cond = cond
+		if !try(func() { bp.was = cond.Eval().(bool) }) {
+			return
+		}
+	}
+	d.breaks = append(d.breaks, bp)
+	fmt.Print("//breakpoint ", len(d.breaks), ": ", bp, "\n")
+}
+
+func (d *debugger) deleteBreak(arg string) {
+	if arg == "" {
+		d.breaks = nil
+		return
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(d.breaks) {
+		LogErr("no breakpoint ", arg)
+		return
+	}
+	d.breaks = append(d.breaks[:n-1], d.breaks[n:]...)
+}
+
+// executes code entered at the prompt and prints the values of expressions.
+func (d *debugger) exec(code string) {
+	tree, err := World.Compile(code)
+	if err != nil {
+		LogErr(err.Error())
+		return
+	}
+	ok := try(func() {
+		for i, stmt := range tree.Children {
+			v := stmt.Eval()
+			if stmt.Type() == nil || v == nil {
+				LogIn(rmln(script.Format(tree.Node[i]))) // changes state, log for reproducibility
+				continue
+			}
+			if q, ok := v.(Quantity); ok {
+				v = AverageOf(q)
+			}
+			fmt.Print("//", v, "\n")
+		}
+	})
+	if !ok {
+		LogErr("the command stopped half-way, the simulation state may be inconsistent")
+	}
+}
+
+// runs f, reporting errors instead of exiting (e.g. bad argument), so that
+// a mistake at the prompt does not kill the session. Returns false on error.
+func try(f func()) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			LogErr(err)
+			ok = false
+		}
+	}()
+	if err := util.CatchFatal(f); err != nil {
+		LogErr(err)
+		return false
+	}
+	return true
+}
+
+// removes breakpoints and lets the simulation finish.
+func (d *debugger) disable() {
+	d.breaks = nil
+	d.stepping = false
+	script.StmtHook = nil
+}
@@ -0,0 +1,561 @@
+package engine
+
+// Linearized LLG eigenmodes around equilibrium.
+//
+// Small deviations δm from the equilibrium m0 are written in a local frame
+// e1, e2 perpendicular to m0. Their dynamics is d(δm)/dt = J δm, with
+// 	J = γ/(1+α²) (Ω - α) K,
+// where Ω rotates by 90° around m0 (m0 x ...) and K the stiffness operator
+// 	K δm = (m0·B0) δm - [δB]⊥,
+// with δB the change of the effective field, evaluated by central finite differences.
+// K is symmetric with respect to the Msat-weighted inner product, and positive at stable equilibrium.
+// The eigenvalues λ = -Γ + iω of J with lowest |λ| are found by Arnoldi iteration on J⁻¹
+// (shift-and-invert around 0), where K is inverted by conjugate gradients.
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+
+	"github.com/mumax/3/cuda"
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/util"
+)
+
+var (
+	EigenmodeTol float64   = 1e-4 // relative accuracy of eigenvalues
+	eigenFreq    []float64        // frequencies found by the last Eigenmodes call (Hz)
+)
+
+func init() {
+	DeclFunc("Eigenmodes", Eigenmodes, "Compute the n lowest-frequency spin-wave eigenmodes around the current, relaxed, magnetization")
+	DeclVar("EigenmodeTol", &EigenmodeTol, "Relative accuracy of Eigenmodes frequencies")
+	DeclFunc("EigenmodeFreq", EigenmodeFreq, "Frequency (Hz) of eigenmode i, found by the last Eigenmodes call")
+}
+
+// EigenmodeFreq returns the frequency of mode i found by the last call to Eigenmodes.
+func EigenmodeFreq(i int) float64 {
+	if i < 0 || i >= len(eigenFreq) {
+		util.Fatal("EigenmodeFreq: have", len(eigenFreq), "eigenmodes, need mode", i)
+	}
+	return eigenFreq[i]
+}
+
+// Eigenmodes linearizes the LLG equation around the current magnetization,
+// which should be relaxed, and computes its n lowest-frequency eigenmodes.
+// The frequencies and linewidths (due to damping) are written to eigenmodes.txt,
+// the mode profiles to eigenmodeXXXXXX_amp.ovf and eigenmodeXXXXXX_phase.ovf.
+// The magnetization is left unchanged.
+func Eigenmodes(n int) {
+	if n < 1 {
+		util.Fatal("Eigenmodes: need at least 1 mode, have", n)
+	}
+	SanityCheck()
+
+	prevM := M.Buffer().HostCopy()
+	defer func() {
+		data.Copy(M.Buffer(), prevM)
+		relaxing = false
+	}()
+	relaxing = true // disable temperature noise
+
+	l := newLinearLLG(prevM)
+	if len(l.cells) == 0 {
+		util.Fatal("Eigenmodes: no free magnetization")
+	}
+	if τ := l.maxTorque(); τ > 1e-3 {
+		LogErr("Eigenmodes: magnetization does not seem relaxed, max torque:", τ, "T")
+	}
+
+	λ, u := l.arnoldi(n)
+
+	info := data.Meta{Time: Time, CellSize: Mesh().CellSize()}
+	f, err := httpfs.Create(OD() + "eigenmodes.txt")
+	util.FatalErr(err)
+	defer f.Close()
+	table := bufio.NewWriter(f)
+	defer table.Flush()
+	fprintln(table, "# mode ()\tf (Hz)\tlinewidth (Hz)\tdecay (1/s)")
+	eigenFreq = eigenFreq[:0]
+	for i := range λ {
+		freq := imag(λ[i]) / (2 * math.Pi)
+		eigenFreq = append(eigenFreq, freq)
+		decay := -real(λ[i])
+		fwhm := decay / math.Pi
+		fprint(table, i, "\t", float32(freq), "\t", float32(fwhm), "\t", float32(decay), "\n")
+		LogOut("Eigenmode", i, ": f =", float32(freq), "Hz, linewidth =", float32(fwhm), "Hz")
+		if decay < 0 {
+			LogErr("Eigenmodes: mode", i, "grows in time, magnetization is not in a stable equilibrium")
+		}
+
+		amp, phase := l.profile(u[i])
+		base, ext := OD()+fmt.Sprintf(FilenameFormat, "eigenmode", i), "."+StringFromOutputFormat[outputFormat]
+		ampInfo, phaseInfo := info, info
+		ampInfo.Name, phaseInfo.Name, phaseInfo.Unit = "eigenmode_amp", "eigenmode_phase", "rad"
+		queOutput(func() { saveAs_sync(base+"_amp"+ext, amp, ampInfo, outputFormat) })
+		queOutput(func() { saveAs_sync(base+"_phase"+ext, phase, phaseInfo, outputFormat) })
+	}
+}
+
+// LLG linearized around equilibrium m0.
+// Vectors hold 2 components (along e1, e2) for each free cell.
+type linearLLG struct {
+	m0     *data.Slice   // equilibrium magnetization (host)
+	cells  []int         // indices of free cells
+	e1, e2 []data.Vector // local frame, for each free cell
+	b0     []float64     // m0·B0 (T)
+	w      []float64     // Msat, weight for inner product
+	alpha  []float64     // damping
+	m      *data.Slice   // perturbed magnetization (host)
+	Bp, Bm *data.Slice   // effective field for positive and negative perturbation (host)
+	B      *data.Slice   // effective field (gpu)
+	nEval  int           // number of stiffness evaluations
+}
+
+func newLinearLLG(m0 *data.Slice) *linearLLG {
+	size := m0.Size()
+	l := &linearLLG{m0: m0, m: m0.HostCopy(), Bp: data.NewSlice(3, size), Bm: data.NewSlice(3, size), B: cuda.NewSlice(3, size)}
+
+	msat := ValueOf(Msat)
+	defer cuda.Recycle(msat)
+	alpha := ValueOf(Alpha)
+	defer cuda.Recycle(alpha)
+	free := cuda.Buffer(1, size)
+	defer cuda.Recycle(free)
+	cuda.Memset(free, 1)
+	FreezeSpins(free)
+	Ms, α, ok := msat.HostCopy().Host()[0], alpha.HostCopy().Host()[0], free.HostCopy().Host()[0]
+
+	data.Copy(M.Buffer(), m0)
+	SetEffectiveField(l.B)
+	B := l.B.HostCopy().Host()
+	m := m0.Host()
+	for c := range m[X] {
+		m0 := vec3(m, c)
+		if m0.Len() == 0 || Ms[c] == 0 || ok[c] == 0 {
+			continue
+		}
+		e1 := data.Vector{1, 0, 0}
+		if math.Abs(m0[X]) > 0.5 {
+			e1 = data.Vector{0, 1, 0}
+		}
+		e1 = e1.Sub(m0.Mul(e1.Dot(m0)))
+		e1 = e1.Div(e1.Len())
+		l.cells = append(l.cells, c)
+		l.e1 = append(l.e1, e1)
+		l.e2 = append(l.e2, m0.Cross(e1))
+		l.b0 = append(l.b0, m0.Dot(vec3(B, c)))
+		l.w = append(l.w, float64(Ms[c]))
+		l.alpha = append(l.alpha, float64(α[c]))
+	}
+	return l
+}
+
+// number of degrees of freedom
+func (l *linearLLG) len() int { return 2 * len(l.cells) }
+
+// max |m0 x B0| over free cells, in T
+func (l *linearLLG) maxTorque() float64 {
+	max := 0.
+	B, m := l.B.HostCopy().Host(), l.m0.Host()
+	for _, c := range l.cells {
+		max = math.Max(max, vec3(m, c).Cross(vec3(B, c)).Len())
+	}
+	return max
+}
+
+// dst = K x
+func (l *linearLLG) stiffness(dst, x []float64) {
+	// scale perturbation so that the largest one is ε:
+	// large enough for single precision, small enough for non-linear fields.
+	const ε = 1e-2
+	s := 0.
+	for i := range l.cells {
+		s = math.Max(s, math.Hypot(x[2*i], x[2*i+1]))
+	}
+	if s == 0 {
+		for i := range dst {
+			dst[i] = 0
+		}
+		return
+	}
+	for _, p := range []struct {
+		sign float64
+		B    *data.Slice
+	}{{ε / s, l.Bp}, {-ε / s, l.Bm}} {
+		data.Copy(l.m, l.m0)
+		m := l.m.Host()
+		for i, c := range l.cells {
+			δ := l.e1[i].Mul(x[2*i]).Add(l.e2[i].Mul(x[2*i+1])).Mul(p.sign)
+			for k := 0; k < 3; k++ {
+				m[k][c] += float32(δ[k])
+			}
+		}
+		data.Copy(M.Buffer(), l.m)
+		SetEffectiveField(l.B)
+		data.Copy(p.B, l.B)
+	}
+	l.nEval++
+	NEvals += 2
+
+	Bp, Bm := l.Bp.Host(), l.Bm.Host()
+	for i, c := range l.cells {
+		δB := vec3(Bp, c).Sub(vec3(Bm, c)).Mul(s / (2 * ε))
+		dst[2*i] = l.b0[i]*x[2*i] - l.e1[i].Dot(δB)
+		dst[2*i+1] = l.b0[i]*x[2*i+1] - l.e2[i].Dot(δB)
+	}
+}
+
+// dst = J⁻¹ y = -1/γ K⁻¹ (Ω + α) y
+func (l *linearLLG) inverse(dst, y []float64) {
+	b := make([]float64, len(y))
+	for i := range l.cells {
+		a, c := y[2*i], y[2*i+1]
+		b[2*i] = -(-c + l.alpha[i]*a) / GammaLL
+		b[2*i+1] = -(a + l.alpha[i]*c) / GammaLL
+	}
+	l.solve(dst, b)
+}
+
+// solve K x = b by conjugate gradients, in the Msat-weighted inner product.
+func (l *linearLLG) solve(x, b []float64) {
+	N := len(b)
+	r := append([]float64(nil), b...)
+	p := append([]float64(nil), b...)
+	Kp := make([]float64, N)
+	for i := range x {
+		x[i] = 0
+	}
+	tol := 0.01 * EigenmodeTol * math.Sqrt(l.dot(b, b))
+	rr := l.dot(r, r)
+	for iter := 0; iter < 10*N && iter < 5000 && math.Sqrt(rr) > tol; iter++ {
+		l.stiffness(Kp, p)
+		a := rr / l.dot(p, Kp)
+		for i := range x {
+			x[i] += a * p[i]
+			r[i] -= a * Kp[i]
+		}
+		rr0 := rr
+		rr = l.dot(r, r)
+		for i := range p {
+			p[i] = r[i] + (rr/rr0)*p[i]
+		}
+	}
+}
+
+// Msat-weighted inner product
+func (l *linearLLG) dot(a, b []float64) float64 {
+	sum := 0.
+	for i := range a {
+		sum += l.w[i/2] * a[i] * b[i]
+	}
+	return sum
+}
+
+// arnoldi returns the n eigenvalues λ of J with lowest frequency, and their eigenvectors,
+// in order of increasing frequency. Only eigenvalues with Im λ >= 0 are returned,
+// the others are their complex conjugates.
+func (l *linearLLG) arnoldi(n int) ([]complex128, [][]complex128) {
+	N := l.len()
+	m := 4*n + 20 // Krylov subspace dimension
+	if m > N {
+		m = N
+	}
+	rng := rand.New(rand.NewSource(0))
+	start := make([]float64, N)
+	for i := range start {
+		start[i] = rng.NormFloat64()
+	}
+
+	const maxRestart = 10
+	var λ []complex128
+	var u [][]complex128
+	for restart := 0; ; restart++ {
+		V, H, k := l.krylov(start, m)
+		θ := hessenbergEigs(H, k)
+
+		// wanted: largest |θ|, i.e. lowest |λ|, one of each conjugate pair
+		sort.Slice(θ, func(i, j int) bool { return cmplx.Abs(θ[i]) > cmplx.Abs(θ[j]) })
+		λ, u = λ[:0], u[:0]
+		converged := true
+		for _, θ := range θ {
+			if len(λ) == n {
+				break
+			}
+			if θ == 0 || imag(1/θ) < 0 {
+				continue
+			}
+			y := hessenbergVector(H, k, θ)
+			if len(V) > k && cmplx.Abs(complex(H[k][k-1], 0)*y[k-1]) > EigenmodeTol*cmplx.Abs(θ) {
+				converged = false
+			}
+			λ = append(λ, 1/θ)
+			u = append(u, ritzVector(V[:k], y))
+		}
+		if converged || restart == maxRestart {
+			if !converged {
+				LogErr("Eigenmodes: not converged after", maxRestart, "restarts")
+			}
+			break
+		}
+		// restart from the wanted Ritz vectors
+		for i := range start {
+			start[i] = 0
+			for _, u := range u {
+				start[i] += real(u[i]) + imag(u[i])
+			}
+		}
+	}
+	LogOut("Eigenmodes:", l.nEval, "stiffness evaluations")
+
+	order := make([]int, len(λ))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return imag(λ[order[i]]) < imag(λ[order[j]]) })
+	λs, us := make([]complex128, len(λ)), make([][]complex128, len(λ))
+	for i, o := range order {
+		λs[i], us[i] = λ[o], u[o]
+	}
+	return λs, us
+}
+
+// krylov builds an orthonormal basis V of the Krylov subspace of J⁻¹,
+// starting from v, and the (k+1)xk upper Hessenberg matrix H with J⁻¹ V[:k] = V H.
+// On breakdown, k < m and V has only k vectors.
+func (l *linearLLG) krylov(v []float64, m int) (V [][]float64, H [][]float64, k int) {
+	N := len(v)
+	V = [][]float64{vscale(append([]float64(nil), v...), 1/vnorm(v))}
+	H = make([][]float64, m+1)
+	for i := range H {
+		H[i] = make([]float64, m)
+	}
+	for j := 0; j < m; j++ {
+		w := make([]float64, N)
+		l.inverse(w, V[j])
+		for pass := 0; pass < 2; pass++ { // re-orthogonalize for stability
+			for i := 0; i <= j; i++ {
+				h := vdot(V[i], w)
+				H[i][j] += h
+				for k := range w {
+					w[k] -= h * V[i][k]
+				}
+			}
+		}
+		H[j+1][j] = vnorm(w)
+		if H[j+1][j] <= 1e-12*math.Abs(H[j][j]) {
+			return V, H, j + 1 // invariant subspace found
+		}
+		V = append(V, vscale(w, 1/H[j+1][j]))
+	}
+	return V, H, m
+}
+
+// complex combination of the basis vectors
+func ritzVector(V [][]float64, y []complex128) []complex128 {
+	u := make([]complex128, len(V[0]))
+	for j := range V {
+		for i := range u {
+			u[i] += complex(V[j][i], 0) * y[j]
+		}
+	}
+	return u
+}
+
+// profile converts the mode vector u to the amplitude and phase of
+// each magnetization component, normalized so that the largest amplitude is 1
+// and has zero phase.
+func (l *linearLLG) profile(u []complex128) (amp, phase *data.Slice) {
+	size := l.m0.Size()
+	amp, phase = data.NewSlice(3, size), data.NewSlice(3, size)
+	δm := make([][3]complex128, len(l.cells))
+	var ref complex128
+	for i := range l.cells {
+		for k := 0; k < 3; k++ {
+			δm[i][k] = complex(l.e1[i][k], 0)*u[2*i] + complex(l.e2[i][k], 0)*u[2*i+1]
+			if cmplx.Abs(δm[i][k]) > cmplx.Abs(ref) {
+				ref = δm[i][k]
+			}
+		}
+	}
+	A, P := amp.Host(), phase.Host()
+	for i, c := range l.cells {
+		for k := 0; k < 3; k++ {
+			v := δm[i][k] / ref
+			A[k][c] = float32(cmplx.Abs(v))
+			P[k][c] = float32(cmplx.Phase(v))
+		}
+	}
+	return amp, phase
+}
+
+// hessenbergEigs returns the eigenvalues of the kxk upper Hessenberg matrix H,
+// by the shifted QR algorithm.
+func hessenbergEigs(H [][]float64, k int) []complex128 {
+	a := make([][]complex128, k)
+	for i := range a {
+		a[i] = make([]complex128, k)
+		for j := range a[i] {
+			a[i][j] = complex(H[i][j], 0)
+		}
+	}
+
+	eig := make([]complex128, k)
+	const eps = 1e-15
+	for hi, iter := k-1, 0; hi >= 0; {
+		// find the active block lo..hi, with negligible sub-diagonal element above it
+		lo := hi
+		for lo > 0 && cmplx.Abs(a[lo][lo-1]) > eps*(cmplx.Abs(a[lo-1][lo-1])+cmplx.Abs(a[lo][lo])) {
+			lo--
+		}
+		if lo == hi {
+			eig[hi] = a[hi][hi]
+			hi--
+			iter = 0
+			continue
+		}
+		if lo > 0 {
+			a[lo][lo-1] = 0
+		}
+
+		// Wilkinson shift: eigenvalue of the trailing 2x2 block closest to its last element,
+		// with an exceptional shift now and then to break cycles.
+		p, q, r, s := a[hi-1][hi-1], a[hi-1][hi], a[hi][hi-1], a[hi][hi]
+		tr, det := p+s, p*s-q*r
+		d := cmplx.Sqrt(tr*tr/4 - det)
+		μ := tr/2 + d
+		if cmplx.Abs(tr/2-d-s) < cmplx.Abs(μ-s) {
+			μ = tr/2 - d
+		}
+		iter++
+		if iter%11 == 10 {
+			μ = s + complex(cmplx.Abs(r), 0)
+		}
+		if iter > 1000*k {
+			util.Fatal("Eigenmodes: QR iteration does not converge")
+		}
+
+		// QR step on the active block: a - μ = QR, a = RQ + μ
+		for i := lo; i <= hi; i++ {
+			a[i][i] -= μ
+		}
+		c := make([]complex128, hi-lo)
+		sn := make([]complex128, hi-lo)
+		for j := lo; j < hi; j++ {
+			x, y := a[j][j], a[j+1][j]
+			r := math.Hypot(cmplx.Abs(x), cmplx.Abs(y))
+			if r == 0 {
+				c[j-lo], sn[j-lo] = 1, 0
+				continue
+			}
+			cj, sj := x/complex(r, 0), y/complex(r, 0)
+			c[j-lo], sn[j-lo] = cj, sj
+			for col := j; col <= hi; col++ {
+				u, v := a[j][col], a[j+1][col]
+				a[j][col] = cmplx.Conj(cj)*u + cmplx.Conj(sj)*v
+				a[j+1][col] = -sj*u + cj*v
+			}
+		}
+		for j := lo; j < hi; j++ {
+			cj, sj := c[j-lo], sn[j-lo]
+			for row := lo; row <= j+1; row++ {
+				u, v := a[row][j], a[row][j+1]
+				a[row][j] = u*cj + v*sj
+				a[row][j+1] = -u*cmplx.Conj(sj) + v*cmplx.Conj(cj)
+			}
+		}
+		for i := lo; i <= hi; i++ {
+			a[i][i] += μ
+		}
+	}
+	return eig
+}
+
+// hessenbergVector returns the normalized eigenvector of the kxk matrix H
+// for eigenvalue θ, by inverse iteration.
+func hessenbergVector(H [][]float64, k int, θ complex128) []complex128 {
+	norm := 0.
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			norm = math.Max(norm, math.Abs(H[i][j]))
+		}
+	}
+	shift := θ + complex(1e-10*norm, 0) // not exactly singular
+	y := make([]complex128, k)
+	for i := range y {
+		y[i] = 1
+	}
+	for iter := 0; iter < 3; iter++ {
+		a := make([][]complex128, k)
+		for i := range a {
+			a[i] = make([]complex128, k)
+			for j := range a[i] {
+				a[i][j] = complex(H[i][j], 0)
+			}
+			a[i][i] -= shift
+		}
+		y = solveComplex(a, y)
+		n := 0.
+		for _, y := range y {
+			n += real(y)*real(y) + imag(y)*imag(y)
+		}
+		for i := range y {
+			y[i] /= complex(math.Sqrt(n), 0)
+		}
+	}
+	return y
+}
+
+// solves a x = b by Gaussian elimination with partial pivoting, overwriting a.
+func solveComplex(a [][]complex128, b []complex128) []complex128 {
+	n := len(b)
+	x := append([]complex128(nil), b...)
+	for col := 0; col < n; col++ {
+		piv := col
+		for i := col + 1; i < n; i++ {
+			if cmplx.Abs(a[i][col]) > cmplx.Abs(a[piv][col]) {
+				piv = i
+			}
+		}
+		a[col], a[piv] = a[piv], a[col]
+		x[col], x[piv] = x[piv], x[col]
+		if a[col][col] == 0 {
+			a[col][col] = 1e-300
+		}
+		for i := col + 1; i < n; i++ {
+			f := a[i][col] / a[col][col]
+			for j := col; j < n; j++ {
+				a[i][j] -= f * a[col][j]
+			}
+			x[i] -= f * x[col]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= a[i][j] * x[j]
+		}
+		x[i] /= a[i][i]
+	}
+	return x
+}
+
+func vdot(a, b []float64) float64 {
+	sum := 0.
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func vnorm(a []float64) float64 { return math.Sqrt(vdot(a, a)) }
+
+func vscale(a []float64, s float64) []float64 {
+	for i := range a {
+		a[i] *= s
+	}
+	return a
+}
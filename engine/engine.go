@@ -47,7 +47,7 @@ func GetBusy() bool {
 // Cleanly exits the simulation, assuring all output is flushed.
 func Close() {
 	drainOutput()
-	Table.flush()
+	Table.close()
 	if logfile != nil {
 		logfile.Close()
 	}
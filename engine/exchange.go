@@ -75,29 +75,39 @@ func GetExchangeEnergy() float64 {
 // Scales the heisenberg exchange interaction between region1 and 2.
 // Scale = 1 means the harmonic mean over the regions of Aex/Msat.
 func ScaleInterExchange(region1, region2 int, scale float64) {
-	lex2.scale[symmidx(region1, region2)] = float32(scale)
+	lex2.scale[regionPair(region1, region2)] = float32(scale)
 	lex2.invalidate()
 }
 
 // Scales the DMI interaction between region 1 and 2.
 func ScaleInterDind(region1, region2 int, scale float64) {
-	din2.scale[symmidx(region1, region2)] = float32(scale)
+	din2.scale[regionPair(region1, region2)] = float32(scale)
 	din2.invalidate()
 }
 
 // Sets the DMI interaction between region 1 and 2.
 func InterDind(region1, region2 int, value float64) {
-	din2.scale[symmidx(region1, region2)] = float32(0.)
-	din2.interdmi[symmidx(region1, region2)] = float32(value)
+	din2.scale[regionPair(region1, region2)] = float32(0.)
+	din2.interdmi[regionPair(region1, region2)] = float32(value)
 	din2.invalidate()
 }
 
 // stores interregion exchange stiffness
 type exchParam struct {
-	lut            [NREGION * (NREGION + 1) / 2]float32 // 1e18 * harmonic mean of Aex/Msat in regions (i,j)
-	scale          [NREGION * (NREGION + 1) / 2]float32 // extra scale factor for lut[SymmIdx(i, j)]
-	gpu            cuda.SymmLUT                         // gpu copy of lut, lazily transferred when needed
-	gpu_ok, cpu_ok bool                                 // gpu cache up-to date with lut source
+	lut            []float32          // 1e18 * harmonic mean of Aex/Msat in regions (i,j), nRegion*(nRegion+1)/2 values
+	scale          map[[2]int]float32 // extra scale factor for lut[SymmIdx(i, j)], 1 if not set
+	gpu            cuda.SymmLUT       // gpu copy of lut, lazily transferred when needed
+	gpu_ok, cpu_ok bool               // gpu cache up-to date with lut source
+}
+
+// key for exchParam.scale: region pair, lowest first.
+func regionPair(i, j int) [2]int {
+	checkRegion(i)
+	checkRegion(j)
+	if j < i {
+		i, j = j, i
+	}
+	return [2]int{i, j}
 }
 
 // to be called after Aex, Msat or scaling changed
@@ -107,18 +117,26 @@ func (p *exchParam) invalidate() {
 }
 
 func (p *aexchParam) init() {
-	for i := range p.scale {
-		p.scale[i] = 1 // default scaling
-	}
+	p.scale = make(map[[2]int]float32)
 }
 
 func (p *dexchParam) init(parent *RegionwiseScalar) {
-	for i := range p.scale {
-		p.scale[i] = 1 // default scaling
-	}
+	p.scale = make(map[[2]int]float32)
+	p.interdmi = make(map[[2]int]float32)
 	p.parent = parent
 }
 
+// drop the tables after the number of regions changed, see SetMaxRegions.
+// They are re-allocated with the new size when needed.
+func (p *exchParam) resize() {
+	p.lut = nil
+	if p.gpu != nil {
+		cuda.MemFree(unsafe.Pointer(p.gpu))
+		p.gpu = nil
+	}
+	p.invalidate()
+}
+
 // Get a GPU mirror of the look-up table.
 // Copies to GPU first only if needed.
 func (p *dexchParam) Gpu() cuda.SymmLUT {
@@ -140,7 +158,7 @@ func (p *aexchParam) Gpu() cuda.SymmLUT {
 
 type aexchParam struct{ exchParam }
 type dexchParam struct {
-	interdmi [NREGION * (NREGION + 1) / 2]float32
+	interdmi map[[2]int]float32 // inter-region DMI, 0 if not set
 	parent   *RegionwiseScalar
 	exchParam
 }
@@ -150,14 +168,18 @@ func (p *aexchParam) update() {
 		msat := Msat.cpuLUT()
 		aex := Aex.cpuLUT()
 
-		for i := 0; i < NREGION; i++ {
+		p.alloc()
+		for i := 0; i < nRegion; i++ {
 			lexi := 1e18 * safediv(aex[0][i], msat[0][i])
-			for j := i; j < NREGION; j++ {
+			for j := i; j < nRegion; j++ {
 				lexj := 1e18 * safediv(aex[0][j], msat[0][j])
 				I := symmidx(i, j)
-				p.lut[I] = p.scale[I] * 2 / (1/lexi + 1/lexj)
+				p.lut[I] = 2 / (1/lexi + 1/lexj)
 			}
 		}
+		for ij, s := range p.scale {
+			p.lut[symmidx(ij[0], ij[1])] *= s
+		}
 		p.gpu_ok = false
 		p.cpu_ok = true
 	}
@@ -167,27 +189,37 @@ func (p *dexchParam) update() {
 	if !p.cpu_ok {
 		msat := Msat.cpuLUT()
 		dex := p.parent.cpuLUT()
-		for i := 0; i < NREGION; i++ {
+		p.alloc()
+		for i := 0; i < nRegion; i++ {
 			dexi := 1e9 * safediv(dex[0][i], msat[0][i])
-			for j := i; j < NREGION; j++ {
+			for j := i; j < nRegion; j++ {
 				dexj := 1e9 * safediv(dex[0][j], msat[0][j])
-				I := symmidx(i, j)
-				interdmi := 1e9 * safediv(p.interdmi[I], msat[0][i])
-				p.lut[I] = p.scale[I]*2/(1/dexi+1/dexj) + interdmi
+				p.lut[symmidx(i, j)] = 2 / (1/dexi + 1/dexj)
 			}
 		}
+		for ij, s := range p.scale {
+			p.lut[symmidx(ij[0], ij[1])] *= s
+		}
+		for ij, d := range p.interdmi {
+			p.lut[symmidx(ij[0], ij[1])] += 1e9 * safediv(d, msat[0][ij[0]])
+		}
 		p.gpu_ok = false
 		p.cpu_ok = true
 	}
 }
 
+func (p *exchParam) alloc() {
+	if p.lut == nil {
+		p.lut = make([]float32, nRegion*(nRegion+1)/2)
+	}
+}
+
 func (p *exchParam) upload() {
 	// alloc if  needed
 	if p.gpu == nil {
 		p.gpu = cuda.SymmLUT(cuda.MemAlloc(int64(len(p.lut)) * cu.SIZEOF_FLOAT32))
 	}
-	lut := p.lut // Copy, to work around Go 1.6 cgo pointer limitations.
-	cuda.MemCpyHtoD(unsafe.Pointer(p.gpu), unsafe.Pointer(&lut[0]), cu.SIZEOF_FLOAT32*int64(len(p.lut)))
+	cuda.MemCpyHtoD(unsafe.Pointer(p.gpu), unsafe.Pointer(&p.lut[0]), cu.SIZEOF_FLOAT32*int64(len(p.lut)))
 	p.gpu_ok = true
 }
 
@@ -13,10 +13,12 @@ import (
 var (
 	// These flags are shared between cmd/mumax3 and Go input files.
 	Flag_cachedir    = flag.String("cache", "/tmp", "Kernel cache directory (empty disables caching)")
+	Flag_cpu         = flag.Bool("cpu", false, "Run on the CPU instead of the GPU (slow)")
 	Flag_gpu         = flag.Int("gpu", 0, "Specify GPU")
 	Flag_interactive = flag.Bool("i", false, "Open interactive browser session")
 	Flag_od          = flag.String("o", "", "Override output directory")
 	Flag_port        = flag.String("http", ":35367", "Port to serve web gui")
+	Flag_resume      = flag.String("resume", "", "Continue from checkpoint file, written by Checkpoint()")
 	Flag_selftest    = flag.Bool("paranoid", false, "Enable convolution self-test for cuFFT sanity.")
 	Flag_silent      = flag.Bool("s", false, "Silent") // provided for backwards compatibility
 	Flag_sync        = flag.Bool("sync", false, "Synchronize all CUDA calls (debug)")
@@ -36,6 +38,7 @@ func InitAndClose() func() {
 
 	flag.Parse()
 
+	cuda.CPU = cuda.CPU || *Flag_cpu
 	cuda.Init(*Flag_gpu)
 	cuda.Synchronous = *Flag_sync
 
@@ -44,7 +47,10 @@ func InitAndClose() func() {
 		od = path.Base(os.Args[0]) + ".out"
 	}
 	inFile := util.NoExt(od)
-	InitIO(inFile, od, *Flag_forceclean)
+	InitIO(inFile, od, *Flag_forceclean && *Flag_resume == "")
+	if *Flag_resume != "" {
+		Resume(*Flag_resume)
+	}
 
 	GoServe(*Flag_port)
 
@@ -437,9 +437,11 @@ func (g *guistate) prepareOnUpdate() {
 			}
 
 			// gpu
-			memfree, _ := cu.MemGetInfo()
-			memfree /= (1024 * 1024)
-			g.Set("memfree", memfree)
+			if !cuda.CPU {
+				memfree, _ := cu.MemGetInfo()
+				memfree /= (1024 * 1024)
+				g.Set("memfree", memfree)
+			}
 		})
 	})
 }
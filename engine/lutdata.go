@@ -10,24 +10,48 @@ import (
 
 // look-up table for region based parameters
 type lut struct {
-	gpu_buf cuda.LUTPtrs       // gpu copy of cpu buffer, only transferred when needed
-	gpu_ok  bool               // gpu cache up-to date with cpu source?
-	cpu_buf [][NREGION]float32 // table data on cpu
-	source  updater            // updates cpu data
+	gpu_buf cuda.LUTPtrs // gpu copy of cpu buffer, only transferred when needed
+	gpu_ok  bool         // gpu cache up-to date with cpu source?
+	cpu_buf [][]float32  // table data on cpu, nRegion values per component
+	source  updater      // updates cpu data
 }
 
+var luts []*lut // all look-up tables, resized by SetMaxRegions
+
 type updater interface {
 	update() // updates cpu lookup table
 }
 
 func (p *lut) init(nComp int, source updater) {
 	p.gpu_buf = make(cuda.LUTPtrs, nComp)
-	p.cpu_buf = make([][NREGION]float32, nComp)
+	p.cpu_buf = make([][]float32, nComp)
+	for c := range p.cpu_buf {
+		p.cpu_buf[c] = make([]float32, nRegion)
+	}
 	p.source = source
+	luts = append(luts, p)
+}
+
+// resize the table to nRegion values. New regions get the value of the last existing region.
+func (p *lut) resize() {
+	for c, old := range p.cpu_buf {
+		p.cpu_buf[c] = make([]float32, nRegion)
+		copy(p.cpu_buf[c], old)
+		for r := len(old); r < nRegion; r++ {
+			p.cpu_buf[c][r] = old[len(old)-1]
+		}
+	}
+	for c := range p.gpu_buf {
+		if p.gpu_buf[c] != nil {
+			cuda.MemFree(p.gpu_buf[c])
+			p.gpu_buf[c] = nil
+		}
+	}
+	p.gpu_ok = false
 }
 
 // get an up-to-date version of the lookup-table on CPU
-func (p *lut) cpuLUT() [][NREGION]float32 {
+func (p *lut) cpuLUT() [][]float32 {
 	p.source.update()
 	return p.cpu_buf
 }
@@ -40,7 +64,7 @@ func (p *lut) gpuLUT() cuda.LUTPtrs {
 		p.assureAlloc()
 		cuda.Sync() // sync previous kernels, may still be using gpu lut
 		for c := range p.gpu_buf {
-			cuda.MemCpyHtoD(p.gpu_buf[c], unsafe.Pointer(&p.cpu_buf[c][0]), cu.SIZEOF_FLOAT32*NREGION)
+			cuda.MemCpyHtoD(p.gpu_buf[c], unsafe.Pointer(&p.cpu_buf[c][0]), cu.SIZEOF_FLOAT32*int64(nRegion))
 		}
 		p.gpu_ok = true
 		cuda.Sync() //sync upload
@@ -58,7 +82,7 @@ func (p *lut) gpuLUT1() cuda.LUTPtr {
 func (p *lut) isZero() bool {
 	v := p.cpuLUT()
 	for c := range v {
-		for i := 0; i < NREGION; i++ {
+		for i := 0; i < nRegion; i++ {
 			if v[c][i] != 0 {
 				return false
 			}
@@ -72,7 +96,7 @@ func (p *lut) nonZero() bool { return !p.isZero() }
 func (p *lut) assureAlloc() {
 	if p.gpu_buf[0] == nil {
 		for i := range p.gpu_buf {
-			p.gpu_buf[i] = cuda.MemAlloc(NREGION * cu.SIZEOF_FLOAT32)
+			p.gpu_buf[i] = cuda.MemAlloc(int64(nRegion) * cu.SIZEOF_FLOAT32)
 		}
 	}
 }
@@ -115,6 +115,10 @@ func (mini *Minimizer) Free() {
 
 func Minimize() {
 	Refer("exl2014")
+	if _, ok := beginRun(); !ok {
+		return
+	}
+	defer endRun()
 	SanityCheck()
 	// Save the settings we are changing...
 	prevType := solvertype
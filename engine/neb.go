@@ -0,0 +1,449 @@
+package engine
+
+// Geodesic nudged elastic band method with climbing image,
+// as per Bessarab et al., Comput. Phys. Commun. 196, 335 (2015)
+// and Henkelman et al., J. Chem. Phys. 113, 9901 (2000).
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+
+	"github.com/mumax/3/cuda"
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/util"
+)
+
+var (
+	NEBImages   int     = 10   // number of intermediate images
+	NEBSpring   float64 = 1    // spring constant (T)
+	NEBClimb    bool    = true // use climbing image
+	NEBStop     float64 = 1e-4 // stop when max NEB force (T) is smaller than this
+	NEBMaxSteps int     = 1e5  // give up after this many steps
+	nebBarrier  float64        // forward energy barrier found by the last NEB (J)
+)
+
+func init() {
+	DeclFunc("NEB", NEB, "Find the minimum energy path between two or more saved magnetization states, using the geodesic nudged elastic band method")
+	DeclVar("NEBImages", &NEBImages, "Number of intermediate images for NEB")
+	DeclVar("NEBSpring", &NEBSpring, "Spring constant between NEB images (T)")
+	DeclVar("NEBClimb", &NEBClimb, "Let the highest NEB image climb to the saddle point (default=true)")
+	DeclVar("NEBStop", &NEBStop, "Stopping max NEB force (T)")
+	DeclVar("NEBMaxSteps", &NEBMaxSteps, "Maximum number of NEB steps")
+	_ = NewScalarValue("NEBBarrier", "J", "Forward energy barrier found by the last NEB", func() float64 { return nebBarrier })
+}
+
+// Elastic band of magnetization images, kept on the host.
+// The first and last image are fixed, the others are moved by the NEB force
+// using the same steepest descent scheme as Minimize.
+type elasticBand struct {
+	m        []*data.Slice // images, including end points
+	f        []*data.Slice // NEB force on each image, nil for end points
+	energy   []float64     // total energy of each image (J)
+	mask     []float32     // 0 for empty or frozen cells
+	h        float64       // step size (1/T)
+	climbing bool
+	maxF     float64 // max NEB force norm (T)
+}
+
+// NEB finds the minimum energy path through the given states,
+// which are typically relaxed and saved with Save(m).
+// The first and last state are the fixed end points, states in between
+// only serve to guide the initial path. NEBImages intermediate images are
+// placed along the geodesic path through the states and moved to the minimum energy path.
+// Each image is saved as nebXXXXXX.ovf, and their energies to neb.txt.
+// The magnetization is restored afterwards.
+func NEB(files ...string) {
+	Refer("bessarab2015")
+	if len(files) < 2 {
+		util.Fatal("NEB: need at least two states, have", len(files))
+	}
+	if NEBImages < 1 {
+		util.Fatal("NEB: NEBImages should be at least 1, have", NEBImages)
+	}
+	if _, ok := beginRun(); !ok {
+		return
+	}
+	defer endRun()
+	SanityCheck()
+	pause = false
+
+	// Save the settings we are changing...
+	prevType := solvertype
+	prevPrecess := Precess
+	prevM := M.Buffer().HostCopy()
+	t0 := Time
+
+	// ...to restore them later
+	defer func() {
+		SetSolver(prevType)
+		Precess = prevPrecess
+		Time = t0
+		data.Copy(M.Buffer(), prevM)
+		relaxing = false
+	}()
+
+	Precess = false
+	relaxing = true // disable temperature noise
+
+	drainOutput() // states may just have been saved
+	states := make([]*data.Slice, len(files))
+	for i, f := range files {
+		M.SetArray(LoadFile(f)) // resamples and normalizes
+		states[i] = M.Buffer().HostCopy()
+	}
+
+	if stepper != nil {
+		stepper.Free()
+	}
+	b := newElasticBand(states, NEBImages)
+	stepper = b
+
+	stop := NSteps + NEBMaxSteps
+	cond := func() bool {
+		if b.maxF < NEBStop && NEBClimb && !b.climbing {
+			Refer("henkelman2000")
+			b.climbing = true // pre-converged: now start climbing
+			b.updateForces()
+		}
+		return b.maxF > NEBStop && NSteps < stop
+	}
+	runWhile(cond, false)
+	if b.maxF > NEBStop && !pause {
+		LogErr("NEB: not converged after", NEBMaxSteps, "steps, max force:", b.maxF, "T")
+	}
+	b.save()
+	pause = true
+}
+
+func newElasticBand(states []*data.Slice, nImages int) *elasticBand {
+	n := nImages + 2
+	b := &elasticBand{
+		m:      geodesicPath(states, n),
+		f:      make([]*data.Slice, n),
+		energy: make([]float64, n),
+		h:      1e-4}
+
+	size := states[0].Size()
+	for i := 1; i < n-1; i++ {
+		b.f[i] = data.NewSlice(3, size)
+	}
+
+	// empty and frozen cells do not move
+	one := cuda.Buffer(3, size)
+	defer cuda.Recycle(one)
+	cuda.Memset(one, 1, 1, 1)
+	FreezeSpins(one)
+	b.mask = one.Comp(X).HostCopy().Host()[0]
+	m := states[0].Host()
+	for c := range b.mask {
+		if m[X][c] == 0 && m[Y][c] == 0 && m[Z][c] == 0 {
+			b.mask[c] = 0
+		}
+	}
+
+	for _, i := range []int{0, n - 1} {
+		data.Copy(M.Buffer(), b.m[i])
+		b.energy[i] = GetTotalEnergy()
+	}
+	b.updateForces()
+	return b
+}
+
+// take one steepest descent step on all movable images,
+// with Barzilai-Borwein step size like Minimizer.
+func (b *elasticBand) Step() {
+	n := len(b.m)
+	m0 := make([]*data.Slice, n)
+	f0 := make([]*data.Slice, n)
+	for i := 1; i < n-1; i++ {
+		m0[i] = b.m[i].HostCopy()
+		f0[i] = b.f[i].HostCopy()
+		rotate(b.m[i], b.f[i], b.h)
+	}
+	b.updateForces()
+
+	var dmdm, dmdf, dfdf, maxDm float64
+	for i := 1; i < n-1; i++ {
+		m, mOld := b.m[i].Host(), m0[i].Host()
+		f, fOld := b.f[i].Host(), f0[i].Host()
+		for c := range m[X] {
+			var dm2 float64
+			for k := 0; k < 3; k++ {
+				dm := float64(m[k][c] - mOld[k][c])
+				df := float64(fOld[k][c] - f[k][c]) // reversed: f points downhill
+				dm2 += dm * dm
+				dmdf += dm * df
+				dfdf += df * df
+			}
+			dmdm += dm2
+			maxDm = math.Max(maxDm, dm2)
+		}
+	}
+	setLastErr(math.Sqrt(maxDm))
+	LastTorque = b.maxF
+
+	var nom, div float64
+	if NSteps%2 == 0 {
+		nom, div = dmdm, dmdf
+	} else {
+		nom, div = dmdf, dfdf
+	}
+	// do not rotate any spin over more than maxAngle in one step,
+	// take the largest step when the curvature is negative
+	const maxAngle = 0.2
+	b.h = maxAngle / b.maxF
+	if div > 0 && nom > 0 && nom/div < b.h {
+		b.h = nom / div
+	}
+
+	// as a convention, time does not advance during NEB
+	NSteps++
+}
+
+func (b *elasticBand) Free() {}
+
+// evaluate energy and NEB force of all movable images
+func (b *elasticBand) updateForces() {
+	n := len(b.m)
+	size := b.m[0].Size()
+	torque := cuda.Buffer(3, size)
+	defer cuda.Recycle(torque)
+	for i := 1; i < n-1; i++ {
+		data.Copy(M.Buffer(), b.m[i])
+		b.energy[i] = GetTotalEnergy()
+		SetEffectiveField(torque)
+		cuda.LLNoPrecess(torque, M.Buffer(), torque) // = -m x (m x B): downhill, in the tangent space
+		FreezeSpins(torque)
+		NEvals++
+		data.Copy(b.f[i], torque)
+	}
+
+	climber := -1
+	if b.climbing {
+		climber = 1
+		for i := 1; i < n-1; i++ {
+			if b.energy[i] > b.energy[climber] {
+				climber = i
+			}
+		}
+	}
+
+	dist := make([]float64, n-1) // dist[i]: between image i and i+1
+	for i := range dist {
+		dist[i] = geodesicDist(b.m[i], b.m[i+1])
+	}
+
+	b.maxF = 0
+	for i := 1; i < n-1; i++ {
+		t := b.tangent(i)
+		f := b.f[i].Host()
+		var fτ float64
+		for c := range t[X] {
+			for k := 0; k < 3; k++ {
+				fτ += float64(f[k][c]) * t[k][c]
+			}
+		}
+		// nudge: perpendicular part of true force, parallel part of spring force.
+		// climbing image: true force with parallel part inverted.
+		var ft float64
+		if i == climber {
+			ft = -2 * fτ
+		} else {
+			ft = -fτ + NEBSpring*(dist[i]-dist[i-1])
+		}
+		for c := range t[X] {
+			var f2 float64
+			for k := 0; k < 3; k++ {
+				f[k][c] += float32(ft * t[k][c])
+				f2 += float64(f[k][c]) * float64(f[k][c])
+			}
+			b.maxF = math.Max(b.maxF, math.Sqrt(f2))
+		}
+	}
+}
+
+// unit tangent to the path at image i, in the tangent space of the image,
+// using the energy-weighted upwind scheme of Henkelman and Jónsson.
+func (b *elasticBand) tangent(i int) [3][]float64 {
+	E0, E1, E2 := b.energy[i-1], b.energy[i], b.energy[i+1]
+	var wPlus, wMinus float64 // weights of m[i+1]-m[i] and m[i]-m[i-1]
+	switch {
+	case E2 > E1 && E1 > E0:
+		wPlus, wMinus = 1, 0
+	case E2 < E1 && E1 < E0:
+		wPlus, wMinus = 0, 1
+	default:
+		dEmax := math.Max(math.Abs(E2-E1), math.Abs(E0-E1))
+		dEmin := math.Min(math.Abs(E2-E1), math.Abs(E0-E1))
+		if E2 > E0 {
+			wPlus, wMinus = dEmax, dEmin
+		} else {
+			wPlus, wMinus = dEmin, dEmax
+		}
+		if dEmax == 0 {
+			wPlus, wMinus = 1, 1
+		}
+	}
+
+	prev, m, next := b.m[i-1].Host(), b.m[i].Host(), b.m[i+1].Host()
+	N := len(m[X])
+	t := [3][]float64{make([]float64, N), make([]float64, N), make([]float64, N)}
+	var norm2 float64
+	for c := 0; c < N; c++ {
+		if b.mask[c] == 0 {
+			continue
+		}
+		var v [3]float64
+		var vm float64
+		for k := 0; k < 3; k++ {
+			v[k] = wPlus*float64(next[k][c]-m[k][c]) + wMinus*float64(m[k][c]-prev[k][c])
+			vm += v[k] * float64(m[k][c])
+		}
+		for k := 0; k < 3; k++ {
+			t[k][c] = v[k] - vm*float64(m[k][c])
+			norm2 += t[k][c] * t[k][c]
+		}
+	}
+	if norm2 > 0 {
+		inv := 1 / math.Sqrt(norm2)
+		for k := range t {
+			for c := range t[k] {
+				t[k][c] *= inv
+			}
+		}
+	}
+	return t
+}
+
+// save all images and the energy along the path
+func (b *elasticBand) save() {
+	n := len(b.m)
+	s := 0.
+	f, err := httpfs.Create(OD() + "neb.txt")
+	util.FatalErr(err)
+	defer f.Close()
+	table := bufio.NewWriter(f)
+	defer table.Flush()
+	fprintln(table, "# image ()\tpath (rad)\tE_total (J)\tdE_total (J)")
+
+	top := 0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s += geodesicDist(b.m[i-1], b.m[i])
+		}
+		fprint(table, i, "\t", float32(s), "\t", float32(b.energy[i]), "\t", float32(b.energy[i]-b.energy[0]), "\n")
+		if b.energy[i] > b.energy[top] {
+			top = i
+		}
+
+		fname := OD() + fmt.Sprintf(FilenameFormat, "neb", i) + "." + StringFromOutputFormat[outputFormat]
+		info := data.Meta{Time: Time, Name: "m", CellSize: Mesh().CellSize()}
+		img := b.m[i]
+		queOutput(func() { saveAs_sync(fname, img, info, outputFormat) })
+	}
+	nebBarrier = b.energy[top] - b.energy[0]
+	LogOut("NEB: highest image:", top, ", energy barrier:", b.energy[top]-b.energy[0], "J (forward),",
+		b.energy[top]-b.energy[n-1], "J (backward)")
+}
+
+// geodesicPath returns n images along the geodesic path through the given states,
+// equally spaced in geodesic distance, including the first and last state.
+func geodesicPath(states []*data.Slice, n int) []*data.Slice {
+	seg := make([]float64, len(states)-1)
+	L := 0.
+	for i := range seg {
+		seg[i] = geodesicDist(states[i], states[i+1])
+		L += seg[i]
+	}
+	if L == 0 {
+		util.Fatal("NEB: all states are the same")
+	}
+
+	images := make([]*data.Slice, n)
+	images[0] = states[0].HostCopy()
+	images[n-1] = states[len(states)-1].HostCopy()
+	j, start := 0, 0. // current segment and its start
+	for i := 1; i < n-1; i++ {
+		s := L * float64(i) / float64(n-1)
+		for j < len(seg)-1 && s > start+seg[j] {
+			start += seg[j]
+			j++
+		}
+		t := 0.
+		if seg[j] > 0 {
+			t = math.Min((s-start)/seg[j], 1)
+		}
+		images[i] = slerp(states[j], states[j+1], t)
+	}
+	return images
+}
+
+// geodesic distance between a and b: square root of
+// the sum of squared rotation angles of all cells.
+func geodesicDist(a, b *data.Slice) float64 {
+	A, B := a.Host(), b.Host()
+	d2 := 0.
+	for c := range A[X] {
+		u := vec3(A, c)
+		v := vec3(B, c)
+		θ := math.Atan2(u.Cross(v).Len(), u.Dot(v))
+		d2 += θ * θ
+	}
+	return math.Sqrt(d2)
+}
+
+// interpolates each cell along the great circle from a (t=0) to b (t=1).
+func slerp(a, b *data.Slice, t float64) *data.Slice {
+	dst := data.NewSlice(3, a.Size())
+	A, B, D := a.Host(), b.Host(), dst.Host()
+	for c := range A[X] {
+		u := vec3(A, c)
+		v := vec3(B, c)
+		var r data.Vector
+		sin := u.Cross(v).Len()
+		θ := math.Atan2(sin, u.Dot(v))
+		switch {
+		case u.Len() == 0 || v.Len() == 0:
+			r = u
+		case sin < 1e-6 && θ < 1: // parallel
+			r = u.Add(v.Sub(u).Mul(t)).Div(u.Add(v.Sub(u).Mul(t)).Len())
+		case sin < 1e-6: // anti-parallel: rotate about any perpendicular axis
+			axis := data.Vector{1, 0, 0}
+			if math.Abs(u[X]) > 0.5 {
+				axis = data.Vector{0, 1, 0}
+			}
+			w := axis.Cross(u)
+			w = w.Div(w.Len())
+			r = u.Mul(math.Cos(t * math.Pi)).Add(w.Mul(math.Sin(t * math.Pi)))
+		default:
+			r = u.Mul(math.Sin((1-t)*θ) / sin).Add(v.Mul(math.Sin(t*θ) / sin))
+		}
+		for k := 0; k < 3; k++ {
+			D[k][c] = float32(r[k])
+		}
+	}
+	return dst
+}
+
+// rotates m along f over angle ~h|f|, keeping |m|, like cuda.Minimize.
+func rotate(m, f *data.Slice, h float64) {
+	M, F := m.Host(), f.Host()
+	for c := range M[X] {
+		u := vec3(M, c)
+		t := vec3(F, c)
+		t2 := h * h * t.Dot(t)
+		r := u.Mul(4 - t2).Add(t.Mul(4 * h)).Div(4 + t2)
+		if l := r.Len(); l != 0 {
+			r = r.Div(l)
+		}
+		for k := 0; k < 3; k++ {
+			M[k][c] = float32(r[k])
+		}
+	}
+}
+
+func vec3(s [][]float32, i int) data.Vector {
+	return data.Vector{float64(s[X][i]), float64(s[Y][i]), float64(s[Z][i])}
+}
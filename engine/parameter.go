@@ -19,14 +19,15 @@ import (
 // input parameter, settable by user
 type regionwise struct {
 	lut
-	upd_reg    [NREGION]func() []float64 // time-dependent values
-	timestamp  float64                   // used not to double-evaluate f(t)
-	children   []derived                 // derived parameters
+	upd_reg    []func() []float64 // time-dependent values, per region
+	timestamp  float64            // used not to double-evaluate f(t)
+	children   []derived          // derived parameters
 	name, unit string
 }
 
 func (p *regionwise) init(nComp int, name, unit string, children []derived) {
 	p.lut.init(nComp, p)
+	p.upd_reg = make([]func() []float64, nRegion)
 	p.name = name
 	p.unit = unit
 	p.children = children
@@ -70,8 +71,7 @@ func (p *regionwise) update() {
 	if p.timestamp != Time {
 		changed := false
 		// update functions of time
-		for r := 0; r < NREGION; r++ {
-			updFunc := p.upd_reg[r]
+		for r, updFunc := range p.upd_reg {
 			if updFunc != nil {
 				p.bufset_(r, updFunc())
 				changed = true
@@ -95,13 +95,15 @@ func (p *regionwise) setRegion(region int, v []float64) {
 
 // set in all regions
 func (p *regionwise) setUniform(v []float64) {
-	p.setRegions(0, NREGION, v)
+	p.setRegions(0, nRegion, v)
 }
 
 // set in regions r1..r2(excl)
 func (p *regionwise) setRegions(r1, r2 int, v []float64) {
 	util.Argument(len(v) == len(p.cpu_buf))
 	util.Argument(r1 < r2) // exclusive upper bound
+	checkRegion(r1)
+	checkRegion(r2 - 1)
 	for r := r1; r < r2; r++ {
 		p.upd_reg[r] = nil
 		p.bufset_(r, v)
@@ -109,6 +111,18 @@ func (p *regionwise) setRegions(r1, r2 int, v []float64) {
 	p.invalidate()
 }
 
+// resize upd_reg after the look-up table, see SetMaxRegions.
+// New regions get the function of the last existing region.
+func (p *regionwise) resizeFuncs() {
+	old := p.upd_reg
+	p.upd_reg = make([]func() []float64, nRegion)
+	copy(p.upd_reg, old)
+	for r := len(old); r < nRegion; r++ {
+		p.upd_reg[r] = old[len(old)-1]
+	}
+	p.invalidate()
+}
+
 func (p *regionwise) bufset_(region int, v []float64) {
 	for c := range p.cpu_buf {
 		p.cpu_buf[c][region] = float32(v[c])
@@ -117,6 +131,8 @@ func (p *regionwise) bufset_(region int, v []float64) {
 
 func (p *regionwise) setFunc(r1, r2 int, f func() []float64) {
 	util.Argument(r1 < r2) // exclusive upper bound
+	checkRegion(r1)
+	checkRegion(r2 - 1)
 	for r := r1; r < r2; r++ {
 		p.upd_reg[r] = f
 	}
@@ -143,7 +159,7 @@ func (p *regionwise) getRegion(region int) []float64 {
 func (p *regionwise) IsUniform() bool {
 	cpu := p.cpuLUT()
 	v1 := p.getRegion(0)
-	for r := 1; r < NREGION; r++ {
+	for r := 1; r < nRegion; r++ {
 		for c := range v1 {
 			if cpu[c][r] != float32(v1[c]) {
 				return false
@@ -238,7 +254,7 @@ func NewScalarParam(name, unit, desc string, children ...derived) *RegionwiseSca
 
 func (p *RegionwiseScalar) SetRegion(region int, f script.ScalarFunction) {
 	if region == -1 {
-		p.setRegionsFunc(0, NREGION, f) // uniform
+		p.setRegionsFunc(0, nRegion, f) // uniform
 	} else {
 		p.setRegionsFunc(region, region+1, f) // upper bound exclusive
 	}
@@ -246,11 +262,11 @@ func (p *RegionwiseScalar) SetRegion(region int, f script.ScalarFunction) {
 
 func (p *RegionwiseScalar) SetValue(v interface{}) {
 	f := v.(script.ScalarFunction)
-	p.setRegionsFunc(0, NREGION, f)
+	p.setRegionsFunc(0, nRegion, f)
 }
 
 func (p *RegionwiseScalar) Set(v float64) {
-	p.setRegions(0, NREGION, []float64{v})
+	p.setRegions(0, nRegion, []float64{v})
 }
 
 func (p *RegionwiseScalar) setRegionsFunc(r1, r2 int, f script.ScalarFunction) {
@@ -293,7 +309,7 @@ func cat(desc, unit string) string {
 
 func (p *RegionwiseScalar) SetRegionValueGo(region int, v float64) {
 	if region == -1 {
-		p.setRegions(0, NREGION, []float64{v})
+		p.setRegions(0, nRegion, []float64{v})
 	} else {
 		p.setRegions(region, region+1, []float64{v})
 	}
@@ -301,7 +317,7 @@ func (p *RegionwiseScalar) SetRegionValueGo(region int, v float64) {
 
 func (p *RegionwiseScalar) SetRegionFuncGo(region int, f func() float64) {
 	if region == -1 {
-		p.setFunc(0, NREGION, func() []float64 {
+		p.setFunc(0, nRegion, func() []float64 {
 			return []float64{f()}
 		})
 	} else {
@@ -327,7 +343,7 @@ func NewVectorParam(name, unit, desc string) *RegionwiseVector {
 
 func (p *RegionwiseVector) SetRegion(region int, f script.VectorFunction) {
 	if region == -1 {
-		p.setRegionsFunc(0, NREGION, f) //uniform
+		p.setRegionsFunc(0, nRegion, f) //uniform
 	} else {
 		p.setRegionsFunc(region, region+1, f)
 	}
@@ -335,7 +351,7 @@ func (p *RegionwiseVector) SetRegion(region int, f script.VectorFunction) {
 
 func (p *RegionwiseVector) SetValue(v interface{}) {
 	f := v.(script.VectorFunction)
-	p.setRegionsFunc(0, NREGION, f)
+	p.setRegionsFunc(0, nRegion, f)
 }
 
 func (p *RegionwiseVector) setRegionsFunc(r1, r2 int, f script.VectorFunction) {
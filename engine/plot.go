@@ -56,7 +56,7 @@ func (g *guistate) servePlot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := httpfs.Read(fmt.Sprintf(`%vtable.txt`, OD()))
+	data, err := httpfs.Read(Table.fname())
 	if handle(err) {
 		return
 	}
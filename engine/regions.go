@@ -8,10 +8,16 @@ import (
 
 var regions = Regions{info: info{1, "regions", ""}} // global regions map
 
-const NREGION = 256 // maximum number of regions, limited by size of byte.
+const (
+	NREGION      = 256  // default maximum number of regions, limited by size of byte.
+	NREGION_WIDE = 4096 // maximum number of regions with SetMaxRegions, regions are then stored as uint16. Limited by the n² exchange tables.
+)
+
+var nRegion = NREGION // maximum number of regions, see SetMaxRegions
 
 func init() {
-	DeclFunc("DefRegion", DefRegion, "Define a material region with given index (0-255) and shape")
+	DeclFunc("DefRegion", DefRegion, "Define a material region with given index (0-255, or up to the limit set by SetMaxRegions) and shape")
+	DeclFunc("SetMaxRegions", SetMaxRegions, "Raise the maximum number of regions above 256, for up to 4096 regions (CPU backend only, unless the GPU kernels were compiled)")
 	DeclFunc("DefRegionCell", DefRegionCell, "Set a material region in one cell by index")
 	DeclROnly("regions", &regions, "Outputs the region index for each cell")
 }
@@ -25,20 +31,20 @@ type Regions struct {
 
 func (r *Regions) alloc() {
 	mesh := r.Mesh()
-	r.gpuCache = cuda.NewBytes(mesh.NCell())
+	r.gpuCache = newRegionBytes(mesh.NCell())
 	DefRegion(0, universe)
 }
 
 func (r *Regions) resize() {
 	newSize := Mesh().Size()
 	r.gpuCache.Free()
-	r.gpuCache = cuda.NewBytes(prod(newSize))
+	r.gpuCache = newRegionBytes(prod(newSize))
 	for _, f := range r.hist {
 		r.render(f)
 	}
 }
 
-// Define a region with id (0-255) to be inside the Shape.
+// Define a region with id (0-255, see SetMaxRegions) to be inside the Shape.
 func DefRegion(id int, s Shape) {
 	defRegionId(id)
 	f := func(x, y, z float64) int {
@@ -57,7 +63,7 @@ func DefRegion(id int, s Shape) {
 func (r *Regions) render(f func(x, y, z float64) int) {
 	n := Mesh().Size()
 	l := r.HostList() // need to start from previous state
-	arr := reshapeBytes(l, r.Mesh().Size())
+	arr := reshapeRegions(l, r.Mesh().Size())
 
 	for iz := 0; iz < n[Z]; iz++ {
 		for iy := 0; iy < n[Y]; iy++ {
@@ -65,13 +71,13 @@ func (r *Regions) render(f func(x, y, z float64) int) {
 				r := Index2Coord(ix, iy, iz)
 				region := f(r[X], r[Y], r[Z])
 				if region >= 0 {
-					arr[iz][iy][ix] = byte(region)
+					arr[iz][iy][ix] = uint16(region)
 				}
 			}
 		}
 	}
 	//log.Print("regions.upload")
-	r.gpuCache.Upload(l)
+	r.upload(l)
 }
 
 // get the region for position R based on the history
@@ -87,44 +93,66 @@ func (r *Regions) get(R data.Vector) int {
 	return 0
 }
 
-func (r *Regions) HostArray() [][][]byte {
-	return reshapeBytes(r.HostList(), r.Mesh().Size())
+func (r *Regions) HostArray() [][][]uint16 {
+	return reshapeRegions(r.HostList(), r.Mesh().Size())
 }
 
-func (r *Regions) HostList() []byte {
-	regionsList := make([]byte, r.Mesh().NCell())
-	regions.gpuCache.Download(regionsList)
+// HostList returns the region of each cell, whether they are stored as bytes or not.
+func (r *Regions) HostList() []uint16 {
+	regionsList := make([]uint16, r.Mesh().NCell())
+	if r.gpuCache.Wide {
+		r.gpuCache.Download16(regionsList)
+		return regionsList
+	}
+	b := make([]byte, len(regionsList))
+	r.gpuCache.Download(b)
+	for i := range b {
+		regionsList[i] = uint16(b[i])
+	}
 	return regionsList
 }
 
+// upload the region of each cell, inverse of HostList.
+func (r *Regions) upload(l []uint16) {
+	if r.gpuCache.Wide {
+		r.gpuCache.Upload16(l)
+		return
+	}
+	b := make([]byte, len(l))
+	for i := range l {
+		b[i] = byte(l[i])
+	}
+	r.gpuCache.Upload(b)
+}
+
 func DefRegionCell(id int, x, y, z int) {
 	defRegionId(id)
 	index := data.Index(Mesh().Size(), x, y, z)
-	regions.gpuCache.Set(index, byte(id))
+	regions.gpuCache.Set(index, id)
 }
 
 // Load regions from ovf file, use first component.
-// Regions should be between 0 and 256
+// Regions should be between 0 and 255, or the limit set by SetMaxRegions
 func (r *Regions) LoadFile(fname string) {
 	inSlice := LoadFile(fname)
 	n := r.Mesh().Size()
 	inSlice = data.Resample(inSlice, n)
 	inArr := inSlice.Tensors()[0]
 	l := r.HostList()
-	arr := reshapeBytes(l, n)
+	arr := reshapeRegions(l, n)
 
 	for iz := 0; iz < n[Z]; iz++ {
 		for iy := 0; iy < n[Y]; iy++ {
 			for ix := 0; ix < n[X]; ix++ {
 				val := inArr[iz][iy][ix]
-				if val < 0 || val > 256 {
-					util.Fatal("regions.LoadFile(", fname, "): all values should be between 0 & 256, have: ", val)
+				if val < 0 || int(val) >= nRegion {
+					util.Fatal("regions.LoadFile(", fname, "): all values should be between 0 & ", nRegion-1, ", have: ", val)
 				}
-				arr[iz][iy][ix] = byte(val)
+				arr[iz][iy][ix] = uint16(val)
 			}
 		}
 	}
-	r.gpuCache.Upload(l)
+	r.upload(l)
 }
 
 func (r *Regions) average() []float64 {
@@ -141,26 +169,85 @@ func (r *Regions) Average() float64 { return r.average()[0] }
 func (r *Regions) SetCell(ix, iy, iz int, region int) {
 	size := Mesh().Size()
 	i := data.Index(size, ix, iy, iz)
-	r.gpuCache.Set(i, byte(region))
+	r.gpuCache.Set(i, region)
 }
 
 func (r *Regions) GetCell(ix, iy, iz int) int {
 	size := Mesh().Size()
 	i := data.Index(size, ix, iy, iz)
-	return int(r.gpuCache.Get(i))
+	return r.gpuCache.Get(i)
 }
 
 func defRegionId(id int) {
-	if id < 0 || id > NREGION {
-		util.Fatalf("region id should be 0 -%v, have: %v", NREGION, id)
-	}
+	checkRegion(id)
 	checkMesh()
 }
 
+func checkRegion(id int) {
+	if id < 0 || id >= nRegion {
+		if id >= nRegion && id < NREGION_WIDE {
+			util.Fatalf("region id should be 0-%v, have: %v (use SetMaxRegions for more regions)", nRegion-1, id)
+		}
+		util.Fatalf("region id should be 0-%v, have: %v", nRegion-1, id)
+	}
+}
+
+// regions are stored as uint16 when there can be more than 256 of them.
+func newRegionBytes(N int) *cuda.Bytes {
+	if nRegion > NREGION {
+		return cuda.NewWideBytes(N)
+	}
+	return cuda.NewBytes(N)
+}
+
+// SetMaxRegions raises the maximum number of regions from 256 to n (at most 4096),
+// e.g. to give each grain of ext_makegrains its own material parameters.
+// New regions start with the parameter values of the highest existing region,
+// which normally holds the uniform value, so it is best called at the top of the input file.
+// The inter-region exchange tables hold n(n+1)/2 values each (34 MB for 4096 regions),
+// so n should not be larger than needed.
+// On the GPU this needs the 16-bit region kernels (cuda/*16.cu) compiled to PTX,
+// which the distributed sources do not contain: without them, use the CPU backend (-cpu).
+func SetMaxRegions(n int) {
+	if n < nRegion || n > NREGION_WIDE {
+		util.Fatalf("SetMaxRegions: number of regions should be %v-%v, have: %v", nRegion, NREGION_WIDE, n)
+	}
+	if n == nRegion {
+		return
+	}
+	if !cuda.HaveWideRegions() {
+		util.Fatal("SetMaxRegions: more than 256 regions are not supported on the GPU by this build, ",
+			"run with -cpu (or compile the 16-bit region kernels with cuda/make.bash, needs nvcc)")
+	}
+	var l []uint16
+	if regions.gpuCache != nil {
+		l = regions.HostList()
+	}
+	nRegion = n
+	for _, p := range luts {
+		p.resize()
+		switch s := p.source.(type) {
+		case *regionwise:
+			s.resizeFuncs()
+		case *DerivedParam:
+			s.invalidate()
+		}
+	}
+	lex2.resize()
+	din2.resize()
+	dbulk2.resize()
+	setUnitMap()
+	if l != nil {
+		regions.gpuCache.Free()
+		regions.gpuCache = newRegionBytes(len(l))
+		regions.upload(l)
+	}
+}
+
 // normalized volume (0..1) of region.
 // TODO: a tidbit too expensive
 func (r *Regions) volume(region_ int) float64 {
-	region := byte(region_)
+	region := uint16(region_)
 	vol := 0
 	list := r.HostList()
 	for _, reg := range list {
@@ -181,7 +268,11 @@ var unitMap regionwise // unit map used to output regions quantity
 
 func init() {
 	unitMap.init(1, "unit", "", nil)
-	for r := 0; r < NREGION; r++ {
+	setUnitMap()
+}
+
+func setUnitMap() {
+	for r := 0; r < nRegion; r++ {
 		unitMap.setRegion(r, []float64{float64(r)})
 	}
 }
@@ -198,12 +289,12 @@ func (r *Regions) EvalTo(dst *data.Slice) { EvalTo(r, dst) }
 var _ Quantity = &regions
 
 // Re-interpret a contiguous array as a multi-dimensional array of given size.
-func reshapeBytes(array []byte, size [3]int) [][][]byte {
+func reshapeRegions(array []uint16, size [3]int) [][][]uint16 {
 	Nx, Ny, Nz := size[X], size[Y], size[Z]
 	util.Argument(Nx*Ny*Nz == len(array))
-	sliced := make([][][]byte, Nz)
+	sliced := make([][][]uint16, Nz)
 	for i := range sliced {
-		sliced[i] = make([][]byte, Ny)
+		sliced[i] = make([][]uint16, Ny)
 	}
 	for i := range sliced {
 		for j := range sliced[i] {
@@ -216,9 +307,9 @@ func reshapeBytes(array []byte, size [3]int) [][][]byte {
 func (b *Regions) shift(dx int) {
 	// TODO: return if no regions defined
 	r1 := b.Gpu()
-	r2 := cuda.NewBytes(b.Mesh().NCell()) // TODO: somehow recycle
+	r2 := newRegionBytes(b.Mesh().NCell()) // TODO: somehow recycle
 	defer r2.Free()
-	newreg := byte(0) // new region at edge
+	newreg := 0 // new region at edge
 	cuda.ShiftBytes(r2, r1, b.Mesh(), dx, newreg)
 	r1.Copy(r2)
 
@@ -15,6 +15,10 @@ func init() {
 var relaxing = false
 
 func Relax() {
+	if _, ok := beginRun(); !ok {
+		return
+	}
+	defer endRun()
 	SanityCheck()
 	pause = false
 
@@ -153,19 +153,33 @@ func adaptDt(corr float64) {
 
 // Run the simulation for a number of seconds.
 func Run(seconds float64) {
-	stop := Time + seconds
+	start, ok := beginRun()
+	if !ok {
+		return
+	}
+	defer endRun()
+	stop := start.Time + seconds
 	alarm = stop // don't have dt adapt to go over alarm
 	RunWhile(func() bool { return Time < stop })
 }
 
 // Run the simulation for a number of steps.
 func Steps(n int) {
-	stop := NSteps + n
+	start, ok := beginRun()
+	if !ok {
+		return
+	}
+	defer endRun()
+	stop := start.NSteps + n
 	RunWhile(func() bool { return NSteps < stop })
 }
 
 // Runs as long as condition returns true, saves output.
 func RunWhile(condition func() bool) {
+	if _, ok := beginRun(); !ok {
+		return
+	}
+	defer endRun()
 	SanityCheck()
 	pause = false // may be set by <-Inject
 	const output = true
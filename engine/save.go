@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"bytes"
 	"fmt"
 	"path"
 	"reflect"
@@ -11,6 +12,7 @@ import (
 	"github.com/mumax/3/draw"
 	"github.com/mumax/3/dump"
 	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/npy"
 	"github.com/mumax/3/oommf"
 	"github.com/mumax/3/util"
 )
@@ -20,21 +22,27 @@ func init() {
 	DeclFunc("SaveAs", SaveAs, "Save space-dependent with custom filename")
 
 	DeclLValue("FilenameFormat", &fformat{}, "printf formatting string for output filenames.")
-	DeclLValue("OutputFormat", &oformat{}, "Format for data files: OVF1_TEXT, OVF1_BINARY, OVF2_TEXT or OVF2_BINARY")
+	DeclLValue("OutputFormat", &oformat{}, "Format for data files: OVF1_TEXT, OVF1_BINARY, OVF2_TEXT, OVF2_BINARY, DUMP, NPY or NPZ")
 
 	DeclROnly("OVF1_BINARY", OVF1_BINARY, "OutputFormat = OVF1_BINARY sets binary OVF1 output")
 	DeclROnly("OVF2_BINARY", OVF2_BINARY, "OutputFormat = OVF2_BINARY sets binary OVF2 output")
 	DeclROnly("OVF1_TEXT", OVF1_TEXT, "OutputFormat = OVF1_TEXT sets text OVF1 output")
 	DeclROnly("OVF2_TEXT", OVF2_TEXT, "OutputFormat = OVF2_TEXT sets text OVF2 output")
 	DeclROnly("DUMP", DUMP, "OutputFormat = DUMP sets text DUMP output")
+	DeclROnly("NPY", NPY, "OutputFormat = NPY sets NumPy .npy output")
+	DeclROnly("NPZ", NPZ, "OutputFormat = NPZ sets NumPy .npz output, including time, cell size, name and unit")
+	DeclVar("OVFSeries", &OVFSeries, "Save OVF2 output as consecutive segments of one file per quantity (e.g. m.ovf), instead of one file per save")
 	DeclFunc("Snapshot", Snapshot, "Save image of quantity")
 	DeclVar("SnapshotFormat", &SnapshotFormat, "Image format for snapshots: jpg, png or gif.")
+	DeclVar("SnapshotAnnotate", &SnapshotAnnotate, "Add title, color bar or color wheel and scale bar to snapshots")
 }
 
 var (
-	FilenameFormat = "%s%06d"    // formatting string for auto filenames.
-	SnapshotFormat = "jpg"       // user-settable snapshot format
-	outputFormat   = OVF2_BINARY // user-settable output format
+	FilenameFormat   = "%s%06d"    // formatting string for auto filenames.
+	SnapshotFormat   = "jpg"       // user-settable snapshot format
+	SnapshotAnnotate = false       // add title, legend and scale bar to snapshots
+	outputFormat     = OVF2_BINARY // user-settable output format
+	OVFSeries        = false       // pack OVF2 output of Save/AutoSave into one file per quantity
 )
 
 type fformat struct{}
@@ -51,13 +59,67 @@ func (*oformat) Type() reflect.Type     { return reflect.TypeOf(OutputFormat(OVF
 
 // Save once, with auto file name
 func Save(q Quantity) {
+	if OVFSeries && (outputFormat == OVF2_TEXT || outputFormat == OVF2_BINARY) {
+		saveSeries(q)
+		return
+	}
 	fname := autoFname(NameOf(q), outputFormat, autonum[q])
 	SaveAs(q, fname)
 	autonum[q]++
 }
 
+// number of segments in each OVF2 time series file, by file name
+var seriesFiles = make(map[string]int)
+
+// Save as next segment of the OVF2 time series of q (e.g. m.ovf).
+func saveSeries(q Quantity) {
+	if replaying() {
+		autonum[q]++
+		return
+	}
+	fname := OD() + NameOf(q) + "." + StringFromOutputFormat[outputFormat]
+	buffer := ValueOf(q)
+	defer cuda.Recycle(buffer)
+	info := data.Meta{Time: Time, Name: NameOf(q), Unit: UnitOf(q), CellSize: MeshOf(q).CellSize()}
+	data := buffer.HostCopy() // must be copy (async io)
+	format := outputFormat
+	queOutput(func() { appendSeries_sync(fname, data, info, format) })
+	autonum[q]++
+}
+
+// synchronously add the next segment to an OVF2 time series.
+// The segments are counted per file, independent of autonum,
+// which ordinary Saves of the same quantity advance as well.
+func appendSeries_sync(fname string, s *data.Slice, info data.Meta, format OutputFormat) {
+	dataformat := "binary 4"
+	if format == OVF2_TEXT {
+		dataformat = "text"
+	}
+	var buf bytes.Buffer
+	num := seriesFiles[fname]
+	seriesFiles[fname] = num + 1
+	if num == 0 {
+		oommf.WriteOVF2Series(&buf, s, info, dataformat)
+		util.FatalErr(httpfs.Put(fname, buf.Bytes()))
+		return
+	}
+	oommf.AppendOVF2(&buf, s, info, dataformat)
+	util.FatalErr(httpfs.Append(fname, buf.Bytes()))
+	util.FatalErr(oommf.SetSegmentCount(httpfsWriterAt(fname), num+1))
+}
+
+// io.WriterAt for a file given by URL
+type httpfsWriterAt string
+
+func (f httpfsWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return len(p), httpfs.WriteAt(string(f), p, off)
+}
+
 // Save under given file name (transparent async I/O).
 func SaveAs(q Quantity, fname string) {
+	if replaying() {
+		return
+	}
 
 	if !strings.HasPrefix(fname, OD()) {
 		fname = OD() + fname // don't clean, turns http:// in http:/
@@ -75,20 +137,32 @@ func SaveAs(q Quantity, fname string) {
 
 // Save image once, with auto file name
 func Snapshot(q Quantity) {
+	if replaying() {
+		autonum[q]++
+		return
+	}
 	fname := fmt.Sprintf(OD()+FilenameFormat+"."+SnapshotFormat, NameOf(q), autonum[q])
 	s := ValueOf(q)
 	defer cuda.Recycle(s)
+	info := data.Meta{Time: Time, Name: NameOf(q), Unit: UnitOf(q), CellSize: MeshOf(q).CellSize()}
 	data := s.HostCopy() // must be copy (asyncio)
-	queOutput(func() { snapshot_sync(fname, data) })
+	annotate := SnapshotAnnotate
+	queOutput(func() { snapshot_sync(fname, data, info, annotate) })
 	autonum[q]++
 }
 
 // synchronous snapshot
-func snapshot_sync(fname string, output *data.Slice) {
+func snapshot_sync(fname string, output *data.Slice, info data.Meta, annotate bool) {
 	f, err := httpfs.Create(fname)
 	util.FatalErr(err)
 	defer f.Close()
-	draw.RenderFormat(f, output, "auto", "auto", arrowSize, path.Ext(fname))
+	if !annotate {
+		draw.RenderFormat(f, output, "auto", "auto", arrowSize, path.Ext(fname))
+		return
+	}
+	a := draw.Annotation{Title: draw.AutoTitle(info), Unit: info.Unit, Legend: true, CellSize: info.CellSize}
+	img := draw.AnnotatedImage(output, "auto", "auto", arrowSize, a)
+	util.FatalErr(draw.EncodeFormat(f, img, path.Ext(fname)))
 }
 
 // synchronous save
@@ -108,6 +182,10 @@ func saveAs_sync(fname string, s *data.Slice, info data.Meta, format OutputForma
 		oommf.WriteOVF2(f, s, info, "binary 4")
 	case DUMP:
 		dump.Write(f, s, info)
+	case NPY:
+		util.FatalErr(npy.Write(f, s, info))
+	case NPZ:
+		util.FatalErr(npy.WriteNPZ(f, s, info))
 	default:
 		panic("invalid output format")
 	}
@@ -122,6 +200,8 @@ const (
 	OVF2_TEXT
 	OVF2_BINARY
 	DUMP
+	NPY
+	NPZ
 )
 
 var (
@@ -130,5 +210,7 @@ var (
 		OVF1_BINARY: "ovf",
 		OVF2_TEXT:   "ovf",
 		OVF2_BINARY: "ovf",
-		DUMP:        "dump"}
+		DUMP:        "dump",
+		NPY:         "npy",
+		NPZ:         "npz"}
 )
@@ -87,6 +87,20 @@ func DeclLValue(name string, value LValue, doc string) {
 	GUIAdd(name, value)
 }
 
+// IdentUnit returns the unit of the declared parameter or quantity with given name,
+// or "" if it has none (e.g. for functions). Used by mumax3-lsp.
+func IdentUnit(name string) string {
+	if p, ok := gui_.Params[name]; ok {
+		return p.Unit()
+	}
+	if q, ok := gui_.Quants[name]; ok {
+		if u := UnitOf(q); u != "?" {
+			return u
+		}
+	}
+	return ""
+}
+
 // LValue is settable
 type LValue interface {
 	SetValue(interface{}) // assigns a new value
@@ -99,8 +113,9 @@ func EvalFile(code *script.BlockStmt) {
 	for i := range code.Children {
 		formatted := rmln(script.Format(code.Node[i]))
 		LogIn(formatted)
-		code.Children[i].Eval()
+		code.EvalStmt(i)
 	}
+	checkResumed()
 }
 
 // wraps LValue and provides empty Child()
@@ -115,6 +130,16 @@ func newLValueWrapper(lv LValue) script.LValue {
 func (w *lValueWrapper) Child() []script.Expr { return nil }
 func (w *lValueWrapper) Fix() script.Expr     { return script.NewConst(w) }
 
+// Unit returns the declared unit, used by the script's dimension check.
+func (w *lValueWrapper) Unit() string {
+	if u, ok := w.LValue.(interface {
+		Unit() string
+	}); ok {
+		return u.Unit()
+	}
+	return ""
+}
+
 func (w *lValueWrapper) InputType() reflect.Type {
 	if i, ok := w.LValue.(interface {
 		InputType() reflect.Type
@@ -1,12 +1,15 @@
 package engine
 
 import (
+	"fmt"
 	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/trimesh"
 	"github.com/mumax/3/util"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
 	"math"
+	"path"
 )
 
 func init() {
@@ -25,6 +28,7 @@ func init() {
 	DeclFunc("Universe", Universe, "Entire space")
 	DeclFunc("Cell", Cell, "Single cell with given integer index (i, j, k)")
 	DeclFunc("ImageShape", ImageShape, "Use black/white image as shape")
+	DeclFunc("MeshShape", MeshShape, "Use closed surface from STL or OBJ file as shape. Args: file name, length of one file unit in meter, center around origin or not")
 	DeclFunc("GrainRoughness", GrainRoughness, "Grainy surface with different heights per grain")
 }
 
@@ -179,6 +183,31 @@ func ImageShape(fname string) Shape {
 	}
 }
 
+// Closed triangulated surface read from an STL (ASCII or binary) or Wavefront OBJ file.
+// unit is the length of one file unit in meter, e.g. 1e-9 if the CAD file is in nm.
+// If center is true, the mesh is centered around the origin, like the other shapes,
+// otherwise the file's coordinates are used.
+func MeshShape(fname string, unit float64, center bool) Shape {
+	r, err1 := httpfs.Open(fname)
+	CheckRecoverable(err1)
+	defer r.Close()
+	m, err2 := trimesh.Read(r, path.Ext(fname))
+	CheckRecoverable(err2)
+	if len(m.Triangles) == 0 {
+		CheckRecoverable(fmt.Errorf("%v: no triangles", fname))
+	}
+
+	var offset trimesh.Vector
+	if center {
+		min, max := m.Bounds()
+		for i := range offset {
+			offset[i] = -unit * (min[i] + max[i]) / 2
+		}
+	}
+	m.Transform(unit, offset)
+	return trimesh.NewSolid(m).Inside
+}
+
 func GrainRoughness(grainsize, zmin, zmax float64, seed int) Shape {
 	t := newTesselation(grainsize, 256, int64(seed))
 	return func(x, y, z float64) bool {
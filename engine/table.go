@@ -5,10 +5,12 @@ import (
 	"github.com/mumax/3/cuda"
 	"github.com/mumax/3/data"
 	"github.com/mumax/3/httpfs"
+	"github.com/mumax/3/odt"
 	"github.com/mumax/3/script"
 	"github.com/mumax/3/timer"
 	"github.com/mumax/3/util"
 	"io"
+	"reflect"
 	"sync"
 	"time"
 )
@@ -22,9 +24,32 @@ func init() {
 	DeclFunc("TableSave", TableSave, "Save the data table right now (appends one line).")
 	DeclFunc("TableAutoSave", TableAutoSave, "Auto-save the data table every period (s). Zero disables save.")
 	DeclFunc("TablePrint", TablePrint, "Print anyting in the data table")
+	DeclLValue("TableFormat", &tformat{}, "Format for the data table: TXT (tab-separated table.txt) or ODT (OOMMF table.odt)")
+	DeclROnly("TXT", TXT, "TableFormat = TXT sets tab-separated table output")
+	DeclROnly("ODT", ODT, "TableFormat = ODT sets OOMMF ODT table output")
 	Table.Add(&M)
 }
 
+type TableFormat int
+
+const (
+	TXT TableFormat = iota + 1
+	ODT
+)
+
+var tableFormat = TXT // user-settable table format
+
+type tformat struct{}
+
+func (*tformat) Eval() interface{} { return tableFormat }
+func (*tformat) SetValue(v interface{}) {
+	if Table.inited() {
+		util.Fatal("TableFormat: need to set format before table is output the first time")
+	}
+	tableFormat = v.(TableFormat)
+}
+func (*tformat) Type() reflect.Type { return reflect.TypeOf(TableFormat(TXT)) }
+
 type DataTable struct {
 	output interface {
 		io.Writer
@@ -34,11 +59,13 @@ type DataTable struct {
 	outputs []Quantity
 	autosave
 	flushlock sync.Mutex
+	size      int64 // bytes written, needed for checkpoints
 }
 
 func (t *DataTable) Write(p []byte) (int, error) {
 	n, err := t.output.Write(p)
 	util.FatalErr(err)
+	t.size += int64(n)
 	return n, err
 }
 
@@ -108,6 +135,9 @@ func (t *DataTable) Add(output Quantity) {
 }
 
 func (t *DataTable) Save() {
+	if replaying() {
+		return
+	}
 	t.flushlock.Lock() // flush during write gives errShortWrite
 	defer t.flushlock.Unlock()
 
@@ -132,6 +162,9 @@ func (t *DataTable) Save() {
 }
 
 func (t *DataTable) Println(msg ...interface{}) {
+	if replaying() {
+		return
+	}
 	t.init()
 	fprintln(t, msg...)
 }
@@ -145,11 +178,15 @@ func (t *DataTable) init() {
 	if t.inited() {
 		return
 	}
-	f, err := httpfs.Create(OD() + t.name + ".txt")
+	f, err := httpfs.Create(t.fname())
 	util.FatalErr(err)
 	t.output = f
 
 	// write header
+	if tableFormat == ODT {
+		t.writeODTHeader()
+		return
+	}
 	fprint(t, "# t (s)")
 	for _, o := range t.outputs {
 		if o.NComp() == 1 {
@@ -162,14 +199,66 @@ func (t *DataTable) init() {
 	}
 	fprintln(t)
 	t.Flush()
+	t.autoFlush()
+}
+
+// ODT header, column names without units
+func (t *DataTable) writeODTHeader() {
+	cols, units := []string{"t"}, []string{"s"}
+	for _, o := range t.outputs {
+		if o.NComp() == 1 {
+			cols = append(cols, NameOf(o))
+			units = append(units, UnitOf(o))
+		} else {
+			for c := 0; c < o.NComp(); c++ {
+				cols = append(cols, NameOf(o)+string(rune('x'+c)))
+				units = append(units, UnitOf(o))
+			}
+		}
+	}
+	util.FatalErr(odt.WriteHeader(t, "mumax3", cols, units))
+	t.Flush()
+	t.autoFlush()
+}
+
+// file name, depending on TableFormat
+func (t *DataTable) fname() string {
+	if tableFormat == ODT {
+		return OD() + t.name + ".odt"
+	}
+	return OD() + t.name + ".txt"
+}
+
+// terminate and flush the table at exit
+func (t *DataTable) close() {
+	if t.inited() && tableFormat == ODT && !replaying() {
+		t.flushlock.Lock()
+		util.FatalErr(odt.WriteEnd(t))
+		t.flushlock.Unlock()
+	}
+	t.flush()
+}
+
+// re-open the table file when resuming from a checkpoint,
+// dropping what was written after the checkpoint.
+func (t *DataTable) resume(size int64) {
+	fname := t.fname()
+	truncate(fname, size)
+	f, err := httpfs.OpenAppend(fname)
+	util.FatalErr(err)
+	t.output = f
+	t.size = size
+	t.autoFlush()
+}
 
-	// periodically flush so GUI shows graph,
-	// but don't flush after every output for performance
-	// (httpfs flush is expensive)
+// periodically flush so GUI shows graph,
+// but don't flush after every output for performance
+// (httpfs flush is expensive)
+func (t *DataTable) autoFlush() {
 	go func() {
 		for {
 			time.Sleep(TableAutoflushRate * time.Second)
-			Table.flush()
+			t.flush()
 		}
 	}()
 }
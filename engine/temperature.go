@@ -2,7 +2,6 @@ package engine
 
 import (
 	"github.com/mumax/3/cuda"
-	"github.com/mumax/3/cuda/curand"
 	"github.com/mumax/3/data"
 	"github.com/mumax/3/mag"
 	"github.com/mumax/3/util"
@@ -19,11 +18,11 @@ var AddThermalEnergyDensity = makeEdensAdder(&B_therm, -1)
 
 // thermField calculates and caches thermal noise.
 type thermField struct {
-	seed      int64            // seed for generator
-	generator curand.Generator //
-	noise     *data.Slice      // noise buffer
-	step      int              // solver step corresponding to noise
-	dt        float64          // solver timestep corresponding to noise
+	seed      int64           // seed for generator
+	generator *cuda.Generator //
+	noise     *data.Slice     // noise buffer
+	step      int             // solver step corresponding to noise
+	dt        float64         // solver timestep corresponding to noise
 }
 
 func init() {
@@ -47,9 +46,8 @@ func (b *thermField) update() {
 		Dt_si = FixDt
 	}
 
-	if b.generator == 0 {
-		b.generator = curand.CreateGenerator(curand.PSEUDO_DEFAULT)
-		b.generator.SetSeed(b.seed)
+	if b.generator == nil {
+		b.generator = cuda.NewGenerator(b.seed)
 	}
 	if b.noise == nil {
 		b.noise = cuda.NewSlice(b.NComp(), b.Mesh().Size())
@@ -74,7 +72,6 @@ func (b *thermField) update() {
 		util.Fatal("Finite temperature requires fixed time step. Set FixDt != 0.")
 	}
 
-	N := Mesh().NCell()
 	k2_VgammaDt := 2 * mag.Kb / (GammaLL * cellVolume() * Dt_si)
 	noise := cuda.Buffer(1, Mesh().Size())
 	defer cuda.Recycle(noise)
@@ -89,7 +86,7 @@ func (b *thermField) update() {
 	alpha := Alpha.MSlice()
 	defer alpha.Recycle()
 	for i := 0; i < 3; i++ {
-		b.generator.GenerateNormal(uintptr(noise.DevPtr(0)), int64(N), mean, stddev)
+		b.generator.GenerateNormal(noise, mean, stddev)
 		cuda.SetTemperature(dst.Comp(i), noise, k2_VgammaDt, ms, temp, alpha)
 	}
 
@@ -108,7 +105,7 @@ func GetThermalEnergy() float64 {
 // Seeds the thermal noise generator
 func ThermSeed(seed int) {
 	B_therm.seed = int64(seed)
-	if B_therm.generator != 0 {
+	if B_therm.generator != nil {
 		B_therm.generator.SetSeed(B_therm.seed)
 	}
 }
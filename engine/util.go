@@ -7,6 +7,7 @@ import (
 	"github.com/mumax/3/dump"
 	"github.com/mumax/3/httpfs"
 	"github.com/mumax/3/mag"
+	"github.com/mumax/3/npy"
 	"github.com/mumax/3/oommf"
 	"github.com/mumax/3/util"
 	"math"
@@ -52,6 +53,9 @@ func Vector(x, y, z float64) data.Vector {
 // Test if have lies within want +/- maxError,
 // and print suited message.
 func Expect(msg string, have, want, maxError float64) {
+	if replaying() {
+		return
+	}
 	if math.IsNaN(have) || math.IsNaN(want) || math.Abs(have-want) > maxError {
 		LogOut(msg, ":", " have: ", have, " want: ", want, "±", maxError)
 		Close()
@@ -73,6 +77,9 @@ func Fprintln(filename string, msg ...interface{}) {
 	if !path.IsAbs(filename) {
 		filename = OD() + filename
 	}
+	if replaying() {
+		return
+	}
 	httpfs.Touch(filename)
 	err := httpfs.Append(filename, []byte(fmt.Sprintln(myFmt(msg)...)))
 	util.FatalErr(err)
@@ -80,12 +87,16 @@ func Fprintln(filename string, msg ...interface{}) {
 
 // Read a magnetization state from .dump file.
 func LoadFile(fname string) *data.Slice {
+	drainOutput() // the file may just have been saved
 	in, err := httpfs.Open(fname)
 	util.FatalErr(err)
 	var s *data.Slice
-	if path.Ext(fname) == ".dump" {
+	switch path.Ext(fname) {
+	case ".dump":
 		s, _, err = dump.Read(in)
-	} else {
+	case ".npy", ".npz":
+		s, _, err = npy.Read(in)
+	default:
 		s, _, err = oommf.Read(in)
 	}
 	util.FatalErr(err)
@@ -158,9 +169,9 @@ func safediv(a, b float32) float32 {
 }
 
 // dst = a/b, unless b == 0
-func paramDiv(dst, a, b [][NREGION]float32) {
+func paramDiv(dst, a, b [][]float32) {
 	util.Assert(len(dst) == 1 && len(a) == 1 && len(b) == 1)
-	for i := 0; i < NREGION; i++ { // not regions.maxreg
+	for i := 0; i < nRegion; i++ { // not regions.maxreg
 		dst[0][i] = safediv(a[0][i], b[0][i])
 	}
 }
@@ -0,0 +1,66 @@
+package fft
+
+import (
+	"math"
+)
+
+// Bluestein's algorithm: a length-n DFT written as a convolution,
+// evaluated with power-of-two FFTs:
+//
+//	X[k] = w[k] * sum_j (x[j] w[j]) conj(w[k-j]),  w[k] = exp(-πi k²/n)
+type bluestein struct {
+	n     int
+	chirp []complex128 // w[k]
+	kern  []complex128 // FFT of conj(w), wrapped around, scaled by 1/m
+	buf   []complex128 // convolution buffer, length m
+	sub   *Plan        // power-of-two plan, length m >= 2n-1
+}
+
+func newBluestein(n int) *bluestein {
+	m := 1
+	for m < 2*n-1 {
+		m *= 2
+	}
+	b := &bluestein{n: n, chirp: make([]complex128, n), kern: make([]complex128, m), buf: make([]complex128, m), sub: NewPlan(m)}
+
+	for k := range b.chirp {
+		// k² mod 2n keeps the phase accurate for large k
+		k2 := (int64(k) * int64(k)) % int64(2*n)
+		b.chirp[k] = expi(-math.Pi * float64(k2) / float64(n))
+	}
+
+	b.kern[0] = conjc(b.chirp[0])
+	for k := 1; k < n; k++ {
+		b.kern[k] = conjc(b.chirp[k])
+		b.kern[m-k] = conjc(b.chirp[k])
+	}
+	b.sub.Forward(b.kern)
+	scale := complex(1/float64(m), 0)
+	for i := range b.kern {
+		b.kern[i] *= scale
+	}
+	return b
+}
+
+// in-place forward transform
+func (b *bluestein) transform(x []complex128) {
+	buf := b.buf
+	for k := range x {
+		buf[k] = x[k] * b.chirp[k]
+	}
+	for k := len(x); k < len(buf); k++ {
+		buf[k] = 0
+	}
+	b.sub.Forward(buf)
+	for i := range buf {
+		buf[i] *= b.kern[i]
+	}
+	b.sub.Inverse(buf)
+	for k := range x {
+		x[k] = buf[k] * b.chirp[k]
+	}
+}
+
+func conjc(x complex128) complex128 {
+	return complex(real(x), -imag(x))
+}
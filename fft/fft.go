@@ -0,0 +1,189 @@
+// Package fft provides pure-Go fast Fourier transforms of arbitrary length.
+//
+// Lengths are factored into radices 4, 2, 3, 5, ... and transformed with a
+// recursive mixed-radix Cooley-Tukey algorithm. Lengths with a large prime
+// factor are handled by Bluestein's algorithm, so that every length costs
+// O(N log N).
+//
+// Transforms are unnormalized, like FFTW and cuFFT:
+// Inverse(Forward(x)) = N*x.
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Prime factors larger than this are not used as a radix,
+// lengths containing them are transformed with Bluestein's algorithm.
+const maxRadix = 31
+
+// Plan holds the pre-computed twiddle factors for 1D complex transforms of one length.
+// A Plan uses internal scratch space, so it must not be used by multiple goroutines at once.
+type Plan struct {
+	n       int
+	factors []int        // radices, product is n
+	tw      []complex128 // tw[k] = exp(-2πik/n)
+	work    []complex128 // scratch space, length n
+	tmp     []complex128 // scratch space for butterflies, length max(factors)
+	blue    *bluestein   // used instead of factors for lengths with large prime factors
+}
+
+// NewPlan returns a plan for transforms of length n.
+func NewPlan(n int) *Plan {
+	if n < 1 {
+		panic(fmt.Sprint("fft: invalid length ", n))
+	}
+	p := &Plan{n: n, work: make([]complex128, n)}
+	factors, ok := factorize(n)
+	if !ok {
+		p.blue = newBluestein(n)
+		return p
+	}
+	p.factors = factors
+	p.tw = twiddles(n)
+	maxf := 1
+	for _, f := range factors {
+		if f > maxf {
+			maxf = f
+		}
+	}
+	p.tmp = make([]complex128, maxf)
+	return p
+}
+
+// Len returns the transform length.
+func (p *Plan) Len() int { return p.n }
+
+// Forward replaces x by its discrete Fourier transform:
+//
+//	X[k] = sum_j x[j] exp(-2πi jk/N)
+func (p *Plan) Forward(x []complex128) {
+	if len(x) != p.n {
+		panic(fmt.Sprint("fft: length mismatch: plan ", p.n, ", data ", len(x)))
+	}
+	if p.blue != nil {
+		p.blue.transform(x)
+		return
+	}
+	copy(p.work, x)
+	p.transform(x, p.work, 1, p.n, 0)
+}
+
+// Inverse replaces x by its unnormalized inverse discrete Fourier transform:
+//
+//	x[j] = sum_k X[k] exp(+2πi jk/N)
+func (p *Plan) Inverse(x []complex128) {
+	conj(x)
+	p.Forward(x)
+	conj(x)
+}
+
+// recursive mixed-radix decimation in time:
+// dst[0:n] = DFT of src[0], src[stride], ... src[(n-1)*stride],
+// using factors[f:].
+func (p *Plan) transform(dst, src []complex128, stride, n, f int) {
+	if n == 1 {
+		dst[0] = src[0]
+		return
+	}
+
+	r := p.factors[f]
+	m := n / r
+
+	// DFT of the r decimated sub-sequences,
+	// sub-sequence j ends up in dst[j*m : (j+1)*m].
+	if m == 1 {
+		for j := 0; j < r; j++ {
+			dst[j] = src[j*stride]
+		}
+	} else {
+		for j := 0; j < r; j++ {
+			p.transform(dst[j*m:(j+1)*m], src[j*stride:], stride*r, m, f+1)
+		}
+	}
+
+	// combine with butterflies of size r
+	step := p.n / n // twiddle table stride for length n
+	switch r {
+	case 2:
+		for k := 0; k < m; k++ {
+			a := dst[k]
+			b := dst[k+m] * p.tw[k*step]
+			dst[k] = a + b
+			dst[k+m] = a - b
+		}
+	case 4:
+		for k := 0; k < m; k++ {
+			t0 := dst[k]
+			t1 := dst[k+m] * p.tw[k*step]
+			t2 := dst[k+2*m] * p.tw[2*k*step]
+			t3 := dst[k+3*m] * p.tw[3*k*step]
+			y0, y1 := t0+t2, t0-t2
+			y2, y3 := t1+t3, mulMinusI(t1-t3)
+			dst[k] = y0 + y2
+			dst[k+m] = y1 + y3
+			dst[k+2*m] = y0 - y2
+			dst[k+3*m] = y1 - y3
+		}
+	default:
+		t := p.tmp[:r]
+		rstep := p.n / r // twiddle table stride for length r
+		for k := 0; k < m; k++ {
+			for j := range t {
+				t[j] = dst[k+j*m] * p.tw[j*k*step]
+			}
+			for q := 0; q < r; q++ {
+				sum := t[0]
+				for j := 1; j < r; j++ {
+					sum += t[j] * p.tw[((j*q)%r)*rstep]
+				}
+				dst[k+q*m] = sum
+			}
+		}
+	}
+}
+
+// factorize splits n into radices, largest-first radix 4.
+// ok is false if n has a prime factor larger than maxRadix.
+func factorize(n int) (factors []int, ok bool) {
+	for n%4 == 0 {
+		factors = append(factors, 4)
+		n /= 4
+	}
+	for f := 2; f <= maxRadix && n > 1; f++ {
+		for n%f == 0 {
+			factors = append(factors, f)
+			n /= f
+		}
+	}
+	return factors, n == 1
+}
+
+// twiddles returns exp(-2πik/n), k = 0..n-1.
+func twiddles(n int) []complex128 {
+	tw := make([]complex128, n)
+	for k := range tw {
+		tw[k] = expi(-2 * math.Pi * float64(k) / float64(n))
+	}
+	return tw
+}
+
+// exp(i*phi)
+func expi(phi float64) complex128 {
+	s, c := math.Sincos(phi)
+	return complex(c, s)
+}
+
+// returns -i*x
+func mulMinusI(x complex128) complex128 {
+	return complex(imag(x), -real(x))
+}
+
+// complex conjugate in-place
+func conj(x []complex128) {
+	for i, v := range x {
+		x[i] = cmplx.Conj(v)
+	}
+}
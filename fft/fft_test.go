@@ -0,0 +1,58 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// naive O(N²) DFT for reference
+func dft(x []complex128, sign float64) []complex128 {
+	n := len(x)
+	X := make([]complex128, n)
+	for k := range X {
+		for j := range x {
+			X[k] += x[j] * expi(sign*2*math.Pi*float64((j*k)%n)/float64(n))
+		}
+	}
+	return X
+}
+
+func TestFFT(t *testing.T) {
+	lengths := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 12, 16, 25, 30, 31, 37, 64, 97, 100, 128, 210, 243, 256, 1009}
+	for _, n := range lengths {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rand.Float64()-0.5, rand.Float64()-0.5)
+		}
+		p := NewPlan(n)
+
+		for _, dir := range []struct {
+			sign float64
+			exec func([]complex128)
+		}{{-1, p.Forward}, {+1, p.Inverse}} {
+			want := dft(x, dir.sign)
+			got := append([]complex128{}, x...)
+			dir.exec(got)
+			for k := range got {
+				if err := cmplx.Abs(got[k] - want[k]); err > 1e-10*float64(n) {
+					t.Fatalf("n=%v sign=%v: X[%v]=%v, want %v", n, dir.sign, k, got[k], want[k])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkFFT1024(b *testing.B) { benchmarkFFT(b, 1024) }
+func BenchmarkFFT1000(b *testing.B) { benchmarkFFT(b, 1000) }
+func BenchmarkFFT1009(b *testing.B) { benchmarkFFT(b, 1009) }
+
+func benchmarkFFT(b *testing.B, n int) {
+	p := NewPlan(n)
+	x := make([]complex128, n)
+	b.SetBytes(int64(16 * n))
+	for i := 0; i < b.N; i++ {
+		p.Forward(x)
+	}
+}
@@ -81,6 +81,17 @@ func Read(URL string) ([]byte, error) {
 	}
 }
 
+// Size returns the size in bytes of the file at URL. It fails for directories.
+// Remote files are read as a whole.
+func Size(URL string) (int64, error) {
+	URL = addWorkDir(URL)
+	if isRemote(URL) {
+		return httpSize(URL)
+	} else {
+		return localSize(URL)
+	}
+}
+
 // Append p to the file given by URL,
 // but first assure that the file had the expected size.
 // Used to avoid accidental concurrent writes by two processes to the same file.
@@ -99,6 +110,17 @@ func Append(URL string, p []byte) error {
 	return AppendSize(URL, p, -1)
 }
 
+// Overwrite part of the file given by URL with p, starting at byte offset off.
+// Remote files are read and put back as a whole.
+func WriteAt(URL string, p []byte, off int64) error {
+	URL = addWorkDir(URL)
+	if isRemote(URL) {
+		return httpWriteAt(URL, p, off)
+	} else {
+		return localWriteAt(URL, p, off)
+	}
+}
+
 // Create file given by URL and put data from p there.
 func Put(URL string, p []byte) error {
 	URL = addWorkDir(URL)
@@ -161,6 +183,23 @@ func httpPut(URL string, data []byte) error {
 	return err
 }
 
+func httpWriteAt(URL string, p []byte, off int64) error {
+	data, err := httpRead(URL)
+	if err != nil {
+		return err
+	}
+	if off+int64(len(p)) > int64(len(data)) {
+		data = append(data, make([]byte, off+int64(len(p))-int64(len(data)))...)
+	}
+	copy(data[off:], p)
+	return httpPut(URL, data)
+}
+
+func httpSize(URL string) (int64, error) {
+	data, err := httpRead(URL)
+	return int64(len(data)), err
+}
+
 func httpRead(URL string) ([]byte, error) {
 	return do(READ, URL, nil, nil)
 }
@@ -105,10 +105,32 @@ func localPut(fname string, data []byte) error {
 	return err2
 }
 
+func localWriteAt(fname string, data []byte, off int64) error {
+	f, err := os.OpenFile(fname, os.O_WRONLY, FilePerm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err2 := f.WriteAt(data, off)
+	return err2
+}
+
 func localRead(fname string) ([]byte, error) {
 	return ioutil.ReadFile(fname)
 }
 
+func localSize(fname string) (int64, error) {
+	fi, err := os.Stat(fname)
+	if err != nil {
+		return 0, err
+	}
+	if fi.IsDir() {
+		return 0, fmt.Errorf("%v: is a directory", fname)
+	}
+	return fi.Size(), nil
+}
+
 func localRemove(fname string) error {
 	return os.RemoveAll(fname)
 }
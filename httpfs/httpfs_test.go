@@ -218,6 +218,45 @@ func TestPutRead(t *testing.T) {
 	}
 }
 
+func TestWriteAt(t *testing.T) {
+	Remove("testdata")
+	defer Remove("testdata")
+
+	mustPass(t, Mkdir("testdata"))
+	mustFail(t, WriteAt("testdata/file", []byte("x"), 0)) // file does not exist yet
+
+	mustPass(t, Put("testdata/file", []byte("hello httpfs\n")))
+	mustPass(t, WriteAt("testdata/file", []byte("HELLO"), 0))
+	mustPass(t, WriteAt("testdata/file", []byte("FS\n!"), 10))
+
+	b, errR := Read("testdata/file")
+	if errR != nil {
+		t.Error(errR)
+	}
+	if string(b) != "HELLO httpFS\n!" {
+		t.Errorf("%q", b)
+	}
+}
+
+func TestSize(t *testing.T) {
+	Remove("testdata")
+	defer Remove("testdata")
+
+	mustPass(t, Mkdir("testdata"))
+	_, err := Size("testdata/file")
+	mustFail(t, err) // file does not exist yet
+
+	mustPass(t, Put("testdata/file", []byte("hello httpfs\n")))
+	size, err := Size("testdata/file")
+	mustPass(t, err)
+	if size != 13 {
+		t.Error(size)
+	}
+
+	_, err = Size("testdata")
+	mustFail(t, err)
+}
+
 func TestReaderWriter(t *testing.T) {
 	Remove("testdata")
 	defer Remove("testdata")
@@ -29,6 +29,15 @@ func MustCreate(URL string) WriteCloseFlusher {
 	return f
 }
 
+// open an existing file for appending.
+func OpenAppend(URL string) (WriteCloseFlusher, error) {
+	data, err := Read(URL)
+	if err != nil {
+		return nil, err
+	}
+	return &bufWriter{bufio.NewWriterSize(&appendWriter{URL, int64(len(data))}, BUFSIZE)}, nil
+}
+
 type WriteCloseFlusher interface {
 	io.WriteCloser
 	Flush() error
@@ -0,0 +1,431 @@
+/*
+Package npy reads and writes NumPy .npy and .npz files.
+
+Data is written as little-endian float32 arrays shaped [z][y][x][comp],
+so that in Python
+
+	m = numpy.load("m000000.npy")
+	mx = m[..., 0]
+
+A .npy file only holds the data. A .npz archive additionally holds the
+metadata: entry "data" is the array, "time" the time in s, "cellsize" the cell
+size (x, y, z) in m, "name" and "unit" the quantity's name and unit. E.g.:
+
+	f = numpy.load("m000000.npz")
+	m, t = f["data"], f["time"]
+*/
+package npy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/util"
+)
+
+const (
+	MAGIC    = "\x93NUMPY" // identifies .npy format
+	zipMagic = "PK\x03\x04"
+)
+
+// Write the slice to out in .npy format, without metadata.
+func Write(out io.Writer, s *data.Slice, info data.Meta) error {
+	size := s.Size()
+	return writeArray(out, "<f4", []int{size[Z], size[Y], size[X], s.NComp()}, sliceBytes(s))
+}
+
+// Write the slice and its metadata to out in .npz format.
+func WriteNPZ(out io.Writer, s *data.Slice, info data.Meta) error {
+	z := zip.NewWriter(out)
+	c := info.CellSize
+	entries := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"data", func(w io.Writer) error { return Write(w, s, info) }},
+		{"time", func(w io.Writer) error { return writeFloat64s(w, nil, info.Time) }},
+		{"cellsize", func(w io.Writer) error { return writeFloat64s(w, []int{3}, c[X], c[Y], c[Z]) }},
+		{"name", func(w io.Writer) error { return writeString(w, info.Name) }},
+		{"unit", func(w io.Writer) error { return writeString(w, info.Unit) }},
+	}
+	for _, e := range entries {
+		// stored, like numpy.savez
+		w, err := z.CreateHeader(&zip.FileHeader{Name: e.name + ".npy", Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if err := e.write(w); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// Write the slice to file, in .npz format if the extension is .npz,
+// and .npy format otherwise.
+func WriteFile(fname string, s *data.Slice, info data.Meta) error {
+	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if strings.HasSuffix(strings.ToLower(fname), ".npz") {
+		return WriteNPZ(f, s, info)
+	}
+	return Write(f, s, info)
+}
+
+// Write the slice to file, panic on error.
+func MustWriteFile(fname string, s *data.Slice, info data.Meta) {
+	err := WriteFile(fname, s, info)
+	util.FatalErr(err)
+}
+
+// Read a slice from .npy or .npz data.
+// The array should be shaped [z][y][x][comp], or [z][y][x] for scalar data.
+// Metadata is only present in .npz archives.
+func Read(in io.Reader) (*data.Slice, data.Meta, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, in); err != nil {
+		return nil, data.Meta{}, err
+	}
+	b := buf.Bytes()
+	switch {
+	default:
+		return nil, data.Meta{}, fmt.Errorf("npy: bad magic number: %q", b[:min(len(b), len(MAGIC))])
+	case bytes.HasPrefix(b, []byte(MAGIC)):
+		a, err := readArray(bytes.NewReader(b))
+		if err != nil {
+			return nil, data.Meta{}, err
+		}
+		s, err := a.slice()
+		return s, data.Meta{}, err
+	case bytes.HasPrefix(b, []byte(zipMagic)):
+		return readNPZ(b)
+	}
+}
+
+func ReadFile(fname string) (*data.Slice, data.Meta, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, data.Meta{}, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func MustReadFile(fname string) (*data.Slice, data.Meta) {
+	s, t, err := ReadFile(fname)
+	util.FatalErr(err)
+	return s, t
+}
+
+func readNPZ(b []byte) (*data.Slice, data.Meta, error) {
+	var info data.Meta
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, info, err
+	}
+	arrays := make(map[string]*array)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			return nil, info, err
+		}
+		a, err := readArray(r)
+		r.Close()
+		if err != nil {
+			return nil, info, fmt.Errorf("npy: %v: %v", f.Name, err)
+		}
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = a
+	}
+
+	if a, ok := arrays["time"]; ok {
+		if t, err := a.floats(); err == nil && len(t) == 1 {
+			info.Time = t[0]
+		}
+	}
+	if a, ok := arrays["cellsize"]; ok {
+		if c, err := a.floats(); err == nil && len(c) == 3 {
+			info.CellSize = [3]float64{c[0], c[1], c[2]}
+		}
+	}
+	if a, ok := arrays["name"]; ok {
+		info.Name, _ = a.string()
+	}
+	if a, ok := arrays["unit"]; ok {
+		info.Unit, _ = a.string()
+	}
+
+	a, ok := arrays["data"]
+	if !ok {
+		return nil, info, fmt.Errorf("npy: no data entry in npz archive")
+	}
+	s, err := a.slice()
+	return s, info, err
+}
+
+// array as stored in a .npy file
+type array struct {
+	descr string // data type, e.g.: "<f4"
+	shape []int
+	data  []byte
+}
+
+var (
+	descrRegexp   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	fortranRegexp = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	shapeRegexp   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+func readArray(in io.Reader) (*array, error) {
+	var pre [8]byte
+	if _, err := io.ReadFull(in, pre[:]); err != nil {
+		return nil, err
+	}
+	if string(pre[:6]) != MAGIC {
+		return nil, fmt.Errorf("npy: bad magic number: %q", pre[:6])
+	}
+	var hlen int
+	switch pre[6] {
+	case 1:
+		var l uint16
+		if err := binary.Read(in, binary.LittleEndian, &l); err != nil {
+			return nil, err
+		}
+		hlen = int(l)
+	case 2, 3:
+		var l uint32
+		if err := binary.Read(in, binary.LittleEndian, &l); err != nil {
+			return nil, err
+		}
+		hlen = int(l)
+	default:
+		return nil, fmt.Errorf("npy: unsupported version %v.%v", pre[6], pre[7])
+	}
+	header := make([]byte, hlen)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return nil, err
+	}
+
+	h := string(header)
+	descr := descrRegexp.FindStringSubmatch(h)
+	fortran := fortranRegexp.FindStringSubmatch(h)
+	shape := shapeRegexp.FindStringSubmatch(h)
+	if descr == nil || fortran == nil || shape == nil {
+		return nil, fmt.Errorf("npy: bad header: %q", h)
+	}
+	a := &array{descr: descr[1]}
+	for _, s := range strings.Split(shape[1], ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("npy: bad shape: %q", shape[1])
+		}
+		a.shape = append(a.shape, n)
+	}
+	if fortran[1] == "True" && len(a.shape) > 1 {
+		return nil, fmt.Errorf("npy: fortran order not supported")
+	}
+
+	n := a.len()
+	if itemsize := a.itemSize(); itemsize > 0 {
+		a.data = make([]byte, n*itemsize)
+		_, err := io.ReadFull(in, a.data)
+		return a, err
+	}
+	return nil, fmt.Errorf("npy: unsupported data type: %q", a.descr)
+}
+
+// number of elements
+func (a *array) len() int {
+	n := 1
+	for _, s := range a.shape {
+		n *= s
+	}
+	return n
+}
+
+// bytes per element, 0 if not supported
+func (a *array) itemSize() int {
+	if len(a.descr) < 3 {
+		return 0
+	}
+	n, err := strconv.Atoi(a.descr[2:])
+	if err != nil {
+		return 0
+	}
+	switch a.descr[1] {
+	case 'f', 'i', 'u', 'S':
+		return n
+	case 'U':
+		return 4 * n
+	}
+	return 0
+}
+
+func (a *array) byteOrder() binary.ByteOrder {
+	if a.descr[0] == '>' {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// elements converted to float64
+func (a *array) floats() ([]float64, error) {
+	n := a.len()
+	f := make([]float64, n)
+	order := a.byteOrder()
+	switch a.descr[1:] {
+	default:
+		return nil, fmt.Errorf("npy: unsupported data type for numbers: %q", a.descr)
+	case "f4":
+		for i := range f {
+			f[i] = float64(math.Float32frombits(order.Uint32(a.data[4*i:])))
+		}
+	case "f8":
+		for i := range f {
+			f[i] = math.Float64frombits(order.Uint64(a.data[8*i:]))
+		}
+	case "i4":
+		for i := range f {
+			f[i] = float64(int32(order.Uint32(a.data[4*i:])))
+		}
+	case "i8":
+		for i := range f {
+			f[i] = float64(int64(order.Uint64(a.data[8*i:])))
+		}
+	}
+	return f, nil
+}
+
+// single string element
+func (a *array) string() (string, error) {
+	if a.len() != 1 {
+		return "", fmt.Errorf("npy: need single string, have shape %v", a.shape)
+	}
+	switch a.descr[1] {
+	default:
+		return "", fmt.Errorf("npy: unsupported data type for string: %q", a.descr)
+	case 'S':
+		return string(bytes.TrimRight(a.data, "\x00")), nil
+	case 'U':
+		var s []rune
+		order := a.byteOrder()
+		for i := 0; i < len(a.data); i += 4 {
+			if r := rune(order.Uint32(a.data[i:])); r != 0 {
+				s = append(s, r)
+			}
+		}
+		return string(s), nil
+	}
+}
+
+// convert [z][y][x][comp] or [z][y][x] array to slice
+func (a *array) slice() (*data.Slice, error) {
+	var size [3]int
+	nComp := 1
+	switch len(a.shape) {
+	default:
+		return nil, fmt.Errorf("npy: need [z][y][x][comp] or [z][y][x] array, have shape %v", a.shape)
+	case 4:
+		nComp = a.shape[3]
+		fallthrough
+	case 3:
+		size = [3]int{a.shape[2], a.shape[1], a.shape[0]}
+	}
+	f, err := a.floats()
+	if err != nil {
+		return nil, err
+	}
+	s := data.NewSlice(nComp, size)
+	list := s.Host()
+	N := size[X] * size[Y] * size[Z]
+	for i := 0; i < N; i++ {
+		for c := 0; c < nComp; c++ {
+			list[c][i] = float32(f[i*nComp+c])
+		}
+	}
+	return s, nil
+}
+
+// little-endian float32 data, with the component index running fastest
+func sliceBytes(s *data.Slice) []byte {
+	list := s.Host()
+	nComp := s.NComp()
+	N := s.Len()
+	b := make([]byte, 4*N*nComp)
+	for i := 0; i < N; i++ {
+		for c := 0; c < nComp; c++ {
+			binary.LittleEndian.PutUint32(b[4*(i*nComp+c):], math.Float32bits(list[c][i]))
+		}
+	}
+	return b
+}
+
+func writeFloat64s(out io.Writer, shape []int, v ...float64) error {
+	b := make([]byte, 8*len(v))
+	for i, v := range v {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(v))
+	}
+	return writeArray(out, "<f8", shape, b)
+}
+
+func writeString(out io.Writer, s string) error {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		n = 1 // numpy has no zero-length strings
+	}
+	b := make([]byte, 4*n)
+	i := 0
+	for _, r := range s {
+		binary.LittleEndian.PutUint32(b[4*i:], uint32(r))
+		i++
+	}
+	return writeArray(out, fmt.Sprint("<U", n), nil, b)
+}
+
+// write .npy version 1.0 with header padded to a multiple of 64 bytes, like numpy does.
+func writeArray(out io.Writer, descr string, shape []int, data []byte) error {
+	dims := make([]string, len(shape))
+	for i, s := range shape {
+		dims[i] = fmt.Sprint(s)
+	}
+	tuple := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, tuple)
+	pad := 64 - (len(MAGIC)+4+len(header)+1)%64
+	header += strings.Repeat(" ", pad%64) + "\n"
+
+	var pre bytes.Buffer
+	pre.WriteString(MAGIC)
+	pre.Write([]byte{1, 0})
+	binary.Write(&pre, binary.LittleEndian, uint16(len(header)))
+	pre.WriteString(header)
+	if _, err := out.Write(pre.Bytes()); err != nil {
+		return err
+	}
+	_, err := out.Write(data)
+	return err
+}
+
+const (
+	X = data.X
+	Y = data.Y
+	Z = data.Z
+)
@@ -0,0 +1,101 @@
+package npy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/mumax/3/data"
+)
+
+func testSlice() *data.Slice {
+	s := data.NewSlice(3, [3]int{4, 3, 2})
+	for c, l := range s.Host() {
+		for i := range l {
+			l[i] = float32(100*c + i)
+		}
+	}
+	return s
+}
+
+func TestNPY(t *testing.T) {
+	s := testSlice()
+	var buf bytes.Buffer
+	if err := Write(&buf, s, data.Meta{}); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	hlen := int(binary.LittleEndian.Uint16(b[8:]))
+	if (10+hlen)%64 != 0 || b[10+hlen-1] != '\n' {
+		t.Error("header not aligned:", hlen)
+	}
+	want := "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3, 4, 3), }"
+	if string(b[10:10+len(want)]) != want {
+		t.Errorf("header: %q", b[10:10+hlen])
+	}
+	// [z][y][x][comp] order: second element is y component of cell 0
+	if v := math.Float32frombits(binary.LittleEndian.Uint32(b[10+hlen+4:])); v != 100 {
+		t.Error("data order: have", v)
+	}
+
+	s2, _, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, s, s2)
+}
+
+func TestNPZ(t *testing.T) {
+	s := testSlice()
+	info := data.Meta{Name: "m", Unit: "A/m", Time: 1e-9, CellSize: [3]float64{1e-9, 2e-9, 3e-9}}
+	var buf bytes.Buffer
+	if err := WriteNPZ(&buf, s, info); err != nil {
+		t.Fatal(err)
+	}
+	s2, info2, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, s, s2)
+	if info2 != info {
+		t.Error("have", info2, "want", info)
+	}
+}
+
+// mimics the output of numpy.save(f, numpy.arange(6, dtype='>f8').reshape(1, 2, 3))
+func TestReadNumpy(t *testing.T) {
+	header := "{'descr': '>f8', 'fortran_order': False, 'shape': (1, 2, 3), }"
+	header += string(bytes.Repeat([]byte{' '}, 128-10-len(header)-1)) + "\n"
+	var buf bytes.Buffer
+	buf.WriteString(MAGIC + "\x01\x00")
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	for i := 0; i < 6; i++ {
+		binary.Write(&buf, binary.BigEndian, float64(i))
+	}
+
+	s, _, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.NComp() != 1 || s.Size() != [3]int{3, 2, 1} {
+		t.Fatal("have", s.NComp(), "components, size", s.Size())
+	}
+	if s.Get(0, 2, 1, 0) != 5 {
+		t.Error("have", s.Host()[0])
+	}
+}
+
+func checkEqual(t *testing.T, a, b *data.Slice) {
+	if a.NComp() != b.NComp() || a.Size() != b.Size() {
+		t.Fatal("have", b.NComp(), "components, size", b.Size())
+	}
+	for c := range a.Host() {
+		for i := range a.Host()[c] {
+			if a.Host()[c][i] != b.Host()[c][i] {
+				t.Fatal("component", c, "element", i, ": have", b.Host()[c][i], "want", a.Host()[c][i])
+			}
+		}
+	}
+}
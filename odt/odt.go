@@ -0,0 +1,256 @@
+/*
+Package odt reads and writes data tables in the OOMMF ODT 1.0 format:
+
+	# ODT 1.0
+	# Table Start
+	# Title: mumax3
+	# Columns: t mx my mz {Total energy}
+	# Units: s {} {} {} J
+	0 1 0 0 -1e-18
+	...
+	# Table End
+
+Column names and units are Tcl lists: elements containing spaces are enclosed
+in braces, empty ones are written as {}. Data rows are whitespace-separated.
+*/
+package odt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Table holds an ODT table in memory.
+type Table struct {
+	Title   string
+	Columns []string
+	Units   []string
+	Data    [][]float64 // Data[column][row]
+}
+
+// Rows returns the number of data rows.
+func (t *Table) Rows() int {
+	if len(t.Data) == 0 {
+		return 0
+	}
+	return len(t.Data[0])
+}
+
+// Column returns the index of the column with given name (case-insensitive), or -1.
+func (t *Table) Column(name string) int {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TimeColumn returns the index of the simulation time column,
+// and the factor that converts its unit to seconds.
+// mumax3 names it "t", OOMMF "Simulation time" (prefixed by the driver name),
+// or "Sim Time" in older versions.
+func (t *Table) TimeColumn() (int, float64, error) {
+	col := -1
+	for i, c := range t.Columns {
+		c = strings.ToLower(c)
+		if c == "t" || c == "sim time" || strings.HasSuffix(c, "simulation time") {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		return -1, 0, fmt.Errorf("odt: no time column in %v", t.Columns)
+	}
+	unit := ""
+	if col < len(t.Units) {
+		unit = t.Units[col]
+	}
+	scale, ok := timeUnits[unit]
+	if !ok {
+		return -1, 0, fmt.Errorf("odt: unknown time unit: %q", unit)
+	}
+	return col, scale, nil
+}
+
+var timeUnits = map[string]float64{"s": 1, "ms": 1e-3, "us": 1e-6, "ns": 1e-9, "ps": 1e-12, "fs": 1e-15}
+
+// Read an ODT table. Multiple tables in one file are concatenated,
+// provided they have the same columns.
+func Read(in io.Reader) (*Table, error) {
+	t := new(Table)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<24) // OOMMF column lines can be long
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "##") {
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			if err := t.parseHeader(text); err != nil {
+				return nil, fmt.Errorf("odt: line %v: %v", line, err)
+			}
+			continue
+		}
+		if t.Columns == nil {
+			return nil, fmt.Errorf("odt: line %v: data before # Columns", line)
+		}
+		fields := strings.Fields(text)
+		if len(fields) != len(t.Columns) {
+			return nil, fmt.Errorf("odt: line %v: have %v values, need %v", line, len(fields), len(t.Columns))
+		}
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("odt: line %v: %v", line, err)
+			}
+			t.Data[i] = append(t.Data[i], v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if t.Columns == nil {
+		return nil, fmt.Errorf("odt: no columns")
+	}
+	if t.Units == nil {
+		t.Units = make([]string, len(t.Columns))
+	}
+	return t, nil
+}
+
+func ReadFile(fname string) (*Table, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// parse a "# key: value" line
+func (t *Table) parseHeader(line string) error {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	kv := strings.SplitN(line, ":", 2)
+	if len(kv) != 2 {
+		return nil // ODT 1.0, Table Start, Table End
+	}
+	value := strings.TrimSpace(kv[1])
+	switch strings.ToLower(strings.TrimSpace(kv[0])) {
+	case "title":
+		t.Title = value
+	case "columns":
+		cols := splitList(value)
+		if t.Rows() > 0 && !equal(cols, t.Columns) {
+			return fmt.Errorf("tables with different columns")
+		}
+		t.Columns = cols
+		if len(t.Data) != len(cols) {
+			t.Data = make([][]float64, len(cols))
+		}
+	case "units":
+		units := splitList(value)
+		if t.Columns != nil && len(units) != len(t.Columns) {
+			return fmt.Errorf("have %v units for %v columns", len(units), len(t.Columns))
+		}
+		t.Units = units
+	}
+	return nil
+}
+
+// Write the table, including header.
+func (t *Table) Write(out io.Writer) error {
+	if err := WriteHeader(out, t.Title, t.Columns, t.Units); err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	for r := 0; r < t.Rows(); r++ {
+		for c := range t.Data {
+			if c > 0 {
+				w.WriteByte('\t')
+			}
+			w.WriteString(strconv.FormatFloat(t.Data[c][r], 'g', -1, 64))
+		}
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return WriteEnd(out)
+}
+
+// WriteHeader writes the ODT header for a table with given column names and units.
+// The data rows follow, terminated by WriteEnd.
+func WriteHeader(out io.Writer, title string, columns, units []string) error {
+	if len(units) != len(columns) {
+		return fmt.Errorf("odt: have %v units for %v columns", len(units), len(columns))
+	}
+	_, err := fmt.Fprint(out, "# ODT 1.0\n# Table Start\n# Title: ", title, "\n# Columns: ", joinList(columns), "\n# Units: ", joinList(units), "\n")
+	return err
+}
+
+// WriteEnd terminates the table.
+func WriteEnd(out io.Writer) error {
+	_, err := fmt.Fprint(out, "# Table End\n")
+	return err
+}
+
+// Splits a Tcl list, elements may be grouped by braces or double quotes.
+func splitList(s string) []string {
+	list := []string{}
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return list
+		}
+		var elem string
+		switch s[0] {
+		case '{', '"':
+			quote := byte('}')
+			if s[0] == '"' {
+				quote = '"'
+			}
+			end := strings.IndexByte(s[1:], quote) + 1
+			if end == 0 {
+				elem, s = s[1:], "" // unterminated
+			} else {
+				elem, s = s[1:end], s[end+1:]
+			}
+		default:
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+			elem, s = s[:end], s[end:]
+		}
+		list = append(list, elem)
+	}
+}
+
+// Joins a Tcl list, quoting empty elements and elements with spaces.
+func joinList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, e := range list {
+		if e == "" || strings.ContainsAny(e, " \t") {
+			e = "{" + e + "}"
+		}
+		quoted[i] = e
+	}
+	return strings.Join(quoted, " ")
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
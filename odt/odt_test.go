@@ -0,0 +1,82 @@
+package odt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// excerpt of OOMMF output
+const oommfTable = `# ODT 1.0
+# Table Start
+## mmGraph output
+# Columns:  Iteration           {Field Updates}     {Sim Time}          Mx/Ms
+# Units:       {}                     {}                ns                {}
+            10                  32                  0.008956979824829592  0.9998642008044737
+            20                  56                  0.02333484813262519  0.999576340804824
+# Table End
+`
+
+func TestRead(t *testing.T) {
+	tab, err := Read(strings.NewReader(oommfTable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(tab.Columns, []string{"Iteration", "Field Updates", "Sim Time", "Mx/Ms"}) {
+		t.Errorf("columns: %q", tab.Columns)
+	}
+	if !equal(tab.Units, []string{"", "", "ns", ""}) {
+		t.Errorf("units: %q", tab.Units)
+	}
+	if tab.Rows() != 2 || tab.Data[1][1] != 56 || tab.Data[3][0] != 0.9998642008044737 {
+		t.Error("data:", tab.Data)
+	}
+	col, scale, err := tab.TimeColumn()
+	if col != 2 || scale != 1e-9 || err != nil {
+		t.Error("time column:", col, scale, err)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	tab := &Table{
+		Title:   "test",
+		Columns: []string{"t", "mx", "Total energy"},
+		Units:   []string{"s", "", "J"},
+		Data:    [][]float64{{0, 1e-12}, {1, 0.5}, {-1e-18, 2e-18}},
+	}
+	var buf bytes.Buffer
+	if err := tab.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "# Columns: t mx {Total energy}\n# Units: s {} J\n") {
+		t.Error(buf.String())
+	}
+
+	tab2, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab2.Title != tab.Title || !equal(tab2.Columns, tab.Columns) || !equal(tab2.Units, tab.Units) {
+		t.Error("have", tab2, "want", tab)
+	}
+	for c := range tab.Data {
+		for r := range tab.Data[c] {
+			if tab2.Data[c][r] != tab.Data[c][r] {
+				t.Error("have", tab2.Data, "want", tab.Data)
+			}
+		}
+	}
+}
+
+func TestReadBad(t *testing.T) {
+	for _, in := range []string{
+		"1 2 3\n",
+		"# Columns: a b\n1 2 3\n",
+		"# Columns: a b\n1 x\n",
+		"# Columns: a b\n# Units: s\n",
+	} {
+		if _, err := Read(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
@@ -3,46 +3,59 @@ package oommf
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
-	"github.com/mumax/3/data"
-	"github.com/mumax/3/util"
 	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/mumax/3/data"
+	"github.com/mumax/3/util"
 )
 
-// Read any OOMMF file, autodetect OVF1/OVF2 format
+// Read any OOMMF file, autodetect OVF1/OVF2 format.
+// Only the first segment of multi-segment files is returned,
+// use ReadAll or NewReader to get all of them.
 func Read(in io.Reader) (s *data.Slice, meta data.Meta, err error) {
-	//in := fullReader{bufio.NewReader(in_)}
-	info := readHeader(in)
-
-	n := info.Size
-	c := info.StepSize
-	if c == [3]float64{0, 0, 0} {
-		c = [3]float64{1, 1, 1} // default (presumably unitless) cell size
+	r, err := NewReader(in)
+	if err != nil {
+		return nil, data.Meta{}, err
 	}
-	data_ := data.NewSlice(info.NComp, n)
-
-	format := strings.ToLower(info.Format)
-	ovf := info.OVF
-
-	switch {
-	default:
-		panic(fmt.Sprint("unknown format: OVF", ovf, " ", format))
-	case format == "text":
-		readOVFDataText(in, data_)
-	case format == "binary 4" && ovf == 1:
-		readOVF1DataBinary4(in, data_)
-	case format == "binary 8" && ovf == 1:
-		readOVF1DataBinary8(in, data_)
-	case format == "binary 4" && ovf == 2:
-		readOVF2DataBinary4(in, data_)
-	case format == "binary 8" && ovf == 2:
-		readOVF2DataBinary8(in, data_)
+	s, info, err := r.Next()
+	if err == io.EOF {
+		err = fmt.Errorf("oommf: no data segment")
 	}
+	if err != nil {
+		return nil, data.Meta{}, err
+	}
+	return s, info.Meta(), nil
+}
 
-	return data_, data.Meta{Name: info.Title, Time: info.TotalTime, Unit: info.ValueUnit, CellSize: info.StepSize}, nil
+// ReadAll reads all segments of an OOMMF file, e.g., a time series.
+func ReadAll(in io.Reader) ([]*data.Slice, []*Info, error) {
+	r, err := NewReader(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	var slices []*data.Slice
+	var infos []*Info
+	for {
+		s, info, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return slices, infos, err
+		}
+		slices = append(slices, s)
+		infos = append(infos, info)
+	}
+	if len(slices) == 0 {
+		return nil, nil, fmt.Errorf("oommf: no data segment")
+	}
+	return slices, infos, nil
 }
 
 func ReadFile(fname string) (*data.Slice, data.Meta, error) {
@@ -60,115 +73,235 @@ func MustReadFile(fname string) (*data.Slice, data.Meta) {
 	return s, t
 }
 
-// omf.Info represents the header part of an omf file.
-// TODO: add Err to return error status
-// Perhaps CheckErr() func
+// omf.Info represents the header part of an omf file segment.
+// Header keys that are not interpreted are kept in Desc,
+// as well as the key: value pairs of Desc lines.
 type Info struct {
 	Desc            map[string]interface{}
 	Title           string
 	NComp           int
 	Size            [3]int
 	ValueMultiplier float32
-	ValueUnit       string
-	Format          string // binary or text
+	ValueUnit       string   // unit of the first component
+	ValueUnits      []string // per-component units (OVF2)
+	ValueLabels     []string // per-component labels (OVF2)
+	Format          string   // binary or text
 	OVF             int
+	SegmentCount    int // as announced in the file header
 	TotalTime       float64
 	StageTime       float64
 	SizeofFloat     int // 4/8
 	StepSize        [3]float64
 	MeshUnit        string
+	MeshType        string        // rectangular or irregular
+	PointCount      int           // number of nodes of an irregular mesh
+	Points          []data.Vector // node positions of an irregular mesh
 }
 
-// Parses the header part of the OVF1/OVF2 file
-func readHeader(in io.Reader) *Info {
-	desc := make(map[string]interface{})
-	info := new(Info)
-	info.Desc = desc
+// Meta returns the metadata in the form used by the rest of mumax3.
+func (i *Info) Meta() data.Meta {
+	return data.Meta{Name: i.Title, Time: i.TotalTime, Unit: i.ValueUnit, CellSize: i.StepSize, MeshUnit: i.MeshUnit}
+}
+
+// Reader reads the segments of an OOMMF file one by one.
+// It may buffer data beyond the last segment.
+type Reader struct {
+	in           *bufio.Reader
+	ovf          int
+	meshType     string
+	segmentCount int
+}
 
-	line, eof := readLine(in)
-	switch strings.ToLower(line) {
+// NewReader reads the file header of an OVF1 or OVF2 file.
+func NewReader(in io.Reader) (*Reader, error) {
+	r := &Reader{in: bufio.NewReader(in)}
+	line, err := r.readLine()
+	for err == nil && strings.TrimSpace(line) == "" {
+		line, err = r.readLine()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oommf: reading header: %v", err)
+	}
+	switch strings.Join(strings.Fields(strings.ToLower(line)), " ") {
 	default:
-		panic("unknown header: " + line)
+		return nil, fmt.Errorf("oommf: unknown header: %q", line)
 	case "# oommf ovf 2.0":
-		info.OVF = 2
+		r.ovf = 2
+		r.meshType = "rectangular"
 	case "# oommf: rectangular mesh v1.0":
-		info.OVF = 1
-		info.NComp = 3 // OVF1 only supports vector
+		r.ovf = 1
+		r.meshType = "rectangular"
+	case "# oommf: irregular mesh v1.0":
+		r.ovf = 1
+		r.meshType = "irregular"
 	}
-	line, eof = readLine(in)
-	for !eof && !isHeaderEnd(line) {
-		key, value := parseHeaderLine(line)
+	return r, nil
+}
 
-		switch strings.ToLower(key) {
-		default:
-			panic("Unknown key: " + key)
-			// ignored
-		case "oommf", "segment count", "begin", "meshtype", "xbase", "ybase", "zbase", "xmin", "ymin", "zmin", "xmax", "ymax", "zmax", "valuerangeminmag", "valuerangemaxmag", "end": // ignored (OVF1)
-		case "", "valuelabels": // ignored (OVF2)
-		case "title":
-			info.Title = value
-		case "valueunits":
-			info.ValueUnit = strings.Split(value, " ")[0] // take unit of first component, we don't support per-component units
-		case "valuedim":
-			info.NComp = atoi(value)
-		case "xnodes":
-			info.Size[X] = atoi(value)
-		case "ynodes":
-			info.Size[Y] = atoi(value)
-		case "znodes":
-			info.Size[Z] = atoi(value)
-		case "xstepsize":
-			info.StepSize[X] = atof(value)
-		case "ystepsize":
-			info.StepSize[Y] = atof(value)
-		case "zstepsize":
-			info.StepSize[Z] = atof(value)
-		case "valuemultiplier":
-		case "valueunit":
-		case "meshunit":
-		// desc tags: parse further and add to metadata table
-		case "desc":
-			strs := strings.SplitN(value, ":", 2)
-			desc_key := strings.Trim(strs[0], "# ")
-			// Desc tag does not neccesarily have a key:value layout.
-			// If not, we use an empty value string.
-			desc_value := ""
-			if len(strs) > 1 {
-				desc_value = strings.Trim(strs[1], "# ")
-			}
-			desc[desc_key] = desc_value
-		}
+// Next reads the next segment. It returns io.EOF when there are no more segments.
+func (r *Reader) Next() (*data.Slice, *Info, error) {
+	info, err := r.readHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := r.readData(info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, info, nil
+}
 
-		line, eof = readLine(in)
+// Parses the header part of an OVF1/OVF2 segment, up to and including "Begin: Data".
+func (r *Reader) readHeader() (*Info, error) {
+	info := &Info{
+		Desc:            make(map[string]interface{}),
+		OVF:             r.ovf,
+		MeshType:        r.meshType,
+		SegmentCount:    r.segmentCount,
+		ValueMultiplier: 1,
 	}
-	// the remaining line should now be the begin:data clause
-	key, value := parseHeaderLine(line)
-	value = strings.TrimSpace(value)
-	strs := strings.SplitN(value, " ", 3)
-	if strings.ToLower(key) != "begin" || strings.ToLower(strs[0]) != "data" {
-		panic("Expected: Begin: Data")
+	if r.ovf == 1 {
+		info.NComp = 3 // OVF1 only supports vector
 	}
-	info.Format = strings.ToLower(strs[1])
-	if len(strs) >= 3 { // dataformat for text is empty
-		info.Format = "binary " + strs[2] // binary + 4 or 8
-	} else {
-		info.Format = "text"
+
+	started := false // seen anything but the end of the previous segment
+	for {
+		line, err := r.readLine()
+		if err == io.EOF && !started {
+			return nil, io.EOF
+		}
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "##") || strings.TrimSpace(line) == "" {
+			continue // comment
+		}
+		if isHeaderEnd(line) {
+			if err := info.parseFormat(line); err != nil {
+				return nil, err
+			}
+			break
+		}
+		key, value := parseHeaderLine(line)
+		if strings.ToLower(key) != "end" {
+			started = true
+		}
+		if err := info.parse(key, value); err != nil {
+			return nil, err
+		}
 	}
+	r.segmentCount = info.SegmentCount
 
 	// OVF1-style time info
 	if t1, ok := info.Desc["Time (s)"]; ok {
-		timestr := fmt.Sprint(t1)
-		t, _ := strconv.ParseFloat(timestr, 64)
-		info.TotalTime = t
+		info.TotalTime = parseTime(t1)
 	}
 	// OVF2-style time info
 	if t2, ok := info.Desc["Total simulation time"]; ok {
-		timestr := fmt.Sprint(t2)
-		words := strings.Split(timestr, " ")
-		t, _ := strconv.ParseFloat(words[0], 64)
-		info.TotalTime = t
+		info.TotalTime = parseTime(t2)
+	}
+	if t3, ok := info.Desc["Stage simulation time"]; ok {
+		info.StageTime = parseTime(t3)
+	}
+
+	if info.NComp <= 0 {
+		return nil, fmt.Errorf("oommf: invalid valuedim: %v", info.NComp)
+	}
+	if info.MeshType == "irregular" {
+		info.Size = [3]int{info.PointCount, 1, 1}
+	}
+	if info.Size[X] <= 0 || info.Size[Y] <= 0 || info.Size[Z] <= 0 {
+		return nil, fmt.Errorf("oommf: invalid mesh size: %v", info.Size)
+	}
+	return info, nil
+}
+
+// parse one "key: value" header line into info.
+func (info *Info) parse(key, value string) error {
+	var err error
+	switch strings.ToLower(key) {
+	default:
+		info.Desc[key] = value
+	case "", "oommf", "begin", "end", "xbase", "ybase", "zbase", "xmin", "ymin", "zmin", "xmax", "ymax", "zmax", "valuerangeminmag", "valuerangemaxmag", "boundary": // ignored
+	case "segment count":
+		info.SegmentCount, err = parseInt(key, value)
+	case "title":
+		info.Title = value
+	case "meshtype":
+		info.MeshType = strings.ToLower(value)
+	case "meshunit":
+		info.MeshUnit = value
+	case "pointcount":
+		info.PointCount, err = parseInt(key, value)
+	case "valuedim":
+		info.NComp, err = parseInt(key, value)
+	case "valuelabels":
+		info.ValueLabels = splitList(value)
+	case "valueunits":
+		info.ValueUnits = splitList(value)
+		if len(info.ValueUnits) > 0 {
+			info.ValueUnit = info.ValueUnits[0]
+		}
+	case "valueunit":
+		info.ValueUnit = value
+	case "valuemultiplier":
+		var m float64
+		m, err = parseFloat(key, value)
+		info.ValueMultiplier = float32(m)
+	case "xnodes":
+		info.Size[X], err = parseInt(key, value)
+	case "ynodes":
+		info.Size[Y], err = parseInt(key, value)
+	case "znodes":
+		info.Size[Z], err = parseInt(key, value)
+	case "xstepsize":
+		info.StepSize[X], err = parseFloat(key, value)
+	case "ystepsize":
+		info.StepSize[Y], err = parseFloat(key, value)
+	case "zstepsize":
+		info.StepSize[Z], err = parseFloat(key, value)
+	// desc tags: parse further and add to metadata table
+	case "desc":
+		strs := strings.SplitN(value, ":", 2)
+		desc_key := strings.Trim(strs[0], "# ")
+		// Desc tag does not neccesarily have a key:value layout.
+		// If not, we use an empty value string.
+		desc_value := ""
+		if len(strs) > 1 {
+			desc_value = strings.Trim(strs[1], "# ")
+		}
+		info.Desc[desc_key] = desc_value
 	}
-	return info
+	return err
+}
+
+// parse the "Begin: Data Binary 4" clause.
+func (info *Info) parseFormat(line string) error {
+	_, value := parseHeaderLine(line)
+	strs := strings.Fields(value)
+	switch {
+	case len(strs) == 2 && strings.ToLower(strs[1]) == "text":
+		info.Format = "text"
+	case len(strs) == 3 && strings.ToLower(strs[1]) == "binary" && (strs[2] == "4" || strs[2] == "8"):
+		info.Format = "binary " + strs[2]
+		info.SizeofFloat = atoi(strs[2])
+	default:
+		return fmt.Errorf("oommf: unknown data format: %q", line)
+	}
+	return nil
+}
+
+// time in the form "1e-9" or "1e-9 s"
+func parseTime(v interface{}) float64 {
+	words := strings.Fields(fmt.Sprint(v))
+	if len(words) == 0 {
+		return 0
+	}
+	t, _ := strconv.ParseFloat(words[0], 64)
+	return t
 }
 
 // INTERNAL: Splits "# key: value" into "key", "value".
@@ -193,22 +326,110 @@ func isHeaderEnd(str string) bool {
 const OVF_CONTROL_NUMBER_4 = 1234567.0 // The omf format requires the first encoded number in the binary data section to be this control number
 const OVF_CONTROL_NUMBER_8 = 123456789012345.0
 
-// read data block in text format, for OVF1 and OVF2
-func readOVFDataText(in io.Reader, t *data.Slice) {
-	size := t.Size()
-	data := t.Tensors()
-	for iz := 0; iz < size[Z]; iz++ {
-		for iy := 0; iy < size[Y]; iy++ {
-			for ix := 0; ix < size[X]; ix++ {
-				for c := 0; c < t.NComp(); c++ {
-					_, err := fmt.Fscan(in, &data[c][iz][iy][ix])
-					if err != nil {
-						panic(err)
-					}
-				}
+// reads the data block following the header.
+// Nodes of an irregular mesh are preceded by their x, y, z position.
+func (r *Reader) readData(info *Info) (*data.Slice, error) {
+	s := data.NewSlice(info.NComp, info.Size)
+	n := info.Size[X] * info.Size[Y] * info.Size[Z]
+	irregular := info.MeshType == "irregular"
+	perNode := info.NComp
+	if irregular {
+		perNode += 3
+		info.Points = make([]data.Vector, n)
+	}
+
+	var next func() (float64, error)
+	switch info.Format {
+	case "text":
+		next = r.readWord
+	case "binary 4", "binary 8":
+		var order binary.ByteOrder = binary.LittleEndian // OVF2
+		if info.OVF == 1 {
+			order = binary.BigEndian // OVF1 is network byte order
+		}
+		next = r.binaryReader(info.SizeofFloat, order)
+		control, err := next()
+		if err != nil {
+			return nil, fmt.Errorf("oommf: reading data: %v", err)
+		}
+		if (info.SizeofFloat == 4 && control != OVF_CONTROL_NUMBER_4) || (info.SizeofFloat == 8 && control != OVF_CONTROL_NUMBER_8) {
+			return nil, fmt.Errorf("oommf: invalid OVF%v control number: %v", info.OVF, control)
+		}
+	}
+
+	mul := info.ValueMultiplier
+	if mul == 0 {
+		mul = 1
+	}
+	list := s.Host()
+	node := make([]float64, perNode)
+	for i := 0; i < n; i++ {
+		for j := range node {
+			v, err := next()
+			if err != nil {
+				return nil, fmt.Errorf("oommf: reading data: %v", err)
+			}
+			node[j] = v
+		}
+		values := node
+		if irregular {
+			info.Points[i] = data.Vector{node[X], node[Y], node[Z]}
+			values = node[3:]
+		}
+		// internal in C-order == external in Fortran-order
+		for c := range list {
+			list[c][i] = float32(values[c]) * mul
+		}
+	}
+	return s, nil
+}
+
+// returns a function that reads one binary number of given size.
+func (r *Reader) binaryReader(size int, order binary.ByteOrder) func() (float64, error) {
+	buf := make([]byte, size)
+	return func() (float64, error) {
+		if _, err := io.ReadFull(r.in, buf); err != nil {
+			return 0, err
+		}
+		if size == 4 {
+			return float64(math.Float32frombits(order.Uint32(buf))), nil
+		}
+		return math.Float64frombits(order.Uint64(buf)), nil
+	}
+}
+
+// reads one whitespace-separated number in text format.
+func (r *Reader) readWord() (float64, error) {
+	var word []byte
+	for {
+		c, err := r.in.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(word) > 0 {
+				break
 			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if isSpace(c) {
+			if len(word) > 0 {
+				break
+			}
+			continue
 		}
+		word = append(word, c)
+	}
+	return strconv.ParseFloat(string(word), 64)
+}
+
+// reads one line, without the trailing newline.
+func (r *Reader) readLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
 	}
+	return strings.TrimRight(line, "\r\n"), err
 }
 
 // write data block in text format, for OVF1 and OVF2
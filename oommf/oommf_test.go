@@ -0,0 +1,235 @@
+package oommf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mumax/3/data"
+)
+
+// 3-component test data with distinct, exactly representable values
+func testSlice(size [3]int, offset float32) *data.Slice {
+	s := data.NewSlice(3, size)
+	for c, l := range s.Host() {
+		for i := range l {
+			l[i] = offset + float32(10*i+c)/4
+		}
+	}
+	return s
+}
+
+func checkSlice(t *testing.T, have, want *data.Slice) {
+	t.Helper()
+	if have.NComp() != want.NComp() || have.Size() != want.Size() {
+		t.Fatalf("have %v components of size %v, want %v of size %v", have.NComp(), have.Size(), want.NComp(), want.Size())
+	}
+	h, w := have.Host(), want.Host()
+	for c := range w {
+		for i := range w[c] {
+			if h[c][i] != w[c][i] {
+				t.Fatalf("component %v, cell %v: have %v, want %v", c, i, h[c][i], w[c][i])
+			}
+		}
+	}
+}
+
+// in-memory file, for SetSegmentCount
+type buffer struct{ bytes.Buffer }
+
+func (b *buffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b.Bytes()[off:], p), nil
+}
+
+func TestReadText(t *testing.T) {
+	want := testSlice([3]int{3, 2, 1}, 0)
+	meta := data.Meta{Name: "m", Time: 1e-9, Unit: "A/m", CellSize: [3]float64{1e-9, 2e-9, 3e-9}}
+	var buf bytes.Buffer
+	WriteOVF2(&buf, want, meta, "text")
+
+	have, m, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlice(t, have, want)
+	if m.Name != "m" || m.Time != 1e-9 || m.Unit != "A/m" || m.CellSize != meta.CellSize {
+		t.Errorf("have meta %+v, want %+v", m, meta)
+	}
+}
+
+func TestReadOVF1BigEndian(t *testing.T) {
+	want := testSlice([3]int{2, 2, 2}, -1)
+	var buf bytes.Buffer
+	WriteOVF1(&buf, want, data.Meta{Name: "m", Time: 2e-9}, "binary 4")
+
+	// the control number must be big-endian
+	b := buf.Bytes()
+	i := bytes.Index(b, []byte("Begin: Data Binary 4\n")) + len("Begin: Data Binary 4\n")
+	if c := math.Float32frombits(binary.BigEndian.Uint32(b[i:])); c != OVF_CONTROL_NUMBER_4 {
+		t.Fatalf("control number: %v", c)
+	}
+
+	have, m, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlice(t, have, want)
+	if m.Time != 2e-9 {
+		t.Errorf("time: %v", m.Time)
+	}
+}
+
+// OVF1 "binary 8", written by OOMMF but not by mumax3.
+func TestReadOVF1Binary8(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(`# OOMMF: rectangular mesh v1.0
+# Segment count: 1
+# Begin: Segment
+# Begin: Header
+# meshtype: rectangular
+# xnodes: 2
+# ynodes: 1
+# znodes: 1
+# valuemultiplier: 2
+# End: Header
+# Begin: Data Binary 8
+`)
+	for _, v := range []float64{OVF_CONTROL_NUMBER_8, 1, 2, 3, 4, 5, 6} {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	buf.WriteString("\n# End: Data Binary 8\n# End: Segment\n")
+
+	have, _, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := have.Host(); h[X][0] != 2 || h[Z][1] != 12 {
+		t.Errorf("have %v", h)
+	}
+}
+
+func TestReadAllSeries(t *testing.T) {
+	var buf buffer
+	var want []*data.Slice
+	for i := 0; i < 3; i++ {
+		s := testSlice([3]int{4, 1, 2}, float32(i))
+		want = append(want, s)
+		meta := data.Meta{Name: "m", Time: float64(i) * 1e-12}
+		format := "binary 4"
+		if i == 1 {
+			format = "text"
+		}
+		if i == 0 {
+			WriteOVF2Series(&buf, s, meta, format)
+		} else {
+			AppendOVF2(&buf, s, meta, format)
+		}
+	}
+	if err := SetSegmentCount(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// segment by segment
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		s, info, err := r.Next()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		checkSlice(t, s, want[i])
+		if info.SegmentCount != 3 || info.TotalTime != float64(i)*1e-12 {
+			t.Errorf("segment %v: count %v, time %v", i, info.SegmentCount, info.TotalTime)
+		}
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("after last segment: %v, want EOF", err)
+	}
+
+	// all at once
+	slices, infos, err := ReadAll(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slices) != 3 || len(infos) != 3 {
+		t.Fatalf("have %v segments", len(slices))
+	}
+	for i := range want {
+		checkSlice(t, slices[i], want[i])
+	}
+}
+
+// irregular mesh, unknown keys, comments and blank lines should not get in the way.
+func TestReadIrregular(t *testing.T) {
+	in := `# OOMMF: irregular mesh v1.0
+## a comment
+# Segment count: 1
+# Begin: Segment
+# Begin: Header
+
+# Title: spins
+# Desc: Time (s): 3e-12
+# SomeUnknownKey: some value
+# meshtype: irregular
+# meshunit: nm
+# pointcount: 2
+# valueunit: A/m
+# End: Header
+# Begin: Data Text
+0 0 0   1 0 0
+5 0 0   0 1 0
+# End: Data Text
+# End: Segment
+`
+	r, err := NewReader(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, info, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != [3]int{2, 1, 1} {
+		t.Errorf("size: %v", s.Size())
+	}
+	if h := s.Host(); h[X][0] != 1 || h[Y][1] != 1 || h[Y][0] != 0 {
+		t.Errorf("values: %v", h)
+	}
+	if len(info.Points) != 2 || info.Points[1] != (data.Vector{5, 0, 0}) {
+		t.Errorf("points: %v", info.Points)
+	}
+	if info.Desc["SomeUnknownKey"] != "some value" {
+		t.Errorf("unknown key not kept: %v", info.Desc)
+	}
+	if info.TotalTime != 3e-12 || info.Title != "spins" || info.MeshUnit != "nm" {
+		t.Errorf("info: %+v", info)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var good bytes.Buffer
+	WriteOVF2(&good, testSlice([3]int{2, 2, 1}, 0), data.Meta{}, "binary 4")
+	g := good.String()
+	dataStart := strings.Index(g, "# Begin: Data Binary 4\n") + len("# Begin: Data Binary 4\n")
+
+	for name, in := range map[string]string{
+		"empty":            "",
+		"bad header":       "# OOMMF OVF 3.0\n",
+		"not oommf":        "hello\nworld\n",
+		"truncated header": g[:strings.Index(g, "# xnodes")],
+		"truncated data":   g[:dataStart+10],
+		"bad control":      g[:dataStart] + "\x00\x00\x00\x00" + g[dataStart+4:],
+		"bad format":       strings.Replace(g, "Begin: Data Binary 4", "Begin: Data Binary 3", 1),
+		"bad valuedim":     strings.Replace(g, "valuedim: 3", "valuedim: x", 1),
+		"no size":          strings.Replace(g, "# xnodes: 2", "", 1),
+	} {
+		if _, _, err := Read(strings.NewReader(in)); err == nil {
+			t.Errorf("%v: no error", name)
+		}
+	}
+}
@@ -2,12 +2,11 @@ package oommf
 
 import (
 	"encoding/binary"
-	"fmt"
 	"github.com/mumax/3/data"
 	"io"
 	"log"
+	"math"
 	"strings"
-	"unsafe"
 )
 
 func WriteOVF1(out io.Writer, q *data.Slice, meta data.Meta, dataformat string) {
@@ -71,90 +70,27 @@ func writeOVF1Header(out io.Writer, q *data.Slice, meta data.Meta) {
 	hdr(out, "End", "Header")
 }
 
-// Writes data in OMF Binary 4 format
+// Writes data in OMF Binary 4 format: big-endian.
 func writeOVF1Binary4(out io.Writer, array *data.Slice) (err error) {
 	data := array.Tensors()
 	gridsize := array.Size()
 
-	var bytes []byte
+	var bytes [4]byte
 
 	// OOMMF requires this number to be first to check the format
-	var controlnumber float32 = OVF_CONTROL_NUMBER_4
-	// Conversion form float32 [4]byte in big-endian
-	// Inlined for performance, terabytes of data will pass here...
-	bytes = (*[4]byte)(unsafe.Pointer(&controlnumber))[:]
-	bytes[0], bytes[1], bytes[2], bytes[3] = bytes[3], bytes[2], bytes[1], bytes[0] // swap endianess
-	_, err = out.Write(bytes)
+	binary.BigEndian.PutUint32(bytes[:], math.Float32bits(OVF_CONTROL_NUMBER_4))
+	_, err = out.Write(bytes[:])
 
 	ncomp := array.NComp()
 	for iz := 0; iz < gridsize[Z]; iz++ {
 		for iy := 0; iy < gridsize[Y]; iy++ {
 			for ix := 0; ix < gridsize[X]; ix++ {
 				for c := 0; c < ncomp; c++ {
-					// dirty conversion from float32 to [4]byte
-					bytes = (*[4]byte)(unsafe.Pointer(&data[c][iz][iy][ix]))[:]
-					bytes[0], bytes[1], bytes[2], bytes[3] = bytes[3], bytes[2], bytes[1], bytes[0]
-					out.Write(bytes)
+					binary.BigEndian.PutUint32(bytes[:], math.Float32bits(data[c][iz][iy][ix]))
+					out.Write(bytes[:])
 				}
 			}
 		}
 	}
 	return
 }
-
-func readOVF1DataBinary4(in io.Reader, t *data.Slice) {
-	size := t.Size()
-	data := t.Tensors()
-
-	// OOMMF requires this number to be first to check the format
-	var controlnumber float32
-	// OVF 1.0 is network byte order (MSB)
-	binary.Read(in, binary.BigEndian, &controlnumber)
-	if controlnumber != OVF_CONTROL_NUMBER_4 {
-		panic("invalid OVF1 control number: " + fmt.Sprint(controlnumber))
-	}
-
-	var tmp float32
-	for iz := 0; iz < size[Z]; iz++ {
-		for iy := 0; iy < size[Y]; iy++ {
-			for ix := 0; ix < size[X]; ix++ {
-				for c := 0; c < 3; c++ {
-					err := binary.Read(in, binary.BigEndian, &tmp)
-					if err != nil {
-						panic(err)
-					}
-					data[c][iz][iy][ix] = tmp
-				}
-			}
-		}
-	}
-}
-
-func readOVF1DataBinary8(in io.Reader, t *data.Slice) {
-	size := t.Size()
-	data := t.Tensors()
-
-	// OOMMF requires this number to be first to check the format
-	var controlnumber float64
-	// OVF 1.0 is network byte order (MSB)
-	binary.Read(in, binary.BigEndian, &controlnumber)
-
-	if controlnumber != OVF_CONTROL_NUMBER_8 {
-		panic("invalid OVF1 control number: " + fmt.Sprint(controlnumber))
-	}
-
-	var tmp float64
-	for iz := 0; iz < size[Z]; iz++ {
-		for iy := 0; iy < size[Y]; iy++ {
-			for ix := 0; ix < size[X]; ix++ {
-				for c := 0; c < 3; c++ {
-					err := binary.Read(in, binary.BigEndian, &tmp)
-					if err != nil {
-						panic(err)
-					}
-					data[c][iz][iy][ix] = float32(tmp)
-				}
-			}
-		}
-	}
-}
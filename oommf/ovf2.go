@@ -10,17 +10,44 @@ import (
 )
 
 func WriteOVF2(out io.Writer, q *data.Slice, meta data.Meta, dataformat string) {
+	fmt.Fprintln(out, "# OOMMF OVF 2.0")
+	hdr(out, "Segment count", "1")
+	AppendOVF2(out, q, meta, dataformat)
+}
+
+// WriteOVF2Series starts a multi-segment OVF2 file, e.g. for a time series,
+// containing q as its first segment. Further segments are added with AppendOVF2,
+// after which SetSegmentCount must update the segment count in the file header.
+func WriteOVF2Series(out io.Writer, q *data.Slice, meta data.Meta, dataformat string) {
+	fmt.Fprintln(out, "# OOMMF OVF 2.0")
+	fmt.Fprintf(out, segmentCountFmt+"\n", 1)
+	AppendOVF2(out, q, meta, dataformat)
+}
+
+// AppendOVF2 writes q as one OVF2 segment, without file header.
+func AppendOVF2(out io.Writer, q *data.Slice, meta data.Meta, dataformat string) {
 	writeOVF2Header(out, q, meta)
 	writeOVF2Data(out, q, dataformat)
 	hdr(out, "End", "Segment")
 }
 
+// The segment count of a series is a fixed-width field,
+// so that it can be overwritten in place.
+const (
+	segmentCountFmt    = "# Segment count: %10d"
+	segmentCountOffset = int64(len("# OOMMF OVF 2.0\n"))
+)
+
+// SetSegmentCount updates the segment count in the header of a file started by WriteOVF2Series.
+func SetSegmentCount(out io.WriterAt, count int) error {
+	_, err := out.WriteAt([]byte(fmt.Sprintf(segmentCountFmt, count)), segmentCountOffset)
+	return err
+}
+
 func writeOVF2Header(out io.Writer, q *data.Slice, meta data.Meta) {
 	gridsize := q.Size()
 	cellsize := meta.CellSize
 
-	fmt.Fprintln(out, "# OOMMF OVF 2.0")
-	hdr(out, "Segment count", "1")
 	hdr(out, "Begin", "Segment")
 	hdr(out, "Begin", "Header")
 
@@ -42,7 +69,7 @@ func writeOVF2Header(out io.Writer, q *data.Slice, meta data.Meta) {
 		labels = []interface{}{name}
 	} else {
 		for i := 0; i < q.NComp(); i++ {
-			labels = append(labels, name+"_"+string('x'+i))
+			labels = append(labels, name+"_"+string(rune('x'+i)))
 		}
 	}
 	hdr(out, "valuedim", q.NComp())
@@ -51,11 +78,11 @@ func writeOVF2Header(out io.Writer, q *data.Slice, meta data.Meta) {
 	if unit == "" {
 		unit = "1"
 	}
-	if q.NComp() == 1 {
-		hdr(out, "valueunits", unit)
-	} else {
-		hdr(out, "valueunits", unit, unit, unit)
+	var units []interface{}
+	for i := 0; i < q.NComp(); i++ {
+		units = append(units, unit)
 	}
+	hdr(out, "valueunits", units...)
 
 	// We don't really have stages
 	//fmt.Fprintln(out, "# Desc: Stage simulation time: ", meta.TimeStep, " s") // TODO
@@ -116,72 +143,3 @@ func writeOVF2DataBinary4(out io.Writer, array *data.Slice) {
 		}
 	}
 }
-
-func readOVF2DataBinary4(in io.Reader, array *data.Slice) {
-	size := array.Size()
-	data := array.Tensors()
-
-	// OOMMF requires this number to be first to check the format
-	controlnumber := readFloat32(in)
-	if controlnumber != OVF_CONTROL_NUMBER_4 {
-		panic("invalid OVF2 control number: " + fmt.Sprint(controlnumber))
-	}
-
-	ncomp := array.NComp()
-	for iz := 0; iz < size[Z]; iz++ {
-		for iy := 0; iy < size[Y]; iy++ {
-			for ix := 0; ix < size[X]; ix++ {
-				for c := 0; c < ncomp; c++ {
-					data[c][iz][iy][ix] = readFloat32(in)
-				}
-			}
-		}
-	}
-}
-
-// fully read buf, panic on error
-func readFull(in io.Reader, buf []byte) {
-	_, err := io.ReadFull(in, buf)
-	if err != nil {
-		panic(err)
-	}
-	return
-}
-
-// read float32 in machine endianess, panic on error
-func readFloat32(in io.Reader) float32 {
-	var bytes4 [4]byte
-	bytes := bytes4[:]
-	readFull(in, bytes)
-	return *((*float32)(unsafe.Pointer(&bytes4)))
-}
-
-// read float64 in machine endianess, panic on error
-func readFloat64(in io.Reader) float64 {
-	var bytes8 [8]byte
-	bytes := bytes8[:]
-	readFull(in, bytes)
-	return *((*float64)(unsafe.Pointer(&bytes8)))
-}
-
-func readOVF2DataBinary8(in io.Reader, array *data.Slice) {
-	size := array.Size()
-	data := array.Tensors()
-
-	// OOMMF requires this number to be first to check the format
-	controlnumber := readFloat64(in)
-	if controlnumber != OVF_CONTROL_NUMBER_8 {
-		panic("invalid OVF2 control number: " + fmt.Sprint(controlnumber))
-	}
-
-	ncomp := array.NComp()
-	for iz := 0; iz < size[Z]; iz++ {
-		for iy := 0; iy < size[Y]; iy++ {
-			for ix := 0; ix < size[X]; ix++ {
-				for c := 0; c < ncomp; c++ {
-					data[c][iz][iy][ix] = float32(readFloat64(in))
-				}
-			}
-		}
-	}
-}
@@ -1,50 +1,13 @@
 package oommf
 
 import (
-	"io"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
-func readLine(in io.Reader) (line string, eof bool) {
-	char := readChar(in)
-	eof = isEOF(char)
-
-	for !isEndline(char) {
-		line += string(byte(char))
-		char = readChar(in)
-	}
-	return line, eof
-}
-
-func isEOF(char int) bool {
-	return char == -1
-}
-
-func isEndline(char int) bool {
-	return isEOF(char) || char == int('\n')
-}
-
-//// Blocks until all requested bytes are read.
-//type fullReader struct{ io.Reader }
-//
-//func (r fullReader) Read(p []byte) (n int, err error) {
-//	return io.ReadFull(r.Reader, p)
-//}
-
-// Reads one character from the Reader.
-// -1 means EOF.
-// Errors are cought and cause panic
-func readChar(in io.Reader) int {
-	buffer := [1]byte{}
-	switch nr, err := in.Read(buffer[0:]); true {
-	case nr < 0: // error
-		panic(err)
-	case nr == 0: // eof
-		return -1
-	case nr > 0: // ok
-		return int(buffer[0])
-	}
-	panic("unreachable")
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
 
 func atoi(a string) int {
@@ -55,12 +18,54 @@ func atoi(a string) int {
 	return i
 }
 
-func atof(a string) float64 {
-	i, err := strconv.ParseFloat(a, 64)
+func parseInt(key, value string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
 	if err != nil {
-		panic(err)
+		return 0, fmt.Errorf("oommf: invalid %v: %q", key, value)
+	}
+	return i, nil
+}
+
+func parseFloat(key, value string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("oommf: invalid %v: %q", key, value)
+	}
+	return f, nil
+}
+
+// Splits a Tcl list, as used for valuelabels and valueunits.
+// Elements may be grouped by braces or double quotes, e.g.:
+//
+//	{Magnetization x} "Magnetization y" m_z
+func splitList(s string) []string {
+	var list []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return list
+		}
+		end := strings.IndexAny(s, " \t")
+		switch s[0] {
+		case '{':
+			end = strings.IndexByte(s, '}')
+		case '"':
+			end = strings.IndexByte(s[1:], '"') + 1
+		}
+		if end < 0 {
+			end = len(s)
+		}
+		if s[0] == '{' || s[0] == '"' {
+			list = append(list, s[1:end])
+			end++
+		} else {
+			list = append(list, s[:end])
+		}
+		if end > len(s) {
+			end = len(s)
+		}
+		s = s[end:]
 	}
-	return i
 }
 
 const (
@@ -11,6 +11,9 @@ func (w *World) compileAssignStmt(a *ast.AssignStmt) Expr {
 	if len(a.Lhs) != 1 || len(a.Rhs) != 1 {
 		panic(err(a.Pos(), "multiple assignment not allowed"))
 	}
+	if name, lit, ok := w.funcDecl(a); ok {
+		return w.compileFuncDecl(name, lit)
+	}
 	lhs, rhs := a.Lhs[0], a.Rhs[0]
 	r := w.compileExpr(rhs)
 
@@ -31,6 +34,7 @@ func (w *World) compileAssignStmt(a *ast.AssignStmt) Expr {
 // compile a = b
 func (w *World) compileAssign(a *ast.AssignStmt, lhs ast.Expr, r Expr) Expr {
 	l := w.compileLvalue(lhs)
+	w.checkAssignUnit(a.Pos(), lhs, l, a.Rhs[0])
 	return &assignStmt{lhs: l, rhs: typeConv(a.Pos(), r, inputType(l))}
 }
 
@@ -40,14 +44,29 @@ func (w *World) compileDefine(a *ast.AssignStmt, lhs ast.Expr, r Expr) Expr {
 	if !ok {
 		panic(err(a.Pos(), "non-name on left side of :="))
 	}
-	addr := reflect.New(r.Type())
-	ok = w.safeDeclare(ident.Name, &reflectLvalue{addr.Elem()})
-	if !ok {
-		panic(err(a.Pos(), "already defined: "+ident.Name))
+	if r.Type() == nil {
+		panic(err(a.Pos(), "void used as value"))
 	}
+	l := w.declareVar(ident, r.Type())
+	w.declareUnit(l, a.Rhs[0])
 	return w.compileAssign(a, lhs, r)
 }
 
+// declares a new variable of type t in the current scope.
+func (w *World) declareVar(ident *ast.Ident, t reflect.Type) LValue {
+	var l LValue
+	if w.fn != nil {
+		l = w.fn.newVar(t) // local variable, lives in the function's frame
+	} else {
+		l = &reflectLvalue{reflect.New(t).Elem()}
+	}
+	if ok := w.safeDeclare(ident.Name, l); !ok {
+		panic(err(ident.Pos(), "already defined: "+ident.Name))
+	}
+	w.declared(ident, l)
+	return l
+}
+
 type assignStmt struct {
 	lhs LValue
 	rhs Expr
@@ -65,6 +84,7 @@ func (a *assignStmt) Child() []Expr {
 
 func (w *World) compileAddAssign(a *ast.AssignStmt, lhs ast.Expr, r Expr) Expr {
 	l := w.compileLvalue(lhs)
+	w.checkAssignUnit(a.Pos(), lhs, l, a.Rhs[0])
 	x := typeConv(a.Pos(), l, float64_t)
 	y := typeConv(a.Pos(), r, float64_t)
 	sum := &add{binaryExpr{x, y}}
@@ -73,6 +93,7 @@ func (w *World) compileAddAssign(a *ast.AssignStmt, lhs ast.Expr, r Expr) Expr {
 
 func (w *World) compileSubAssign(a *ast.AssignStmt, lhs ast.Expr, r Expr) Expr {
 	l := w.compileLvalue(lhs)
+	w.checkAssignUnit(a.Pos(), lhs, l, a.Rhs[0])
 	x := typeConv(a.Pos(), l, float64_t)
 	y := typeConv(a.Pos(), r, float64_t)
 	sub := &sub{binaryExpr{x, y}}
@@ -8,6 +8,9 @@ import (
 
 // compiles a binary expression x 'op' y
 func (w *World) compileBinaryExpr(n *ast.BinaryExpr) Expr {
+	if u, ok := w.unitLit(n); ok {
+		return w.compileUnitLit(n, u)
+	}
 	switch n.Op {
 	default:
 		panic(err(n.Pos(), "not allowed:", n.Op))
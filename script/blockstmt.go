@@ -14,29 +14,45 @@ import (
 type BlockStmt struct {
 	Children []Expr
 	Node     []ast.Node
+	Line     []int // source line of each statement, 0 if unknown
 }
 
+// StmtHook, if not nil, is called before each statement is executed.
+// Used by the debugger, see mumax3 -debug.
+var StmtHook func(b *BlockStmt, i int)
+
 // does not enter scope because it does not necessarily needs to (e.g. for, if).
 func (w *World) compileBlockStmt_noScope(n *ast.BlockStmt) *BlockStmt {
 	b := &BlockStmt{}
 	for _, s := range n.List {
-		b.append(w.compileStmt(s), s)
+		b.append(w.compileStmt(s), s, w.line(s.Pos()))
 	}
 	return b
 }
 
-func (b *BlockStmt) append(s Expr, n ast.Node) {
+func (b *BlockStmt) append(s Expr, n ast.Node, line int) {
 	b.Children = append(b.Children, s)
 	b.Node = append(b.Node, n)
+	b.Line = append(b.Line, line)
 }
 
 func (b *BlockStmt) Eval() interface{} {
-	for _, s := range b.Children {
-		s.Eval()
+	for i := range b.Children {
+		if c, ok := b.EvalStmt(i).(ctrl); ok {
+			return c // e.g. return statement
+		}
 	}
 	return nil
 }
 
+// EvalStmt evaluates the i'th statement, after calling StmtHook.
+func (b *BlockStmt) EvalStmt(i int) interface{} {
+	if StmtHook != nil {
+		StmtHook(b, i)
+	}
+	return b.Children[i].Eval()
+}
+
 func (b *BlockStmt) Type() reflect.Type {
 	return nil
 }
@@ -49,7 +65,7 @@ func Format(n ast.Node) string {
 	var buf bytes.Buffer
 	fset := token.NewFileSet()
 	format.Node(&buf, fset, n)
-	str := buf.String()
+	str := formatUnitLits(buf.String())
 	if strings.HasSuffix(str, "\n") {
 		str = str[:len(str)-1]
 	}
@@ -63,9 +79,9 @@ func (b *BlockStmt) Format() string {
 		format.Node(&buf, fset, b.Node[i])
 		fmt.Fprintln(&buf)
 	}
-	return buf.String()
+	return formatUnitLits(buf.String())
 }
 
 func (b *BlockStmt) Fix() Expr {
-	return &BlockStmt{Children: fixExprs(b.Children), Node: b.Node}
+	return &BlockStmt{Children: fixExprs(b.Children), Node: b.Node, Line: b.Line}
 }
@@ -22,6 +22,9 @@ func (w *World) compileCallExpr(n *ast.CallExpr) Expr {
 		if fname == "source" {
 			return w.compileSource(n)
 		}
+		if fname == "len" {
+			return w.compileLen(n)
+		}
 		f = w.compileExpr(Fun)
 	case *ast.SelectorExpr: // method call
 		f = w.compileSelectorStmt(Fun)
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
+	"go/token"
 )
 
 // Compiles an expression, which can then be evaluated. E.g.:
@@ -11,7 +13,11 @@ import (
 // 	expr.Eval()   // returns 2
 func (w *World) CompileExpr(src string) (code Expr, e error) {
 	// parse
-	tree, err := parser.ParseExpr(src)
+	rw := rewrite(src) // unit literals
+	prevUnits, prevExprUnits := w.units, w.exprUnits
+	w.units, w.exprUnits = rw.units, make(map[ast.Expr]unit)
+	defer func() { w.units, w.exprUnits = prevUnits, prevExprUnits }()
+	tree, err := parser.ParseExpr(rw.src)
 	if err != nil {
 		return nil, fmt.Errorf(`parse "%s": %v`, src, err)
 	}
@@ -50,12 +56,46 @@ func (w *World) MustCompileExpr(src string) Expr {
 // 	src = "a = 1; b = sin(x)"
 // 	code, err := world.Compile(src)
 // 	code.Eval()
+// A non-nil error is of type *Error.
 func (w *World) Compile(src string) (code *BlockStmt, e error) {
+	code, _, e = w.compileFile(src, false)
+	return
+}
+
+// CompileRefs is like Compile, but also returns all identifiers in src
+// and where they were declared, for tools like mumax3-lsp.
+// The references found so far are returned even if there is an error.
+func (w *World) CompileRefs(src string) (code *BlockStmt, refs []Ref, e error) {
+	return w.compileFile(src, true)
+}
+
+func (w *World) compileFile(src string, withRefs bool) (code *BlockStmt, refs []Ref, e error) {
 	// parse
-	exprSrc := "func(){\n" + src + "\n}" // wrap in func to turn into expression
+	origSrc := "func(){\n" + src + "\n}" // wrap in func to turn into expression
+	rw := rewrite(origSrc)
+	exprSrc := rw.src
+	m := &srcMap{src: exprSrc, orig: origSrc, edits: rw.edits}
+	// Compile may be called recursively by source(),
+	// and a compile error may leave us in a nested scope or loop.
+	saved := *w
+	w.decls, w.units, w.exprUnits, w.xref, w.src = rw.decls, rw.units, make(map[ast.Expr]unit), nil, m
+	if withRefs {
+		w.xref = newXref()
+	}
+	defer func() {
+		if withRefs {
+			refs = w.xref.refs(m)
+		}
+		w.scope, w.fn, w.nLoop, w.nBreak = saved.scope, saved.fn, saved.nLoop, saved.nBreak
+		w.decls, w.units, w.exprUnits, w.xref, w.src = saved.decls, saved.units, saved.exprUnits, saved.xref, saved.src
+	}()
 	tree, err := parser.ParseExpr(exprSrc)
 	if err != nil {
-		return nil, fmt.Errorf("script line %v: ", err)
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			p := m.position(token.Pos(list[0].Pos.Offset + 1))
+			return nil, refs, &Error{p, list[0].Msg, fmt.Sprintf("script line %v:%v: %v", p.Line, p.Column, list[0].Msg)}
+		}
+		return nil, refs, fmt.Errorf("script: %v", err)
 	}
 
 	// catch compile errors and decode line number
@@ -67,7 +107,7 @@ func (w *World) Compile(src string) (code *BlockStmt, e error) {
 			}
 			if compErr, ok := err.(*compileErr); ok {
 				code = nil
-				e = fmt.Errorf("script %v: %v", pos2line(compErr.pos, exprSrc), compErr.msg)
+				e = &Error{m.position(compErr.pos), compErr.msg, fmt.Sprintf("script %v: %v", m.pos2line(compErr.pos), compErr.msg)}
 			} else {
 				panic(err)
 			}
@@ -81,9 +121,9 @@ func (w *World) Compile(src string) (code *BlockStmt, e error) {
 	}
 	block := new(BlockStmt)
 	for _, s := range stmts {
-		block.append(w.compile(s), s)
+		block.append(w.compile(s), w.funcDeclNode(s), w.line(s.Pos()))
 	}
-	return block, nil
+	return block, nil, nil
 }
 
 // Like Compile but panics on error
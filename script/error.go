@@ -42,21 +42,84 @@ func assert(test bool) {
 	}
 }
 
-// decodes a token position in source to a line number
-// and returns the line number + line code.
-func pos2line(pos token.Pos, src string) string {
+// Error is returned by Compile. It locates the error in the source.
+type Error struct {
+	Position        // where the error occurred
+	Msg      string // message without position
+	str      string
+}
+
+func (e *Error) Error() string { return e.str }
+
+// Position in a script source. Line and Column count from 1,
+// the column is a byte offset within the line.
+type Position struct {
+	Line, Column int
+}
+
+// srcMap maps token positions in the source as parsed (wrapped in func(){...}, see Compile,
+// and with function declarations and unit literals rewritten, see rewrite) back to the user's source.
+type srcMap struct {
+	src, orig string // parsed and original source, both wrapped
+	edits     []edit // function declarations rewritten in src
+}
+
+// position of pos in the user's source.
+func (m *srcMap) position(pos token.Pos) Position {
 	if pos == 0 {
-		return ""
+		return Position{}
+	}
+	off := m.origOffset(int(pos) - 1) // token positions start at 1
+	if off > len(m.orig) {
+		off = len(m.orig)
 	}
-	lines := strings.Split(src, "\n")
-	line := 0
-	for i, b := range src {
-		if token.Pos(i) == pos {
-			return fmt.Sprint("line ", line, ": ", strings.Trim(lines[line], " \t")) // func{ prefix makes lines count from 1
+	line := strings.Count(m.orig[:off], "\n")                 // func(){ prefix makes lines count from 1
+	col := off - (strings.LastIndex(m.orig[:off], "\n") + 1)  // byte offset in line
+	if last := strings.Count(m.orig, "\n") - 1; line > last { // on the closing } we added
+		line, col = last, len(m.lines()[last])
+	}
+	if line < 1 { // on the func(){ we added
+		line, col = 1, 0
+	}
+	return Position{line, col + 1}
+}
+
+// source line of pos in the file being compiled, 0 if unknown.
+func (w *World) line(pos token.Pos) int {
+	if w.src == nil {
+		return 0
+	}
+	return w.src.position(pos).Line
+}
+
+// offset in orig corresponding to offset off in src.
+func (m *srcMap) origOffset(off int) int {
+	shift := 0
+	for _, e := range m.edits {
+		if off < e.out {
+			break
 		}
-		if b == '\n' {
-			line++
+		if off < e.out+e.outLen { // inside "name := func"
+			if off-e.out < e.nameLen {
+				return e.in + e.inLen - e.nameLen + (off - e.out)
+			}
+			return e.in
 		}
+		shift = (e.in + e.inLen) - (e.out + e.outLen)
+	}
+	return off + shift
+}
+
+func (m *srcMap) lines() []string {
+	return strings.Split(m.orig, "\n")
+}
+
+// decodes a token position in source to a line number
+// and returns the line number + line code.
+func (m *srcMap) pos2line(pos token.Pos) string {
+	if pos == 0 {
+		return ""
 	}
-	return fmt.Sprint("position", pos) // we should not reach this
+	p := m.position(pos)
+	return fmt.Sprint("line ", p.Line, ": ", strings.Trim(m.lines()[p.Line], " \t"))
 }
@@ -15,11 +15,24 @@ type Expr interface {
 
 // compiles an expression
 func (w *World) compileExpr(e ast.Expr) Expr {
+	x := w.compileExpr_noUnit(e)
+	w.inferUnit(e, x)
+	return x
+}
+
+func (w *World) compileExpr_noUnit(e ast.Expr) Expr {
 	switch e := e.(type) {
 	default:
 		panic(err(e.Pos(), "not allowed:", typ(e)))
 	case *ast.Ident:
-		return w.resolve(e.Pos(), e.Name)
+		x := w.resolve(e.Pos(), e.Name)
+		w.use(e, x)
+		if w.fn != nil {
+			if l, ok := x.(*localVar); !ok || l.def != w.fn {
+				w.fn.free = append(w.fn.free, x)
+			}
+		}
+		return x
 	case *ast.BasicLit:
 		return w.compileBasicLit(e)
 	case *ast.BinaryExpr:
@@ -28,9 +41,18 @@ func (w *World) compileExpr(e ast.Expr) Expr {
 		return w.compileUnaryExpr(e)
 	case *ast.CallExpr:
 		return w.compileCallExpr(e)
+	case *ast.SelectorExpr:
+		if !w.AutoCall {
+			panic(err(e.Pos(), "not allowed:", typ(e)))
+		}
+		return w.autoCall(e)
 	case *ast.ParenExpr:
 		return w.compileExpr(e.X)
 	case *ast.IndexExpr:
 		return w.compileIndexExpr(e)
+	case *ast.FuncLit:
+		return w.compileFuncLit(e)
+	case *ast.CompositeLit:
+		return w.compileCompositeLit(e, nil)
 	}
 }
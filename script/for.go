@@ -12,7 +12,12 @@ type forStmt struct {
 
 func (b *forStmt) Eval() interface{} {
 	for b.init.Eval(); b.cond.Eval().(bool); b.post.Eval() {
-		b.body.Eval()
+		switch b.body.Eval() {
+		case ctrlBreak:
+			return nil
+		case ctrlReturn:
+			return ctrlReturn
+		}
 	}
 	return nil // void
 }
@@ -34,7 +39,11 @@ func (w *World) compileForStmt(n *ast.ForStmt) *forStmt {
 		stmt.post = w.compileStmt(n.Post)
 	}
 	if n.Body != nil {
+		w.nLoop++
+		w.nBreak++
 		stmt.body = w.compileBlockStmt_noScope(n.Body)
+		w.nLoop--
+		w.nBreak--
 	}
 	return stmt
 }
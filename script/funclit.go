@@ -0,0 +1,342 @@
+package script
+
+// User-defined functions:
+// 	func name(x float64, n int) float64 { ... }
+// 	f := func(x float64) bool { ... }
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// funcDef holds a compiled user-defined function.
+// Its parameters and local variables live in a frame, one per call,
+// so that functions can be recursive.
+type funcDef struct {
+	typ   reflect.Type   // function type
+	vars  []reflect.Type // types of parameters and local variables (parameters first)
+	body  *BlockStmt     //
+	outer []*funcDef     // enclosing functions, whose local variables may be used
+	free  []Expr         // identifiers used by the body that are not our own local variables
+	cur   *frame         // frame of the active call, if any
+}
+
+// storage for one function call
+type frame struct {
+	vars []reflect.Value
+	ret  reflect.Value // return value
+}
+
+// compiles a function literal: func(x float64) float64 { ... }
+func (w *World) compileFuncLit(n *ast.FuncLit) *funcLit {
+	f := w.newFuncLit(n.Type)
+	w.compileFuncBody(f.def, n.Type, n.Body)
+	return f
+}
+
+// compiles a function declaration: func name(x float64) float64 { ... },
+// name is declared before the body is compiled so that it can call itself.
+func (w *World) compileFuncDecl(name *ast.Ident, n *ast.FuncLit) Expr {
+	f := w.newFuncLit(n.Type)
+	if ok := w.safeDeclare(name.Name, f); !ok {
+		panic(err(name.Pos(), "already defined: "+name.Name))
+	}
+	w.declared(name, f)
+	w.compileFuncBody(f.def, n.Type, n.Body)
+	return &nop{}
+}
+
+func (w *World) newFuncLit(n *ast.FuncType) *funcLit {
+	def := &funcDef{typ: w.compileType(n)}
+	if w.fn != nil {
+		def.outer = append(append(def.outer, w.fn.outer...), w.fn)
+	}
+	f := &funcLit{def: def}
+	if w.fn != nil {
+		w.fn.free = append(w.fn.free, f)
+	}
+	return f
+}
+
+func (w *World) compileFuncBody(def *funcDef, typ *ast.FuncType, body *ast.BlockStmt) {
+	w.EnterScope()
+	defer w.ExitScope()
+	prev, prevLoop, prevBreak := w.fn, w.nLoop, w.nBreak
+	w.fn, w.nLoop, w.nBreak = def, 0, 0 // can't break out of a function
+	defer func() { w.fn, w.nLoop, w.nBreak = prev, prevLoop, prevBreak }()
+
+	for _, field := range typ.Params.List {
+		t := w.compileType(field.Type)
+		for _, name := range field.Names {
+			v := def.newVar(t)
+			if ok := w.safeDeclare(name.Name, v); !ok {
+				panic(err(name.Pos(), "duplicate argument "+name.Name))
+			}
+			w.declared(name, v)
+		}
+	}
+
+	def.body = w.compileBlockStmt_noScope(body)
+	if def.typ.NumOut() > 0 && !terminates(body) {
+		panic(err(body.Rbrace, "missing return at end of function"))
+	}
+}
+
+// allocates a new local variable
+func (d *funcDef) newVar(t reflect.Type) *localVar {
+	d.vars = append(d.vars, t)
+	return &localVar{def: d, index: len(d.vars) - 1}
+}
+
+// call with the enclosing functions' frames as they were when the closure was created.
+func (d *funcDef) call(env []*frame, args []reflect.Value) []reflect.Value {
+	f := &frame{vars: make([]reflect.Value, len(d.vars))}
+	for i, t := range d.vars {
+		f.vars[i] = reflect.New(t).Elem()
+	}
+	for i, a := range args {
+		f.vars[i].Set(a)
+	}
+
+	saved := make([]*frame, len(d.outer))
+	for i, o := range d.outer {
+		saved[i], o.cur = o.cur, env[i]
+	}
+	prev := d.cur
+	d.cur = f
+	defer func() {
+		d.cur = prev
+		for i, o := range d.outer {
+			o.cur = saved[i]
+		}
+	}()
+
+	d.body.Eval()
+
+	if d.typ.NumOut() == 0 {
+		return nil
+	}
+	return []reflect.Value{f.ret}
+}
+
+// funcLit evaluates to a Go function (closure), so that it can be called
+// and passed wherever native functions are accepted.
+type funcLit struct {
+	def  *funcDef
+	impl reflect.Value // cached closure for functions not nested in others
+}
+
+func (f *funcLit) Eval() interface{} {
+	if len(f.def.outer) == 0 {
+		if !f.impl.IsValid() {
+			f.impl = f.closure(nil)
+		}
+		return f.impl.Interface()
+	}
+	env := make([]*frame, len(f.def.outer))
+	for i, o := range f.def.outer {
+		env[i] = o.cur
+	}
+	return f.closure(env).Interface()
+}
+
+func (f *funcLit) closure(env []*frame) reflect.Value {
+	return reflect.MakeFunc(f.def.typ, func(args []reflect.Value) []reflect.Value {
+		return f.def.call(env, args)
+	})
+}
+
+func (f *funcLit) Type() reflect.Type { return f.def.typ }
+func (f *funcLit) Fix() Expr          { return f }
+
+// Child returns all identifiers the function depends on, including those used by
+// the functions it calls, so that e.g. Contains(f, t) tells if f depends on time.
+func (f *funcLit) Child() []Expr {
+	var child []Expr
+	visited := make(map[*funcDef]bool)
+	var visit func(d *funcDef)
+	visit = func(d *funcDef) {
+		if visited[d] {
+			return // recursion
+		}
+		visited[d] = true
+		for _, e := range d.free {
+			if g, ok := e.(*funcLit); ok {
+				visit(g.def)
+			} else {
+				child = append(child, e)
+			}
+		}
+	}
+	visit(f.def)
+	return child
+}
+
+// parameter or local variable of a user-defined function
+type localVar struct {
+	def   *funcDef
+	index int
+}
+
+func (l *localVar) Eval() interface{}           { return l.elem().Interface() }
+func (l *localVar) Type() reflect.Type          { return l.def.vars[l.index] }
+func (l *localVar) SetValue(rvalue interface{}) { l.elem().Set(reflect.ValueOf(rvalue)) }
+func (l *localVar) Child() []Expr               { return nil }
+func (l *localVar) Fix() Expr                   { return NewConst(l) }
+func (l *localVar) elem() reflect.Value         { return l.def.cur.vars[l.index] }
+
+// compiles a type expression like float64 or func(float64) bool.
+func (w *World) compileType(n ast.Expr) reflect.Type {
+	switch n := n.(type) {
+	default:
+		panic(err(n.Pos(), "not allowed:", typ(n)))
+	case *ast.Ident:
+		if t := w.lookupType(n.Name); t != nil {
+			return t
+		}
+		panic(err(n.Pos(), "undefined type:", n.Name))
+	case *ast.ParenExpr:
+		return w.compileType(n.X)
+	case *ast.ArrayType:
+		if n.Len != nil {
+			panic(err(n.Pos(), "arrays not allowed, use a slice"))
+		}
+		return reflect.SliceOf(w.compileType(n.Elt))
+	case *ast.FuncType:
+		return w.compileFuncType(n)
+	}
+}
+
+func (w *World) compileFuncType(n *ast.FuncType) reflect.Type {
+	var in, out []reflect.Type
+	for _, field := range n.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			panic(err(field.Pos(), "variadic functions not allowed"))
+		}
+		t := w.compileType(field.Type)
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			in = append(in, t)
+		}
+	}
+	if n.Results != nil {
+		for _, field := range n.Results.List {
+			if len(field.Names) != 0 {
+				panic(err(field.Pos(), "named results not allowed"))
+			}
+			out = append(out, w.compileType(field.Type))
+		}
+	}
+	if len(out) > 1 {
+		panic(err(n.Results.Pos(), "multiple return values not allowed"))
+	}
+	return reflect.FuncOf(in, out, false)
+}
+
+// basic types, other type names are looked up in the declared identifiers.
+var basicTypes = map[string]reflect.Type{
+	"bool":    bool_t,
+	"float64": float64_t,
+	"int":     int_t,
+	"string":  string_t,
+	"vector":  vector_t,
+}
+
+// lookupType returns the type with given (case-independent) name:
+// a basic type, or a named type used by a native function or variable (e.g. Shape, Quantity).
+// Returns nil if not found.
+func (w *World) lookupType(name string) reflect.Type {
+	lname := strings.ToLower(name)
+	if t, ok := basicTypes[lname]; ok {
+		return t
+	}
+	var found reflect.Type
+	visited := make(map[reflect.Type]bool)
+	var search func(t reflect.Type)
+	search = func(t reflect.Type) {
+		if t == nil || visited[t] || found != nil {
+			return
+		}
+		visited[t] = true
+		if strings.ToLower(t.Name()) == lname {
+			found = t
+			return
+		}
+		if t.Kind() == reflect.Func {
+			for i := 0; i < t.NumIn(); i++ {
+				search(t.In(i))
+			}
+			for i := 0; i < t.NumOut(); i++ {
+				search(t.Out(i))
+			}
+		}
+	}
+	for _, e := range w.toplevel.Identifiers {
+		search(e.Type())
+	}
+	return found
+}
+
+// terminates tells if the statement is terminating (in the sense of the Go specification),
+// so that we can check for a missing return at the end of a function.
+func terminates(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && terminates(s.List[len(s.List)-1])
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(s.Body) && terminates(s.Else)
+	case *ast.ForStmt:
+		return s.Cond == nil && !hasBreak(s.Body)
+	case *ast.SwitchStmt:
+		hasDefault := false
+		for _, c := range s.Body.List {
+			c := c.(*ast.CaseClause)
+			if c.List == nil {
+				hasDefault = true
+			}
+			if len(c.Body) == 0 || !terminates(c.Body[len(c.Body)-1]) {
+				return false
+			}
+		}
+		return hasDefault && !hasBreak(s.Body)
+	}
+	return false
+}
+
+// hasBreak tells if n contains a break statement that refers to the enclosing statement.
+func hasBreak(n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BranchStmt:
+			found = found || n.Tok == token.BREAK
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.FuncLit:
+			return false // break in there refers to something else
+		}
+		return !found
+	})
+	return found
+}
+
+// if a is a rewritten function declaration, returns the declared name and function literal.
+func (w *World) funcDecl(a *ast.AssignStmt) (*ast.Ident, *ast.FuncLit, bool) {
+	if a.Tok != token.DEFINE || !w.decls[a.Pos()] || len(a.Lhs) != 1 || len(a.Rhs) != 1 {
+		return nil, nil, false
+	}
+	name, ok1 := a.Lhs[0].(*ast.Ident)
+	lit, ok2 := a.Rhs[0].(*ast.FuncLit)
+	return name, lit, ok1 && ok2
+}
+
+// original form of a rewritten function declaration, for printing.
+func (w *World) funcDeclNode(s ast.Stmt) ast.Node {
+	if a, ok := s.(*ast.AssignStmt); ok {
+		if name, lit, ok := w.funcDecl(a); ok {
+			return &ast.FuncDecl{Name: name, Type: lit.Type, Body: lit.Body}
+		}
+	}
+	return s
+}
@@ -12,10 +12,10 @@ type ifStmt struct {
 
 func (b *ifStmt) Eval() interface{} {
 	if b.cond.Eval().(bool) {
-		b.body.Eval()
+		return b.body.Eval() // passes on ctrl, if any
 	} else {
 		if b.else_ != nil {
-			b.else_.Eval()
+			return b.else_.Eval()
 		}
 	}
 	return nil // void
@@ -1,12 +1,16 @@
 package script
 
 import (
+	"fmt"
 	"go/ast"
 	"reflect"
 )
 
 func (w *World) compileIndexExpr(n *ast.IndexExpr) Expr {
 	x := w.compileExpr(n.X)
+	if x.Type() == nil {
+		panic(err(n.Pos(), "void used as value"))
+	}
 	kind := x.Type().Kind()
 	if !(kind == reflect.Array || kind == reflect.Slice) {
 		panic(err(n.Pos(), "can not index", x.Type()))
@@ -24,8 +28,16 @@ func (e *index) Type() reflect.Type {
 }
 func (e *index) Eval() interface{} {
 	x := reflect.ValueOf(e.x.Eval())
+	return x.Index(e.checkIndex(x)).Interface()
+}
+
+// evaluates the index and checks that it is in range for x.
+func (e *index) checkIndex(x reflect.Value) int {
 	i := e.index.Eval().(int)
-	return x.Index(i).Interface()
+	if i < 0 || i >= x.Len() {
+		panic(fmt.Errorf("index out of range: %v, length %v", i, x.Len()))
+	}
+	return i
 }
 
 func (e *index) Child() []Expr {
@@ -35,3 +47,35 @@ func (e *index) Child() []Expr {
 func (e *index) Fix() Expr {
 	return &index{x: e.x.Fix(), index: e.index.Fix()}
 }
+
+// compiles x[i] on the left-hand side of an assignment.
+func (w *World) compileIndexLvalue(n *ast.IndexExpr) LValue {
+	e := w.compileIndexExpr(n).(*index)
+	if e.x.Type().Kind() == reflect.Array {
+		// arrays (like vector) are values, x itself must be assignable
+		if _, ok := e.x.(LValue); !ok {
+			panic(err(n.Pos(), "cannot assign to", Format(n)))
+		}
+	}
+	return &indexLvalue{*e}
+}
+
+type indexLvalue struct {
+	index
+}
+
+func (l *indexLvalue) SetValue(v interface{}) {
+	x := reflect.ValueOf(l.x.Eval())
+	i := l.checkIndex(x)
+	if x.Kind() == reflect.Slice {
+		x.Index(i).Set(reflect.ValueOf(v))
+		return
+	}
+	// array: modify a copy and assign it back
+	a := reflect.New(x.Type()).Elem()
+	a.Set(x)
+	a.Index(i).Set(reflect.ValueOf(v))
+	l.x.(LValue).SetValue(a.Interface())
+}
+
+func (l *indexLvalue) Fix() Expr { return l.index.Fix() }
@@ -17,10 +17,13 @@ func (w *World) compileLvalue(lhs ast.Node) LValue {
 		panic(err(lhs.Pos(), "cannot assign to", typ(lhs)))
 	case *ast.Ident:
 		if l, ok := w.resolve(lhs.Pos(), lhs.Name).(LValue); ok {
+			w.use(lhs, l)
 			return l
 		} else {
 			panic(err(lhs.Pos(), "cannot assign to", lhs.Name))
 		}
+	case *ast.IndexExpr:
+		return w.compileIndexLvalue(lhs)
 	}
 }
 
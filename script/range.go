@@ -0,0 +1,100 @@
+package script
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// range statement: for i, v := range x {...}, x is a slice, array or int.
+type rangeStmt struct {
+	x          Expr
+	key, value *reflectLvalue // current index and element
+	assign     []Expr         // assigns key and value to the loop variables
+	body       Expr
+	void
+}
+
+func (b *rangeStmt) Eval() interface{} {
+	x := reflect.ValueOf(b.x.Eval())
+	n := 0
+	if x.Kind() == reflect.Int {
+		n = int(x.Int())
+	} else {
+		n = x.Len()
+	}
+	for i := 0; i < n; i++ {
+		b.key.SetValue(i)
+		if x.Kind() != reflect.Int {
+			b.value.elem.Set(x.Index(i))
+		}
+		for _, a := range b.assign {
+			a.Eval()
+		}
+		switch b.body.Eval() {
+		case ctrlBreak:
+			return nil
+		case ctrlReturn:
+			return ctrlReturn
+		}
+	}
+	return nil
+}
+
+func (w *World) compileRangeStmt(n *ast.RangeStmt) *rangeStmt {
+	w.EnterScope()
+	defer w.ExitScope()
+
+	x := w.compileExpr(n.X)
+	if x.Type() == nil {
+		panic(err(n.X.Pos(), "void used as value"))
+	}
+	stmt := &rangeStmt{x: x, key: &reflectLvalue{reflect.New(int_t).Elem()}}
+	switch x.Type().Kind() {
+	default:
+		panic(err(n.X.Pos(), "cannot range over", x.Type()))
+	case reflect.Slice, reflect.Array:
+		stmt.value = &reflectLvalue{reflect.New(x.Type().Elem()).Elem()}
+	case reflect.Int:
+		if n.Value != nil {
+			panic(err(n.Value.Pos(), "range over int permits only one iteration variable"))
+		}
+	}
+
+	vars := []ast.Expr{n.Key, n.Value}
+	vals := []*reflectLvalue{stmt.key, stmt.value}
+	for i, v := range vars {
+		if v == nil || isBlank(v) {
+			continue
+		}
+		var lhs LValue
+		if n.Tok == token.DEFINE {
+			ident, ok := v.(*ast.Ident)
+			if !ok {
+				panic(err(v.Pos(), "non-name on left side of :="))
+			}
+			lhs = w.declareVar(ident, vals[i].Type())
+		} else {
+			lhs = w.compileLvalue(v)
+		}
+		rhs := typeConv(v.Pos(), vals[i], inputType(lhs))
+		stmt.assign = append(stmt.assign, &assignStmt{lhs: lhs, rhs: rhs})
+	}
+
+	w.nLoop++
+	w.nBreak++
+	stmt.body = w.compileBlockStmt_noScope(n.Body)
+	w.nLoop--
+	w.nBreak--
+	return stmt
+}
+
+func (e *rangeStmt) Child() []Expr {
+	return append([]Expr{e.x, e.body}, e.assign...)
+}
+
+// is e the blank identifier _ ?
+func isBlank(e ast.Expr) bool {
+	ident, ok := e.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
@@ -0,0 +1,65 @@
+package script
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sort"
+)
+
+// Ref links an identifier in the source to its declaration, see CompileRefs.
+type Ref struct {
+	Name string
+	Pos  Position     // where the identifier appears
+	Decl Position     // where it was declared, zero if not declared in the source (e.g. Msat)
+	Type reflect.Type // type of the identifier
+}
+
+// identifiers recorded while compiling
+type xref struct {
+	uses []use
+	decl map[Expr]token.Pos
+}
+
+type use struct {
+	pos  token.Pos
+	name string
+	x    Expr
+}
+
+func newXref() *xref {
+	return &xref{decl: make(map[Expr]token.Pos)}
+}
+
+// records the use of identifier id, which resolved to x.
+func (w *World) use(id *ast.Ident, x Expr) {
+	if w.xref != nil {
+		w.xref.uses = append(w.xref.uses, use{id.Pos(), id.Name, x})
+	}
+}
+
+// records the declaration of identifier id as x.
+func (w *World) declared(id *ast.Ident, x Expr) {
+	if w.xref != nil {
+		w.xref.decl[x] = id.Pos()
+		w.use(id, x)
+	}
+}
+
+// refs in order of appearance in the source.
+func (r *xref) refs(m *srcMap) []Ref {
+	uses := r.uses
+	sort.SliceStable(uses, func(i, j int) bool { return uses[i].pos < uses[j].pos })
+	var refs []Ref
+	for i, u := range uses {
+		if i > 0 && u.pos == uses[i-1].pos {
+			continue // a := ... is recorded as declaration and assignment
+		}
+		ref := Ref{Name: u.name, Pos: m.position(u.pos), Type: u.x.Type()}
+		if d, ok := r.decl[u.x]; ok {
+			ref.Decl = m.position(d)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
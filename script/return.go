@@ -0,0 +1,51 @@
+package script
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// return statement
+type returnStmt struct {
+	def    *funcDef
+	result Expr // nil for functions without result
+	void
+}
+
+func (w *World) compileReturnStmt(n *ast.ReturnStmt) *returnStmt {
+	if w.fn == nil {
+		panic(err(n.Pos(), "return outside function"))
+	}
+	typ := w.fn.typ
+	switch {
+	case len(n.Results) > 1:
+		panic(err(n.Pos(), "multiple return values not allowed"))
+	case typ.NumOut() == 0 && len(n.Results) != 0:
+		panic(err(n.Pos(), "too many arguments to return"))
+	case typ.NumOut() == 1 && len(n.Results) == 0:
+		panic(err(n.Pos(), "not enough arguments to return"))
+	}
+	r := &returnStmt{def: w.fn}
+	if len(n.Results) == 1 {
+		r.result = typeConv(n.Results[0].Pos(), w.compileExpr(n.Results[0]), typ.Out(0))
+	}
+	return r
+}
+
+func (r *returnStmt) Eval() interface{} {
+	if r.result != nil {
+		v := reflect.New(r.def.typ.Out(0)).Elem()
+		if x := r.result.Eval(); x != nil {
+			v.Set(reflect.ValueOf(x))
+		}
+		r.def.cur.ret = v
+	}
+	return ctrlReturn
+}
+
+func (r *returnStmt) Child() []Expr {
+	if r.result == nil {
+		return nil
+	}
+	return []Expr{r.result}
+}
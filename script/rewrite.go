@@ -0,0 +1,114 @@
+package script
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"strconv"
+)
+
+// rewritten source, see rewrite.
+type rewritten struct {
+	src   string
+	decls map[token.Pos]bool // positions of rewritten function declarations, see funcDecl
+	units map[token.Pos]bool // positions of the unit strings of rewritten unit literals, see unitLit
+	edits []edit             // to map positions back to the original source
+}
+
+// edit made by rewrite: src[in:in+inLen] replaced by out[out:out+outLen].
+// For function declarations, the name is the first nameLen bytes of the replacement.
+type edit struct {
+	out, outLen int
+	in, inLen   int
+	nameLen     int
+}
+
+// token found by the scanner
+type lexeme struct {
+	off int
+	tok token.Token
+	lit string
+}
+
+func (l *lexeme) end() int {
+	if l.lit != "" && l.tok != token.SEMICOLON {
+		return l.off + len(l.lit)
+	}
+	return l.off + len(l.tok.String())
+}
+
+// rewrite turns our extensions into syntax the Go parser accepts:
+// function declarations "func name(...)" become "name := func(...)",
+// which is allowed inside the function body that wraps the script,
+// and unit literals "5 nm" become (5*"nm").
+// Positions are recorded assuming the source will be parsed as the first file of a new token.FileSet.
+func rewrite(src string) *rewritten {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+	var toks []lexeme
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		toks = append(toks, lexeme{file.Offset(pos), tok, lit})
+	}
+
+	r := &rewritten{decls: make(map[token.Pos]bool), units: make(map[token.Pos]bool)}
+	var out bytes.Buffer
+	last := 0 // offset in src up to which we copied
+	replace := func(start, end int, repl string, nameLen int) (outOffset int) {
+		out.WriteString(src[last:start])
+		outOffset = out.Len()
+		r.edits = append(r.edits, edit{out: outOffset, outLen: len(repl), in: start, inLen: end - start, nameLen: nameLen})
+		out.WriteString(repl)
+		last = end
+		return outOffset
+	}
+
+	for i := 0; i < len(toks); i++ {
+		t := &toks[i]
+		switch {
+		case t.tok == token.FUNC && i+1 < len(toks) && toks[i+1].tok == token.IDENT:
+			name := &toks[i+1]
+			o := replace(t.off, name.end(), name.lit+" := func", len(name.lit))
+			r.decls[token.Pos(o+1)] = true
+			i++
+		case t.tok == token.INT || t.tok == token.FLOAT:
+			n := unitLen(toks[i+1:])
+			if n == 0 {
+				continue
+			}
+			unit := src[toks[i+1].off:toks[i+n].end()]
+			o := replace(t.off, toks[i+n].end(), "("+t.lit+"*"+strconv.Quote(unit)+")", 0)
+			r.units[token.Pos(o+len("(")+len(t.lit)+len("*")+1)] = true
+			i += n
+		}
+	}
+	out.WriteString(src[last:])
+	r.src = out.String()
+	return r
+}
+
+// number of tokens in the unit that starts toks, like "nm" or "J/m3", 0 if none.
+// Symbols in a compound unit may not be separated by spaces.
+func unitLen(toks []lexeme) int {
+	isSymbol := func(i int) bool {
+		if i >= len(toks) || toks[i].tok != token.IDENT {
+			return false
+		}
+		_, _, ok := parseSymbol(toks[i].lit)
+		return ok
+	}
+	if !isSymbol(0) {
+		return 0
+	}
+	n := 1
+	for n+1 < len(toks) && (toks[n].tok == token.QUO || toks[n].tok == token.MUL) &&
+		toks[n].off == toks[n-1].end() && toks[n+1].off == toks[n].end() && isSymbol(n+1) {
+		n += 2
+	}
+	return n
+}
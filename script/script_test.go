@@ -1,6 +1,7 @@
 package script
 
 import (
+	"github.com/mumax/3/data"
 	"log"
 	"math"
 	"reflect"
@@ -99,6 +100,102 @@ func TestLoop(t *testing.T) {
 	}
 }
 
+func TestFunc(t *testing.T) {
+	w := NewWorld()
+	sum := 0.0
+	w.Var("sum", &sum)
+	src := `
+		func fib(n int) int {
+			if n < 2 {
+				return n
+			}
+			return fib(n-1) + fib(n-2)
+		}
+		func adder(a float64) func(float64) float64 {
+			return func(b float64) float64 { return a + b }
+		}
+		add3 := adder(3)
+		add5 := adder(5)
+		sum = fib(10) + add3(1) + add5(1)
+	`
+	w.MustExec(src)
+	if sum != 55+4+6 {
+		t.Error("got", sum)
+	}
+
+	// user functions as implicit functions
+	x := 1.0
+	w.Var("x", &x)
+	w.Func("eval", func(f ScalarFunction) float64 { return f.Float() })
+	w.MustExec("func twice() float64 { return 2*x }")
+	if w.MustEval("eval(twice)") != 2.0 {
+		t.Error("got", w.MustEval("eval(twice)"))
+	}
+	if !Contains(w.MustCompileExpr("twice()"), w.Resolve("x")) {
+		t.Error("twice() should depend on x")
+	}
+}
+
+func TestSlice(t *testing.T) {
+	w := NewWorld()
+	w.Func("vector", func(x, y, z float64) data.Vector { return data.Vector{x, y, z} })
+	src := `
+		xs := []float64{1, 2, 3.5}
+		sum := 0.0
+		for _, x := range xs {
+			sum += x
+		}
+		xs[1] = 10
+		vs := []vector{vector(1, 2, 3), vector(4, 5, 6)}
+		v := vs[1]
+		v[0] = 7
+		count := 0
+		for i := range 10 {
+			if i == 2 {
+				continue
+			}
+			if i == 5 {
+				break
+			}
+			count++
+		}
+		s := 0
+		for i, name := range []string{"a", "b", "c"} {
+			switch name {
+			case "a", "c":
+				s += 1
+			default:
+				s += 10
+				break
+				s += 1000
+			}
+			switch {
+			case i == 1:
+				s += 100
+			}
+		}
+		func find(xs []int, x int) int {
+			for i, y := range xs {
+				switch y {
+				case x:
+					return i
+				}
+			}
+			return -1
+		}
+		k := find([]int{4, 5, 6}, 6)
+		m := [][]float64{{1, 2}, {3}}
+	`
+	w.MustExec(src)
+	tests := []T{{"sum", 6.5}, {"xs[1]", 10.}, {"len(xs)", 3}, {"v[0]", 7.}, {"vs[1][0]", 4.},
+		{"count", 4}, {"s", 112}, {"k", 2}, {"len(m[0])", 2}}
+	for _, c := range tests {
+		if out := w.MustEval(c.in); out != c.out {
+			t.Error(c.in, "returned", out, "expected:", c.out)
+		}
+	}
+}
+
 type test struct {
 	a, b, c int
 }
@@ -116,6 +213,55 @@ func TestMethod(t *testing.T) {
 	}
 }
 
+func TestAutoCall(t *testing.T) {
+	w := NewWorld()
+	w.AutoCall = true
+	v := data.Vector{1, 2, 3}
+	w.Var("v", &v)
+	if have := w.MustEval("v.x + 2*v.Z"); have != 7.0 {
+		t.Error("have", have)
+	}
+	if have := w.MustEval("sqrt(v.y)"); have != math.Sqrt(2) {
+		t.Error("have", have)
+	}
+	if _, err := w.Eval("v.Div"); err == nil {
+		t.Error("method with arguments used as value")
+	}
+}
+
+// Without AutoCall, like in input files, methods must be called explicitly.
+func TestNoAutoCall(t *testing.T) {
+	w := NewWorld()
+	v := data.Vector{1, 2, 3}
+	var f func() float64
+	w.Var("v", &v)
+	w.Var("f", &f)
+	for _, src := range []string{"v.x", "v.x + 1", "sqrt(v.y)", "f = v.X"} {
+		if _, err := w.Compile(src); err == nil {
+			t.Errorf("%v: compiled without AutoCall", src)
+		}
+	}
+}
+
+// Scripts that compile without AutoCall, like input files, give the same results with it.
+func TestAutoCallCompatible(t *testing.T) {
+	for _, src := range []string{"v.X() + 2*v.z()", "v.Len()", "v.Mul(2).Y()", "v.Div(2)[2]", "vf()[1] + sf()"} {
+		var have [2]interface{}
+		for i, auto := range []bool{false, true} {
+			w := NewWorld()
+			w.AutoCall = auto
+			v := data.Vector{1, 2, 3}
+			w.Var("v", &v)
+			w.Func("sf", v.X)
+			w.Func("vf", func() data.Vector { return v })
+			have[i] = w.MustEval(src)
+		}
+		if have[0] != have[1] {
+			t.Errorf("%v: have %v with AutoCall, %v without", src, have[1], have[0])
+		}
+	}
+}
+
 func TestScope(t *testing.T) {
 	w := NewWorld()
 	w.MustEval("sin(0)")
@@ -208,9 +354,142 @@ func TestFail(test *testing.T) {
 	w.Const("c", 3e8)
 	a := 1.
 	w.Var("a", &a)
-	tests := []string{"c=1", "undefined", "1++", "a=true", "x:=a++"}
+	tests := []string{"c=1", "undefined", "1++", "a=true", "x:=a++",
+		"return 1", "func f() float64 {}", "func g(x int) { return x }", "func h(x undefined) {}",
+		"break", "for { func() { continue } }", "a[0] = 1", "for i, v := range 3 {}", "len(1)"}
+	for _, t := range tests {
+		_, err := w.Compile(t)
+		if err == nil {
+			test.Error(t, "should not compile")
+		} else {
+			log.Println(t, ":", err, ":OK")
+		}
+	}
+}
+
+func TestErrorPos(test *testing.T) {
+	w := NewWorld()
+	tests := []struct {
+		src       string
+		line, col int
+	}{
+		{"x := 1\n  y = 2", 2, 3},
+		{"func f(x float64) {}\nfunc  f() {}", 2, 7},
+		{"func f() {}; x := 1 +", 1, 22},
+		{"a := \"π\"; b := c", 1, 17},
+		{"x := 5 nm\ny := 2 ns + x + 1 s", 2, 13},
+		{"x := 5 nm;  y := 3 mT + c", 1, 25},
+	}
+	for _, t := range tests {
+		w.EnterScope()
+		_, err := w.Compile(t.src)
+		w.ExitScope()
+		e, ok := err.(*Error)
+		if !ok || e.Line != t.line || e.Column != t.col {
+			test.Errorf("%q: got %v, want %v:%v", t.src, err, t.line, t.col)
+		}
+	}
+}
+
+func TestRefs(test *testing.T) {
+	w := NewWorld()
+	src := "a := 1\nfunc sq(x float64) float64 { return x*x }\nb := sq(a) + pi"
+	_, refs, err := w.CompileRefs(src)
+	if err != nil {
+		test.Fatal(err)
+	}
+	want := []Ref{
+		{"a", Position{1, 1}, Position{1, 1}, int_t},
+		{"sq", Position{2, 6}, Position{2, 6}, nil},
+		{"x", Position{2, 9}, Position{2, 9}, float64_t},
+		{"x", Position{2, 37}, Position{2, 9}, float64_t},
+		{"x", Position{2, 39}, Position{2, 9}, float64_t},
+		{"b", Position{3, 1}, Position{3, 1}, float64_t},
+		{"sq", Position{3, 6}, Position{2, 6}, nil},
+		{"a", Position{3, 9}, Position{1, 1}, int_t},
+		{"pi", Position{3, 14}, Position{}, float64_t},
+	}
+	if len(refs) != len(want) {
+		test.Fatal("got", refs)
+	}
+	for i, r := range refs {
+		if r.Type.Kind() == reflect.Func {
+			r.Type = nil
+		}
+		if r != want[i] {
+			test.Error("got", r, "want", want[i])
+		}
+	}
+}
+
+// variable with a declared unit, like engine parameters
+type unitVar struct {
+	reflectLvalue
+	unit string
+}
+
+func (u *unitVar) Unit() string { return u.unit }
+
+func TestStmtHook(test *testing.T) {
+	w := NewWorld()
+	code, err := w.Compile("a := 1\n\nfor i := 0; i < 2; i++ {\n\ta += i\n}\nfunc f() {\n\ta++\n}\nf()")
+	if err != nil {
+		test.Fatal(err)
+	}
+	var lines []int
+	StmtHook = func(b *BlockStmt, i int) { lines = append(lines, b.Line[i]) }
+	defer func() { StmtHook = nil }()
+	for i := range code.Children {
+		code.EvalStmt(i)
+	}
+	want := []int{1, 3, 4, 4, 6, 9, 7}
+	if !reflect.DeepEqual(lines, want) {
+		test.Error("got", lines, "want", want)
+	}
+}
+
+func TestUnits(test *testing.T) {
+	w := NewWorld()
+	w.Func("vector", func(x, y, z float64) data.Vector { return data.Vector{x, y, z} })
+	var msat float64
+	var b data.Vector
+	w.LValue("Msat", &unitVar{reflectLvalue{reflect.ValueOf(&msat).Elem()}, "A/m"})
+	w.LValue("B", &unitVar{reflectLvalue{reflect.ValueOf(&b).Elem()}, "T"})
+
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"5 nm", 5e-9},
+		{"10mT", 10e-3},
+		{"1 GHz", 1e9},
+		{"-2 J/m3", -2},
+		{"800 kA/m", 800e3},
+		{"1 µm", 1e-6},
+		{"2 nm3", 2e-27},
+		{"1 / 2 ns", 0.5e9},
+		{"2 * 3 nm + 1 nm", 7e-9},
+		{"1 + 1 ns", 1 + 1e-9},
+		{"180 deg", math.Pi},
+	}
 	for _, t := range tests {
+		if got := w.MustEval(t.src).(float64); math.Abs(got-t.want) > 1e-6*math.Abs(t.want) {
+			test.Error(t.src, ": got", got, "want", t.want)
+		}
+	}
+
+	w.MustExec("Msat = 800 kA/m; Msat = 1e6; B = vector(0, 0, 10 mT)")
+	if msat != 1e6 || b != (data.Vector{0, 0, 10e-3}) {
+		test.Error("got", msat, b)
+	}
+	w.MustExec("l := 5 nm; d := l / 1 ns; l += 1 nm")
+
+	fail := []string{"1 nm + 1 ns", "Msat = 1 T", "B = vector(1 mT, 0, 1 A/m)", "x := 5 nm; x = 1 T",
+		"x := 1 nm; x -= 1 s", "1 m < 1 s", "Msat = 1 mT / 2"}
+	for _, t := range fail {
+		w.EnterScope()
 		_, err := w.Compile(t)
+		w.ExitScope()
 		if err == nil {
 			test.Error(t, "should not compile")
 		} else {
@@ -218,3 +497,12 @@ func TestFail(test *testing.T) {
 		}
 	}
 }
+
+func TestFormatUnits(t *testing.T) {
+	w := NewWorld()
+	code := w.MustCompile("x := 1e-3 kA/m + 5 A/m * (2)\nmax(x*2, 10mT)")
+	want := "x := 1e-3 kA/m + 5 A/m*(2)\nmax(x*2, 10 mT)\n"
+	if got := code.(*BlockStmt).Format(); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
@@ -39,6 +39,16 @@ func (w *World) compileSelectorStmt(n *ast.SelectorExpr) Expr {
 	return &selector{x, N}
 }
 
+// compiles x.sel used as a value, with World.AutoCall:
+// methods without arguments returning a number or vector are called, e.g. v.x -> v.X()
+func (w *World) autoCall(n *ast.SelectorExpr) Expr {
+	m := w.compileSelectorStmt(n)
+	if t := m.Type(); t != func_float64_t && t != func_vector_t {
+		panic(err(n.Pos(), "can not use method", n.Sel.Name, "of type", t, "as value"))
+	}
+	return &call{m, nil}
+}
+
 func (e *selector) Eval() interface{} {
 	obj := reflect.ValueOf(e.x.Eval())
 	meth := obj.MethodByName(e.method)
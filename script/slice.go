@@ -0,0 +1,74 @@
+package script
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// compiles a slice literal like []float64{1, 2, 3}.
+// typ is the slice type if it is implied by an enclosing literal, like in [][]float64{{1}, {2, 3}}.
+func (w *World) compileCompositeLit(n *ast.CompositeLit, typ reflect.Type) Expr {
+	if n.Type != nil {
+		typ = w.compileType(n.Type)
+	}
+	if typ == nil {
+		panic(err(n.Pos(), "missing type in composite literal"))
+	}
+	if typ.Kind() != reflect.Slice {
+		panic(err(n.Pos(), "invalid composite literal type", typ))
+	}
+	elemT := typ.Elem()
+	elems := make([]Expr, len(n.Elts))
+	for i, e := range n.Elts {
+		switch e := e.(type) {
+		case *ast.KeyValueExpr:
+			panic(err(e.Pos(), "keys not allowed in slice literal"))
+		case *ast.CompositeLit:
+			elems[i] = typeConv(e.Pos(), w.compileCompositeLit(e, elemT), elemT)
+		default:
+			elems[i] = typeConv(e.Pos(), w.compileExpr(e), elemT)
+		}
+	}
+	return &sliceLit{typ, elems}
+}
+
+type sliceLit struct {
+	typ   reflect.Type
+	elems []Expr
+}
+
+func (l *sliceLit) Eval() interface{} {
+	s := reflect.MakeSlice(l.typ, len(l.elems), len(l.elems))
+	for i, e := range l.elems {
+		s.Index(i).Set(reflect.ValueOf(e.Eval()))
+	}
+	return s.Interface()
+}
+
+func (l *sliceLit) Type() reflect.Type { return l.typ }
+func (l *sliceLit) Child() []Expr      { return l.elems }
+func (l *sliceLit) Fix() Expr          { return &sliceLit{l.typ, fixExprs(l.elems)} }
+
+// compiles the built-in len(x)
+func (w *World) compileLen(n *ast.CallExpr) Expr {
+	if len(n.Args) != 1 {
+		panic(err(n.Pos(), "len needs 1 argument, got", len(n.Args)))
+	}
+	x := w.compileExpr(n.Args[0])
+	if x.Type() == nil {
+		panic(err(n.Pos(), "void used as value"))
+	}
+	switch x.Type().Kind() {
+	default:
+		panic(err(n.Pos(), "invalid argument for len:", x.Type()))
+	case reflect.Slice, reflect.Array, reflect.String:
+		return &lenExpr{x}
+	}
+}
+
+type lenExpr struct{ x Expr }
+
+func (e *lenExpr) Eval() interface{}  { return reflect.ValueOf(e.x.Eval()).Len() }
+func (e *lenExpr) Type() reflect.Type { return int_t }
+func (e *lenExpr) Child() []Expr      { return []Expr{e.x} }
+func (e *lenExpr) Fix() Expr          { return &lenExpr{e.x.Fix()} }
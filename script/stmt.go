@@ -34,6 +34,14 @@ func (w *World) compileStmt(st ast.Stmt) Expr {
 		return w.compileForStmt(st)
 	case *ast.IncDecStmt:
 		return w.compileIncDecStmt(st)
+	case *ast.ReturnStmt:
+		return w.compileReturnStmt(st)
+	case *ast.RangeStmt:
+		return w.compileRangeStmt(st)
+	case *ast.SwitchStmt:
+		return w.compileSwitchStmt(st)
+	case *ast.BranchStmt:
+		return w.compileBranchStmt(st)
 	case *ast.BlockStmt:
 		w.EnterScope()
 		defer w.ExitScope()
@@ -41,6 +49,16 @@ func (w *World) compileStmt(st ast.Stmt) Expr {
 	}
 }
 
+// ctrl is returned by Eval() of statements that interrupt the normal flow of execution:
+// return, break and continue. Blocks pass it on to the enclosing statement.
+type ctrl int
+
+const (
+	ctrlReturn ctrl = iota + 1
+	ctrlBreak
+	ctrlContinue
+)
+
 // embed to get Type() that returns nil
 type void struct{}
 
@@ -0,0 +1,141 @@
+package script
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// switch statement
+type switchStmt struct {
+	init     Expr
+	tag      Expr // nil for switch without tag, cases are conditions then
+	cases    []caseClause
+	default_ *BlockStmt
+	void
+}
+
+type caseClause struct {
+	values []Expr
+	body   *BlockStmt
+}
+
+func (s *switchStmt) Eval() interface{} {
+	s.init.Eval()
+	var tag interface{} = true
+	if s.tag != nil {
+		tag = s.tag.Eval()
+	}
+	body := s.default_
+	for _, c := range s.cases {
+		if c.matches(tag) {
+			body = c.body
+			break
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	if c := body.Eval(); c != ctrlBreak {
+		return c // return, or continue of an enclosing loop
+	}
+	return nil
+}
+
+func (c *caseClause) matches(tag interface{}) bool {
+	for _, v := range c.values {
+		if v.Eval() == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *World) compileSwitchStmt(n *ast.SwitchStmt) *switchStmt {
+	w.EnterScope()
+	defer w.ExitScope()
+
+	stmt := &switchStmt{init: &nop{}}
+	if n.Init != nil {
+		stmt.init = w.compileStmt(n.Init)
+	}
+	caseT := bool_t
+	if n.Tag != nil {
+		stmt.tag = w.compileExpr(n.Tag)
+		caseT = stmt.tag.Type()
+		if caseT == nil {
+			panic(err(n.Tag.Pos(), "void used as value"))
+		}
+		if !caseT.Comparable() {
+			panic(err(n.Tag.Pos(), "cannot switch on", caseT))
+		}
+	}
+
+	w.nBreak++
+	defer func() { w.nBreak-- }()
+	for _, c := range n.Body.List {
+		c := c.(*ast.CaseClause)
+		w.EnterScope()
+		body := &BlockStmt{}
+		for _, s := range c.Body {
+			body.append(w.compileStmt(s), s, w.line(s.Pos()))
+		}
+		w.ExitScope()
+
+		if c.List == nil {
+			if stmt.default_ != nil {
+				panic(err(c.Pos(), "multiple defaults in switch"))
+			}
+			stmt.default_ = body
+			continue
+		}
+		clause := caseClause{body: body}
+		for _, v := range c.List {
+			clause.values = append(clause.values, typeConv(v.Pos(), w.compileExpr(v), caseT))
+		}
+		stmt.cases = append(stmt.cases, clause)
+	}
+	return stmt
+}
+
+func (s *switchStmt) Child() []Expr {
+	child := []Expr{s.init}
+	if s.tag != nil {
+		child = append(child, s.tag)
+	}
+	for _, c := range s.cases {
+		child = append(append(child, c.values...), c.body)
+	}
+	if s.default_ != nil {
+		child = append(child, s.default_)
+	}
+	return child
+}
+
+// break and continue
+type branchStmt struct {
+	ctrl ctrl
+	void
+}
+
+func (w *World) compileBranchStmt(n *ast.BranchStmt) *branchStmt {
+	if n.Label != nil {
+		panic(err(n.Pos(), "labels not allowed"))
+	}
+	switch n.Tok {
+	default:
+		panic(err(n.Pos(), "not allowed:", n.Tok))
+	case token.BREAK:
+		if w.nBreak == 0 {
+			panic(err(n.Pos(), "break is not in a loop or switch"))
+		}
+		return &branchStmt{ctrl: ctrlBreak}
+	case token.CONTINUE:
+		if w.nLoop == 0 {
+			panic(err(n.Pos(), "continue is not in a loop"))
+		}
+		return &branchStmt{ctrl: ctrlContinue}
+	}
+}
+
+func (b *branchStmt) Eval() interface{} { return b.ctrl }
+func (b *branchStmt) Child() []Expr     { return nil }
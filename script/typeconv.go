@@ -49,6 +49,10 @@ func typeConv(pos token.Pos, in Expr, outT reflect.Type) Expr {
 		return &scalFn{&intToFloat64{in}}
 	case inT == vector_t && outT.AssignableTo(VectorFunction_t):
 		return &vecFn{in}
+	case inT == func_float64_t && outT.AssignableTo(ScalarFunction_t):
+		return &scalFn{&call{in, nil}}
+	case inT == func_vector_t && outT.AssignableTo(VectorFunction_t):
+		return &vecFn{&call{in, nil}}
 	case inT == bool_t && outT == func_bool_t:
 		return &boolToFunc{in}
 	}
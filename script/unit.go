@@ -0,0 +1,318 @@
+package script
+
+// Unit literals and dimensional analysis.
+// A number followed by a unit, like 5 nm, 10 mT, 1 GHz or 800 kA/m,
+// is converted to SI units. The compiler tracks the units of expressions
+// and reports mixing different units, e.g.: 1 nm + 1 ns,
+// or assigning to a parameter with another declared unit, e.g.: Msat = 1 T.
+// Plain numbers go with any unit, so scripts without unit literals are not affected.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// dimension of a quantity: exponents of the SI base units m, kg, s, A, K.
+type dimension [5]int8
+
+var dimNames = [5]string{"m", "kg", "s", "A", "K"}
+
+// unit of an expression, as far as known.
+type unit struct {
+	kind int
+	dim  dimension
+}
+
+const (
+	unknownUnit = iota // no unit information
+	plainNumber        // number without unit, goes with any unit
+	knownUnit
+)
+
+// unit symbols: SI factor and dimension
+var symbols = map[string]struct {
+	factor float64
+	dim    dimension
+}{
+	"m":   {1, dimension{1, 0, 0, 0, 0}},
+	"g":   {1e-3, dimension{0, 1, 0, 0, 0}},
+	"s":   {1, dimension{0, 0, 1, 0, 0}},
+	"A":   {1, dimension{0, 0, 0, 1, 0}},
+	"K":   {1, dimension{0, 0, 0, 0, 1}},
+	"Hz":  {1, dimension{0, 0, -1, 0, 0}},
+	"N":   {1, dimension{1, 1, -2, 0, 0}},
+	"J":   {1, dimension{2, 1, -2, 0, 0}},
+	"eV":  {1.602176634e-19, dimension{2, 1, -2, 0, 0}},
+	"W":   {1, dimension{2, 1, -3, 0, 0}},
+	"C":   {1, dimension{0, 0, 1, 1, 0}},
+	"V":   {1, dimension{2, 1, -3, -1, 0}},
+	"T":   {1, dimension{0, 1, -2, -1, 0}},
+	"rad": {1, dimension{}},
+	"deg": {math.Pi / 180, dimension{}},
+}
+
+var prefixes = map[string]float64{
+	"f": 1e-15, "p": 1e-12, "n": 1e-9, "u": 1e-6, "µ": 1e-6, "μ": 1e-6, "m": 1e-3, "c": 1e-2,
+	"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12,
+}
+
+// parseSymbol parses a unit symbol with optional prefix and exponent, like "nm" or "m3".
+func parseSymbol(sym string) (factor float64, dim dimension, ok bool) {
+	base := strings.TrimRight(sym, "0123456789")
+	exp := 1
+	if base != sym {
+		var err error
+		exp, err = strconv.Atoi(sym[len(base):])
+		if err != nil || exp == 0 {
+			return 0, dim, false
+		}
+	}
+	s, ok := symbols[base]
+	prefix := 1.0
+	if !ok {
+		_, size := utf8.DecodeRuneInString(base)
+		s, ok = symbols[base[size:]]
+		p, okp := prefixes[base[:size]]
+		ok, prefix = ok && okp, p
+	}
+	if !ok {
+		return 0, dim, false
+	}
+	factor = math.Pow(prefix*s.factor, float64(exp))
+	for i := range dim {
+		dim[i] = s.dim[i] * int8(exp)
+	}
+	return factor, dim, true
+}
+
+// parseUnit parses a unit like "nm", "A/m" or "J/m3".
+// Symbols are separated by * or /, a leading "1" is allowed: "1/s".
+func parseUnit(u string) (factor float64, dim dimension, ok bool) {
+	factor = 1
+	sign := int8(1) // -1 after /
+	start := 0
+	for i := 0; i <= len(u); i++ {
+		if i < len(u) && u[i] != '*' && u[i] != '/' {
+			continue
+		}
+		if sym := u[start:i]; !(start == 0 && sym == "1") {
+			f, d, ok := parseSymbol(sym)
+			if !ok {
+				return 0, dim, false
+			}
+			factor *= math.Pow(f, float64(sign))
+			for j := range dim {
+				dim[j] += sign * d[j]
+			}
+		}
+		if i < len(u) && u[i] == '/' {
+			sign = -1
+		} else {
+			sign = 1
+		}
+		start = i + 1
+	}
+	return factor, dim, u != ""
+}
+
+// named units used to print dimensions, most specific first.
+var unitNames = []string{"T", "A/m", "J/m3", "J/m2", "J/m", "J", "A/m2", "m/s", "Hz", "s", "m", "A", "K", "N", "W", "V", "C", "kg"}
+
+func (d dimension) String() string {
+	if d == (dimension{}) {
+		return "1"
+	}
+	for _, name := range unitNames {
+		if _, dim, _ := parseUnit(name); dim == d {
+			return name
+		}
+	}
+	var num, den []string
+	for i, e := range d {
+		switch {
+		case e == 1:
+			num = append(num, dimNames[i])
+		case e > 1:
+			num = append(num, fmt.Sprint(dimNames[i], e))
+		case e == -1:
+			den = append(den, dimNames[i])
+		case e < -1:
+			den = append(den, fmt.Sprint(dimNames[i], -e))
+		}
+	}
+	if len(num) == 0 {
+		num = []string{"1"}
+	}
+	return strings.Join(append([]string{strings.Join(num, "*")}, den...), "/")
+}
+
+// if n is a rewritten unit literal, returns its unit, see rewrite.
+func (w *World) unitLit(n *ast.BinaryExpr) (string, bool) {
+	if n.Op != token.MUL {
+		return "", false
+	}
+	lit, ok := n.Y.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING || !w.units[lit.Pos()] {
+		return "", false
+	}
+	u, _ := strconv.Unquote(lit.Value)
+	return u, true
+}
+
+// unit literals as printed by Format after rewrite, e.g.: (5*"nm"), (1e-9 * "m")
+var rewrittenUnitLit = regexp.MustCompile(`\(([0-9.][0-9a-zA-Z_.]*(?:[+-][0-9_]+)?) ?\* ?"([^"\\]*)"\)`)
+
+// turns rewritten unit literals back into their original form, e.g.: 5 nm.
+func formatUnitLits(src string) string {
+	return rewrittenUnitLit.ReplaceAllString(src, "$1 $2")
+}
+
+// compiles a unit literal like 5 nm to its value in SI units.
+func (w *World) compileUnitLit(n *ast.BinaryExpr, u string) Expr {
+	factor, _, ok := parseUnit(u)
+	if !ok {
+		panic(err(n.Pos(), "unknown unit:", u))
+	}
+	v := typeConv(n.Pos(), w.compileExpr(n.X), float64_t).Eval().(float64)
+	return floatLit(v * factor)
+}
+
+// unit of an expression that was compiled before
+func (w *World) unitOf(e ast.Expr) unit {
+	return w.exprUnits[e]
+}
+
+// records the unit of expression e, compiled to x,
+// based on the units of its operands, which were compiled before.
+// Panics with a compile error on mismatched units.
+func (w *World) inferUnit(e ast.Expr, x Expr) {
+	var u unit
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.INT || e.Kind == token.FLOAT {
+			u = unit{kind: plainNumber}
+		}
+	case *ast.ParenExpr:
+		u = w.unitOf(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			u = w.unitOf(e.X)
+		}
+	case *ast.Ident:
+		u = w.identUnit(x)
+	case *ast.BinaryExpr:
+		u = w.binaryUnit(e)
+	case *ast.CallExpr:
+		if f, ok := e.Fun.(*ast.Ident); ok && strings.ToLower(f.Name) == "vector" {
+			u = w.sameUnit(e.Args, func(a, b unit) string {
+				return fmt.Sprint("mismatched units in vector(): ", a.dim, " and ", b.dim)
+			})
+		}
+	}
+	if u.kind != unknownUnit {
+		w.exprUnits[e] = u
+	}
+}
+
+func (w *World) binaryUnit(n *ast.BinaryExpr) unit {
+	if s, ok := w.unitLit(n); ok {
+		_, dim, _ := parseUnit(s)
+		return unit{knownUnit, dim}
+	}
+	x, y := w.unitOf(n.X), w.unitOf(n.Y)
+	switch n.Op {
+	default:
+		return unit{}
+	case token.MUL:
+		return mulUnit(x, y, 1)
+	case token.QUO:
+		return mulUnit(x, y, -1)
+	case token.ADD, token.SUB, token.LSS, token.GTR, token.LEQ, token.GEQ, token.EQL, token.NEQ:
+		u := w.sameUnit([]ast.Expr{n.X, n.Y}, func(a, b unit) string {
+			return fmt.Sprint("mismatched units: ", a.dim, " ", n.Op, " ", b.dim)
+		})
+		if n.Op == token.ADD || n.Op == token.SUB {
+			return u
+		}
+		return unit{} // bool
+	}
+}
+
+// unit of x*y (sign=1) or x/y (sign=-1)
+func mulUnit(x, y unit, sign int8) unit {
+	if x.kind == unknownUnit || y.kind == unknownUnit {
+		return unit{}
+	}
+	if x.kind == plainNumber && y.kind == plainNumber {
+		return x
+	}
+	u := unit{kind: knownUnit}
+	for i := range u.dim {
+		u.dim[i] = x.dim[i] + sign*y.dim[i]
+	}
+	return u
+}
+
+// common unit of expressions that should have the same unit,
+// panics with the message returned by mismatch if they don't.
+func (w *World) sameUnit(args []ast.Expr, mismatch func(a, b unit) string) unit {
+	result := unit{kind: plainNumber}
+	unknown := false
+	for _, a := range args {
+		u := w.unitOf(a)
+		switch u.kind {
+		case unknownUnit:
+			unknown = true
+		case knownUnit:
+			if result.kind == knownUnit && result.dim != u.dim {
+				panic(err(a.Pos(), mismatch(result, u)))
+			}
+			result = u
+		}
+	}
+	if unknown {
+		return unit{}
+	}
+	return result
+}
+
+// unit of an identifier that resolved to x:
+// the unit of its initial value for variables declared in the script,
+// or the declared unit of native variables, which implement Unit() string.
+func (w *World) identUnit(x Expr) unit {
+	if u, ok := w.varUnits[x]; ok {
+		return u
+	}
+	if x, ok := x.(interface {
+		Unit() string
+	}); ok {
+		if _, dim, ok := parseUnit(x.Unit()); ok {
+			return unit{knownUnit, dim}
+		}
+	}
+	return unit{}
+}
+
+// checks that the value assigned to l, which is lhs in the source, has the same unit.
+func (w *World) checkAssignUnit(pos token.Pos, lhs ast.Expr, l LValue, rhs ast.Expr) {
+	lu, ru := w.identUnit(l), w.unitOf(rhs)
+	if lu.kind == knownUnit && ru.kind == knownUnit && lu.dim != ru.dim {
+		panic(err(pos, "cannot assign", ru.dim, "to", Format(lhs), "("+lu.dim.String()+")"))
+	}
+}
+
+// remembers the unit of a newly declared variable.
+func (w *World) declareUnit(l LValue, rhs ast.Expr) {
+	if u := w.unitOf(rhs); u.kind != unknownUnit {
+		if w.varUnits == nil {
+			w.varUnits = make(map[Expr]unit)
+		}
+		w.varUnits[l] = u
+	}
+}
@@ -3,6 +3,7 @@ package script
 
 import (
 	"fmt"
+	"go/ast"
 	"go/token"
 	"strings"
 )
@@ -11,7 +12,18 @@ import (
 // like declared variables and functions.
 type World struct {
 	*scope
-	toplevel *scope
+	toplevel  *scope
+	fn        *funcDef           // function being compiled, nil at top level
+	nLoop     int                // number of enclosing loops, for continue
+	nBreak    int                // number of enclosing loops and switch statements, for break
+	decls     map[token.Pos]bool // function declarations in the source being compiled, see rewrite
+	units     map[token.Pos]bool // unit literals in the source being compiled, see rewrite
+	exprUnits map[ast.Expr]unit  // units of the expressions compiled so far, see inferUnit
+	varUnits  map[Expr]unit      // units of variables declared in scripts
+	xref      *xref              // records identifiers for CompileRefs, nil otherwise
+	src       *srcMap            // source being compiled, for statement lines, nil in CompileExpr
+
+	AutoCall bool // call methods without arguments used as values, e.g. v.x for v.X() (mumax3-convert -expr)
 }
 
 // scope stores identifiers
@@ -0,0 +1,94 @@
+//+build ignore
+
+/*
+	Check the headers of NumPy output, as numpy.load expects them:
+	magic, version 1.0, a header dict padded to a multiple of 64 bytes,
+	and for .npz the metadata entries. npy.mx3 tests loading them back.
+*/
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	. "github.com/mumax/3/engine"
+	"github.com/mumax/3/util"
+)
+
+func main() {
+	defer InitAndClose()()
+
+	Eval(`setgridsize(32, 8, 2)
+		setcellsize(1e-9, 2e-9, 3e-9)
+		Msat = 800e3
+		m = uniform(1, 0, 0)
+		outputformat = NPY
+		saveas(m, "m")
+		saveas(Msat, "Msat")
+		outputformat = NPZ
+		saveas(m, "m")`)
+	LoadFile(OD() + "m.npz") // waits for the output to be written
+
+	b := read(OD() + "m.npy")
+	checkHeader("m.npy", b, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 8, 32, 3), }")
+	Expect("m.npy size", float64(len(b)), float64(headerLen(b)+4*2*8*32*3), 0)
+	x := math.Float32frombits(binary.LittleEndian.Uint32(b[headerLen(b):]))
+	Expect("m.npy mx", float64(x), 1, 0)
+
+	b = read(OD() + "Msat.npy")
+	checkHeader("Msat.npy", b, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 8, 32, 1), }")
+
+	b = read(OD() + "m.npz")
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	util.FatalErr(err)
+	entries := make(map[string][]byte)
+	for _, f := range z.File {
+		r, err := f.Open()
+		util.FatalErr(err)
+		entries[f.Name], err = ioutil.ReadAll(r)
+		util.FatalErr(err)
+		r.Close()
+	}
+	for _, e := range []string{"data.npy", "time.npy", "cellsize.npy", "name.npy", "unit.npy"} {
+		if entries[e] == nil {
+			util.Fatal("m.npz: missing entry", e)
+		}
+	}
+	checkHeader("data.npy", entries["data.npy"], "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 8, 32, 3), }")
+	checkHeader("time.npy", entries["time.npy"], "{'descr': '<f8', 'fortran_order': False, 'shape': (), }")
+	c := entries["cellsize.npy"]
+	checkHeader("cellsize.npy", c, "{'descr': '<f8', 'fortran_order': False, 'shape': (3,), }")
+	for i, want := range []float64{1e-9, 2e-9, 3e-9} {
+		have := math.Float64frombits(binary.LittleEndian.Uint64(c[headerLen(c)+8*i:]))
+		Expect("cellsize", have, want, 0)
+	}
+}
+
+// check magic, version, padding and header dict of .npy data
+func checkHeader(name string, b []byte, dict string) {
+	if !strings.HasPrefix(string(b), "\x93NUMPY\x01\x00") {
+		util.Fatalf("%v: bad magic or version: %q", name, b[:8])
+	}
+	n := headerLen(b)
+	Expect(name+" header alignment", float64(n%64), 0, 0)
+	header := string(b[10:n])
+	if !strings.HasSuffix(header, "\n") || strings.TrimRight(header, " \n") != dict {
+		util.Fatalf("%v: have header %q, want %q", name, header, dict)
+	}
+	LogOut(name, "header OK")
+}
+
+// length of magic, version and header, i.e. offset of the data
+func headerLen(b []byte) int {
+	return 10 + int(binary.LittleEndian.Uint16(b[8:10]))
+}
+
+func read(fname string) []byte {
+	b, err := ioutil.ReadFile(fname)
+	util.FatalErr(err)
+	return b
+}
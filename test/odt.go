@@ -0,0 +1,79 @@
+//+build ignore
+
+/*
+	TableFormat = ODT: runs odt.mx3 and checks its table.odt,
+	as read by OOMMF's mmGraph: header lines, column names and units
+	as Tcl lists, and the table terminated by "# Table End".
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	. "github.com/mumax/3/engine"
+	"github.com/mumax/3/odt"
+	"github.com/mumax/3/util"
+)
+
+func main() {
+	defer InitAndClose()()
+
+	od := OD() + "odt.out/"
+	run("-f", "-o", od, "odt.mx3")
+
+	b, err := ioutil.ReadFile(od + "table.odt")
+	util.FatalErr(err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	header := []string{
+		"# ODT 1.0",
+		"# Table Start",
+		"# Title: mumax3",
+		"# Columns: t mx my mz B_extx B_exty B_extz E_total MaxAngle {my var}",
+		"# Units: s {} {} {} T T T J rad {}",
+	}
+	for i, want := range header {
+		if lines[i] != want {
+			util.Fatalf("table.odt line %v: have %q, want %q", i+1, lines[i], want)
+		}
+	}
+	if end := lines[len(lines)-1]; end != "# Table End" {
+		util.Fatalf("table.odt: last line %q", end)
+	}
+
+	t, err := odt.ReadFile(od + "table.odt")
+	util.FatalErr(err)
+	units := map[string]string{"t": "s", "mx": "", "B_extz": "T", "E_total": "J", "MaxAngle": "rad", "my var": ""}
+	for name, unit := range units {
+		i := t.Column(name)
+		if i < 0 {
+			util.Fatal("table.odt: no column", name)
+		}
+		if t.Units[i] != unit {
+			util.Fatalf("table.odt: column %v has unit %q, want %q", name, t.Units[i], unit)
+		}
+	}
+	Expect("rows", float64(t.Rows()), 6, 0)
+	Expect("t", t.Data[t.Column("t")][t.Rows()-1], 50e-12, 0)
+	Expect("B_extz", t.Data[t.Column("B_extz")][0], 0.01, 0)
+	Expect("my var", t.Data[t.Column("my var")][0], 0.5, 0)
+}
+
+// runs mumax3 with the flags of this program, followed by args.
+func run(args ...string) {
+	var flags []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "o" && f.Name != "f" {
+			flags = append(flags, fmt.Sprintf("-%v=%v", f.Name, f.Value))
+		}
+	})
+	cmd := exec.Command("mumax3", append(flags, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	LogOut("mumax3", cmd.Args[1:])
+	util.FatalErr(cmd.Run())
+}
@@ -0,0 +1,74 @@
+//+build ignore
+
+/*
+	Checkpoint and -resume: runs checkpoint.mx3 in full, then again resumed
+	from its checkpoint. The resumed run must leave the same output behind,
+	without duplicate table rows, Fprintln lines or OVF segments.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	. "github.com/mumax/3/engine"
+	"github.com/mumax/3/oommf"
+	"github.com/mumax/3/util"
+)
+
+func main() {
+	defer InitAndClose()()
+
+	od := OD() + "checkpoint.out/"
+	run("-f", "-o", od, "checkpoint.mx3")
+	files := []string{"table.txt", "before.txt", "after.txt", "m.ovf", "state.chk"}
+	full := make(map[string][]byte)
+	for _, f := range files {
+		full[f] = read(od + f)
+	}
+
+	run("-o", od, "-resume", od+"checkpoint.zip", "checkpoint.mx3")
+	for _, f := range files {
+		Expect(f+" size", float64(len(read(od+f))), float64(len(full[f])), 0)
+		Expect(f+" unchanged", bool2float(string(read(od+f)) == string(full[f])), 1, 0)
+	}
+
+	in, err := os.Open(od + "m.ovf")
+	util.FatalErr(err)
+	defer in.Close()
+	slices, info, err := oommf.ReadAll(in)
+	util.FatalErr(err)
+	Expect("m.ovf segments", float64(len(slices)), 12, 0)
+	Expect("m.ovf segment count", float64(info[0].SegmentCount), 12, 0)
+}
+
+// runs mumax3 with the flags of this program, followed by args.
+func run(args ...string) {
+	var flags []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "o" && f.Name != "f" && f.Name != "resume" {
+			flags = append(flags, fmt.Sprintf("-%v=%v", f.Name, f.Value))
+		}
+	})
+	cmd := exec.Command("mumax3", append(flags, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	LogOut("mumax3", cmd.Args[1:])
+	util.FatalErr(cmd.Run())
+}
+
+func read(fname string) []byte {
+	b, err := ioutil.ReadFile(fname)
+	util.FatalErr(err)
+	return b
+}
+
+func bool2float(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
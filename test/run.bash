@@ -4,5 +4,9 @@ set -e
 
 mumax3 -vet *.mx3
 
-mumax3 -paranoid=false -failfast -cache /tmp -f -http "" *.go *.mx3
+shopt -s extglob
+mumax3 -paranoid=false -failfast -cache /tmp -f -http "" *.go !(maxregions).mx3
+
+# more than 256 regions need the CPU backend, see SetMaxRegions
+mumax3 -cpu -paranoid=false -failfast -cache /tmp -f -http "" maxregions.mx3
 
@@ -0,0 +1,165 @@
+package trimesh
+
+import "math"
+
+// Solid answers whether points lie inside a closed mesh,
+// by counting the crossings of a ray from the point along +z with the surface (ray parity).
+// Triangles are projected onto the xy plane and binned in a grid,
+// so that only a few of them need to be tested per point.
+// Points on an edge or vertex shared by projected triangles are attributed to exactly one of them,
+// so rays through edges and vertices, common on regular grids, count correctly.
+type Solid struct {
+	tris     []projTri
+	min, max Vector
+	nx, ny   int
+	cellx    float64
+	celly    float64
+	bins     [][]int32 // triangles overlapping each grid cell
+}
+
+// triangle projected onto xy
+type projTri struct {
+	min, max [2]float64 // bounding box
+	e        [3]edgeFn
+	plane    [3]float64 // z = plane[0] + plane[1]*x + plane[2]*y
+}
+
+// edge of a counter-clockwise projected triangle:
+// f(x, y) = sign*(dx*(y-ay) - dy*(x-ax)) is positive on the inside.
+// a is the lowest endpoint, see less, so that neighbouring triangles
+// sharing the edge evaluate exactly the same f, up to sign.
+type edgeFn struct {
+	ax, ay, dx, dy, sign float64
+	own                  bool // points on the edge belong to the triangle, see topLeft
+}
+
+func newProjTri(a, b, c Vector) projTri {
+	var t projTri
+	for i, v := range [3]Vector{a, b, c} {
+		for j := 0; j < 2; j++ {
+			if i == 0 || v[j] < t.min[j] {
+				t.min[j] = v[j]
+			}
+			if i == 0 || v[j] > t.max[j] {
+				t.max[j] = v[j]
+			}
+		}
+	}
+	for i, e := range [3][2]Vector{{a, b}, {b, c}, {c, a}} {
+		p, q, sign := e[0], e[1], 1.0
+		if less(q, p) {
+			p, q, sign = q, p, -1
+		}
+		t.e[i] = edgeFn{p[0], p[1], q[0] - p[0], q[1] - p[1], sign, topLeft(e[0], e[1])}
+	}
+	// normal n = (b-a) x (c-a), n.(r-a) = 0
+	u, v := Vector{b[0] - a[0], b[1] - a[1], b[2] - a[2]}, Vector{c[0] - a[0], c[1] - a[1], c[2] - a[2]}
+	nx, ny, nz := u[1]*v[2]-u[2]*v[1], u[2]*v[0]-u[0]*v[2], u[0]*v[1]-u[1]*v[0]
+	t.plane = [3]float64{a[2] + (nx*a[0]+ny*a[1])/nz, -nx / nz, -ny / nz}
+	return t
+}
+
+// whether x, y lies inside the projected triangle, with the tie-breaking rule for edges.
+func (t *projTri) contains(x, y float64) bool {
+	if x < t.min[0] || x > t.max[0] || y < t.min[1] || y > t.max[1] {
+		return false
+	}
+	for i := range t.e {
+		e := &t.e[i]
+		f := e.sign * (e.dx*(y-e.ay) - e.dy*(x-e.ax))
+		if f < 0 || f == 0 && !e.own {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSolid prepares m for inside tests.
+func NewSolid(m *Mesh) *Solid {
+	s := new(Solid)
+	s.min, s.max = m.Bounds()
+	for _, t := range m.Triangles {
+		a, b, c := t[0], t[1], t[2]
+		ar := area(a, b, c)
+		if ar == 0 {
+			continue // vertical, never crossed
+		}
+		if ar < 0 {
+			b, c = c, b
+		}
+		s.tris = append(s.tris, newProjTri(a, b, c))
+	}
+
+	// bins about half the size of a triangle, so that only a few overlap each bin
+	n := 2*int(math.Sqrt(float64(len(s.tris)))) + 1
+	if n > 1024 {
+		n = 1024
+	}
+	s.nx, s.ny = n, n
+	s.cellx = (s.max[0] - s.min[0]) / float64(n)
+	s.celly = (s.max[1] - s.min[1]) / float64(n)
+	if !(s.cellx > 0 && s.celly > 0) { // flat or empty mesh
+		s.cellx, s.celly = 1, 1
+	}
+	s.bins = make([][]int32, n*n)
+	for i, t := range s.tris {
+		x0, y0 := s.bin(t.min[0], t.min[1])
+		x1, y1 := s.bin(t.max[0], t.max[1])
+		for iy := y0; iy <= y1; iy++ {
+			for ix := x0; ix <= x1; ix++ {
+				s.bins[iy*s.nx+ix] = append(s.bins[iy*s.nx+ix], int32(i))
+			}
+		}
+	}
+	return s
+}
+
+// grid cell containing x, y, clamped to the grid.
+func (s *Solid) bin(x, y float64) (ix, iy int) {
+	return clamp(int((x-s.min[0])/s.cellx), s.nx), clamp(int((y-s.min[1])/s.celly), s.ny)
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// Inside returns whether x, y, z lies inside the mesh.
+// It is safe for concurrent use.
+func (s *Solid) Inside(x, y, z float64) bool {
+	if x < s.min[0] || x > s.max[0] || y < s.min[1] || y > s.max[1] || z < s.min[2] || z > s.max[2] {
+		return false
+	}
+	ix, iy := s.bin(x, y)
+	above := 0 // crossings above z
+	for _, i := range s.bins[iy*s.nx+ix] {
+		t := &s.tris[i]
+		if t.contains(x, y) && t.plane[0]+t.plane[1]*x+t.plane[2]*y > z {
+			above++
+		}
+	}
+	return above%2 == 1
+}
+
+// twice the signed area of triangle a, b, c projected onto xy, positive if counter-clockwise.
+func area(a, b, c Vector) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// lexicographic order in xy
+func less(a, b Vector) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+// tie-breaking rule for points on edge a->b of a counter-clockwise triangle:
+// they belong to the triangle if the edge points down, or left if horizontal.
+// The opposite edge b->a of a neighbouring triangle then does not own them.
+func topLeft(a, b Vector) bool {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	return dy < 0 || dy == 0 && dx < 0
+}
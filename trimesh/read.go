@@ -0,0 +1,211 @@
+// Package trimesh reads triangulated surfaces from STL and Wavefront OBJ files
+// and tests whether points lie inside them.
+package trimesh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Vector [3]float64
+
+type Triangle [3]Vector
+
+// Mesh is a triangulated surface. It should be closed to have a well-defined inside,
+// but the orientation of the triangles does not matter.
+type Mesh struct {
+	Triangles []Triangle
+}
+
+// Read reads a mesh in the given format: "stl" (ASCII or binary) or "obj".
+func Read(in io.Reader, format string) (*Mesh, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	default:
+		return nil, fmt.Errorf("trimesh: unknown format: %q", format)
+	case "stl":
+		return ReadSTL(in)
+	case "obj":
+		return ReadOBJ(in)
+	}
+}
+
+// ReadSTL reads an ASCII or binary STL file.
+func ReadSTL(in io.Reader) (*Mesh, error) {
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	// binary files may start with "solid" too, so check the size first.
+	if len(buf) >= 84 {
+		n := int(binary.LittleEndian.Uint32(buf[80:84]))
+		if len(buf) == 84+50*n {
+			return readBinarySTL(buf[84:], n), nil
+		}
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(buf), []byte("solid")) {
+		return nil, fmt.Errorf("trimesh: not an STL file")
+	}
+	return readASCIISTL(buf)
+}
+
+// binary STL: per triangle a normal, 3 vertices as float32 and a 2-byte attribute.
+func readBinarySTL(buf []byte, n int) *Mesh {
+	m := &Mesh{Triangles: make([]Triangle, n)}
+	for i := range m.Triangles {
+		rec := buf[50*i+12:] // skip normal
+		for v := 0; v < 3; v++ {
+			for c := 0; c < 3; c++ {
+				bits := binary.LittleEndian.Uint32(rec[4*(3*v+c):])
+				m.Triangles[i][v][c] = float64(math.Float32frombits(bits))
+			}
+		}
+	}
+	return m
+}
+
+// ASCII STL: "vertex x y z" lines, grouped per 3 in "facet ... endfacet".
+func readASCIISTL(buf []byte) (*Mesh, error) {
+	m := new(Mesh)
+	var (
+		t    Triangle
+		nv   int // vertices read for t
+		line int
+	)
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		line++
+		f := strings.Fields(s.Text())
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "vertex":
+			if nv == 3 {
+				return nil, fmt.Errorf("trimesh: line %v: facet with more than 3 vertices", line)
+			}
+			v, err := parseVector(f[1:])
+			if err != nil {
+				return nil, fmt.Errorf("trimesh: line %v: %v", line, err)
+			}
+			t[nv] = v
+			nv++
+		case "endfacet":
+			if nv != 3 {
+				return nil, fmt.Errorf("trimesh: line %v: facet with %v vertices", line, nv)
+			}
+			m.Triangles = append(m.Triangles, t)
+			nv = 0
+		}
+	}
+	return m, s.Err()
+}
+
+// ReadOBJ reads the vertices ("v") and faces ("f") of a Wavefront OBJ file.
+// Polygons are split into triangles, everything else is ignored.
+func ReadOBJ(in io.Reader) (*Mesh, error) {
+	m := new(Mesh)
+	var verts []Vector
+	s := bufio.NewScanner(in)
+	line := 0
+	for s.Scan() {
+		line++
+		f := strings.Fields(s.Text())
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "v":
+			if len(f) < 4 {
+				return nil, fmt.Errorf("trimesh: line %v: vertex needs 3 coordinates", line)
+			}
+			v, err := parseVector(f[1:4]) // ignore optional w
+			if err != nil {
+				return nil, fmt.Errorf("trimesh: line %v: %v", line, err)
+			}
+			verts = append(verts, v)
+		case "f":
+			if len(f) < 4 {
+				return nil, fmt.Errorf("trimesh: line %v: face needs at least 3 vertices", line)
+			}
+			face := make([]Vector, len(f)-1)
+			for i, ref := range f[1:] {
+				v, err := objVertex(verts, ref)
+				if err != nil {
+					return nil, fmt.Errorf("trimesh: line %v: %v", line, err)
+				}
+				face[i] = v
+			}
+			for i := 1; i+1 < len(face); i++ {
+				m.Triangles = append(m.Triangles, Triangle{face[0], face[i], face[i+1]})
+			}
+		}
+	}
+	return m, s.Err()
+}
+
+// vertex referred to by a face, like "7", "7/1/2" or "-1" (last vertex).
+func objVertex(verts []Vector, ref string) (Vector, error) {
+	if i := strings.Index(ref, "/"); i >= 0 {
+		ref = ref[:i]
+	}
+	i, err := strconv.Atoi(ref)
+	if err != nil {
+		return Vector{}, err
+	}
+	if i < 0 {
+		i += len(verts) + 1
+	}
+	if i < 1 || i > len(verts) {
+		return Vector{}, fmt.Errorf("vertex index out of range: %v", ref)
+	}
+	return verts[i-1], nil
+}
+
+func parseVector(f []string) (Vector, error) {
+	var v Vector
+	if len(f) != 3 {
+		return v, fmt.Errorf("need 3 coordinates, have %v", len(f))
+	}
+	for i := range v {
+		var err error
+		v[i], err = strconv.ParseFloat(f[i], 64)
+		if err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// Bounds returns the corners of the bounding box.
+func (m *Mesh) Bounds() (min, max Vector) {
+	for i := range min {
+		min[i], max[i] = math.Inf(1), math.Inf(-1)
+	}
+	for _, t := range m.Triangles {
+		for _, v := range t {
+			for i := range v {
+				min[i] = math.Min(min[i], v[i])
+				max[i] = math.Max(max[i], v[i])
+			}
+		}
+	}
+	return
+}
+
+// Transform replaces each vertex v by v*scale + offset.
+func (m *Mesh) Transform(scale float64, offset Vector) {
+	for i := range m.Triangles {
+		for j := range m.Triangles[i] {
+			for c := range m.Triangles[i][j] {
+				m.Triangles[i][j][c] = m.Triangles[i][j][c]*scale + offset[c]
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package trimesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// unit cube [0,1]^3, faces split along a diagonal
+var cube = Mesh{[]Triangle{
+	{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}}, {{0, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+	{{0, 0, 1}, {1, 1, 1}, {1, 0, 1}}, {{0, 0, 1}, {0, 1, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {1, 0, 1}, {1, 0, 0}}, {{0, 0, 0}, {0, 0, 1}, {1, 0, 1}},
+	{{0, 1, 0}, {1, 1, 0}, {1, 1, 1}}, {{0, 1, 0}, {1, 1, 1}, {0, 1, 1}},
+	{{0, 0, 0}, {0, 1, 0}, {0, 1, 1}}, {{0, 0, 0}, {0, 1, 1}, {0, 0, 1}},
+	{{1, 0, 0}, {1, 1, 1}, {1, 1, 0}}, {{1, 0, 0}, {1, 0, 1}, {1, 1, 1}},
+}}
+
+func asciiSTL(m *Mesh) string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "solid test")
+	for _, t := range m.Triangles {
+		fmt.Fprintln(&b, "  facet normal 0 0 0\n    outer loop")
+		for _, v := range t {
+			fmt.Fprintln(&b, "      vertex", v[0], v[1], v[2])
+		}
+		fmt.Fprintln(&b, "    endloop\n  endfacet")
+	}
+	fmt.Fprintln(&b, "endsolid test")
+	return b.String()
+}
+
+func binarySTL(m *Mesh) []byte {
+	var b bytes.Buffer
+	header := make([]byte, 80)
+	copy(header, "solid looks like ASCII but is not")
+	b.Write(header)
+	binary.Write(&b, binary.LittleEndian, uint32(len(m.Triangles)))
+	for _, t := range m.Triangles {
+		binary.Write(&b, binary.LittleEndian, [3]float32{})
+		for _, v := range t {
+			binary.Write(&b, binary.LittleEndian, [3]float32{float32(v[0]), float32(v[1]), float32(v[2])})
+		}
+		binary.Write(&b, binary.LittleEndian, uint16(0))
+	}
+	return b.Bytes()
+}
+
+func TestReadSTL(t *testing.T) {
+	for _, in := range [][]byte{[]byte(asciiSTL(&cube)), binarySTL(&cube)} {
+		m, err := Read(bytes.NewReader(in), ".STL")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Triangles) != len(cube.Triangles) {
+			t.Fatal("got", len(m.Triangles), "triangles")
+		}
+		for i := range m.Triangles {
+			if m.Triangles[i] != cube.Triangles[i] {
+				t.Error("triangle", i, ":", m.Triangles[i], "want", cube.Triangles[i])
+			}
+		}
+	}
+}
+
+// octahedron |x|+|y|+|z| <= 1, with shared vertices on the z axis.
+const octahedron = `# test
+v 1 0 0
+v -1 0 0
+v 0 1 0
+v 0 -1 0
+v 0 0 1
+v 0 0 -1
+f 1 3 5
+f 3 2 5
+f 2 4 5
+f 4/1 1/2 5/3
+f 3 1 6
+f 2 3 6
+f 4 2 -1
+f 1 4 -1
+`
+
+func TestInside(t *testing.T) {
+	c := NewSolid(&cube)
+	// regular grid, with rays through the cube's edges and the face diagonals
+	n := 0
+	for z := 0.05; z < 1; z += 0.1 {
+		for y := -0.5; y <= 1.5; y += 0.25 {
+			for x := -0.5; x <= 1.5; x += 0.25 {
+				in := c.Inside(x, y, z)
+				want := x > 0 && x < 1 && y > 0 && y < 1
+				if x == 0 || x == 1 || y == 0 || y == 1 {
+					continue // on the surface
+				}
+				if in != want {
+					t.Error("cube: inside", x, y, z, ":", in)
+				}
+				if in {
+					n++
+				}
+			}
+		}
+	}
+	if n != 9*10 {
+		t.Error("cube: got", n, "points inside")
+	}
+
+	m, err := ReadOBJ(strings.NewReader(octahedron))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Triangles) != 8 {
+		t.Fatal("got", len(m.Triangles), "triangles")
+	}
+	o := NewSolid(m)
+	if !o.Inside(0, 0, 0) || !o.Inside(0, 0, 0.5) || o.Inside(0, 0, 1.5) {
+		t.Error("octahedron: ray through vertices")
+	}
+	if !o.Inside(0.25, 0, 0) || !o.Inside(0, -0.25, 0.25) {
+		t.Error("octahedron: ray through edges")
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		x, y, z := 2*rng.Float64()-1, 2*rng.Float64()-1, 2*rng.Float64()-1
+		r := math.Abs(x) + math.Abs(y) + math.Abs(z)
+		if math.Abs(r-1) < 1e-9 {
+			continue
+		}
+		if in := o.Inside(x, y, z); in != (r < 1) {
+			t.Error("octahedron: inside", x, y, z, ":", in)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	m := Mesh{append([]Triangle(nil), cube.Triangles...)}
+	m.Transform(1e-9, Vector{-0.5e-9, -0.5e-9, -0.5e-9})
+	min, max := m.Bounds()
+	if min != (Vector{-0.5e-9, -0.5e-9, -0.5e-9}) || max != (Vector{0.5e-9, 0.5e-9, 0.5e-9}) {
+		t.Error("bounds:", min, max)
+	}
+	if !NewSolid(&m).Inside(0, 0, 0) {
+		t.Error("center not inside")
+	}
+}
@@ -6,26 +6,92 @@ import (
 	"fmt"
 	"log"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 func Fatal(msg ...interface{}) {
-	log.Fatal(msg...)
+	fatal(fmt.Sprint(msg...))
 }
 
 func Fatalf(format string, msg ...interface{}) {
-	log.Fatalf(format, msg...)
+	fatal(fmt.Sprintf(format, msg...))
 }
 
 // If err != nil, trigger log.Fatal(msg, err)
 func FatalErr(err interface{}) {
 	_, file, line, _ := runtime.Caller(1)
 	if err != nil {
-		log.Fatal(file, ":", line, err)
+		fatal(fmt.Sprint(file, ":", line, err))
 	}
 }
 
+// Error of Fatal, Fatalf or FatalErr, returned by CatchFatal.
+type FatalError string
+
+func (e FatalError) Error() string { return string(e) }
+
+var (
+	catching  = make(map[int64]int) // number of CatchFatal calls running, by goroutine id
+	catchLock sync.Mutex
+)
+
+// CatchFatal runs f, and returns the error if f calls Fatal, Fatalf or FatalErr
+// instead of exiting. Used for commands typed interactively, where a mistake
+// should not end the session. Only Fatal in the calling goroutine is caught,
+// in other goroutines (e.g. async output) it still exits.
+// f may have been interrupted half-way, leaving its changes incomplete.
+func CatchFatal(f func()) (err error) {
+	id := goid()
+	catchLock.Lock()
+	catching[id]++
+	catchLock.Unlock()
+	defer func() {
+		catchLock.Lock()
+		catching[id]--
+		if catching[id] == 0 {
+			delete(catching, id)
+		}
+		catchLock.Unlock()
+	}()
+	defer func() {
+		if e := recover(); e != nil {
+			fe, ok := e.(FatalError)
+			if !ok {
+				panic(e)
+			}
+			err = fe
+		}
+	}()
+	f()
+	return nil
+}
+
+func fatal(msg string) {
+	catchLock.Lock()
+	caught := catching[goid()] > 0
+	catchLock.Unlock()
+	if caught {
+		panic(FatalError(msg))
+	}
+	log.Fatal(msg)
+}
+
+// id of the calling goroutine, from the first line of its stack trace:
+// "goroutine 18 [running]:".
+func goid() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	f := strings.Fields(string(buf[:n]))
+	if len(f) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseInt(f[1], 10, 64)
+	return id
+}
+
 // Panics if err is not nil. Signals a bug.
 func PanicErr(err error) {
 	if err != nil {